@@ -2,21 +2,37 @@ package glu
 
 import (
 	"context"
+	"crypto"
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"strings"
+	"sync"
 
 	"github.com/get-glu/glu/internal/git"
 	"github.com/get-glu/glu/internal/oci"
+	"github.com/get-glu/glu/internal/oci/platform"
 	"github.com/get-glu/glu/pkg/config"
 	"github.com/get-glu/glu/pkg/containers"
 	"github.com/get-glu/glu/pkg/credentials"
 	"github.com/get-glu/glu/pkg/kv/bolt"
+	"github.com/get-glu/glu/pkg/kv/redis"
+	"github.com/get-glu/glu/pkg/kv/sqlite"
 	srcgit "github.com/get-glu/glu/pkg/phases/git"
+	"github.com/get-glu/glu/pkg/phases/oci/verify"
+	"github.com/get-glu/glu/pkg/resources/file"
+	"github.com/get-glu/glu/pkg/resources/helm"
+	"github.com/get-glu/glu/pkg/resources/kustomize"
+	"github.com/get-glu/glu/pkg/scm/bitbucket"
+	"github.com/get-glu/glu/pkg/scm/gitea"
 	"github.com/get-glu/glu/pkg/scm/github"
+	"github.com/get-glu/glu/pkg/scm/gitlab"
+	"github.com/get-glu/glu/pkg/scm/links"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	goredis "github.com/redis/go-redis/v9"
 	giturls "github.com/whilp/git-urls"
 )
 
@@ -27,11 +43,16 @@ type Config struct {
 	conf  *config.Config
 	creds *credentials.CredentialSource
 
+	// mu guards the git repository and proposer caches, which are read by
+	// the server (e.g. on webhooks) while repositories are being constructed
+	mu    sync.Mutex
 	cache struct {
 		oci      map[string]*oci.Repository
 		repo     map[string]*git.Repository
 		proposer map[string]srcgit.Proposer
 		bolt     map[string]*bolt.DB
+		sqlite   map[string]*sqlite.DB
+		redis    map[string]*redis.DB
 	}
 }
 
@@ -46,6 +67,8 @@ func newConfigSource(ctx context.Context, conf *config.Config) *Config {
 	c.cache.repo = map[string]*git.Repository{}
 	c.cache.proposer = map[string]srcgit.Proposer{}
 	c.cache.bolt = map[string]*bolt.DB{}
+	c.cache.sqlite = map[string]*sqlite.DB{}
+	c.cache.redis = map[string]*redis.DB{}
 
 	return c
 }
@@ -61,6 +84,9 @@ func (c *Config) GitRepository(name string) (_ *git.Repository, proposer srcgit.
 		}
 	}()
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	// check cache for previously built repository
 	if repo, ok := c.cache.repo[name]; ok {
 		return repo, c.cache.proposer[name], nil
@@ -88,6 +114,7 @@ func (c *Config) GitRepository(name string) (_ *git.Repository, proposer srcgit.
 		srcOpts = append(srcOpts,
 			git.WithRemote(conf.Remote.Name, conf.Remote.URL),
 			git.WithInterval(conf.Remote.Interval),
+			git.WithMaxPushAttempts(conf.Remote.MaxPushAttempts),
 		)
 
 		if conf.Remote.Credential != "" {
@@ -103,6 +130,28 @@ func (c *Config) GitRepository(name string) (_ *git.Repository, proposer srcgit.
 		}
 	}
 
+	if conf.Author != nil {
+		srcOpts = append(srcOpts, git.WithSignature(conf.Author.Name, conf.Author.Email))
+	}
+
+	if conf.Committer != nil {
+		srcOpts = append(srcOpts, git.WithCommitter(conf.Committer.Name, conf.Committer.Email))
+	}
+
+	if conf.Signing != nil {
+		creds, err := c.creds.Get(conf.Signing.Credential)
+		if err != nil {
+			return nil, nil, fmt.Errorf("repository %q: %w", name, err)
+		}
+
+		signer, err := creds.CommitSigner()
+		if err != nil {
+			return nil, nil, fmt.Errorf("repository %q: %w", name, err)
+		}
+
+		srcOpts = append(srcOpts, git.WithSigner(signer))
+	}
+
 	if method == nil {
 		method, err = ssh.DefaultAuthBuilder("git")
 		if err != nil {
@@ -116,51 +165,207 @@ func (c *Config) GitRepository(name string) (_ *git.Repository, proposer srcgit.
 	}
 
 	if conf.Proposals != nil {
-		repoURL, err := giturls.Parse(conf.Remote.URL)
+		proposer, err = c.newProposer(conf)
 		if err != nil {
 			return nil, nil, err
 		}
+	}
+
+	c.cache.repo[name] = repo
+	c.cache.proposer[name] = proposer
+
+	return repo, proposer, nil
+}
 
-		parts := strings.SplitN(strings.TrimPrefix(repoURL.Path, "/"), "/", 2)
-		if len(parts) < 2 {
-			return nil, nil, fmt.Errorf("unexpected repository URL path: %q", repoURL.Path)
+// gitRepositoriesMatching returns the previously built git repositories (keyed by name)
+// whose remote URL satisfies the provided match function.
+// Repositories without a remote are never returned.
+func (c *Config) gitRepositoriesMatching(match func(remote string) bool) map[string]*git.Repository {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	repos := map[string]*git.Repository{}
+	for name, repo := range c.cache.repo {
+		remote := repo.Remote()
+		if remote == nil || len(remote.URLs) == 0 || !match(remote.URLs[0]) {
+			continue
 		}
 
-		var (
-			repoOwner = parts[0]
-			repoName  = strings.TrimSuffix(parts[1], ".git")
-		)
+		repos[name] = repo
+	}
 
-		var proposalsEnabled bool
-		if proposalsEnabled = conf.Proposals.Credential != ""; proposalsEnabled {
-			creds, err := c.creds.Get(conf.Proposals.Credential)
-			if err != nil {
-				return nil, nil, err
-			}
+	return repos
+}
 
-			client, err := creds.GitHubClient(c.ctx)
-			if err != nil {
-				return nil, nil, err
-			}
+// GitLinkProvider constructs a link provider for the named git repository
+// using the builtin templates for its (configured or detected) provider along
+// with any configured template overrides.
+// It returns nil when no links are configured and the provider cannot be determined.
+func (c *Config) GitLinkProvider(name string) (_ srcgit.LinkProvider, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("git %q: links: %w", name, err)
+		}
+	}()
+
+	conf, ok := c.conf.Sources.Git[name]
+	if !ok {
+		return nil, errors.New("configuration not found")
+	}
+
+	if conf.Remote == nil {
+		return nil, nil
+	}
+
+	repo, err := links.ParseRepository(conf.Remote.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		l         = conf.Links
+		templates links.Templates
+		provider  string
+	)
 
-			proposer = github.New(
-				client,
-				repoOwner,
-				repoName,
-			)
+	if l != nil {
+		if l.BaseURL != "" {
+			repo.BaseURL = strings.TrimSuffix(l.BaseURL, "/")
 		}
 
-		slog.Debug("configured scm proposer",
-			slog.String("owner", repoOwner),
-			slog.String("name", repoName),
-			slog.Bool("proposals_enabled", proposalsEnabled),
-		)
+		templates = links.Templates{Commit: l.Commit, Branch: l.Branch, Compare: l.Compare, File: l.File}
+		provider = l.Provider
 	}
 
-	c.cache.repo[name] = repo
-	c.cache.proposer[name] = proposer
+	if provider == "" {
+		provider, _ = links.DetectProvider(repo)
+	}
 
-	return repo, proposer, nil
+	if provider == "" && conf.Proposals != nil {
+		provider = conf.Proposals.Provider
+	}
+
+	if provider == "" {
+		if l == nil {
+			return nil, nil
+		}
+
+		// purely template defined links
+		return links.New(repo, templates)
+	}
+
+	return links.NewBuiltin(provider, repo, templates)
+}
+
+// newProposer constructs the proposer for the configured provider
+// using the owner and name (or full project path) derived from the remote URL.
+func (c *Config) newProposer(conf *config.GitRepository) (srcgit.Proposer, error) {
+	if conf.Remote == nil {
+		return nil, errors.New("proposals: remote is required")
+	}
+
+	repoURL, err := giturls.Parse(conf.Remote.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	repoPath := strings.TrimSuffix(strings.TrimPrefix(repoURL.Path, "/"), ".git")
+	if conf.Proposals.Provider == config.ProposalsProviderBitbucketDataCenter {
+		// bitbucket data center serves http clones from /scm/<project>/<repo>
+		repoPath = strings.TrimPrefix(repoPath, "scm/")
+	}
+
+	parts := strings.SplitN(repoPath, "/", 2)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("unexpected repository URL path: %q", repoURL.Path)
+	}
+
+	var (
+		repoOwner = parts[0]
+		repoName  = parts[1]
+	)
+
+	proposalsEnabled := conf.Proposals.Credential != ""
+
+	slog.Debug("configured scm proposer",
+		slog.String("provider", conf.Proposals.Provider),
+		slog.String("owner", repoOwner),
+		slog.String("name", repoName),
+		slog.Bool("proposals_enabled", proposalsEnabled),
+	)
+
+	if !proposalsEnabled {
+		return nil, nil
+	}
+
+	creds, err := c.creds.Get(conf.Proposals.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := func(path string) string {
+		if conf.Proposals.BaseURL != "" {
+			return conf.Proposals.BaseURL
+		}
+
+		return links.BaseURL(repoURL) + path
+	}
+
+	switch conf.Proposals.Provider {
+	case config.ProposalsProviderGitLab:
+		client, err := creds.HTTPClient(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return gitlab.New(client, baseURL("/api/v4"), repoPath), nil
+	case config.ProposalsProviderGitea:
+		client, err := creds.HTTPClient(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return gitea.New(client, baseURL("/api/v1"), repoOwner, repoName), nil
+	case config.ProposalsProviderBitbucket, config.ProposalsProviderBitbucketDataCenter:
+		client, err := creds.HTTPClient(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var opts []containers.Option[bitbucket.SCM]
+		if len(conf.Proposals.Reviewers) > 0 {
+			opts = append(opts, bitbucket.WithReviewers(conf.Proposals.Reviewers...))
+		}
+
+		if conf.Proposals.DefaultReviewers {
+			opts = append(opts, bitbucket.WithDefaultReviewers())
+		}
+
+		if conf.Proposals.Provider == config.ProposalsProviderBitbucketDataCenter {
+			return bitbucket.NewDataCenter(client, baseURL(""), repoOwner, repoName, opts...), nil
+		}
+
+		cloudURL := bitbucket.CloudBaseURL
+		if conf.Proposals.BaseURL != "" {
+			cloudURL = conf.Proposals.BaseURL
+		}
+
+		return bitbucket.NewCloud(client, cloudURL, repoOwner, repoName, opts...), nil
+	default:
+		client, err := creds.GitHubClient(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if conf.Proposals.BaseURL != "" {
+			// support for GitHub Enterprise Server
+			if client, err = client.WithEnterpriseURLs(conf.Proposals.BaseURL, conf.Proposals.BaseURL); err != nil {
+				return nil, err
+			}
+		}
+
+		return github.New(client, repoOwner, repoName), nil
+	}
 }
 
 // OCIRepository constructs and configures an instance of a *oci.Repository
@@ -186,7 +391,35 @@ func (c *Config) OCIRepository(name string) (_ *oci.Repository, err error) {
 		}
 	}
 
-	repo, err := oci.New(conf.Reference, cred)
+	opts := []containers.Option[oci.Repository]{oci.WithInterval(conf.Interval)}
+	if conf.DisablePolling {
+		opts = append(opts, oci.WithInterval(0))
+	}
+
+	if conf.Verification != nil {
+		verifier, err := newVerifier(conf.Verification)
+		if err != nil {
+			return nil, fmt.Errorf("oci %q: %w", name, err)
+		}
+
+		opts = append(opts, oci.WithVerifier(verifier))
+	}
+
+	if len(conf.Platforms) > 0 {
+		platforms := make([]v1.Platform, 0, len(conf.Platforms))
+		for _, s := range conf.Platforms {
+			p, err := platform.Parse(s)
+			if err != nil {
+				return nil, fmt.Errorf("oci %q: %w", name, err)
+			}
+
+			platforms = append(platforms, p)
+		}
+
+		opts = append(opts, oci.WithPlatforms(conf.RequirePlatforms, platforms...))
+	}
+
+	repo, err := oci.New(conf.Reference, cred, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -196,6 +429,31 @@ func (c *Config) OCIRepository(name string) (_ *oci.Repository, err error) {
 	return repo, nil
 }
 
+func newVerifier(conf *config.OCIVerification) (*verify.Verifier, error) {
+	var keys []crypto.PublicKey
+	for _, k := range conf.PublicKeys {
+		data := []byte(k.Bytes)
+		if k.Path != "" {
+			var err error
+			if data, err = os.ReadFile(k.Path); err != nil {
+				return nil, fmt.Errorf("reading public key: %w", err)
+			}
+		}
+
+		key, err := verify.ParsePublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return verify.New(
+		verify.WithPublicKeys(keys...),
+		verify.WithRequiredPredicateTypes(conf.RequiredPredicateTypes...),
+	), nil
+}
+
 // FileDB constructs and configures a boltdb instance from configuration.
 // It caches built instances and returns the same instance for subsequent
 // calls with the same name.
@@ -218,3 +476,108 @@ func (c *Config) FileDB(name string) (*bolt.DB, error) {
 
 	return db, nil
 }
+
+// SQLiteDB constructs and configures a SQLite database instance from configuration.
+// It caches built instances and returns the same instance for subsequent
+// calls with the same name.
+func (c *Config) SQLiteDB(name string) (*sqlite.DB, error) {
+	if db, ok := c.cache.sqlite[name]; ok {
+		return db, nil
+	}
+
+	conf, ok := c.conf.History.SQLite[name]
+	if !ok {
+		return nil, fmt.Errorf("sqlite db %q: configuration not found", name)
+	}
+
+	db, err := sqlite.Open(conf.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.sqlite[name] = db
+
+	return db, nil
+}
+
+// RedisDB constructs and configures a Redis database instance from configuration.
+// It caches built instances and returns the same instance for subsequent
+// calls with the same name.
+func (c *Config) RedisDB(name string) (*redis.DB, error) {
+	if db, ok := c.cache.redis[name]; ok {
+		return db, nil
+	}
+
+	conf, ok := c.conf.History.Redis[name]
+	if !ok {
+		return nil, fmt.Errorf("redis db %q: configuration not found", name)
+	}
+
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     conf.Address,
+		Username: conf.Username,
+		Password: conf.Password,
+		DB:       conf.DB,
+	})
+
+	if err := client.Ping(c.ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("redis db %q: connecting: %w", name, err)
+	}
+
+	db := redis.New(client, redis.WithPrefix(conf.Prefix))
+
+	c.cache.redis[name] = db
+
+	return db, nil
+}
+
+// FileResource returns a constructor for file resources as configured by the provided name.
+// The constructor can be supplied directly when building a pipeline.
+func (c *Config) FileResource(name string) (func() *file.Resource, error) {
+	conf, ok := c.conf.Resources.File[name]
+	if !ok {
+		return nil, fmt.Errorf("file resource %q: configuration not found", name)
+	}
+
+	return file.New(conf.Path, conf.Expression, file.WithFormat(file.Format(conf.Format))), nil
+}
+
+// KustomizeResource returns a constructor for kustomize image resources as configured by the provided name.
+// The constructor can be supplied directly when building a pipeline.
+func (c *Config) KustomizeResource(name string) (func() *kustomize.Resource, error) {
+	conf, ok := c.conf.Resources.Kustomize[name]
+	if !ok {
+		return nil, fmt.Errorf("kustomize resource %q: configuration not found", name)
+	}
+
+	return kustomize.New(conf.Image, kustomize.WithOverlay(conf.Overlay)), nil
+}
+
+// HelmResource returns a constructor for helm resources as configured by the provided name.
+// The constructor can be supplied directly when building a pipeline.
+func (c *Config) HelmResource(name string) (func() *helm.Resource, error) {
+	conf, ok := c.conf.Resources.Helm[name]
+	if !ok {
+		return nil, fmt.Errorf("helm resource %q: configuration not found", name)
+	}
+
+	var opts []containers.Option[helm.Resource]
+	if conf.ValuesFile != "" {
+		opts = append(opts, helm.WithValuesFile(conf.ValuesFile))
+	}
+
+	if conf.Image != nil {
+		opts = append(opts, helm.WithImagePaths(conf.Image.Repository, conf.Image.Tag, conf.Image.Digest))
+	}
+
+	if conf.BumpChartVersion {
+		opts = append(opts, helm.WithChartVersionBump())
+	}
+
+	if conf.SetAppVersion {
+		opts = append(opts, helm.WithAppVersion())
+	}
+
+	return helm.New(conf.Chart, opts...), nil
+}
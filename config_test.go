@@ -0,0 +1,183 @@
+package glu
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestConfig_GitLinkProvider(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		repo   *config.GitRepository
+		commit string
+		err    string
+	}{
+		{
+			name: "no remote",
+			repo: &config.GitRepository{},
+		},
+		{
+			name:   "detected from host",
+			repo:   &config.GitRepository{Remote: &config.Remote{URL: "git@github.com:owner/repo.git"}},
+			commit: "https://github.com/owner/repo/commit/abc123",
+		},
+		{
+			name: "configured provider takes precedence over detected host",
+			repo: &config.GitRepository{
+				Remote: &config.Remote{URL: "https://github.com/owner/repo.git"},
+				Links:  &config.Links{Provider: config.ProposalsProviderGitLab},
+			},
+			commit: "https://github.com/owner/repo/-/commit/abc123",
+		},
+		{
+			name: "detected host takes precedence over proposals provider",
+			repo: &config.GitRepository{
+				Remote:    &config.Remote{URL: "https://gitlab.com/owner/repo.git"},
+				Proposals: &config.Proposals{Provider: config.ProposalsProviderGitHub},
+			},
+			commit: "https://gitlab.com/owner/repo/-/commit/abc123",
+		},
+		{
+			name: "proposals provider for unknown host",
+			repo: &config.GitRepository{
+				Remote:    &config.Remote{URL: "https://git.example.com/owner/repo.git"},
+				Proposals: &config.Proposals{Provider: config.ProposalsProviderGitea},
+			},
+			commit: "https://git.example.com/owner/repo/commit/abc123",
+		},
+		{
+			name: "base url and template overrides",
+			repo: &config.GitRepository{
+				Remote:    &config.Remote{URL: "https://git.example.com/owner/repo.git"},
+				Proposals: &config.Proposals{Provider: config.ProposalsProviderGitea},
+				Links: &config.Links{
+					BaseURL: "https://web.example.com/",
+					Commit:  "{{.BaseURL}}/{{.Name}}/c/{{pathescape .SHA}}",
+				},
+			},
+			commit: "https://web.example.com/repo/c/abc123",
+		},
+		{
+			name: "template only links for unknown host",
+			repo: &config.GitRepository{
+				Remote: &config.Remote{URL: "https://git.example.com/owner/repo.git"},
+				Links:  &config.Links{Commit: "{{.BaseURL}}/{{.Path}}/-/c/{{.SHA}}"},
+			},
+			commit: "https://git.example.com/owner/repo/-/c/abc123",
+		},
+		{
+			name: "no links for unknown host",
+			repo: &config.GitRepository{Remote: &config.Remote{URL: "https://git.example.com/owner/repo.git"}},
+		},
+		{
+			name: "invalid remote path",
+			repo: &config.GitRepository{Remote: &config.Remote{URL: "https://github.com/owner"}},
+			err:  `git "repo": links: unexpected repository URL path: "/owner"`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf := newConfigSource(context.Background(), &config.Config{
+				Sources: config.Sources{Git: config.GitSources{"repo": test.repo}},
+			})
+
+			provider, err := conf.GitLinkProvider("repo")
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if test.commit == "" {
+				assert.Nil(t, provider)
+				return
+			}
+
+			require.NotNil(t, provider)
+			assert.Equal(t, test.commit, provider.CommitURL("abc123"))
+		})
+	}
+}
+
+func TestConfig_GitRepository_ProposerBaseURL(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		remote   string
+		provider string
+		expected string
+	}{
+		{
+			name:     "https",
+			remote:   "https://gitea.example.com/owner/repo.git",
+			provider: config.ProposalsProviderGitea,
+			expected: "https://gitea.example.com/api/v1/repos/owner/repo/",
+		},
+		{
+			name:     "http with port",
+			remote:   "http://gitea.example.com:3000/owner/repo.git",
+			provider: config.ProposalsProviderGitea,
+			expected: "http://gitea.example.com:3000/api/v1/repos/owner/repo/",
+		},
+		{
+			name:     "ssh with port",
+			remote:   "ssh://git@gitea.example.com:2222/owner/repo.git",
+			provider: config.ProposalsProviderGitea,
+			expected: "https://gitea.example.com/api/v1/repos/owner/repo/",
+		},
+		{
+			name:     "scp-like ssh",
+			remote:   "git@gitlab.example.com:group/project.git",
+			provider: config.ProposalsProviderGitLab,
+			expected: "https://gitlab.example.com/api/v4/projects/group%2Fproject/",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var requested []string
+			// the proposers http client is derived from the client configured on the context
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+				Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					requested = append(requested, r.URL.String())
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{"Content-Type": []string{"application/json"}},
+						Body:       io.NopCloser(strings.NewReader("[]")),
+						Request:    r,
+					}, nil
+				}),
+			})
+
+			token := "token"
+			conf := newConfigSource(ctx, &config.Config{
+				Credentials: config.Credentials{
+					"scm": {Type: config.CredentialTypeAccessToken, AccessToken: &token},
+				},
+				Sources: config.Sources{Git: config.GitSources{"repo": {
+					Remote:    &config.Remote{URL: test.remote},
+					Proposals: &config.Proposals{Provider: test.provider, Credential: "scm"},
+				}}},
+			})
+
+			proposer, err := conf.newProposer(conf.conf.Sources.Git["repo"])
+			require.NoError(t, err)
+
+			_, _ = proposer.GetCurrentProposal(ctx, "main", "glu/")
+
+			require.NotEmpty(t, requested)
+			assert.True(t, strings.HasPrefix(requested[0], test.expected), "unexpected request URL %q", requested[0])
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
@@ -9,12 +9,15 @@ import (
 	"io/fs"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/credentials"
 	glufs "github.com/get-glu/glu/pkg/fs"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/filemode"
@@ -22,9 +25,20 @@ import (
 	gitstorage "github.com/go-git/go-git/v5/storage"
 )
 
-var _ glufs.Filesystem = (*filesystem)(nil)
+var (
+	_ glufs.RenameFilesystem  = (*filesystem)(nil)
+	_ glufs.ChmodFilesystem   = (*filesystem)(nil)
+	_ glufs.SymlinkFilesystem = (*filesystem)(nil)
+)
+
+// maxSymlinks is the maximum number of symbolic links followed when resolving a path
+const maxSymlinks = 40
 
-var ErrEmptyCommit = errors.New("empty commit")
+var (
+	ErrEmptyCommit = errors.New("empty commit")
+	// ErrConflict is returned when an update conflicts with concurrent changes to a branch
+	ErrConflict = core.ErrConflict
+)
 
 type filesystem struct {
 	logger  *slog.Logger
@@ -32,8 +46,9 @@ type filesystem struct {
 	tree    *object.Tree
 	storage gitstorage.Storer
 
-	sigName  string
-	sigEmail string
+	author    object.Signature
+	committer object.Signature
+	signer    credentials.Signer
 }
 
 // ReadDir reads the directory named by dirname and returns a list of
@@ -95,6 +110,7 @@ func (f *filesystem) MkdirAll(filename string, perm os.FileMode) error {
 		append(strings.Split(filename, "/"), ".gitkeep"),
 		true,
 		&plumbing.ZeroHash,
+		filemode.Regular,
 	)
 }
 
@@ -107,7 +123,31 @@ func (f *filesystem) OpenFile(filename string, flag int, perm os.FileMode) (gluf
 		slog.String("path", filename),
 		slog.Bool("create", flag&os.O_CREATE == os.O_CREATE))
 
-	fi, err := f.tree.File(filename)
+	// symbolic links are followed such that reads and writes apply to their destination
+	name, entry, err := f.resolve(filename)
+	if err != nil && !errorIsNotFound(err) {
+		return nil, err
+	}
+
+	if entry != nil && entry.Mode == filemode.Dir {
+		return nil, fmt.Errorf("path %q: is a directory", filename)
+	}
+
+	// existing files retain their mode, while new files are
+	// executable when any execute bit is present in perm
+	mode := filemode.Regular
+	if entry != nil {
+		mode = entry.Mode
+	} else if perm&0111 != 0 {
+		mode = filemode.Executable
+	}
+
+	osMode, err := mode.ToOSFileMode()
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.tree.File(name)
 	if flag == os.O_RDONLY {
 		if err != nil {
 			if errorIsNotFound(err) {
@@ -126,7 +166,8 @@ func (f *filesystem) OpenFile(filename string, flag int, perm os.FileMode) (gluf
 			ReadCloser: rd,
 			info: &fileInfo{
 				name: filename,
-				mode: os.ModePerm,
+				size: fi.Size,
+				mode: osMode,
 			},
 		}, nil
 	}
@@ -137,13 +178,14 @@ func (f *filesystem) OpenFile(filename string, flag int, perm os.FileMode) (gluf
 
 	file := &file{
 		info: &fileInfo{
-			name: filename,
-			mode: os.ModePerm,
+			name: name,
+			mode: osMode,
 		},
 		logger:  f.logger,
 		tree:    f.tree,
 		storage: f.storage,
 		obj:     f.storage.NewEncodedObject(),
+		mode:    mode,
 	}
 
 	file.obj.SetType(plumbing.BlobObject)
@@ -167,7 +209,39 @@ func (f *filesystem) OpenFile(filename string, flag int, perm os.FileMode) (gluf
 }
 
 // Stat returns a FileInfo describing the named file.
+// If the file is a symbolic link, the returned FileInfo describes the link destination.
 func (f *filesystem) Stat(filename string) (_ os.FileInfo, err error) {
+	if isRoot(filename) {
+		return f.Lstat(filename)
+	}
+
+	name, entry, err := f.resolve(filename)
+	if err != nil {
+		if errorIsNotFound(err) {
+			return nil, fmt.Errorf("path %q: %w", filename, os.ErrNotExist)
+		}
+
+		return nil, err
+	}
+
+	info, err := entryToFileInfo(entry)
+	if err != nil {
+		return nil, fmt.Errorf("gathering info: %w", err)
+	}
+
+	info.name = path.Base(filename)
+	info.size, _ = f.tree.Size(name)
+
+	return info, nil
+}
+
+// Lstat returns a FileInfo describing the named file.
+// If the file is a symbolic link, the returned FileInfo describes the link.
+func (f *filesystem) Lstat(filename string) (_ os.FileInfo, err error) {
+	if isRoot(filename) {
+		return &fileInfo{name: ".", mode: fs.ModeDir | os.ModePerm, isDir: true}, nil
+	}
+
 	entry, err := f.tree.FindEntry(filename)
 	if err != nil {
 		if errorIsNotFound(err) {
@@ -188,6 +262,7 @@ func (f *filesystem) Stat(filename string) (_ os.FileInfo, err error) {
 }
 
 // Remove removes the named file or directory.
+// Directories are removed along with their contents.
 func (f *filesystem) Remove(filename string) error {
 	entry, err := f.tree.FindEntry(filename)
 	if err != nil {
@@ -198,21 +273,190 @@ func (f *filesystem) Remove(filename string) error {
 		return fmt.Errorf("removing path %q: %w", filename, err)
 	}
 
-	var hash *plumbing.Hash
-	if entry.Mode.IsFile() {
-		hash = &entry.Hash
-	}
-
 	return updatePath(
 		f.logger,
 		f.storage,
 		f.tree,
 		strings.Split(filename, "/"),
 		false,
-		hash,
+		&entry.Hash,
+		entry.Mode,
 	)
 }
 
+// Rename renames (moves) oldpath to newpath.
+// If newpath already exists and is not a directory, Rename replaces it.
+func (f *filesystem) Rename(oldpath, newpath string) error {
+	oldpath, newpath = path.Clean(oldpath), path.Clean(newpath)
+
+	entry, err := f.tree.FindEntry(oldpath)
+	if err != nil {
+		if errorIsNotFound(err) {
+			return fmt.Errorf("rename %q: %w", oldpath, os.ErrNotExist)
+		}
+
+		return fmt.Errorf("rename %q: %w", oldpath, err)
+	}
+
+	if oldpath == newpath {
+		return nil
+	}
+
+	if strings.HasPrefix(newpath, oldpath+"/") {
+		return fmt.Errorf("rename %q to %q: cannot move a directory into itself", oldpath, newpath)
+	}
+
+	if existing, err := f.tree.FindEntry(newpath); err == nil {
+		if existing.Mode == filemode.Dir || entry.Mode == filemode.Dir {
+			return fmt.Errorf("rename %q to %q: %w", oldpath, newpath, fs.ErrExist)
+		}
+	} else if !errorIsNotFound(err) {
+		return fmt.Errorf("rename %q to %q: %w", oldpath, newpath, err)
+	}
+
+	// the entry is copied before the update as it points into the tree being modified
+	hash, mode := entry.Hash, entry.Mode
+	if err := updatePath(f.logger, f.storage, f.tree, strings.Split(newpath, "/"), true, &hash, mode); err != nil {
+		return err
+	}
+
+	return updatePath(f.logger, f.storage, f.tree, strings.Split(oldpath, "/"), false, &hash, mode)
+}
+
+// Chmod changes the mode of the named file to mode.
+// Git only tracks whether regular files are executable, so the file is
+// marked executable when any execute bit is present in mode.
+func (f *filesystem) Chmod(name string, mode os.FileMode) error {
+	name, entry, err := f.resolve(name)
+	if err != nil {
+		if errorIsNotFound(err) {
+			return fmt.Errorf("chmod %q: %w", name, os.ErrNotExist)
+		}
+
+		return err
+	}
+
+	if entry.Mode != filemode.Regular && entry.Mode != filemode.Executable && entry.Mode != filemode.Deprecated {
+		return fmt.Errorf("chmod %q: not a regular file", name)
+	}
+
+	target := filemode.Regular
+	if mode&0111 != 0 {
+		target = filemode.Executable
+	}
+
+	if entry.Mode == target {
+		return nil
+	}
+
+	hash := entry.Hash
+	return updatePath(f.logger, f.storage, f.tree, strings.Split(name, "/"), true, &hash, target)
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+func (f *filesystem) Symlink(oldname, newname string) error {
+	if _, err := f.tree.FindEntry(newname); err == nil {
+		return fmt.Errorf("symlink %q: %w", newname, fs.ErrExist)
+	} else if !errorIsNotFound(err) {
+		return fmt.Errorf("symlink %q: %w", newname, err)
+	}
+
+	obj := f.storage.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+
+	wr, err := obj.Writer()
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(wr, oldname); err != nil {
+		return err
+	}
+
+	if err := wr.Close(); err != nil {
+		return err
+	}
+
+	hash, err := f.storage.SetEncodedObject(obj)
+	if err != nil {
+		return err
+	}
+
+	return updatePath(f.logger, f.storage, f.tree, strings.Split(newname, "/"), true, &hash, filemode.Symlink)
+}
+
+// Readlink returns the destination of the named symbolic link.
+func (f *filesystem) Readlink(name string) (string, error) {
+	entry, err := f.tree.FindEntry(name)
+	if err != nil {
+		if errorIsNotFound(err) {
+			return "", fmt.Errorf("readlink %q: %w", name, os.ErrNotExist)
+		}
+
+		return "", fmt.Errorf("readlink %q: %w", name, err)
+	}
+
+	if entry.Mode != filemode.Symlink {
+		return "", fmt.Errorf("readlink %q: not a symbolic link", name)
+	}
+
+	return f.readBlob(entry.Hash)
+}
+
+// resolve follows symbolic links until the named path refers to an entry which is not a link.
+// It returns the resolved path along with its entry.
+// Only the final element of the path is resolved (links to directories are not traversed)
+// and links which are absolute or escape the root of the tree are rejected.
+func (f *filesystem) resolve(name string) (string, *object.TreeEntry, error) {
+	for range maxSymlinks {
+		entry, err := f.tree.FindEntry(name)
+		if err != nil {
+			return name, nil, err
+		}
+
+		if entry.Mode != filemode.Symlink {
+			return name, entry, nil
+		}
+
+		target, err := f.readBlob(entry.Hash)
+		if err != nil {
+			return name, nil, err
+		}
+
+		if path.IsAbs(target) {
+			return name, nil, fmt.Errorf("path %q: absolute symbolic link %q not supported", name, target)
+		}
+
+		link := name
+		if name = path.Join(path.Dir(name), target); name == ".." || strings.HasPrefix(name, "../") {
+			return link, nil, fmt.Errorf("path %q: symbolic link %q escapes repository", link, target)
+		}
+	}
+
+	return name, nil, fmt.Errorf("path %q: too many levels of symbolic links", name)
+}
+
+func (f *filesystem) readBlob(hash plumbing.Hash) (string, error) {
+	blob, err := object.GetBlob(f.storage, hash)
+	if err != nil {
+		return "", err
+	}
+
+	rd, err := blob.Reader()
+	if err != nil {
+		return "", err
+	}
+
+	defer rd.Close()
+
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
 func entryToFileInfo(entry *object.TreeEntry) (*fileInfo, error) {
 	mode, err := entry.Mode.ToOSFileMode()
 	if err != nil {
@@ -234,6 +478,7 @@ type file struct {
 	tree    *object.Tree
 	storage gitstorage.Storer
 	obj     plumbing.EncodedObject
+	mode    filemode.FileMode
 }
 
 func (f *file) Close() error {
@@ -254,6 +499,7 @@ func (f *file) Close() error {
 			strings.Split(f.info.name, "/"),
 			true,
 			&hash,
+			f.mode,
 		)
 	}
 
@@ -307,9 +553,9 @@ func (f *fileInfo) Sys() any {
 
 // updatePath recursively descends into the provided tree node and updates
 // the entries signified by the provided path
-// given the provided blob hash is zero, then it deletes the leaf and rewrites the path
-// otherwise, it creates the path and inserts the blob
-func updatePath(logger *slog.Logger, storage gitstorage.Storer, node *object.Tree, parts []string, insert bool, blob *plumbing.Hash) (err error) {
+// when insert is false, then it deletes the leaf and rewrites the path
+// otherwise, it creates the path and inserts the blob (or tree) hash with the provided mode
+func updatePath(logger *slog.Logger, storage gitstorage.Storer, node *object.Tree, parts []string, insert bool, blob *plumbing.Hash, mode filemode.FileMode) (err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("updating path %q: %w", parts, err)
@@ -333,10 +579,11 @@ func updatePath(logger *slog.Logger, storage gitstorage.Storer, node *object.Tre
 	}
 
 	// build a target for search / insertion
-	// only the last entry in parts is considered a regular file
+	// only the last entry in parts is considered the leaf (with the provided mode)
+	leaf := len(parts) == 1 && blob != nil
 	target := object.TreeEntry{Name: parts[0], Mode: filemode.Dir}
-	if len(parts) == 1 && blob != nil {
-		target.Mode = filemode.Regular
+	if leaf {
+		target.Mode = mode
 	}
 
 	// the comparison function here matches the less function for object.TreeEntrySorter
@@ -362,26 +609,31 @@ func updatePath(logger *slog.Logger, storage gitstorage.Storer, node *object.Tre
 			return nil
 		}
 
-		if target.Mode.IsFile() {
-			// adding a blob
+		if leaf {
+			// removing the leaf
 			node.Entries = append(node.Entries[:i], node.Entries[i+1:]...)
 		} else {
-			// adding a tree
+			// descending into a tree
 			tree, err := object.GetTree(storage, node.Entries[i].Hash)
 			if err != nil {
 				return err
 			}
 
-			if err := updatePath(logger, storage, tree, parts[1:], insert, blob); err != nil {
+			if err := updatePath(logger, storage, tree, parts[1:], insert, blob, mode); err != nil {
 				return err
 			}
 
-			node.Entries[i].Hash = tree.Hash
+			if len(tree.Entries) == 0 {
+				// git does not track empty trees so the directory is removed
+				node.Entries = append(node.Entries[:i], node.Entries[i+1:]...)
+			} else {
+				node.Entries[i].Hash = tree.Hash
+			}
 		}
 	} else {
 		// performing an insert or update
-		if target.Mode.IsFile() {
-			// adding blob (assumes blob hash has been inserted)
+		if leaf {
+			// adding blob or tree (assumes hash has been inserted)
 			target.Hash = *blob
 
 			if len(node.Entries) == 1 && node.Entries[0].Name == ".gitkeep" {
@@ -403,7 +655,7 @@ func updatePath(logger *slog.Logger, storage gitstorage.Storer, node *object.Tre
 			}
 
 			// descend into tree with rest of path
-			if err := updatePath(logger, storage, child, parts[1:], insert, blob); err != nil {
+			if err := updatePath(logger, storage, child, parts[1:], insert, blob, mode); err != nil {
 				return err
 			}
 
@@ -413,6 +665,7 @@ func updatePath(logger *slog.Logger, storage gitstorage.Storer, node *object.Tre
 		if ok {
 			// has existing entry in parent
 			node.Entries[i].Hash = target.Hash
+			node.Entries[i].Mode = target.Mode
 		} else {
 			// needs inserting in parent
 			node.Entries = slices.Insert(node.Entries, i, target)
@@ -434,8 +687,16 @@ func updatePath(logger *slog.Logger, storage gitstorage.Storer, node *object.Tre
 		"tree_hash", node.Hash,
 		"blob_hash", blob)
 
-	// decode back into node to reset state
-	return node.Decode(obj)
+	// reload the node to reset its state, including the subtrees
+	// it caches by path which are now potentially stale
+	tree, err := object.GetTree(storage, node.Hash)
+	if err != nil {
+		return err
+	}
+
+	*node = *tree
+
+	return nil
 }
 
 func (f *filesystem) commit(_ context.Context, msg string) (*object.Commit, error) {
@@ -443,11 +704,13 @@ func (f *filesystem) commit(_ context.Context, msg string) (*object.Commit, erro
 		return nil, ErrEmptyCommit
 	}
 
-	signature := object.Signature{
-		Name:  f.sigName,
-		Email: f.sigEmail,
-		When:  time.Now().UTC(),
-	}
+	var (
+		now       = time.Now().UTC()
+		author    = f.author
+		committer = f.committer
+	)
+
+	author.When, committer.When = now, now
 
 	var hashes []plumbing.Hash
 	if f.base != nil {
@@ -455,13 +718,22 @@ func (f *filesystem) commit(_ context.Context, msg string) (*object.Commit, erro
 	}
 
 	commit := &object.Commit{
-		Author:       signature,
-		Committer:    signature,
+		Author:       author,
+		Committer:    committer,
 		Message:      msg,
 		TreeHash:     f.tree.Hash,
 		ParentHashes: hashes,
 	}
 
+	if f.signer != nil {
+		signature, err := f.sign(commit)
+		if err != nil {
+			return nil, err
+		}
+
+		commit.PGPSignature = signature
+	}
+
 	obj := f.storage.NewEncodedObject()
 	err := commit.Encode(obj)
 	if err != nil {
@@ -476,6 +748,32 @@ func (f *filesystem) commit(_ context.Context, msg string) (*object.Commit, erro
 	return commit, nil
 }
 
+func (f *filesystem) sign(commit *object.Commit) (string, error) {
+	obj := f.storage.NewEncodedObject()
+	if err := commit.EncodeWithoutSignature(obj); err != nil {
+		return "", fmt.Errorf("encoding commit: %w", err)
+	}
+
+	rd, err := obj.Reader()
+	if err != nil {
+		return "", err
+	}
+
+	defer rd.Close()
+
+	signature, err := f.signer.Sign(rd)
+	if err != nil {
+		return "", fmt.Errorf("signing commit: %w", err)
+	}
+
+	return string(signature), nil
+}
+
+func isRoot(name string) bool {
+	name = path.Clean(name)
+	return name == "." || name == "/"
+}
+
 func errorIsNotFound(err error) bool {
 	return errors.Is(err, object.ErrEntryNotFound) ||
 		errors.Is(err, object.ErrDirectoryNotFound) ||
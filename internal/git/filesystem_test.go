@@ -0,0 +1,360 @@
+package git
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystem_Symlinks(t *testing.T) {
+	fs := newTestFilesystem(t, map[string]string{
+		"values.yaml":     "values",
+		"env/app.yaml":    "app",
+		"env/link.yaml":   "@../values.yaml",
+		"env/chain.yaml":  "@link.yaml",
+		"env/dir":         "@../env",
+		"env/dangling":    "@missing.yaml",
+		"env/escape.yaml": "@../../outside.yaml",
+		"env/abs.yaml":    "@/etc/passwd",
+		"env/loop-a.yaml": "@loop-b.yaml",
+		"env/loop-b.yaml": "@loop-a.yaml",
+	})
+
+	t.Run("read", func(t *testing.T) {
+		assert.Equal(t, "values", readFile(t, fs, "env/link.yaml"))
+		assert.Equal(t, "values", readFile(t, fs, "env/chain.yaml"))
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		info, err := fs.Stat("env/chain.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "chain.yaml", info.Name())
+		assert.Equal(t, int64(len("values")), info.Size())
+		assert.True(t, info.Mode().IsRegular())
+
+		info, err = fs.Lstat("env/chain.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink)
+
+		info, err = fs.Stat("env/dir")
+		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+	})
+
+	t.Run("readlink", func(t *testing.T) {
+		target, err := fs.Readlink("env/chain.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "link.yaml", target)
+
+		_, err = fs.Readlink("env/app.yaml")
+		assert.ErrorContains(t, err, "not a symbolic link")
+
+		_, err = fs.Readlink("env/missing.yaml")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	for _, test := range []struct {
+		name string
+		path string
+		err  error
+		msg  string
+	}{
+		{name: "dangling", path: "env/dangling", err: os.ErrNotExist},
+		{name: "escape", path: "env/escape.yaml", msg: "escapes repository"},
+		{name: "absolute", path: "env/abs.yaml", msg: "absolute symbolic link"},
+		{name: "loop", path: "env/loop-a.yaml", msg: "too many levels of symbolic links"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := fs.OpenFile(test.path, os.O_RDONLY, 0)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+
+			assert.ErrorContains(t, err, test.msg)
+
+			_, err = fs.Stat(test.path)
+			assert.ErrorContains(t, err, test.msg)
+		})
+	}
+
+	t.Run("write", func(t *testing.T) {
+		fs := newTestFilesystem(t, map[string]string{
+			"values.yaml":   "values",
+			"env/link.yaml": "@../values.yaml",
+		})
+
+		writeTestFile(t, fs, "env/link.yaml", "updated")
+
+		// the destination is written and the link is left in place
+		tree := commitTestFilesystem(t, fs)
+		assertTreeFile(t, tree, "values.yaml", "updated", filemode.Regular)
+		assertTreeFile(t, tree, "env/link.yaml", "../values.yaml", filemode.Symlink)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		fs := newTestFilesystem(t, map[string]string{"values.yaml": "values"})
+
+		require.NoError(t, fs.Symlink("../values.yaml", "env/link.yaml"))
+		assert.Equal(t, "values", readFile(t, fs, "env/link.yaml"))
+
+		err := fs.Symlink("values.yaml", "env/link.yaml")
+		assert.ErrorIs(t, err, os.ErrExist)
+
+		tree := commitTestFilesystem(t, fs)
+		assertTreeFile(t, tree, "env/link.yaml", "../values.yaml", filemode.Symlink)
+	})
+}
+
+func TestFilesystem_Rename(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		fs := newTestFilesystem(t, map[string]string{
+			"old.yaml":  "old",
+			"run.sh":    "!#!/bin/sh",
+			"other.txt": "other",
+		})
+
+		require.NoError(t, fs.Rename("old.yaml", "env/new.yaml"))
+		// the mode of the file is preserved
+		require.NoError(t, fs.Rename("run.sh", "bin/run.sh"))
+		// existing files are replaced
+		require.NoError(t, fs.Rename("other.txt", "env/new.yaml"))
+
+		tree := commitTestFilesystem(t, fs)
+		assertTreeFile(t, tree, "env/new.yaml", "other", filemode.Regular)
+		assertTreeFile(t, tree, "bin/run.sh", "#!/bin/sh", filemode.Executable)
+		assertNoTreeEntry(t, tree, "old.yaml")
+		assertNoTreeEntry(t, tree, "run.sh")
+		assertNoTreeEntry(t, tree, "other.txt")
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		fs := newTestFilesystem(t, map[string]string{
+			"env/staging/a.yaml":        "a",
+			"env/staging/nested/b.yaml": "b",
+			"env/production/a.yaml":     "production",
+		})
+
+		require.NoError(t, fs.Rename("env/staging/", "env/dev"))
+
+		tree := commitTestFilesystem(t, fs)
+		assertTreeFile(t, tree, "env/dev/a.yaml", "a", filemode.Regular)
+		assertTreeFile(t, tree, "env/dev/nested/b.yaml", "b", filemode.Regular)
+		assertTreeFile(t, tree, "env/production/a.yaml", "production", filemode.Regular)
+		assertNoTreeEntry(t, tree, "env/staging")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		fs := newTestFilesystem(t, map[string]string{
+			"a.yaml":     "a",
+			"env/b.yaml": "b",
+			"dir/c":      "c",
+		})
+
+		assert.ErrorIs(t, fs.Rename("missing.yaml", "b.yaml"), os.ErrNotExist)
+		assert.ErrorIs(t, fs.Rename("a.yaml", "env"), os.ErrExist)
+		assert.ErrorIs(t, fs.Rename("dir", "env"), os.ErrExist)
+		assert.ErrorContains(t, fs.Rename("env", "env/nested"), "cannot move a directory into itself")
+		require.NoError(t, fs.Rename("a.yaml", "./a.yaml"))
+
+		assert.Equal(t, "a", readFile(t, fs, "a.yaml"))
+	})
+}
+
+func TestFilesystem_Chmod(t *testing.T) {
+	fs := newTestFilesystem(t, map[string]string{
+		"run.sh":       "#!/bin/sh",
+		"build.sh":     "!#!/bin/sh",
+		"env/app.yaml": "app",
+	})
+
+	require.NoError(t, fs.Chmod("run.sh", 0755))
+	require.NoError(t, fs.Chmod("build.sh", 0644))
+
+	info, err := fs.Stat("run.sh")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	assert.ErrorContains(t, fs.Chmod("env", 0755), "not a regular file")
+	assert.ErrorIs(t, fs.Chmod("missing.sh", 0755), os.ErrNotExist)
+
+	tree := commitTestFilesystem(t, fs)
+	assertTreeFile(t, tree, "run.sh", "#!/bin/sh", filemode.Executable)
+	assertTreeFile(t, tree, "build.sh", "#!/bin/sh", filemode.Regular)
+
+	// links are followed, such that the destination is changed
+	fs = newTestFilesystem(t, map[string]string{
+		"run.sh":  "#!/bin/sh",
+		"link.sh": "@run.sh",
+	})
+
+	require.NoError(t, fs.Chmod("link.sh", 0700))
+
+	tree = commitTestFilesystem(t, fs)
+	assertTreeFile(t, tree, "run.sh", "#!/bin/sh", filemode.Executable)
+	assertTreeFile(t, tree, "link.sh", "run.sh", filemode.Symlink)
+}
+
+func TestFilesystem_OpenFile_Mode(t *testing.T) {
+	fs := newTestFilesystem(t, map[string]string{"run.sh": "!#!/bin/sh"})
+
+	// existing files retain their mode
+	writeTestFile(t, fs, "run.sh", "#!/bin/bash")
+
+	// new files are executable when any execute bit is requested
+	fi, err := fs.OpenFile("bin/new.sh", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0750)
+	require.NoError(t, err)
+
+	_, err = io.WriteString(fi, "#!/bin/sh")
+	require.NoError(t, err)
+	require.NoError(t, fi.Close())
+
+	writeTestFile(t, fs, "bin/new.txt", "text")
+
+	tree := commitTestFilesystem(t, fs)
+	assertTreeFile(t, tree, "run.sh", "#!/bin/bash", filemode.Executable)
+	assertTreeFile(t, tree, "bin/new.sh", "#!/bin/sh", filemode.Executable)
+	assertTreeFile(t, tree, "bin/new.txt", "text", filemode.Regular)
+}
+
+func TestFilesystem_Remove(t *testing.T) {
+	fs := newTestFilesystem(t, map[string]string{
+		"README.md":                 "readme",
+		"env/staging/a.yaml":        "a",
+		"env/staging/nested/b.yaml": "b",
+		"env/production/a.yaml":     "production",
+		"single/only.yaml":          "only",
+	})
+
+	// directories are removed along with their contents
+	require.NoError(t, fs.Remove("env/staging"))
+	// removing the last file of a directory removes the directory
+	require.NoError(t, fs.Remove("single/only.yaml"))
+	// missing paths are ignored
+	require.NoError(t, fs.Remove("env/staging"))
+	require.NoError(t, fs.Remove("missing/file.yaml"))
+
+	_, err := fs.Stat("env/staging/nested/b.yaml")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	tree := commitTestFilesystem(t, fs)
+	assertTreeFile(t, tree, "README.md", "readme", filemode.Regular)
+	assertTreeFile(t, tree, "env/production/a.yaml", "production", filemode.Regular)
+	assertNoTreeEntry(t, tree, "env/staging")
+	assertNoTreeEntry(t, tree, "single")
+}
+
+// newTestFilesystem commits the files (a map of path to contents) to a new repository
+// and returns a filesystem for the resulting commit.
+// Contents prefixed with @ are symbolic links to the remainder and contents
+// prefixed with ! are executable files.
+func newTestFilesystem(t *testing.T, files map[string]string) *filesystem {
+	t.Helper()
+
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+
+	tree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	for path, contents := range files {
+		switch {
+		case contents[0] == '@':
+			require.NoError(t, tree.Filesystem.Symlink(contents[1:], path))
+		default:
+			perm := os.FileMode(0644)
+			if contents[0] == '!' {
+				contents, perm = contents[1:], 0755
+			}
+
+			fi, err := tree.Filesystem.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+			require.NoError(t, err)
+
+			_, err = io.WriteString(fi, contents)
+			require.NoError(t, err)
+			require.NoError(t, fi.Close())
+		}
+
+		_, err = tree.Add(path)
+		require.NoError(t, err)
+	}
+
+	signature := object.Signature{Name: "glu", Email: "glu@get-glu.dev"}
+	hash, err := tree.Commit("initial commit", &git.CommitOptions{Author: &signature})
+	require.NoError(t, err)
+
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+
+	root, err := commit.Tree()
+	require.NoError(t, err)
+
+	return &filesystem{
+		logger:    slog.Default(),
+		base:      commit,
+		tree:      root,
+		storage:   repo.Storer,
+		author:    signature,
+		committer: signature,
+	}
+}
+
+// commitTestFilesystem commits the changes made to the filesystem and returns
+// the tree of the commit as read back from storage.
+func commitTestFilesystem(t *testing.T, fs *filesystem) *object.Tree {
+	t.Helper()
+
+	commit, err := fs.commit(context.Background(), "update")
+	require.NoError(t, err)
+
+	commit, err = object.GetCommit(fs.storage, commit.Hash)
+	require.NoError(t, err)
+
+	tree, err := commit.Tree()
+	require.NoError(t, err)
+
+	return tree
+}
+
+func writeTestFile(t *testing.T, fs *filesystem, path, contents string) {
+	t.Helper()
+
+	fi, err := fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	require.NoError(t, err)
+
+	_, err = io.WriteString(fi, contents)
+	require.NoError(t, err)
+	require.NoError(t, fi.Close())
+}
+
+func assertTreeFile(t *testing.T, tree *object.Tree, path, contents string, mode filemode.FileMode) {
+	t.Helper()
+
+	entry, err := tree.FindEntry(path)
+	require.NoError(t, err, path)
+	assert.Equal(t, mode, entry.Mode, path)
+
+	blob, err := tree.File(path)
+	require.NoError(t, err, path)
+
+	actual, err := blob.Contents()
+	require.NoError(t, err, path)
+	assert.Equal(t, contents, actual, path)
+}
+
+func assertNoTreeEntry(t *testing.T, tree *object.Tree, path string) {
+	t.Helper()
+
+	_, err := tree.FindEntry(path)
+	assert.True(t, errorIsNotFound(err), "expected %q to be removed: %v", path, err)
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/credentials"
 	"github.com/get-glu/glu/pkg/fs"
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
@@ -39,6 +40,10 @@ type Repository struct {
 	readme          []byte
 	sigName         string
 	sigEmail        string
+	committerName   string
+	committerEmail  string
+	signer          credentials.Signer
+	maxPushAttempts int
 	maxOpenDescs    int
 
 	mu   sync.RWMutex
@@ -56,6 +61,13 @@ type Subscriber interface {
 	Notify(ctx context.Context, refs map[string]string) error
 }
 
+// TagSubscriber is a Subscriber which is also notified when matching tags are updated.
+// Tags are identified in the refs passed to Notify by their full reference name (e.g. refs/tags/v1.0.0).
+type TagSubscriber interface {
+	Subscriber
+	Tags() []string
+}
+
 func NewRepository(ctx context.Context, logger *slog.Logger, opts ...containers.Option[Repository]) (*Repository, error) {
 	repo, empty, err := newRepository(ctx, logger, opts...)
 	if err != nil {
@@ -95,11 +107,12 @@ func NewRepository(ctx context.Context, logger *slog.Logger, opts ...containers.
 // It also exposes some common operations and ensures safe concurrent access while fetching and pushing
 func newRepository(ctx context.Context, logger *slog.Logger, opts ...containers.Option[Repository]) (_ *Repository, empty bool, err error) {
 	r := &Repository{
-		logger:        logger,
-		defaultBranch: "main",
-		sigName:       "glu bot",
-		sigEmail:      "bot@get-glu.dev",
-		readme:        []byte(`# Glu Configuration Repository`),
+		logger:          logger,
+		defaultBranch:   "main",
+		sigName:         "glu bot",
+		sigEmail:        "bot@get-glu.dev",
+		maxPushAttempts: 3,
+		readme:          []byte(`# Glu Configuration Repository`),
 		// we initialize with a noop function incase
 		// we dont start the polling loop
 		cancel: func() {},
@@ -232,7 +245,7 @@ func (r *Repository) Close() error {
 }
 
 // Subscribe registers the functions for the given branch name.
-// It will be called each time the branch is updated while holding a lock.
+// It will be called each time the branch is updated, once the repository lock is released.
 func (r *Repository) Subscribe(sub Subscriber) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -240,6 +253,17 @@ func (r *Repository) Subscribe(sub Subscriber) {
 	r.subs = append(r.subs, sub)
 }
 
+// Tracks returns true if the branch is fetched by the repository
+// (either the default branch or a branch of a subscriber).
+func (r *Repository) Tracks(branch string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.ContainsFunc(r.fetchHeads(), func(head string) bool {
+		return refMatch(branch, head)
+	})
+}
+
 func (r *Repository) fetchHeads() []string {
 	heads := map[string]struct{}{r.defaultBranch: {}}
 	for _, sub := range r.subs {
@@ -251,6 +275,19 @@ func (r *Repository) fetchHeads() []string {
 	return slices.Collect(maps.Keys(heads))
 }
 
+func (r *Repository) fetchTagPatterns() []string {
+	tags := map[string]struct{}{}
+	for _, sub := range r.subs {
+		if tsub, ok := sub.(TagSubscriber); ok {
+			for _, tag := range tsub.Tags() {
+				tags[tag] = struct{}{}
+			}
+		}
+	}
+
+	return slices.Collect(maps.Keys(tags))
+}
+
 // Fetch does a fetch for the requested head names on a configured remote.
 // If the remote is not defined, then it is a silent noop.
 // Iff specific is explicitly requested then only the heads in specific are fetched.
@@ -260,21 +297,82 @@ func (r *Repository) Fetch(ctx context.Context, specific ...string) (err error)
 		return nil
 	}
 
-	updatedRefs := map[string]plumbing.Hash{}
+	var updatedRefs map[string]plumbing.Hash
 	r.mu.Lock()
-	defer func() {
-		r.mu.Unlock()
-
-		// we update outside the lock as subscribers often re-enter
-		// the repo with view in reaction to updates to get new state
-		r.updateSubs(ctx, updatedRefs)
-	}()
+	defer func() { r.unlockAndNotify(ctx, updatedRefs) }()
 
 	heads := specific
 	if len(heads) == 0 {
 		heads = r.fetchHeads()
 	}
 
+	updatedRefs, err = r.fetch(ctx, heads...)
+	if err != nil || len(specific) > 0 {
+		return err
+	}
+
+	tags, err := r.fetchTags(ctx, r.fetchTagPatterns()...)
+	if err != nil {
+		return err
+	}
+
+	maps.Insert(updatedRefs, maps.All(tags))
+
+	return nil
+}
+
+// fetchTags fetches the tags matching the patterns from the remote and returns the
+// matching tag references mapped to the commits they identify.
+// It expects the caller to hold the repository lock.
+func (r *Repository) fetchTags(ctx context.Context, patterns ...string) (map[string]plumbing.Hash, error) {
+	if len(patterns) == 0 {
+		return map[string]plumbing.Hash{}, nil
+	}
+
+	// tags are fetched per pattern as a fetch fails entirely
+	// when any one of its refspecs matches no remote reference
+	for _, pattern := range patterns {
+		refSpec := config.RefSpec(fmt.Sprintf("+%[1]s:%[1]s", plumbing.NewTagReferenceName(pattern)))
+
+		r.logger.Debug("preparing refspec for fetch", slog.String("refspec", refSpec.String()))
+
+		if err := r.repo.FetchContext(ctx, &git.FetchOptions{
+			RemoteName:      r.remote.Name,
+			Auth:            r.auth,
+			CABundle:        r.caBundle,
+			InsecureSkipTLS: r.insecureSkipTLS,
+			RefSpecs:        []config.RefSpec{refSpec},
+			Tags:            git.NoTags,
+		}); err != nil &&
+			!errors.Is(err, git.NoErrAlreadyUpToDate) &&
+			!errors.Is(err, git.NoMatchingRefSpecError{}) {
+			return nil, err
+		}
+	}
+
+	tags, err := r.tags(patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedRefs := map[string]plumbing.Hash{}
+	for _, tag := range tags {
+		hash, err := r.ResolveTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		updatedRefs[plumbing.NewTagReferenceName(tag).String()] = hash
+	}
+
+	return updatedRefs, nil
+}
+
+// fetch fetches the heads from the remote and returns the updated remote references.
+// It expects the caller to hold the repository lock.
+func (r *Repository) fetch(ctx context.Context, heads ...string) (map[string]plumbing.Hash, error) {
+	updatedRefs := map[string]plumbing.Hash{}
+
 	var refSpecs = []config.RefSpec{}
 
 	for _, head := range heads {
@@ -297,12 +395,12 @@ func (r *Repository) Fetch(ctx context.Context, specific ...string) (err error)
 		InsecureSkipTLS: r.insecureSkipTLS,
 		RefSpecs:        refSpecs,
 	}); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
-		return err
+		return nil, err
 	}
 
 	allRefs, err := r.repo.References()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := allRefs.ForEach(func(ref *plumbing.Reference) error {
@@ -320,10 +418,10 @@ func (r *Repository) Fetch(ctx context.Context, specific ...string) (err error)
 
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return updatedRefs, nil
 }
 
 func (r *Repository) ListCommits(ctx context.Context, branch, from string, filter func(string) bool) (_ iter.Seq[*object.Commit], err error) {
@@ -376,6 +474,11 @@ type BranchOptions struct {
 	force bool
 	// pushIfEmpty on Update causes a push to happen even if commit is empty
 	pushIfEmpty bool
+	// tag on View resolves to the commit of the tag if revision is not explicitly supplied
+	// tag on Update commits on top of the tag (or revision or the head of base when the tag does
+	// not exist) and pushes the tag (creating or moving it) instead of a branch
+	// the resulting commits are not added to any branch and are only reachable from the tag
+	tag string
 }
 
 func (r *Repository) getOptions(opts ...containers.Option[BranchOptions]) *BranchOptions {
@@ -402,6 +505,12 @@ func WithRevision(rev plumbing.Hash) containers.Option[BranchOptions] {
 	}
 }
 
+func WithTag(tag string) containers.Option[BranchOptions] {
+	return func(o *BranchOptions) {
+		o.tag = tag
+	}
+}
+
 func WithForce(o *BranchOptions) {
 	o.force = true
 }
@@ -418,13 +527,17 @@ func (r *Repository) View(ctx context.Context, fn func(hash plumbing.Hash, fs fs
 
 	hash := options.revision
 	if hash == plumbing.ZeroHash {
-		hash, err = r.Resolve(options.branch)
+		if options.tag != "" {
+			hash, err = r.ResolveTag(options.tag)
+		} else {
+			hash, err = r.Resolve(options.branch)
+		}
 		if err != nil {
 			return err
 		}
 	}
 
-	r.logger.Debug("View", slog.String("branch", options.branch), slog.String("revision", hash.String()))
+	r.logger.Debug("View", slog.String("branch", options.branch), slog.String("tag", options.tag), slog.String("revision", hash.String()))
 
 	fs, err := r.newFilesystem(hash)
 	if err != nil {
@@ -435,61 +548,172 @@ func (r *Repository) View(ctx context.Context, fn func(hash plumbing.Hash, fs fs
 }
 
 func (r *Repository) UpdateAndPush(ctx context.Context, fn func(fs fs.Filesystem) (string, error), opts ...containers.Option[BranchOptions]) (hash plumbing.Hash, err error) {
+	updatedRefs := map[string]plumbing.Hash{}
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer func() { r.unlockAndNotify(ctx, updatedRefs) }()
 
 	var (
 		options = r.getOptions(opts...)
 		branch  = options.branch
 		rev     = options.revision
+		ref     = plumbing.NewBranchReferenceName(branch)
 	)
 
-	hash, err = r.Resolve(branch)
-	if err != nil {
-		return plumbing.ZeroHash, err
+	if options.tag != "" {
+		ref = plumbing.NewTagReferenceName(options.tag)
+
+		hash, err = r.resolveTagBase(options)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		// tags are not fast-forwarded like branches and so they are only
+		// created or moved while still matching the remote as last fetched
+		exists, err := r.checkTagLease(ctx, ref)
+		if err != nil {
+			return hash, err
+		}
+
+		// the base revision is not checked given the update is based on the tag
+		rev = plumbing.ZeroHash
+		options.force = exists
+	} else {
+		hash, err = r.Resolve(branch)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
 	}
 
 	if rev != plumbing.ZeroHash && rev != hash {
-		return hash, fmt.Errorf("base revision %q has changed (now %q): %w", rev, hash, errors.New("conflict"))
+		return hash, fmt.Errorf("base revision %q has changed (now %q): %w", rev, hash, ErrConflict)
+	}
+
+	var (
+		base  = hash
+		first *object.Commit
+	)
+
+	for attempt := 1; ; attempt++ {
+		commit, err := r.commitOn(ctx, hash, fn, options.pushIfEmpty)
+		if err != nil {
+			return hash, err
+		}
+
+		if first == nil {
+			first = commit
+		}
+
+		err = r.push(ctx, ref, commit, options.force)
+		if err == nil {
+			// update references
+			updated := branch
+			if ref.IsTag() {
+				updated = ref.String()
+			}
+
+			updatedRefs[updated] = commit.Hash
+
+			return commit.Hash, nil
+		}
+
+		// forced updates are never rejected for being behind the remote
+		// and tags are guarded by their lease rather than retried
+		if options.force || ref.IsTag() || r.remote == nil {
+			return hash, err
+		}
+
+		// go-git does not return typed errors for updates rejected by the remote
+		// (they are reported as the status string sent by the server) and so
+		// we fetch the branch to determine whether it moved since we last fetched
+		head, moved := r.fetchMoved(ctx, branch, hash, updatedRefs)
+		if !moved {
+			return hash, err
+		}
+
+		// the branch has been updated upstream since we last fetched
+		if rev != plumbing.ZeroHash {
+			// the caller requires the change be based on a specific revision
+			return hash, fmt.Errorf("pushing %q: %w: %w", branch, ErrConflict, err)
+		}
+
+		if attempt >= r.maxPushAttempts {
+			return hash, fmt.Errorf("pushing %q after %d attempts: %w", branch, attempt, err)
+		}
+
+		r.logger.Debug("push rejected, retrying", "branch", branch, "attempt", attempt)
+
+		// upstream changes to the same paths as our own are a real conflict
+		// changes to other paths are a benign race and our change is re-applied on the new head
+		if err := r.checkConflicts(base, head, first); err != nil {
+			return head, err
+		}
+
+		hash = head
 	}
+}
 
+// commitOn invokes fn on a filesystem for the commit identified by hash and commits the result.
+func (r *Repository) commitOn(ctx context.Context, hash plumbing.Hash, fn func(fs fs.Filesystem) (string, error), pushIfEmpty bool) (*object.Commit, error) {
 	// if rev == nil then hash will be the zero hash
 	fs, err := r.newFilesystem(hash)
 	if err != nil {
-		return hash, err
+		return nil, err
 	}
 
 	msg, err := fn(fs)
 	if err != nil {
-		return hash, err
+		return nil, err
 	}
 
 	commit, err := fs.commit(ctx, msg)
 	if err != nil {
-		if !errors.Is(err, ErrEmptyCommit) {
-			return hash, err
-		}
-
-		if !options.pushIfEmpty {
-			return hash, err
+		if !errors.Is(err, ErrEmptyCommit) || !pushIfEmpty {
+			return nil, err
 		}
 
 		// fetch commit for hash and we will attempt to re-push
-		commit, err = r.repo.CommitObject(hash)
-		if err != nil {
-			return hash, err
-		}
+		return r.repo.CommitObject(hash)
 	}
 
-	if r.remote != nil {
-		local := plumbing.NewBranchReferenceName(branch)
+	return commit, nil
+}
+
+// push sets the branch (or tag) reference to the commit and pushes it to the remote (if configured).
+func (r *Repository) push(ctx context.Context, ref plumbing.ReferenceName, commit *object.Commit, force bool) (err error) {
+	if r.remote != nil || ref.IsTag() {
+		previous, rerr := r.repo.Storer.Reference(ref)
+		if rerr != nil && !errors.Is(rerr, plumbing.ErrReferenceNotFound) {
+			return rerr
+		}
+
+		defer func() {
+			if err == nil || r.remote == nil {
+				return
+			}
+
+			// the local reference is restored when the remote rejects the commit
+			// given it would otherwise be advertised to the remote when next fetching
+			var restore error
+			if previous != nil {
+				restore = r.repo.Storer.SetReference(previous)
+			} else {
+				restore = r.repo.Storer.RemoveReference(ref)
+			}
+
+			if restore != nil {
+				err = errors.Join(err, restore)
+			}
+		}()
+
 		if err := r.repo.Storer.SetReference(
-			plumbing.NewHashReference(local, commit.Hash)); err != nil {
-			return hash, err
+			plumbing.NewHashReference(ref, commit.Hash)); err != nil {
+			return err
 		}
+	}
 
-		spec := fmt.Sprintf("%[1]s:%[1]s", local)
-		if options.force {
+	if r.remote != nil {
+		spec := fmt.Sprintf("%[1]s:%[1]s", ref)
+		if force {
 			spec = "+" + spec
 		}
 
@@ -501,11 +725,16 @@ func (r *Repository) UpdateAndPush(ctx context.Context, fn func(fs fs.Filesystem
 			RefSpecs: []config.RefSpec{
 				config.RefSpec(spec),
 			},
-		}); err != nil {
-			return hash, err
+		}); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return err
 		}
 	}
 
+	// tags are stored directly under refs/tags rather than tracked per remote
+	if ref.IsTag() {
+		return nil
+	}
+
 	remoteName := "origin"
 	if r.remote != nil {
 		remoteName = r.remote.Name
@@ -513,26 +742,134 @@ func (r *Repository) UpdateAndPush(ctx context.Context, fn func(fs fs.Filesystem
 
 	// update remote tracking reference to match
 	remoteRef := plumbing.NewHashReference(
-		plumbing.NewRemoteReferenceName(remoteName, branch),
+		plumbing.NewRemoteReferenceName(remoteName, ref.Short()),
 		commit.Hash)
 
-	if err := r.repo.Storer.SetReference(remoteRef); err != nil {
-		return hash, err
+	return r.repo.Storer.SetReference(remoteRef)
+}
+
+// checkConflicts returns ErrConflict when the paths changed between base and head
+// overlap with the paths changed between base and ours, unless both changes
+// produced identical content.
+func (r *Repository) checkConflicts(base, head plumbing.Hash, ours *object.Commit) error {
+	theirs, err := r.changedPaths(base, head)
+	if err != nil {
+		return err
 	}
 
-	// update references
-	r.updateSubs(ctx, map[string]plumbing.Hash{branch: commit.Hash})
+	changed, err := r.changedPaths(base, ours.Hash)
+	if err != nil {
+		return err
+	}
 
-	return commit.Hash, nil
+	var conflicts []string
+	for path, hash := range changed {
+		if theirHash, ok := theirs[path]; ok && theirHash != hash {
+			conflicts = append(conflicts, path)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		slices.Sort(conflicts)
+		return fmt.Errorf("paths changed upstream (now %q) %v: %w", head, conflicts, ErrConflict)
+	}
+
+	return nil
+}
+
+// changedPaths returns the paths changed between the from and to commits
+// mapped to their resulting blob hash (or the zero hash when removed).
+func (r *Repository) changedPaths(from, to plumbing.Hash) (map[string]plumbing.Hash, error) {
+	tree := func(hash plumbing.Hash) (*object.Tree, error) {
+		if hash == plumbing.ZeroHash {
+			return &object.Tree{}, nil
+		}
+
+		commit, err := r.repo.CommitObject(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		return commit.Tree()
+	}
+
+	fromTree, err := tree(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toTree, err := tree(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]plumbing.Hash{}
+	for _, change := range changes {
+		if name := change.From.Name; name != "" {
+			paths[name] = plumbing.ZeroHash
+		}
+
+		if name := change.To.Name; name != "" {
+			paths[name] = change.To.TreeEntry.Hash
+		}
+	}
+
+	return paths, nil
+}
+
+// fetchMoved fetches the branch after a rejected push and returns its new head
+// along with whether it has moved on from hash.
+// The references updated by the fetch are added to updated so that subscribers
+// are notified of them once the lock is released.
+func (r *Repository) fetchMoved(ctx context.Context, branch string, hash plumbing.Hash, updated map[string]plumbing.Hash) (plumbing.Hash, bool) {
+	refs, err := r.fetch(ctx, branch)
+	if err != nil {
+		r.logger.Debug("fetching after rejected push", "branch", branch, "error", err)
+		return hash, false
+	}
+
+	maps.Insert(updated, maps.All(refs))
+
+	head, err := r.Resolve(branch)
+	if err != nil {
+		return hash, false
+	}
+
+	return head, head != hash
+}
+
+// unlockAndNotify releases the write lock held by the caller and then notifies
+// subscribers of the updated refs.
+// Subscribers are notified outside of the lock given they commonly re-enter the
+// repository in reaction (e.g. git phases View the new revision to record their state),
+// which would otherwise deadlock against the write lock held while fetching or pushing.
+func (r *Repository) unlockAndNotify(ctx context.Context, refs map[string]plumbing.Hash) {
+	subs := slices.Clone(r.subs)
+	r.mu.Unlock()
+
+	r.updateSubs(ctx, subs, refs)
 }
 
-func (r *Repository) updateSubs(ctx context.Context, refs map[string]plumbing.Hash) {
+func (r *Repository) updateSubs(ctx context.Context, subs []Subscriber, refs map[string]plumbing.Hash) {
 	// update subscribers for each matching ref
-	for _, sub := range r.subs {
+	for _, sub := range subs {
 		matched := map[string]string{}
 		for ref, hash := range refs {
-			for _, branch := range sub.Branches() {
-				if refMatch(ref, branch) {
+			name, patterns := ref, sub.Branches()
+			if tag, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+				name, patterns = tag, nil
+				if tsub, ok := sub.(TagSubscriber); ok {
+					patterns = tsub.Tags()
+				}
+			}
+
+			for _, pattern := range patterns {
+				if refMatch(name, pattern) {
 					matched[ref] = hash.String()
 				}
 			}
@@ -563,6 +900,131 @@ func (r *Repository) Resolve(branch string) (plumbing.Hash, error) {
 	return reference.Hash(), nil
 }
 
+// ResolveTag returns the hash of the commit identified by the tag.
+// Annotated tags are peeled to the commit they reference.
+func (r *Repository) ResolveTag(tag string) (plumbing.Hash, error) {
+	reference, err := r.repo.Reference(plumbing.NewTagReferenceName(tag), true)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	annotated, err := r.repo.TagObject(reference.Hash())
+	if err != nil {
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			// lightweight tags reference the commit directly
+			return reference.Hash(), nil
+		}
+
+		return plumbing.ZeroHash, err
+	}
+
+	commit, err := annotated.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return commit.Hash, nil
+}
+
+// Tags returns the names of the tags which match any of the provided patterns.
+// A pattern is either an exact tag name or a prefix followed by *.
+func (r *Repository) Tags(patterns ...string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tags(patterns...)
+}
+
+func (r *Repository) tags(patterns ...string) (tags []string, _ error) {
+	iter, err := r.repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if slices.ContainsFunc(patterns, func(pattern string) bool {
+			return refMatch(name, pattern)
+		}) {
+			tags = append(tags, name)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	slices.Sort(tags)
+
+	return tags, nil
+}
+
+// checkTagLease returns ErrConflict when the tag on the remote no longer matches the local tag
+// (including when it was created or deleted upstream since it was last fetched) and
+// otherwise whether the tag exists.
+// go-git only supports leases on branches and so the remote references are listed instead.
+func (r *Repository) checkTagLease(ctx context.Context, ref plumbing.ReferenceName) (bool, error) {
+	var expected plumbing.Hash
+	if local, err := r.repo.Storer.Reference(ref); err == nil {
+		expected = local.Hash()
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return false, err
+	}
+
+	if r.remote == nil {
+		return expected != plumbing.ZeroHash, nil
+	}
+
+	remote, err := r.repo.Remote(r.remote.Name)
+	if err != nil {
+		return false, err
+	}
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{
+		Auth:            r.auth,
+		CABundle:        r.caBundle,
+		InsecureSkipTLS: r.insecureSkipTLS,
+	})
+	if err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return false, fmt.Errorf("listing remote references: %w", err)
+	}
+
+	var actual plumbing.Hash
+	for _, remoteRef := range refs {
+		if remoteRef.Name() == ref {
+			actual = remoteRef.Hash()
+		}
+	}
+
+	if actual != expected {
+		if actual == plumbing.ZeroHash {
+			// fetches do not prune tags and so the local tag is removed
+			// for the update to be retried from a clean slate
+			if err := r.repo.Storer.RemoveReference(ref); err != nil {
+				return false, err
+			}
+		}
+
+		return false, fmt.Errorf("tag %q has changed upstream (now %q): %w", ref.Short(), actual, ErrConflict)
+	}
+
+	return expected != plumbing.ZeroHash, nil
+}
+
+// resolveTagBase returns the commit on which an update to a tag is based.
+func (r *Repository) resolveTagBase(options *BranchOptions) (plumbing.Hash, error) {
+	hash, err := r.ResolveTag(options.tag)
+	if err == nil || !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return hash, err
+	}
+
+	if options.revision != plumbing.ZeroHash {
+		return options.revision, nil
+	}
+
+	return r.Resolve(options.base)
+}
+
 func (r *Repository) CreateBranchIfNotExists(branch string, opts ...containers.Option[BranchOptions]) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -610,13 +1072,20 @@ func (r *Repository) newFilesystem(hash plumbing.Hash) (_ *filesystem, err error
 		}
 	}
 
+	author := object.Signature{Name: r.sigName, Email: r.sigEmail}
+	committer := author
+	if r.committerName != "" {
+		committer = object.Signature{Name: r.committerName, Email: r.committerEmail}
+	}
+
 	return &filesystem{
-		logger:   r.logger,
-		base:     commit,
-		tree:     tree,
-		storage:  r.repo.Storer,
-		sigName:  r.sigName,
-		sigEmail: r.sigEmail,
+		logger:    r.logger,
+		base:      commit,
+		tree:      tree,
+		storage:   r.repo.Storer,
+		author:    author,
+		committer: committer,
+		signer:    r.signer,
 	}, nil
 }
 
@@ -682,6 +1151,30 @@ func WithSignature(name, email string) containers.Option[Repository] {
 	}
 }
 
+// WithCommitter sets the committer name and email on created commits
+// (defaults to the author signature).
+func WithCommitter(name, email string) containers.Option[Repository] {
+	return func(r *Repository) {
+		r.committerName = name
+		r.committerEmail = email
+	}
+}
+
+// WithSigner configures the repository to sign each created commit.
+func WithSigner(signer credentials.Signer) containers.Option[Repository] {
+	return func(r *Repository) {
+		r.signer = signer
+	}
+}
+
+// WithMaxPushAttempts sets the number of attempts made to push an update
+// when the push is rejected because the branch was concurrently updated (defaults to 3).
+func WithMaxPushAttempts(n int) containers.Option[Repository] {
+	return func(r *Repository) {
+		r.maxPushAttempts = max(n, 1)
+	}
+}
+
 // WithInterval sets the period between automatic fetches from the upstream (if a remote is configured)
 func WithInterval(interval time.Duration) containers.Option[Repository] {
 	return func(r *Repository) {
@@ -0,0 +1,416 @@
+package git
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/fs"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_UpdateAndPush_Retry(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		remote = newTestRemote(t)
+		ours   = remote.repository(t)
+		theirs = remote.repository(t)
+		sub    = &testSubscriber{branches: []string{"main"}}
+	)
+
+	ours.Subscribe(sub)
+
+	theirHash, err := theirs.UpdateAndPush(ctx, writeFile("theirs.txt", "theirs"))
+	require.NoError(t, err)
+
+	// ours has not fetched the change and so its first push is rejected
+	// given the change touches other paths it is re-applied on the new head
+	hash, err := ours.UpdateAndPush(ctx, writeFile("ours.txt", "ours"))
+	require.NoError(t, err)
+
+	assert.Equal(t, hash, remote.head(t, "main"))
+
+	commit, err := ours.repo.CommitObject(hash)
+	require.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{theirHash}, commit.ParentHashes)
+
+	require.NoError(t, ours.View(ctx, func(_ plumbing.Hash, fs fs.Filesystem) error {
+		assert.Equal(t, "theirs", readFile(t, fs, "theirs.txt"))
+		assert.Equal(t, "ours", readFile(t, fs, "ours.txt"))
+		return nil
+	}))
+
+	assert.Equal(t, []map[string]string{{"main": hash.String()}}, sub.notified)
+}
+
+// TestRepository_UpdateAndPush_Reentrant ensures subscribers can read from
+// the repository when notified of the refs updated by a push.
+func TestRepository_UpdateAndPush_Reentrant(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		remote = newTestRemote(t)
+		repo   = remote.repository(t)
+		read   string
+	)
+
+	repo.Subscribe(&testSubscriber{branches: []string{"main"}, notify: func(refs map[string]string) error {
+		return repo.View(ctx, func(_ plumbing.Hash, fs fs.Filesystem) error {
+			read = readFile(t, fs, "ours.txt")
+			return nil
+		}, WithRevision(plumbing.NewHash(refs["main"])))
+	}})
+
+	done := make(chan error)
+	go func() {
+		_, err := repo.UpdateAndPush(ctx, writeFile("ours.txt", "ours"))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for push (subscriber deadlocked)")
+	}
+
+	assert.Equal(t, "ours", read)
+}
+
+func TestRepository_UpdateAndPush_Conflict(t *testing.T) {
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name string
+		opts func(ours *Repository) []containers.Option[BranchOptions]
+		path string
+	}{
+		{
+			name: "same path changed upstream",
+			path: "shared.txt",
+		},
+		{
+			name: "revision moved upstream",
+			opts: func(ours *Repository) []containers.Option[BranchOptions] {
+				// the revision is current as far as ours is concerned
+				rev, err := ours.Resolve("main")
+				require.NoError(t, err)
+
+				return []containers.Option[BranchOptions]{WithRevision(rev)}
+			},
+			path: "ours.txt",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				remote = newTestRemote(t)
+				ours   = remote.repository(t)
+				theirs = remote.repository(t)
+				sub    = &testSubscriber{branches: []string{"main"}}
+			)
+
+			ours.Subscribe(sub)
+
+			theirHash, err := theirs.UpdateAndPush(ctx, writeFile("shared.txt", "theirs"))
+			require.NoError(t, err)
+
+			var opts []containers.Option[BranchOptions]
+			if test.opts != nil {
+				opts = test.opts(ours)
+			}
+
+			_, err = ours.UpdateAndPush(ctx, writeFile(test.path, "ours"), opts...)
+			require.ErrorIs(t, err, ErrConflict)
+
+			assert.Equal(t, theirHash, remote.head(t, "main"))
+
+			// subscribers are notified of the upstream changes fetched while retrying
+			assert.Equal(t, []map[string]string{{"main": theirHash.String()}}, sub.notified)
+		})
+	}
+}
+
+func TestRepository_UpdateAndPush_MaxAttempts(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		remote = newTestRemote(t)
+		ours   = remote.repository(t, WithMaxPushAttempts(1))
+		theirs = remote.repository(t)
+	)
+
+	theirHash, err := theirs.UpdateAndPush(ctx, writeFile("theirs.txt", "theirs"))
+	require.NoError(t, err)
+
+	_, err = ours.UpdateAndPush(ctx, writeFile("ours.txt", "ours"))
+	require.ErrorContains(t, err, `pushing "main" after 1 attempts`)
+	assert.NotErrorIs(t, err, ErrConflict)
+
+	assert.Equal(t, theirHash, remote.head(t, "main"))
+}
+
+func TestRepository_UpdateAndPush_Rejected(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		remote = newTestRemote(t)
+		ours   = remote.repository(t)
+	)
+
+	head := remote.head(t, "main")
+
+	// rejections which are not caused by the branch moving upstream are not retried
+	remote.storage = nil
+
+	_, err := ours.UpdateAndPush(ctx, writeFile("ours.txt", "ours"))
+	require.ErrorIs(t, err, transport.ErrRepositoryNotFound)
+	assert.NotErrorIs(t, err, ErrConflict)
+
+	remote.storage = remote.original
+	assert.Equal(t, head, remote.head(t, "main"))
+}
+
+func TestRepository_UpdateAndPush_TagLease(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		tag  = plumbing.NewTagReferenceName("release")
+		opts = []containers.Option[BranchOptions]{WithTag("release"), WithBase("main")}
+	)
+
+	t.Run("moved upstream", func(t *testing.T) {
+		var (
+			remote = newTestRemote(t)
+			ours   = remote.repository(t)
+		)
+
+		first, err := ours.UpdateAndPush(ctx, writeFile("ours.txt", "one"), opts...)
+		require.NoError(t, err)
+
+		// the existing tag is moved to a commit on top of the previous one
+		second, err := ours.UpdateAndPush(ctx, writeFile("ours.txt", "two"), opts...)
+		require.NoError(t, err)
+		assert.Equal(t, second, remote.tag(t, tag))
+
+		// another party moves the tag back before ours has fetched it
+		require.NoError(t, remote.original.SetReference(plumbing.NewHashReference(tag, first)))
+
+		_, err = ours.UpdateAndPush(ctx, writeFile("ours.txt", "three"), opts...)
+		require.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, first, remote.tag(t, tag))
+	})
+
+	t.Run("created upstream", func(t *testing.T) {
+		var (
+			remote = newTestRemote(t)
+			ours   = remote.repository(t)
+		)
+
+		require.NoError(t, remote.original.SetReference(plumbing.NewHashReference(tag, remote.head(t, "main"))))
+
+		_, err := ours.UpdateAndPush(ctx, writeFile("ours.txt", "one"), opts...)
+		require.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, remote.head(t, "main"), remote.tag(t, tag))
+	})
+
+	t.Run("deleted upstream", func(t *testing.T) {
+		var (
+			remote = newTestRemote(t)
+			ours   = remote.repository(t)
+		)
+
+		_, err := ours.UpdateAndPush(ctx, writeFile("ours.txt", "one"), opts...)
+		require.NoError(t, err)
+
+		require.NoError(t, remote.original.RemoveReference(tag))
+
+		_, err = ours.UpdateAndPush(ctx, writeFile("ours.txt", "two"), opts...)
+		require.ErrorIs(t, err, ErrConflict)
+
+		// the stale local tag is removed and so the tag is created again from its base
+		hash, err := ours.UpdateAndPush(ctx, writeFile("ours.txt", "two"), opts...)
+		require.NoError(t, err)
+		assert.Equal(t, hash, remote.tag(t, tag))
+
+		commit, err := ours.repo.CommitObject(hash)
+		require.NoError(t, err)
+		assert.Equal(t, []plumbing.Hash{remote.head(t, "main")}, commit.ParentHashes)
+	})
+}
+
+const testScheme = "repotest"
+
+var (
+	testRemotes = &testLoader{remotes: map[string]*testRemote{}}
+	testInstall sync.Once
+)
+
+// testLoader serves in-memory remotes to the go-git transport by path.
+type testLoader struct {
+	mu      sync.RWMutex
+	remotes map[string]*testRemote
+}
+
+func (l *testLoader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	remote, ok := l.remotes[ep.Path]
+	if !ok || remote.storage == nil {
+		return nil, transport.ErrRepositoryNotFound
+	}
+
+	return remote.storage, nil
+}
+
+type testRemote struct {
+	path     string
+	storage  *memory.Storage
+	original *memory.Storage
+}
+
+// newTestRemote returns an in-memory remote whose main branch contains a single commit.
+func newTestRemote(t *testing.T) *testRemote {
+	t.Helper()
+
+	testInstall.Do(func() {
+		client.InstallProtocol(testScheme, server.NewClient(testRemotes))
+	})
+
+	storage := memory.NewStorage()
+	_, err := git.Init(storage, nil)
+	require.NoError(t, err)
+
+	remote := &testRemote{path: "/" + uuid.NewString() + ".git", storage: storage, original: storage}
+
+	testRemotes.mu.Lock()
+	testRemotes.remotes[remote.path] = remote
+	testRemotes.mu.Unlock()
+
+	t.Cleanup(func() {
+		testRemotes.mu.Lock()
+		delete(testRemotes.remotes, remote.path)
+		testRemotes.mu.Unlock()
+	})
+
+	// seed the remote with an initial commit on main
+	seed, err := git.InitWithOptions(memory.NewStorage(), memfs.New(), git.InitOptions{
+		DefaultBranch: plumbing.NewBranchReferenceName("main"),
+	})
+	require.NoError(t, err)
+
+	_, err = seed.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remote.url()}})
+	require.NoError(t, err)
+
+	tree, err := seed.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, util.WriteFile(tree.Filesystem, "README.md", []byte("# Test"), 0644))
+	_, err = tree.Add("README.md")
+	require.NoError(t, err)
+
+	_, err = tree.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@get-glu.dev", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, seed.Push(&git.PushOptions{RefSpecs: []config.RefSpec{"refs/heads/main:refs/heads/main"}}))
+
+	return remote
+}
+
+func (r *testRemote) repository(t *testing.T, opts ...containers.Option[Repository]) *Repository {
+	t.Helper()
+
+	repo, err := NewRepository(context.Background(), slog.Default(), append([]containers.Option[Repository]{
+		WithRemote("origin", r.url()),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, repo.Close()) })
+
+	return repo
+}
+
+func (r *testRemote) url() string {
+	return testScheme + "://remote" + r.path
+}
+
+func (r *testRemote) head(t *testing.T, branch string) plumbing.Hash {
+	t.Helper()
+
+	ref, err := r.original.Reference(plumbing.NewBranchReferenceName(branch))
+	require.NoError(t, err)
+
+	return ref.Hash()
+}
+
+func (r *testRemote) tag(t *testing.T, tag plumbing.ReferenceName) plumbing.Hash {
+	t.Helper()
+
+	ref, err := r.original.Reference(tag)
+	require.NoError(t, err)
+
+	return ref.Hash()
+}
+
+type testSubscriber struct {
+	branches []string
+	notified []map[string]string
+	notify   func(map[string]string) error
+}
+
+func (s *testSubscriber) Branches() []string {
+	return s.branches
+}
+
+func (s *testSubscriber) Notify(_ context.Context, refs map[string]string) error {
+	s.notified = append(s.notified, refs)
+	if s.notify != nil {
+		return s.notify(refs)
+	}
+
+	return nil
+}
+
+func writeFile(path, contents string) func(fs.Filesystem) (string, error) {
+	return func(fs fs.Filesystem) (string, error) {
+		fi, err := fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return "", err
+		}
+
+		if _, err := io.WriteString(fi, contents); err != nil {
+			return "", err
+		}
+
+		return "update " + path, fi.Close()
+	}
+}
+
+func readFile(t *testing.T, fs fs.Filesystem, path string) string {
+	t.Helper()
+
+	fi, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	require.NoError(t, err)
+	defer fi.Close()
+
+	data, err := io.ReadAll(fi)
+	require.NoError(t, err)
+
+	return string(data)
+}
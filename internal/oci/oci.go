@@ -3,22 +3,60 @@ package oci
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/get-glu/glu/pkg/config"
+	"github.com/get-glu/glu/pkg/containers"
 	"github.com/get-glu/glu/pkg/credentials"
 	"github.com/get-glu/glu/pkg/phases/oci"
+	"github.com/get-glu/glu/pkg/phases/oci/verify"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 )
 
-var _ oci.Resolver = (*Repository)(nil)
+var (
+	_ oci.Resolver                 = (*Repository)(nil)
+	_ content.ReadOnlyGraphStorage = (*Repository)(nil)
+	_ registry.ReferrerLister      = (*Repository)(nil)
+)
 
 type Repository struct {
-	repo *remote.Repository
-	conf config.OCIRepository
+	repo     *remote.Repository
+	conf     config.OCIRepository
+	verifier *verify.Verifier
+	interval time.Duration
+
+	platforms        []v1.Platform
+	requirePlatforms bool
+}
+
+// WithPlatforms configures the platforms to be resolved by phases built from this repository.
+// When required, phases gate promotions of images which do not provide every platform.
+func WithPlatforms(required bool, platforms ...v1.Platform) containers.Option[Repository] {
+	return func(r *Repository) {
+		r.platforms = platforms
+		r.requirePlatforms = required
+	}
+}
+
+// WithInterval configures the polling interval used by phases built from this repository.
+// An interval of zero disables polling.
+func WithInterval(interval time.Duration) containers.Option[Repository] {
+	return func(r *Repository) {
+		r.interval = interval
+	}
+}
+
+// WithVerifier configures a verifier to be used by phases built from this repository.
+func WithVerifier(v *verify.Verifier) containers.Option[Repository] {
+	return func(r *Repository) {
+		r.verifier = v
+	}
 }
 
-func New(reference string, cred *credentials.Credential) (_ *Repository, err error) {
+func New(reference string, cred *credentials.Credential, opts ...containers.Option[Repository]) (_ *Repository, err error) {
 	repo, err := remote.NewRepository(reference)
 	if err != nil {
 		return nil, err
@@ -31,7 +69,11 @@ func New(reference string, cred *credentials.Credential) (_ *Repository, err err
 		}
 	}
 
-	return &Repository{repo: repo}, nil
+	r := &Repository{repo: repo, interval: 20 * time.Second}
+
+	containers.ApplyAll(r, opts...)
+
+	return r, nil
 }
 
 func (r *Repository) Resolve(ctx context.Context) (v1.Descriptor, io.ReadCloser, error) {
@@ -41,3 +83,38 @@ func (r *Repository) Resolve(ctx context.Context) (v1.Descriptor, io.ReadCloser,
 func (r *Repository) Reference() string {
 	return r.repo.Reference.String()
 }
+
+// Interval returns the configured polling interval (zero signifies polling is disabled).
+func (r *Repository) Interval() time.Duration {
+	return r.interval
+}
+
+// Platforms returns the configured platforms and whether or not they are all required.
+func (r *Repository) Platforms() ([]v1.Platform, bool) {
+	return r.platforms, r.requirePlatforms
+}
+
+// Verifier returns the configured verifier (or nil if verification is not configured).
+func (r *Repository) Verifier() *verify.Verifier {
+	return r.verifier
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (r *Repository) Fetch(ctx context.Context, target v1.Descriptor) (io.ReadCloser, error) {
+	return r.repo.Fetch(ctx, target)
+}
+
+// Exists returns true if the described content exists.
+func (r *Repository) Exists(ctx context.Context, target v1.Descriptor) (bool, error) {
+	return r.repo.Exists(ctx, target)
+}
+
+// Predecessors returns the descriptors of manifests directly referencing the given descriptor.
+func (r *Repository) Predecessors(ctx context.Context, desc v1.Descriptor) ([]v1.Descriptor, error) {
+	return r.repo.Predecessors(ctx, desc)
+}
+
+// Referrers lists the descriptors of manifests directly referencing the given manifest descriptor.
+func (r *Repository) Referrers(ctx context.Context, desc v1.Descriptor, artifactType string, fn func([]v1.Descriptor) error) error {
+	return r.repo.Referrers(ctx, desc, artifactType, fn)
+}
@@ -0,0 +1,36 @@
+// Package platform parses and formats OCI platforms in the form os/arch[/variant].
+// It is shared by the configuration (validation) and OCI phases (resolution).
+package platform
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Parse parses a platform in the form os/arch[/variant] (e.g. linux/arm64/v8).
+func Parse(s string) (v1.Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 || slices.Contains(parts, "") {
+		return v1.Platform{}, fmt.Errorf("invalid platform %q: expected os/arch[/variant]", s)
+	}
+
+	platform := v1.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		platform.Variant = parts[2]
+	}
+
+	return platform, nil
+}
+
+// String formats the platform in the form os/arch[/variant].
+func String(p v1.Platform) string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+
+	return s
+}
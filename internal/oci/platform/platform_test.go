@@ -0,0 +1,36 @@
+package platform
+
+import (
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		platform string
+		expected v1.Platform
+		err      string
+	}{
+		{platform: "linux/amd64", expected: v1.Platform{OS: "linux", Architecture: "amd64"}},
+		{platform: "linux/arm64/v8", expected: v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+		{platform: "linux", err: `invalid platform "linux": expected os/arch[/variant]`},
+		{platform: "linux//v8", err: `invalid platform "linux//v8": expected os/arch[/variant]`},
+		{platform: "linux/arm/v7/extra", err: `invalid platform "linux/arm/v7/extra": expected os/arch[/variant]`},
+	} {
+		t.Run(test.platform, func(t *testing.T) {
+			platform, err := Parse(test.platform)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, platform)
+			// formatting round trips the parsed platform
+			assert.Equal(t, test.platform, String(platform))
+		})
+	}
+}
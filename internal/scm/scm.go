@@ -0,0 +1,74 @@
+package scm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Client is a minimal JSON REST API client shared by the SCM proposer implementations.
+type Client struct {
+	name    string
+	client  *http.Client
+	baseURL string
+}
+
+// NewClient constructs a new client for the API rooted at baseURL.
+// The name identifies the SCM in returned errors.
+// The client is expected to authenticate requests (e.g. via an access token).
+func NewClient(name string, client *http.Client, baseURL string) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Client{name: name, client: client, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Do performs a request against the API encoding the body (when non-nil)
+// and decoding the response into out (when non-nil).
+// The path is expected to be escaped and may contain a query string.
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp, fmt.Errorf("%s: %s %s: unexpected status %d: %s", c.name, method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("%s: decoding response: %w", c.name, err)
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,243 @@
+// Package scmtest provides a fake server harness and a conformance test suite
+// for the SCM implementations of git.Proposer.
+//
+// Implementations back their fakes with a Server and run the suite from their
+// own tests by supplying a constructor for a proposer and its fake:
+//
+//	func TestSCM(t *testing.T) {
+//		scmtest.Run(t, func(t *testing.T) (git.Proposer, scmtest.Fake) {
+//			server := newFakeSCM(t)
+//			return New(server.Client(), server.URL), server
+//		})
+//	}
+package scmtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// BaseRevision is the revision fakes report as the base of every proposal.
+	BaseRevision = "base-sha"
+	// HeadRevision is the revision fakes report as the head of every proposal.
+	HeadRevision = "head-sha"
+)
+
+// Fake is implemented by the fake servers backing the proposers under test.
+// Fakes number proposals sequentially from 1 and report BaseRevision and
+// HeadRevision as the revisions of each proposal.
+type Fake interface {
+	// Comments returns the comments made on the proposal identified by id.
+	Comments(id string) []string
+}
+
+// MergingFake is implemented by the fakes backing proposers which implement git.MergingProposer.
+// Fakes report proposals as mergeable and reject merges which guard against a head
+// other than HeadRevision.
+type MergingFake interface {
+	Fake
+	// Check reports a check with the provided name and state against the head of the proposal identified by id.
+	Check(id, name string, state git.CheckState)
+	// Review records a review by user of the proposal identified by id, which either approves it
+	// or requests changes. Only the latest review of each user is expected to be considered.
+	Review(id, user string, approve bool)
+	// Merged returns the method used to merge the proposal identified by id
+	// and false when the proposal has not been merged.
+	Merged(id string) (git.MergeMethod, bool)
+}
+
+// Server is a fake SCM REST API served over HTTP.
+// Handlers registered via Handle are serialized, such that fakes
+// can access their state without any further synchronization.
+type Server struct {
+	*httptest.Server
+
+	mu  sync.Mutex
+	mux *http.ServeMux
+}
+
+// NewServer starts a new Server which is closed when the test completes.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := &Server{mux: http.NewServeMux()}
+	s.Server = httptest.NewServer(s.mux)
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Handle registers the handler for the provided pattern (see http.ServeMux).
+func (s *Server) Handle(pattern string, fn http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		fn(w, r)
+	})
+}
+
+// Decode decodes the JSON body of the request into v.
+// It responds with 400 Bad Request and returns false when the body cannot be decoded.
+func Decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// Encode responds with the provided status and v encoded as JSON.
+func Encode(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Run runs the conformance suite against proposers constructed using newSCM.
+// Each test within the suite calls newSCM to obtain a proposer backed by a new, empty fake.
+func Run(t *testing.T, newSCM func(t *testing.T) (git.Proposer, Fake)) {
+	t.Helper()
+
+	for _, test := range []struct {
+		name string
+		fn   func(*testing.T, git.Proposer, Fake)
+	}{
+		{"Lifecycle", testLifecycle},
+		{"IgnoresUnrelatedProposals", testIgnoresUnrelatedProposals},
+		{"Merging", testMerging},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			scm, fake := newSCM(t)
+			test.fn(t, scm, fake)
+		})
+	}
+}
+
+func testLifecycle(t *testing.T, scm git.Proposer, fake Fake) {
+	ctx := context.Background()
+
+	_, err := scm.GetCurrentProposal(ctx, "main", "glu/pipeline/phase")
+	require.ErrorIs(t, err, git.ErrProposalNotFound)
+
+	proposal := &git.Proposal{
+		BaseBranch: "main",
+		Branch:     "glu/pipeline/phase/abcdef",
+		Title:      "Update phase",
+		Body:       "Some description",
+	}
+
+	require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+	assert.Equal(t, "1", proposal.ID)
+	assert.NotEmpty(t, proposal.URL)
+	assert.Equal(t, BaseRevision, proposal.BaseRevision)
+	assert.Equal(t, HeadRevision, proposal.HeadRevision)
+
+	current, err := scm.GetCurrentProposal(ctx, "main", "glu/pipeline/phase")
+	require.NoError(t, err)
+	assert.Equal(t, &git.Proposal{
+		ID:           "1",
+		URL:          proposal.URL,
+		BaseBranch:   "main",
+		BaseRevision: BaseRevision,
+		Branch:       "glu/pipeline/phase/abcdef",
+		HeadRevision: HeadRevision,
+		Digest:       "abcdef",
+		Annotations:  map[string]string{},
+	}, current)
+
+	assert.True(t, scm.IsProposalOpen(ctx, current))
+
+	require.NoError(t, scm.CommentProposal(ctx, current, "superseded"))
+	assert.Equal(t, []string{"superseded"}, fake.Comments(current.ID))
+
+	require.NoError(t, scm.CloseProposal(ctx, current))
+	assert.False(t, scm.IsProposalOpen(ctx, current))
+
+	_, err = scm.GetCurrentProposal(ctx, "main", "glu/pipeline/phase")
+	require.ErrorIs(t, err, git.ErrProposalNotFound)
+}
+
+func testIgnoresUnrelatedProposals(t *testing.T, scm git.Proposer, _ Fake) {
+	ctx := context.Background()
+
+	for _, proposal := range []*git.Proposal{
+		// targets another base branch
+		{BaseBranch: "other", Branch: "glu/pipeline/phase/123456"},
+		// belongs to another phase
+		{BaseBranch: "main", Branch: "glu/pipeline/other/123456"},
+		// was not opened by glu
+		{BaseBranch: "main", Branch: "feature"},
+	} {
+		require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+	}
+
+	_, err := scm.GetCurrentProposal(ctx, "main", "glu/pipeline/phase")
+	require.ErrorIs(t, err, git.ErrProposalNotFound)
+}
+
+func testMerging(t *testing.T, proposer git.Proposer, fake Fake) {
+	scm, ok := proposer.(git.MergingProposer)
+	if !ok {
+		t.Skip("proposer does not support merging")
+	}
+
+	merging, ok := fake.(MergingFake)
+	require.True(t, ok, "fakes of merging proposers must implement MergingFake")
+
+	ctx := context.Background()
+
+	proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+	require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+
+	status, err := scm.GetProposalStatus(ctx, proposal)
+	require.NoError(t, err)
+	assert.Equal(t, &git.ProposalStatus{Mergeable: true}, status)
+	assert.Equal(t, HeadRevision, proposal.HeadRevision)
+
+	merging.Check(proposal.ID, "build", git.CheckStateSuccess)
+	merging.Check(proposal.ID, "lint", git.CheckStatePending)
+	merging.Check(proposal.ID, "test", git.CheckStateFailure)
+
+	merging.Review(proposal.ID, "alice", true)
+	merging.Review(proposal.ID, "bob", true)
+	// bob has since requested changes
+	merging.Review(proposal.ID, "bob", false)
+
+	status, err = scm.GetProposalStatus(ctx, proposal)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []git.Check{
+		{Name: "build", State: git.CheckStateSuccess},
+		{Name: "lint", State: git.CheckStatePending},
+		{Name: "test", State: git.CheckStateFailure},
+	}, status.Checks)
+	assert.Equal(t, 1, status.Approvals)
+	assert.True(t, status.ChangesRequested)
+
+	// merging is guarded by the head revision of the proposal
+	stale := *proposal
+	stale.HeadRevision = "stale-sha"
+	require.Error(t, scm.MergeProposal(ctx, &stale, git.MergeMethodSquash))
+
+	_, merged := merging.Merged(proposal.ID)
+	assert.False(t, merged)
+
+	require.NoError(t, scm.MergeProposal(ctx, proposal, git.MergeMethodSquash))
+
+	method, merged := merging.Merged(proposal.ID)
+	assert.True(t, merged)
+	assert.Equal(t, git.MergeMethodSquash, method)
+
+	assert.False(t, scm.IsProposalOpen(ctx, proposal))
+}
@@ -0,0 +1,183 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	giturls "github.com/whilp/git-urls"
+)
+
+// PushEvent describes a push of a branch to a git repository.
+type PushEvent struct {
+	// URLs are the clone and web URLs which identify the repository.
+	URLs     []string
+	Branch   string
+	Revision string
+}
+
+// Matches returns true if the event relates to the repository identified by the remote URL.
+// URLs are compared by host and path, such that http and ssh remotes for the same repository match.
+func (e PushEvent) Matches(remote string) bool {
+	return matchesRemote(e.URLs, remote)
+}
+
+// ProposalEvent describes activity which may change whether a proposal (pull or merge request)
+// is ready to be merged, such as updates to the proposal, reviews and status checks.
+type ProposalEvent struct {
+	// URLs are the clone and web URLs which identify the repository.
+	URLs []string
+}
+
+// Matches returns true if the event relates to the repository identified by the remote URL.
+func (e ProposalEvent) Matches(remote string) bool {
+	return matchesRemote(e.URLs, remote)
+}
+
+func matchesRemote(urls []string, remote string) bool {
+	target, ok := repositoryKey(remote)
+	if !ok {
+		return false
+	}
+
+	for _, u := range urls {
+		if key, ok := repositoryKey(u); ok && key == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+func repositoryKey(remote string) (string, bool) {
+	u, err := giturls.Parse(remote)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+
+	return strings.ToLower(u.Hostname() + "/" + path), true
+}
+
+// repositoryEnvelope contains the fields which identify the repository of an event.
+type repositoryEnvelope struct {
+	// Repository is set by GitHub and Gitea (and GitLab with a subset of fields)
+	Repository struct {
+		CloneURL   string `json:"clone_url"`
+		SSHURL     string `json:"ssh_url"`
+		HTMLURL    string `json:"html_url"`
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		Homepage   string `json:"homepage"`
+	} `json:"repository"`
+	// Project is set by GitLab
+	Project struct {
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+}
+
+func (e repositoryEnvelope) urls() (urls []string) {
+	for _, u := range []string{
+		e.Repository.CloneURL,
+		e.Repository.SSHURL,
+		e.Repository.HTMLURL,
+		e.Repository.GitHTTPURL,
+		e.Repository.GitSSHURL,
+		e.Repository.Homepage,
+		e.Project.GitHTTPURL,
+		e.Project.GitSSHURL,
+		e.Project.WebURL,
+	} {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+
+	return urls
+}
+
+type pushEnvelope struct {
+	repositoryEnvelope
+	ObjectKind string `json:"object_kind"`
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+}
+
+// ParsePushEvents decodes a git push webhook payload into a set of branch push events.
+// It supports GitHub, GitLab and Gitea (or Forgejo) push payloads.
+// Pushes of tags and deletions of branches are ignored.
+func ParsePushEvents(body []byte) (events []PushEvent, _ error) {
+	var push pushEnvelope
+	if err := json.Unmarshal(body, &push); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	if push.ObjectKind != "" && push.ObjectKind != "push" {
+		return nil, nil
+	}
+
+	ref := plumbing.ReferenceName(push.Ref)
+	if !ref.IsBranch() || push.Deleted || push.After == plumbing.ZeroHash.String() {
+		return nil, nil
+	}
+
+	urls := push.urls()
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	return append(events, PushEvent{
+		URLs:     urls,
+		Branch:   ref.Short(),
+		Revision: push.After,
+	}), nil
+}
+
+type proposalEnvelope struct {
+	repositoryEnvelope
+	ObjectKind string `json:"object_kind"`
+	// PullRequest is set by GitHub and Gitea pull request and review events
+	PullRequest json.RawMessage `json:"pull_request"`
+	// CheckSuite and CheckRun are set by GitHub check events
+	CheckSuite json.RawMessage `json:"check_suite"`
+	CheckRun   json.RawMessage `json:"check_run"`
+	// SHA and Context are set by GitHub commit status events
+	SHA     string `json:"sha"`
+	Context string `json:"context"`
+}
+
+// ParseProposalEvents decodes a git webhook payload into a set of proposal events.
+// It supports GitHub pull request, review, check and commit status payloads, GitLab
+// merge request and pipeline payloads and Gitea (or Forgejo) pull request payloads.
+// Any other payloads (e.g. pushes) are ignored.
+func ParseProposalEvents(body []byte) (events []ProposalEvent, _ error) {
+	var envelope proposalEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	switch {
+	case envelope.ObjectKind != "":
+		if envelope.ObjectKind != "merge_request" && envelope.ObjectKind != "pipeline" {
+			return nil, nil
+		}
+	case len(envelope.PullRequest) > 0,
+		len(envelope.CheckSuite) > 0,
+		len(envelope.CheckRun) > 0,
+		envelope.SHA != "" && envelope.Context != "":
+	default:
+		return nil, nil
+	}
+
+	urls := envelope.urls()
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	return append(events, ProposalEvent{URLs: urls}), nil
+}
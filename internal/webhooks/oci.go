@@ -0,0 +1,164 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"oras.land/oras-go/v2/registry"
+)
+
+// ImageEvent describes a push of an image to a repository in a registry.
+type ImageEvent struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// Matches returns true if the event relates to the provided OCI reference.
+// Given both the event and reference identify a tag, then the tags must also match.
+func (e ImageEvent) Matches(reference string) bool {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return false
+	}
+
+	if !strings.EqualFold(ref.Registry, e.Registry) || !strings.EqualFold(ref.Repository, e.Repository) {
+		return false
+	}
+
+	if e.Tag == "" || ref.ValidateReferenceAsDigest() == nil {
+		return true
+	}
+
+	return ref.ReferenceOrDefault() == e.Tag
+}
+
+type distributionEnvelope struct {
+	Events []struct {
+		Action string `json:"action"`
+		Target struct {
+			Digest     string `json:"digest"`
+			Repository string `json:"repository"`
+			URL        string `json:"url"`
+			Tag        string `json:"tag"`
+		} `json:"target"`
+		Request struct {
+			Host string `json:"host"`
+		} `json:"request"`
+	} `json:"events"`
+}
+
+type harborEnvelope struct {
+	Type      string `json:"type"`
+	EventData struct {
+		Resources []struct {
+			Digest      string `json:"digest"`
+			Tag         string `json:"tag"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+	} `json:"event_data"`
+}
+
+type githubPackage struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	PackageType    string `json:"package_type"`
+	PackageVersion struct {
+		PackageURL        string `json:"package_url"`
+		ContainerMetadata struct {
+			Tag struct {
+				Name   string `json:"name"`
+				Digest string `json:"digest"`
+			} `json:"tag"`
+		} `json:"container_metadata"`
+	} `json:"package_version"`
+}
+
+type githubEnvelope struct {
+	Action          string         `json:"action"`
+	Package         *githubPackage `json:"package"`
+	RegistryPackage *githubPackage `json:"registry_package"`
+}
+
+// ParseImageEvents decodes a registry notification payload into a set of image push events.
+// It supports the CNCF Distribution notification envelope, Harbor webhook payloads
+// and GitHub package (GHCR) webhook payloads.
+func ParseImageEvents(body []byte) (events []ImageEvent, _ error) {
+	var distribution distributionEnvelope
+	if err := json.Unmarshal(body, &distribution); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	for _, event := range distribution.Events {
+		if event.Action != "push" || event.Target.Repository == "" {
+			continue
+		}
+
+		host := event.Request.Host
+		if u, err := url.Parse(event.Target.URL); err == nil && u.Host != "" {
+			host = u.Host
+		}
+
+		events = append(events, ImageEvent{
+			Registry:   host,
+			Repository: event.Target.Repository,
+			Tag:        event.Target.Tag,
+			Digest:     event.Target.Digest,
+		})
+	}
+
+	var harbor harborEnvelope
+	if err := json.Unmarshal(body, &harbor); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	if harbor.Type == "PUSH_ARTIFACT" {
+		for _, resource := range harbor.EventData.Resources {
+			ref, err := registry.ParseReference(resource.ResourceURL)
+			if err != nil {
+				return nil, fmt.Errorf("parsing resource url: %w", err)
+			}
+
+			events = append(events, ImageEvent{
+				Registry:   ref.Registry,
+				Repository: ref.Repository,
+				Tag:        resource.Tag,
+				Digest:     resource.Digest,
+			})
+		}
+	}
+
+	var github githubEnvelope
+	if err := json.Unmarshal(body, &github); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	pkg := github.Package
+	if pkg == nil {
+		pkg = github.RegistryPackage
+	}
+
+	if pkg != nil && strings.EqualFold(pkg.PackageType, "container") {
+		var (
+			version = pkg.PackageVersion
+			event   = ImageEvent{
+				Registry:   "ghcr.io",
+				Repository: strings.ToLower(pkg.Namespace + "/" + pkg.Name),
+				Tag:        version.ContainerMetadata.Tag.Name,
+				Digest:     version.ContainerMetadata.Tag.Digest,
+			}
+		)
+
+		if ref, err := registry.ParseReference(version.PackageURL); err == nil {
+			event.Registry = ref.Registry
+			event.Repository = ref.Repository
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
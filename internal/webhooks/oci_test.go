@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImageEvents(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		body     string
+		expected []ImageEvent
+	}{
+		{
+			name: "distribution",
+			body: `{"events":[
+				{"action":"push","target":{"digest":"sha256:abc","repository":"team/app","url":"https://registry.local:5000/v2/team/app/manifests/sha256:abc","tag":"v1"},"request":{"host":"ignored.local"}},
+				{"action":"push","target":{"digest":"sha256:def","repository":"team/other","tag":"latest"},"request":{"host":"registry.local"}},
+				{"action":"pull","target":{"digest":"sha256:abc","repository":"team/app"},"request":{"host":"registry.local"}}
+			]}`,
+			expected: []ImageEvent{
+				{Registry: "registry.local:5000", Repository: "team/app", Tag: "v1", Digest: "sha256:abc"},
+				{Registry: "registry.local", Repository: "team/other", Tag: "latest", Digest: "sha256:def"},
+			},
+		},
+		{
+			name: "harbor",
+			body: `{"type":"PUSH_ARTIFACT","event_data":{"resources":[
+				{"digest":"sha256:abc","tag":"v1","resource_url":"harbor.local/library/app:v1"}
+			]}}`,
+			expected: []ImageEvent{
+				{Registry: "harbor.local", Repository: "library/app", Tag: "v1", Digest: "sha256:abc"},
+			},
+		},
+		{
+			name: "harbor non push",
+			body: `{"type":"DELETE_ARTIFACT","event_data":{"resources":[
+				{"digest":"sha256:abc","tag":"v1","resource_url":"harbor.local/library/app:v1"}
+			]}}`,
+		},
+		{
+			name: "github package",
+			body: `{"action":"published","package":{"name":"App","namespace":"Get-Glu","package_type":"CONTAINER","package_version":{
+				"container_metadata":{"tag":{"name":"v1","digest":"sha256:abc"}}
+			}}}`,
+			expected: []ImageEvent{
+				{Registry: "ghcr.io", Repository: "get-glu/app", Tag: "v1", Digest: "sha256:abc"},
+			},
+		},
+		{
+			name: "github registry package with package url",
+			body: `{"action":"published","registry_package":{"name":"app","namespace":"get-glu","package_type":"container","package_version":{
+				"package_url":"ghcr.io/get-glu/app:v1",
+				"container_metadata":{"tag":{"name":"v1","digest":"sha256:abc"}}
+			}}}`,
+			expected: []ImageEvent{
+				{Registry: "ghcr.io", Repository: "get-glu/app", Tag: "v1", Digest: "sha256:abc"},
+			},
+		},
+		{
+			name: "github non container package",
+			body: `{"action":"published","package":{"name":"app","namespace":"get-glu","package_type":"npm"}}`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			events, err := ParseImageEvents([]byte(test.body))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, events)
+		})
+	}
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, err := ParseImageEvents([]byte(`not json`))
+		require.Error(t, err)
+	})
+}
+
+func TestImageEvent_Matches(t *testing.T) {
+	event := ImageEvent{Registry: "registry.local", Repository: "team/app", Tag: "v1"}
+
+	assert.True(t, event.Matches("registry.local/team/app:v1"))
+	assert.True(t, event.Matches("REGISTRY.local/team/app:v1"))
+	assert.True(t, event.Matches("registry.local/team/app@sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"))
+	assert.False(t, event.Matches("registry.local/team/app:v2"))
+	assert.False(t, event.Matches("registry.local/team/other:v1"))
+	assert.False(t, event.Matches("other.local/team/app:v1"))
+
+	// events without a tag match any reference to the repository
+	assert.True(t, ImageEvent{Registry: "registry.local", Repository: "team/app"}.Matches("registry.local/team/app:v2"))
+}
@@ -0,0 +1,59 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthorized is returned when a webhook request could not be authenticated.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotConfigured is returned when a webhook request is received
+	// for a receiver which has not been configured with a secret.
+	ErrNotConfigured = errors.New("webhook not configured")
+)
+
+// Authenticate validates the provided request and its body against the secret.
+// It accepts the secret directly in the Authorization (optionally as a bearer token)
+// or X-Gitlab-Token headers, or as the key of an HMAC-SHA256 signature of the body
+// in the X-Hub-Signature-256 (GitHub) or X-Gitea-Signature (Gitea/Forgejo) headers.
+// Receivers must be configured with a secret, given an empty secret ErrNotConfigured is returned.
+func Authenticate(r *http.Request, body []byte, secret string) error {
+	if secret == "" {
+		return ErrNotConfigured
+	}
+
+	for _, token := range []string{
+		r.Header.Get("X-Gitlab-Token"),
+		r.Header.Get("Authorization"),
+		strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+	} {
+		if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+			return nil
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, signature := range []string{
+		strings.TrimPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256="),
+		r.Header.Get("X-Gitea-Signature"),
+	} {
+		if signature == "" {
+			continue
+		}
+
+		if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return ErrUnauthorized
+}
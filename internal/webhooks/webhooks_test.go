@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	const secret = "s3cr3t"
+
+	var (
+		body      = []byte(`{"events":[]}`)
+		signature = sign(secret, body)
+	)
+
+	for _, test := range []struct {
+		name     string
+		secret   string
+		headers  map[string]string
+		expected error
+	}{
+		{
+			name:     "no secret configured",
+			headers:  map[string]string{"Authorization": secret},
+			expected: ErrNotConfigured,
+		},
+		{
+			name:     "no credentials",
+			secret:   secret,
+			expected: ErrUnauthorized,
+		},
+		{
+			name:    "authorization header",
+			secret:  secret,
+			headers: map[string]string{"Authorization": secret},
+		},
+		{
+			name:    "bearer token",
+			secret:  secret,
+			headers: map[string]string{"Authorization": "Bearer " + secret},
+		},
+		{
+			name:     "wrong bearer token",
+			secret:   secret,
+			headers:  map[string]string{"Authorization": "Bearer other"},
+			expected: ErrUnauthorized,
+		},
+		{
+			name:    "gitlab token",
+			secret:  secret,
+			headers: map[string]string{"X-Gitlab-Token": secret},
+		},
+		{
+			name:    "github signature",
+			secret:  secret,
+			headers: map[string]string{"X-Hub-Signature-256": "sha256=" + signature},
+		},
+		{
+			name:     "github signature with wrong secret",
+			secret:   secret,
+			headers:  map[string]string{"X-Hub-Signature-256": "sha256=" + sign("other", body)},
+			expected: ErrUnauthorized,
+		},
+		{
+			name:    "gitea signature",
+			secret:  secret,
+			headers: map[string]string{"X-Gitea-Signature": signature},
+		},
+		{
+			name:     "gitea signature which is not hex",
+			secret:   secret,
+			headers:  map[string]string{"X-Gitea-Signature": "not-hex"},
+			expected: ErrUnauthorized,
+		},
+		{
+			name:     "signature is not accepted as a token",
+			secret:   secret,
+			headers:  map[string]string{"Authorization": signature},
+			expected: ErrUnauthorized,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/webhooks/oci", strings.NewReader(string(body)))
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+
+			err := Authenticate(req, body, test.secret)
+			if test.expected != nil {
+				assert.ErrorIs(t, err, test.expected)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("signature of another body", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/webhooks/oci", nil)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+
+		assert.ErrorIs(t, Authenticate(req, []byte(`{"events":[{}]}`), secret), ErrUnauthorized)
+	})
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
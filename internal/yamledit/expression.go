@@ -0,0 +1,151 @@
+package yamledit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// segment is a single step in a path expression.
+// It either selects a key from a mapping or an index from a sequence.
+type segment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (s segment) String() string {
+	if s.isIndex {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+
+	return "." + s.key
+}
+
+// parseExpression parses a path expression into its segments.
+// The supported syntax is a subset shared by JSONPath and YAMLPath (yq):
+//
+//	.image.digest
+//	$.image.digest
+//	.containers[0].image
+//	.annotations["app.kubernetes.io/version"]
+func parseExpression(expr string) ([]segment, error) {
+	var (
+		segments []segment
+		rest     = strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	)
+
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		// leading dot is optional (e.g. image.digest)
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			if end == 0 {
+				return nil, fmt.Errorf("expression %q: empty key", expr)
+			}
+
+			segments = append(segments, segment{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("expression %q: unterminated [", expr)
+			}
+
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, segment{key: inner[1 : len(inner)-1]})
+				continue
+			}
+
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("expression %q: invalid index %q", expr, inner)
+			}
+
+			segments = append(segments, segment{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("expression %q: unexpected character %q", expr, rest[0])
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("expression %q: empty path", expr)
+	}
+
+	return segments, nil
+}
+
+// lookup navigates the document node using the provided segments
+// and returns the node found at the end of the path.
+func lookup(doc *yaml.Node, segments []segment) (*yaml.Node, error) {
+	node := doc
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil, ErrNotFound
+		}
+
+		node = node.Content[0]
+	}
+
+	var path string
+	for _, seg := range segments {
+		path += seg.String()
+
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		next, err := step(node, seg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		node = next
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	return node, nil
+}
+
+func step(node *yaml.Node, seg segment) (*yaml.Node, error) {
+	if seg.isIndex {
+		if node.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("expected sequence: %w", ErrNotFound)
+		}
+
+		if seg.index >= len(node.Content) {
+			return nil, fmt.Errorf("index out of range: %w", ErrNotFound)
+		}
+
+		return node.Content[seg.index], nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected mapping: %w", ErrNotFound)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == seg.key {
+			return node.Content[i+1], nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
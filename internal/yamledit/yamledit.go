@@ -0,0 +1,404 @@
+// Package yamledit supports reading and updating values within YAML (and JSON) documents
+// identified by path expressions (e.g. .image.digest), while preserving the comments and
+// formatting of the original document wherever possible.
+package yamledit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrNotFound is returned when the path expression does not resolve to a value
+	ErrNotFound = errors.New("not found")
+	// ErrNotScalar is returned when the path expression resolves to a mapping or sequence
+	ErrNotScalar = errors.New("value is not a scalar")
+)
+
+// Document is a parsed YAML or JSON document.
+// Scalar values are updated by substituting only the bytes of the existing value.
+// When that is not possible (e.g. multi-line block scalars or new keys), a YAML
+// document is re-encoded from its node tree, which preserves comments but not indentation.
+type Document struct {
+	data []byte
+	root yaml.Node
+	json bool
+}
+
+// Parse parses the provided YAML document.
+// Only the first document of a multi-document stream is addressable.
+func Parse(data []byte) (*Document, error) {
+	return parse(data, false)
+}
+
+// ParseJSON parses the provided JSON document.
+func ParseJSON(data []byte) (*Document, error) {
+	return parse(data, true)
+}
+
+func parse(data []byte, json bool) (*Document, error) {
+	d := &Document{data: data, json: json}
+	if err := yaml.Unmarshal(data, &d.root); err != nil {
+		return nil, fmt.Errorf("parsing document: %w", err)
+	}
+
+	return d, nil
+}
+
+// Bytes returns the current encoded form of the document.
+func (d *Document) Bytes() []byte {
+	return d.data
+}
+
+// Node returns the node (scalar, mapping or sequence) found at the path expression.
+// The returned node must not be modified and is invalidated by calls to Set.
+func (d *Document) Node(expr string) (*yaml.Node, error) {
+	segments, err := parseExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return lookup(&d.root, segments)
+}
+
+// Get returns the scalar value found at the path expression.
+func (d *Document) Get(expr string) (string, error) {
+	node, err := d.scalar(expr)
+	if err != nil {
+		return "", err
+	}
+
+	return node.Value, nil
+}
+
+// Set updates the scalar value found at the path expression.
+// If the final key of the expression does not exist within its parent mapping
+// it is added to the mapping (YAML only).
+func (d *Document) Set(expr, value string) error {
+	node, err := d.scalar(expr)
+	if errors.Is(err, ErrNotFound) && !d.json {
+		return d.insert(expr, value)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if node.Value == value {
+		return nil
+	}
+
+	if start, end, ok := scalarExtent(d.data, node); ok {
+		token, err := encodeScalar(node, value, d.json)
+		if err == nil {
+			return d.reparse(append(append(append([]byte{}, d.data[:start]...), token...), d.data[end:]...))
+		}
+
+		if d.json {
+			return err
+		}
+	} else if d.json {
+		return fmt.Errorf("unable to locate value at line %d column %d", node.Line, node.Column)
+	}
+
+	node.Tag = scalarTag(node, value)
+	node.Value = value
+	node.Style = scalarStyle(node.Tag, value, 0)
+
+	return d.reencode()
+}
+
+func (d *Document) scalar(expr string) (*yaml.Node, error) {
+	node, err := d.Node(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("%s: %w", expr, ErrNotScalar)
+	}
+
+	return node, nil
+}
+
+// insert adds the final key of the expression to its parent mapping.
+func (d *Document) insert(expr, value string) error {
+	segments, err := parseExpression(expr)
+	if err != nil {
+		return err
+	}
+
+	last := segments[len(segments)-1]
+	if last.isIndex {
+		return fmt.Errorf("%s: %w", expr, ErrNotFound)
+	}
+
+	parent, err := lookup(&d.root, segments[:len(segments)-1])
+	if err != nil {
+		return err
+	}
+
+	if parent.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected mapping: %w", expr, ErrNotFound)
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last.key}
+	// new values are always strings, as the type of the missing key is unknown
+	val := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: scalarStyle("!!str", value, 0), Value: value}
+
+	if data, ok := d.appendLine(parent, key, val); ok {
+		return d.reparse(data)
+	}
+
+	parent.Content = append(parent.Content, key, val)
+
+	return d.reencode()
+}
+
+// appendLine attempts to add the key and value as a new line following the last entry
+// of a block mapping, at the same indentation as the existing keys.
+func (d *Document) appendLine(parent, key, value *yaml.Node) ([]byte, bool) {
+	if parent.Style&yaml.FlowStyle != 0 || len(parent.Content) < 2 {
+		return nil, false
+	}
+
+	lastKey, lastValue := parent.Content[len(parent.Content)-2], parent.Content[len(parent.Content)-1]
+	if lastValue.Kind != yaml.ScalarNode || lastValue.Line != lastKey.Line {
+		return nil, false
+	}
+
+	if _, _, ok := scalarExtent(d.data, lastValue); !ok {
+		return nil, false
+	}
+
+	start, ok := offset(d.data, lastValue.Line, 1)
+	if !ok {
+		return nil, false
+	}
+
+	// insert following the line of the last value (including any trailing comment)
+	end := len(d.data)
+	prefix := ""
+	if i := bytes.IndexByte(d.data[start:], '\n'); i >= 0 {
+		end = start + i + 1
+	} else {
+		prefix = "\n"
+	}
+
+	entry, err := yaml.Marshal(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}})
+	if err != nil || bytes.Count(entry, []byte("\n")) != 1 {
+		return nil, false
+	}
+
+	line := prefix + strings.Repeat(" ", parent.Content[0].Column-1) + string(entry)
+
+	return append(append(append([]byte{}, d.data[:end]...), line...), d.data[end:]...), true
+}
+
+func (d *Document) reencode() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&d.root); err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	return d.reparse(buf.Bytes())
+}
+
+func (d *Document) reparse(data []byte) error {
+	updated, err := parse(data, d.json)
+	if err != nil {
+		return err
+	}
+
+	*d = *updated
+
+	return nil
+}
+
+// encodeScalar encodes the value as a single line token in the style of the existing node.
+func encodeScalar(node *yaml.Node, value string, asJSON bool) ([]byte, error) {
+	if asJSON {
+		// numbers, booleans and null remain as literals when the new value is of the same kind
+		if node.Style != yaml.DoubleQuotedStyle && resolveTag(value) == node.ShortTag() {
+			return []byte(value), nil
+		}
+
+		return json.Marshal(value)
+	}
+
+	tag := scalarTag(node, value)
+	style := scalarStyle(tag, value, node.Style&^(yaml.LiteralStyle|yaml.FoldedStyle|yaml.FlowStyle|yaml.TaggedStyle))
+	data, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Style: style, Value: value})
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	if bytes.ContainsAny(data, "\n") {
+		return nil, fmt.Errorf("value %q cannot be encoded on a single line", value)
+	}
+
+	return data, nil
+}
+
+// scalarTag returns the tag of the existing node when the new value resolves to it
+// (e.g. an integer replaced with an integer) and otherwise the string tag.
+func scalarTag(node *yaml.Node, value string) string {
+	if tag := node.ShortTag(); resolveTag(value) == tag {
+		return tag
+	}
+
+	return "!!str"
+}
+
+// scalarStyle returns the style used to encode a value with the provided tag.
+// Plain strings which would not be read back as strings are double quoted.
+func scalarStyle(tag, value string, style yaml.Style) yaml.Style {
+	if tag == "!!str" && style == 0 && !isPlainString(value) {
+		return yaml.DoubleQuotedStyle
+	}
+
+	return style
+}
+
+// isPlainString reports whether the plain form of the value resolves to a string
+// under both YAML 1.2 and YAML 1.1. The latter is still used by many consumers
+// (e.g. Helm and kustomize), which read values such as yes, on or 1_000 as booleans and numbers.
+func isPlainString(value string) bool {
+	if resolveTag(value) != "!!str" {
+		return false
+	}
+
+	switch value {
+	case "", "~", "null", "Null", "NULL",
+		"y", "Y", "yes", "Yes", "YES", "n", "N", "no", "No", "NO",
+		"true", "True", "TRUE", "false", "False", "FALSE",
+		"on", "On", "ON", "off", "Off", "OFF":
+		return false
+	}
+
+	if c := value[0]; c != '+' && c != '-' && c != '.' && (c < '0' || c > '9') {
+		return true
+	}
+
+	if base60.MatchString(value) {
+		return false
+	}
+
+	// underscores are permitted between digits of YAML 1.1 numbers
+	number := strings.ReplaceAll(value, "_", "")
+	if _, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return false
+	}
+
+	if _, err := strconv.ParseFloat(number, 64); err == nil {
+		return false
+	}
+
+	return true
+}
+
+// base60 matches YAML 1.1 sexagesimal integers and floats (e.g. 1:30).
+var base60 = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(?::[0-5]?[0-9])+(?:\.[0-9_]*)?$`)
+
+// resolveTag returns the tag a plain scalar value resolves to (e.g. !!int for 5).
+func resolveTag(value string) string {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &node); err != nil || len(node.Content) == 0 {
+		return "!!str"
+	}
+
+	if node.Content[0].Kind != yaml.ScalarNode {
+		return "!!str"
+	}
+
+	return node.Content[0].ShortTag()
+}
+
+// scalarExtent returns the byte range of a single line scalar token in data.
+func scalarExtent(data []byte, node *yaml.Node) (start, end int, ok bool) {
+	start, ok = offset(data, node.Line, node.Column)
+	if !ok || node.Style&yaml.TaggedStyle != 0 {
+		return 0, 0, false
+	}
+
+	line := data[start:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		if len(line) == 0 || line[0] != '"' {
+			return 0, 0, false
+		}
+
+		for i := 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return start, start + i + 1, true
+			}
+		}
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		if len(line) == 0 || line[0] != '\'' {
+			return 0, 0, false
+		}
+
+		for i := 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+
+				return start, start + i + 1, true
+			}
+		}
+	case node.Style == 0:
+		if bytes.HasPrefix(line, []byte(node.Value)) {
+			return start, start + len(node.Value), true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// offset converts a 1-based line and (rune) column into a byte offset.
+func offset(data []byte, line, column int) (int, bool) {
+	var pos int
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(data[pos:], '\n')
+		if i < 0 {
+			return 0, false
+		}
+
+		pos += i + 1
+	}
+
+	for c := 1; c < column; c++ {
+		if pos >= len(data) || data[pos] == '\n' {
+			return 0, false
+		}
+
+		_, size := utf8.DecodeRune(data[pos:])
+		pos += size
+	}
+
+	return pos, true
+}
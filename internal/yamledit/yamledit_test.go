@@ -0,0 +1,286 @@
+package yamledit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `# deployment values
+image:
+  repository: ghcr.io/get-glu/app # the application image
+  tag: "v1.0.0"
+  digest: 'sha256:aaa'
+replicas: 2
+annotations:
+  app.kubernetes.io/version: v1.0.0
+containers:
+  - name: app
+    image: ghcr.io/get-glu/app:v1.0.0
+`
+
+func TestDocument_Get(t *testing.T) {
+	doc, err := Parse([]byte(document))
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		expr     string
+		expected string
+		err      error
+	}{
+		{expr: ".image.repository", expected: "ghcr.io/get-glu/app"},
+		{expr: "$.image.tag", expected: "v1.0.0"},
+		{expr: "image.digest", expected: "sha256:aaa"},
+		{expr: ".replicas", expected: "2"},
+		{expr: `.annotations["app.kubernetes.io/version"]`, expected: "v1.0.0"},
+		{expr: ".containers[0].image", expected: "ghcr.io/get-glu/app:v1.0.0"},
+		{expr: ".containers[1].image", err: ErrNotFound},
+		{expr: ".image.pullPolicy", err: ErrNotFound},
+		{expr: ".replicas.count", err: ErrNotFound},
+		{expr: ".image", err: ErrNotScalar},
+	} {
+		t.Run(test.expr, func(t *testing.T) {
+			value, err := doc.Get(test.expr)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestDocument_Set(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		document string
+		expr     string
+		value    string
+		expected string
+	}{
+		{
+			name:     "plain",
+			document: "image:\n  repository: ghcr.io/get-glu/app # the application image\n  tag: v1.0.0\n",
+			expr:     ".image.repository",
+			value:    "registry.local/app",
+			expected: "image:\n  repository: registry.local/app # the application image\n  tag: v1.0.0\n",
+		},
+		{
+			name:     "double quoted",
+			document: "tag: \"v1.0.0\" # pinned\n",
+			expr:     ".tag",
+			value:    "v1.1.0",
+			expected: "tag: \"v1.1.0\" # pinned\n",
+		},
+		{
+			name:     "single quoted",
+			document: "digest: 'sha256:aaa'\n",
+			expr:     ".digest",
+			value:    "sha256:fff",
+			expected: "digest: 'sha256:fff'\n",
+		},
+		{
+			name:     "sequence",
+			document: "containers:\n  - name: app\n    image: app:v1\n  - name: sidecar\n    image: sidecar:v1\n",
+			expr:     ".containers[1].image",
+			value:    "sidecar:v2",
+			expected: "containers:\n  - name: app\n    image: app:v1\n  - name: sidecar\n    image: sidecar:v2\n",
+		},
+		{
+			name:     "integer",
+			document: "replicas: 2 # scaled\n",
+			expr:     ".replicas",
+			value:    "3",
+			expected: "replicas: 3 # scaled\n",
+		},
+		{
+			name:     "integer replaced with string",
+			document: "replicas: 2\n",
+			expr:     ".replicas",
+			value:    "two",
+			expected: "replicas: two\n",
+		},
+		{
+			name:     "string resembling a float",
+			document: "tag: v1.0.0\n",
+			expr:     ".tag",
+			value:    "1.10",
+			expected: "tag: \"1.10\"\n",
+		},
+		{
+			name:     "string resembling a YAML 1.1 boolean",
+			document: "enabled: v1\n",
+			expr:     ".enabled",
+			value:    "on",
+			expected: "enabled: \"on\"\n",
+		},
+		{
+			name:     "version",
+			document: "version: 0.1.9\n",
+			expr:     ".version",
+			value:    "0.1.10",
+			expected: "version: 0.1.10\n",
+		},
+		{
+			name:     "unchanged",
+			document: "tag:   v1.0.0\n",
+			expr:     ".tag",
+			value:    "v1.0.0",
+			expected: "tag:   v1.0.0\n",
+		},
+		{
+			name:     "block scalar",
+			document: "# values\ndescription: |\n  multiple\n  lines\n",
+			expr:     ".description",
+			value:    "single",
+			expected: "# values\ndescription: single\n",
+		},
+		{
+			name:     "insert",
+			document: "image:\n  repository: ghcr.io/get-glu/app # the application image\nreplicas: 2\n",
+			expr:     ".image.tag",
+			value:    "v1.1.0",
+			expected: "image:\n  repository: ghcr.io/get-glu/app # the application image\n  tag: v1.1.0\nreplicas: 2\n",
+		},
+		{
+			name:     "insert without trailing newline",
+			document: "image:\n  repository: ghcr.io/get-glu/app",
+			expr:     ".image.tag",
+			value:    "v1.1.0",
+			expected: "image:\n  repository: ghcr.io/get-glu/app\n  tag: v1.1.0\n",
+		},
+		{
+			name:     "insert into flow mapping",
+			document: "image: {repository: app}\n",
+			expr:     ".image.tag",
+			value:    "v1.1.0",
+			expected: "image: {repository: app, tag: v1.1.0}\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := Parse([]byte(test.document))
+			require.NoError(t, err)
+
+			require.NoError(t, doc.Set(test.expr, test.value))
+			assert.Equal(t, test.expected, string(doc.Bytes()))
+
+			value, err := doc.Get(test.expr)
+			require.NoError(t, err)
+			assert.Equal(t, test.value, value)
+		})
+	}
+}
+
+func TestDocument_Set_Insert(t *testing.T) {
+	// inserted values are always strings and are quoted whenever
+	// their plain form would be read as another type
+	for _, test := range []struct {
+		value    string
+		expected string
+	}{
+		{value: "v1.10", expected: "v1.10"},
+		{value: "1.2.3", expected: "1.2.3"},
+		{value: "sha256:aaa", expected: "sha256:aaa"},
+		{value: "1.10", expected: `"1.10"`},
+		{value: "10", expected: `"10"`},
+		{value: "1e3", expected: `"1e3"`},
+		{value: "0x1F", expected: `"0x1F"`},
+		{value: "010", expected: `"010"`},
+		{value: "1_000", expected: `"1_000"`},
+		{value: "1:30", expected: `"1:30"`},
+		{value: ".inf", expected: `".inf"`},
+		{value: "true", expected: `"true"`},
+		{value: "yes", expected: `"yes"`},
+		{value: "on", expected: `"on"`},
+		{value: "N", expected: `"N"`},
+		{value: "Off", expected: `"Off"`},
+		{value: "null", expected: `"null"`},
+		{value: "~", expected: `"~"`},
+		{value: "", expected: `""`},
+	} {
+		t.Run(test.value, func(t *testing.T) {
+			doc, err := Parse([]byte("image:\n  repository: app\n"))
+			require.NoError(t, err)
+
+			require.NoError(t, doc.Set(".image.tag", test.value))
+			assert.Equal(t, "image:\n  repository: app\n  tag: "+test.expected+"\n", string(doc.Bytes()))
+
+			node, err := doc.Node(".image.tag")
+			require.NoError(t, err)
+			assert.Equal(t, "!!str", node.ShortTag())
+			assert.Equal(t, test.value, node.Value)
+		})
+	}
+}
+
+func TestDocument_Set_Errors(t *testing.T) {
+	doc, err := Parse([]byte(document))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, doc.Set(".image", "app"), ErrNotScalar)
+	assert.ErrorIs(t, doc.Set(".containers[1].image", "app"), ErrNotFound)
+	assert.ErrorIs(t, doc.Set(".image.tag.major", "1"), ErrNotFound)
+	assert.ErrorIs(t, doc.Set(".missing.tag", "v1"), ErrNotFound)
+
+	// the document is left unchanged
+	assert.Equal(t, document, string(doc.Bytes()))
+}
+
+func TestDocument_SetJSON(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		expr     string
+		value    string
+		expected string
+	}{
+		{
+			name:     "string",
+			expr:     ".image.tag",
+			value:    "v1.1.0",
+			expected: `{"image": {"tag": "v1.1.0", "digest": null}, "replicas": 2}`,
+		},
+		{
+			name:     "number",
+			expr:     ".replicas",
+			value:    "3",
+			expected: `{"image": {"tag": "v1.0.0", "digest": null}, "replicas": 3}`,
+		},
+		{
+			name:     "number replaced with string",
+			expr:     ".replicas",
+			value:    "two",
+			expected: `{"image": {"tag": "v1.0.0", "digest": null}, "replicas": "two"}`,
+		},
+		{
+			name:     "string resembling a number",
+			expr:     ".image.tag",
+			value:    "1.10",
+			expected: `{"image": {"tag": "1.10", "digest": null}, "replicas": 2}`,
+		},
+		{
+			name:     "null",
+			expr:     ".image.digest",
+			value:    "sha256:fff",
+			expected: `{"image": {"tag": "v1.0.0", "digest": "sha256:fff"}, "replicas": 2}`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := ParseJSON([]byte(`{"image": {"tag": "v1.0.0", "digest": null}, "replicas": 2}`))
+			require.NoError(t, err)
+
+			require.NoError(t, doc.Set(test.expr, test.value))
+			assert.Equal(t, test.expected, string(doc.Bytes()))
+		})
+	}
+
+	t.Run("missing key", func(t *testing.T) {
+		doc, err := ParseJSON([]byte(`{"image": {}}`))
+		require.NoError(t, err)
+
+		// keys are not inserted into JSON documents
+		assert.ErrorIs(t, doc.Set(".image.tag", "v1.1.0"), ErrNotFound)
+	})
+}
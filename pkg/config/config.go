@@ -20,6 +20,7 @@ type Config struct {
 	Server      Server      `glu:"server"`
 	Metrics     Metrics     `glu:"metrics"`
 	History     History     `glu:"history"`
+	Resources   Resources   `glu:"resources"`
 }
 
 type Sources struct {
@@ -31,12 +32,31 @@ type defaulter interface {
 	setDefaults() error
 }
 
+var _ defaulter = (*Config)(nil)
+
 func (c *Config) SetDefaults() error {
 	return processValue(reflect.ValueOf(c).Elem(), func(d defaulter) error {
 		return d.setDefaults()
 	})
 }
 
+// setDefaults sets defaults which span multiple sections of the configuration.
+// It is called before the defaults of each section are set, such that values
+// which have not been explicitly configured can still be identified.
+func (c *Config) setDefaults() error {
+	// pushes are delivered via webhook so polling is only a fallback
+	// for remotes which have not configured an explicit interval
+	if fallback := c.Server.Webhooks.Git.FallbackInterval; fallback > 0 {
+		for _, repo := range c.Sources.Git {
+			if repo.Remote != nil && repo.Remote.Interval == 0 {
+				repo.Remote.Interval = fallback
+			}
+		}
+	}
+
+	return nil
+}
+
 type validater interface {
 	validate() error
 }
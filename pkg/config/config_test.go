@@ -183,9 +183,10 @@ func TestConfig(t *testing.T) {
 					Git: GitSources{
 						"default": &GitRepository{
 							Remote: &Remote{
-								Name:     "upstream",
-								URL:      "https://corp-repos/default.git",
-								Interval: 10 * time.Second,
+								Name:            "upstream",
+								URL:             "https://corp-repos/default.git",
+								Interval:        10 * time.Second,
+								MaxPushAttempts: 3,
 							},
 							DefaultBranch: "main",
 						},
@@ -210,10 +211,11 @@ func TestConfig(t *testing.T) {
 					Git: GitSources{
 						"custom": &GitRepository{
 							Remote: &Remote{
-								Name:       "origin",
-								URL:        "https://corp-repos/custom",
-								Credential: "vault",
-								Interval:   time.Minute,
+								Name:            "origin",
+								URL:             "https://corp-repos/custom",
+								Credential:      "vault",
+								Interval:        time.Minute,
+								MaxPushAttempts: 5,
 							},
 							Path:          "v1",
 							DefaultBranch: "release-v1",
@@ -231,6 +233,47 @@ func TestConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			path: "testdata/git/fallback",
+			expected: &Config{
+				Log: Log{Level: "info"},
+				Sources: Sources{
+					Git: GitSources{
+						// explicit intervals take precedence over the fallback interval
+						"polled": &GitRepository{
+							Remote: &Remote{
+								Name:            "origin",
+								URL:             "https://corp-repos/polled",
+								Interval:        time.Minute,
+								MaxPushAttempts: 3,
+							},
+							DefaultBranch: "main",
+						},
+						"fallback": &GitRepository{
+							Remote: &Remote{
+								Name:            "origin",
+								URL:             "https://corp-repos/fallback",
+								Interval:        10 * time.Minute,
+								MaxPushAttempts: 3,
+							},
+							DefaultBranch: "main",
+						},
+					},
+				},
+				Server: Server{
+					Port:     8080,
+					Host:     "0.0.0.0",
+					Protocol: "http",
+					Webhooks: Webhooks{
+						Git: GitWebhook{Secret: "s3cr3t", FallbackInterval: 10 * time.Minute},
+					},
+				},
+				Metrics: Metrics{
+					Enabled:  true,
+					Exporter: MetricsExporterPrometheus,
+				},
+			},
+		},
 		{
 			path: "testdata/history/file/default",
 			expected: &Config{
@@ -254,6 +297,53 @@ func TestConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			path: "testdata/history/sqlite/default",
+			expected: &Config{
+				Log: Log{Level: "info"},
+				History: History{
+					SQLite: SQLiteDBs{
+						"default": &SQLiteDB{
+							Name: "default",
+							Path: "history.sqlite",
+						},
+					},
+				},
+				Server: Server{
+					Port:     8080,
+					Host:     "0.0.0.0",
+					Protocol: "http",
+				},
+				Metrics: Metrics{
+					Enabled:  true,
+					Exporter: MetricsExporterPrometheus,
+				},
+			},
+		},
+		{
+			path: "testdata/history/redis/default",
+			expected: &Config{
+				Log: Log{Level: "info"},
+				History: History{
+					Redis: RedisDBs{
+						"default": &RedisDB{
+							Name:    "default",
+							Address: "localhost:6379",
+							Prefix:  "glu",
+						},
+					},
+				},
+				Server: Server{
+					Port:     8080,
+					Host:     "0.0.0.0",
+					Protocol: "http",
+				},
+				Metrics: Metrics{
+					Enabled:  true,
+					Exporter: MetricsExporterPrometheus,
+				},
+			},
+		},
 		{
 			path: "testdata/json",
 			expected: &Config{
@@ -262,10 +352,11 @@ func TestConfig(t *testing.T) {
 					Git: GitSources{
 						"custom": &GitRepository{
 							Remote: &Remote{
-								Name:       "origin",
-								URL:        "https://corp-repos/custom",
-								Credential: "vault",
-								Interval:   time.Minute,
+								Name:            "origin",
+								URL:             "https://corp-repos/custom",
+								Credential:      "vault",
+								Interval:        time.Minute,
+								MaxPushAttempts: 3,
 							},
 							Path:          "v1",
 							DefaultBranch: "release-v1",
@@ -301,3 +392,62 @@ func TestReadFromFileWhenNoConfig(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 }
+
+func TestOCIRepositoryValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		platforms []string
+		require   bool
+		expected  string
+	}{
+		{name: "no platforms"},
+		{name: "os and arch", platforms: []string{"linux/amd64"}, require: true},
+		{name: "os, arch and variant", platforms: []string{"linux/arm64/v8"}},
+		{name: "missing arch", platforms: []string{"linux"}, expected: `invalid platform "linux"`},
+		{name: "empty variant", platforms: []string{"linux/arm64/"}, expected: `invalid platform "linux/arm64/"`},
+		{name: "too many parts", platforms: []string{"linux/arm64/v8/extra"}, expected: `invalid platform "linux/arm64/v8/extra"`},
+		{name: "require without platforms", require: true, expected: "require_platforms"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &OCIRepository{
+				Reference:        "registry.local/app:latest",
+				Platforms:        tt.platforms,
+				RequirePlatforms: tt.require,
+			}
+
+			err := repo.validate()
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestServerValidate_GitWebhook(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		webhook  GitWebhook
+		expected string
+	}{
+		{name: "disabled"},
+		{name: "secret", webhook: GitWebhook{Secret: "s3cr3t"}},
+		{name: "fallback interval", webhook: GitWebhook{Secret: "s3cr3t", FallbackInterval: time.Minute}},
+		{name: "fallback interval without secret", webhook: GitWebhook{FallbackInterval: time.Minute}, expected: "server.webhooks.git.secret"},
+		{name: "negative fallback interval", webhook: GitWebhook{Secret: "s3cr3t", FallbackInterval: -time.Minute}, expected: "server.webhooks.git.fallback_interval"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &Server{Protocol: ProtocolHTTP, Webhooks: Webhooks{Git: tt.webhook}}
+
+			err := server.validate()
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
@@ -27,6 +27,7 @@ const (
 	CredentialTypeAccessToken = CredentialType("access_token")
 	CredentialTypeGitHubApp   = CredentialType("github_app")
 	CredentialTypeDockerLocal = CredentialType("docker_local")
+	CredentialTypeGPG         = CredentialType("gpg")
 )
 
 type Credential struct {
@@ -35,6 +36,7 @@ type Credential struct {
 	SSH         *SSHAuthConfig   `glu:"ssh"`
 	AccessToken *string          `glu:"access_token"`
 	GitHubApp   *GitHubAppConfig `glu:"github_app"`
+	GPG         *GPGConfig       `glu:"gpg"`
 }
 
 func (c *Credential) validate() error {
@@ -49,6 +51,8 @@ func (c *Credential) validate() error {
 		}
 	case CredentialTypeGitHubApp:
 		return c.GitHubApp.validate()
+	case CredentialTypeGPG:
+		return c.GPG.validate()
 	case CredentialTypeDockerLocal:
 	default:
 		return fmt.Errorf("unexpected credential type %q", c.Type)
@@ -132,3 +136,29 @@ func (c *GitHubAppConfig) validate() (err error) {
 
 	return nil
 }
+
+// GPGConfig provides configuration for an armored OpenPGP private key
+// used to sign commits.
+type GPGConfig struct {
+	PrivateKeyBytes string `glu:"private_key_bytes"`
+	PrivateKeyPath  string `glu:"private_key_path"`
+	Passphrase      string `glu:"passphrase"`
+}
+
+func (c *GPGConfig) validate() (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("gpg: %w", err)
+		}
+	}()
+
+	if c == nil {
+		return errors.New("configuration is mising")
+	}
+
+	if (c.PrivateKeyBytes == "" && c.PrivateKeyPath == "") || (c.PrivateKeyBytes != "" && c.PrivateKeyPath != "") {
+		return errors.New("please provide exclusively one of private_key_bytes or private_key_path")
+	}
+
+	return nil
+}
@@ -39,6 +39,10 @@ type GitRepository struct {
 	DefaultBranch string     `glu:"default_branch"`
 	Remote        *Remote    `glu:"remote"`
 	Proposals     *Proposals `glu:"proposals"`
+	Links         *Links     `glu:"links"`
+	Author        *Identity  `glu:"author"`
+	Committer     *Identity  `glu:"committer"`
+	Signing       *Signing   `glu:"signing"`
 }
 
 func (r *GitRepository) validate() error {
@@ -60,6 +64,52 @@ func (r *GitRepository) validate() error {
 		}
 	}
 
+	if proposals := r.Proposals; proposals != nil {
+		switch proposals.Provider {
+		case ProposalsProviderGitHub,
+			ProposalsProviderGitLab,
+			ProposalsProviderGitea,
+			ProposalsProviderBitbucket,
+			ProposalsProviderBitbucketDataCenter:
+		default:
+			return errFieldWrap("proposals.provider", fmt.Errorf("unexpected provider %q", proposals.Provider))
+		}
+	}
+
+	if links := r.Links; links != nil {
+		switch links.Provider {
+		case "",
+			ProposalsProviderGitHub,
+			ProposalsProviderGitLab,
+			ProposalsProviderGitea,
+			ProposalsProviderBitbucket,
+			ProposalsProviderBitbucketDataCenter:
+		default:
+			return errFieldWrap("links.provider", fmt.Errorf("unexpected provider %q", links.Provider))
+		}
+	}
+
+	for _, identity := range []struct {
+		field string
+		*Identity
+	}{{"author", r.Author}, {"committer", r.Committer}} {
+		if identity.Identity == nil {
+			continue
+		}
+
+		if identity.Name == "" {
+			return errFieldRequired(identity.field + ".name")
+		}
+
+		if identity.Email == "" {
+			return errFieldRequired(identity.field + ".email")
+		}
+	}
+
+	if signing := r.Signing; signing != nil && signing.Credential == "" {
+		return errFieldRequired("signing.credential")
+	}
+
 	return nil
 }
 
@@ -80,6 +130,14 @@ func (r *GitRepository) setDefaults(name string) error {
 		if remote.Interval < 1 {
 			remote.Interval = 10 * time.Second
 		}
+
+		if remote.MaxPushAttempts < 1 {
+			remote.MaxPushAttempts = 3
+		}
+	}
+
+	if proposals := r.Proposals; proposals != nil && proposals.Provider == "" {
+		proposals.Provider = ProposalsProviderGitHub
 	}
 
 	return nil
@@ -90,8 +148,60 @@ type Remote struct {
 	URL        string        `glu:"url"`
 	Credential string        `glu:"credential"`
 	Interval   time.Duration `glu:"interval"`
+	// MaxPushAttempts is the number of times a push rejected because the remote
+	// branch moved is re-applied on the new head before giving up
+	MaxPushAttempts int `glu:"max_push_attempts"`
 }
 
+const (
+	ProposalsProviderGitHub = "github"
+	ProposalsProviderGitLab = "gitlab"
+	ProposalsProviderGitea  = "gitea"
+	// ProposalsProviderBitbucket targets Bitbucket Cloud
+	ProposalsProviderBitbucket = "bitbucket"
+	// ProposalsProviderBitbucketDataCenter targets Bitbucket Data Center (or Server)
+	ProposalsProviderBitbucketDataCenter = "bitbucket_datacenter"
+)
+
 type Proposals struct {
+	// Provider is the SCM used to manage proposals
+	// (github, gitlab, gitea, bitbucket or bitbucket_datacenter).
+	Provider string `glu:"provider"`
+	// BaseURL overrides the root of the providers API
+	// (derived from the remote URL host by default).
+	BaseURL    string `glu:"base_url"`
+	Credential string `glu:"credential"`
+	// Reviewers are requested on each created proposal (bitbucket only).
+	Reviewers []string `glu:"reviewers"`
+	// DefaultReviewers requests the repositories default reviewers (bitbucket only).
+	DefaultReviewers bool `glu:"default_reviewers"`
+}
+
+// Links configures the generation of URLs for commits, branches,
+// compare views and files in the SCM web UI.
+// Each template is a Go text/template (see pkg/scm/links for the available fields).
+type Links struct {
+	// Provider selects the builtin templates (github, gitlab, gitea, bitbucket or bitbucket_datacenter).
+	// It defaults to a provider detected from the remote host, or otherwise the proposals provider.
+	Provider string `glu:"provider"`
+	// BaseURL overrides the root of the SCM web UI (derived from the remote URL host by default).
+	BaseURL string `glu:"base_url"`
+	Commit  string `glu:"commit"`
+	Branch  string `glu:"branch"`
+	Compare string `glu:"compare"`
+	File    string `glu:"file"`
+}
+
+// Identity is the name and email recorded on commits.
+type Identity struct {
+	Name  string `glu:"name"`
+	Email string `glu:"email"`
+}
+
+// Signing configures signing of each commit created in the repository.
+type Signing struct {
+	// Credential identifies the key used to sign commits.
+	// Credentials of type gpg produce OpenPGP signatures and
+	// credentials of type ssh produce SSH signatures.
 	Credential string `glu:"credential"`
 }
@@ -4,16 +4,21 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 )
 
 type HistoryType string
 
 const (
-	HistoryTypeFile HistoryType = "file"
+	HistoryTypeFile   HistoryType = "file"
+	HistoryTypeSQLite HistoryType = "sqlite"
+	HistoryTypeRedis  HistoryType = "redis"
 )
 
 type History struct {
-	File FileDBs `glu:"file"`
+	File   FileDBs   `glu:"file"`
+	SQLite SQLiteDBs `glu:"sqlite"`
+	Redis  RedisDBs  `glu:"redis"`
 }
 
 type FileDBs map[string]*FileDB
@@ -81,3 +86,124 @@ func (s *FileDB) setDefaults(name string) error {
 
 	return nil
 }
+
+type SQLiteDBs map[string]*SQLiteDB
+
+func (b SQLiteDBs) validate() error {
+	for name, source := range b {
+		if err := source.validate(); err != nil {
+			return fmt.Errorf("history: sqlite %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (b SQLiteDBs) setDefaults() error {
+	for name, source := range b {
+		if err := source.setDefaults(name); err != nil {
+			return fmt.Errorf("history: sqlite %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+type SQLiteDB struct {
+	Name string `glu:"name"`
+	Path string `glu:"path"`
+}
+
+func (s *SQLiteDB) validate() error {
+	if s == nil {
+		return errFieldRequired("sqlite")
+	}
+
+	if s.Path == "" {
+		return errFieldRequired("path")
+	}
+
+	return nil
+}
+
+func (s *SQLiteDB) setDefaults(name string) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Name == "" {
+		s.Name = name
+	}
+
+	if s.Path == "" {
+		dir, err := os.MkdirTemp("", "sqlite-*")
+		if err != nil {
+			return fmt.Errorf("creating temp dir: %w", err)
+		}
+
+		s.Path = filepath.Join(dir, "glu.db")
+
+		slog.Info("created temporary directory for sqlite db", "history.sqlite", name, "path", s.Path)
+	}
+
+	return nil
+}
+
+type RedisDBs map[string]*RedisDB
+
+func (b RedisDBs) validate() error {
+	for name, source := range b {
+		if err := source.validate(); err != nil {
+			return fmt.Errorf("history: redis %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (b RedisDBs) setDefaults() error {
+	for name, source := range b {
+		if err := source.setDefaults(name); err != nil {
+			return fmt.Errorf("history: redis %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+type RedisDB struct {
+	Name     string `glu:"name"`
+	Address  string `glu:"address"`
+	Username string `glu:"username"`
+	Password string `glu:"password"`
+	DB       int    `glu:"db"`
+	Prefix   string `glu:"prefix"`
+}
+
+func (s *RedisDB) validate() error {
+	if s == nil {
+		return errFieldRequired("redis")
+	}
+
+	if s.Address == "" {
+		return errFieldRequired("address")
+	}
+
+	return nil
+}
+
+func (s *RedisDB) setDefaults(name string) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Name == "" {
+		s.Name = name
+	}
+
+	if s.Prefix == "" {
+		s.Prefix = "glu"
+	}
+
+	return nil
+}
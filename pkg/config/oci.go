@@ -1,7 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/get-glu/glu/internal/oci/platform"
 )
 
 var (
@@ -32,9 +36,15 @@ func (o OCISources) validate() error {
 }
 
 type OCIRepository struct {
-	Name       string `glu:"name"`
-	Reference  string `glu:"reference"`
-	Credential string `glu:"credential"`
+	Name           string           `glu:"name"`
+	Reference      string           `glu:"reference"`
+	Credential     string           `glu:"credential"`
+	Interval       time.Duration    `glu:"interval"`
+	DisablePolling bool             `glu:"disable_polling"`
+	Verification   *OCIVerification `glu:"verification"`
+	// Platforms selects the platforms (os/arch[/variant]) to resolve from image indexes.
+	Platforms        []string `glu:"platforms"`
+	RequirePlatforms bool     `glu:"require_platforms"`
 }
 
 func (o *OCIRepository) setDefaults(name string) error {
@@ -42,6 +52,10 @@ func (o *OCIRepository) setDefaults(name string) error {
 		o.Name = name
 	}
 
+	if o.Interval <= 0 {
+		o.Interval = 20 * time.Second
+	}
+
 	return nil
 }
 
@@ -50,5 +64,56 @@ func (o *OCIRepository) validate() error {
 		return errFieldRequired("reference")
 	}
 
+	for _, p := range o.Platforms {
+		if _, err := platform.Parse(p); err != nil {
+			return errFieldWrap("platforms", err)
+		}
+	}
+
+	if o.RequirePlatforms && len(o.Platforms) == 0 {
+		return errFieldWrap("require_platforms", errors.New("requires at-least one platform to be selected"))
+	}
+
+	if o.Verification != nil {
+		if err := o.Verification.validate(); err != nil {
+			return fmt.Errorf("verification: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// OCIVerification configures the verification of signatures and attestations
+// discovered through the referrers API for an OCI repository.
+type OCIVerification struct {
+	PublicKeys             []PublicKey `glu:"public_keys"`
+	RequiredPredicateTypes []string    `glu:"required_predicate_types"`
+}
+
+func (v *OCIVerification) validate() error {
+	if len(v.PublicKeys) == 0 {
+		return errFieldRequired("public_keys")
+	}
+
+	for _, key := range v.PublicKeys {
+		if err := key.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PublicKey is a PEM encoded public key supplied either inline or via a path.
+type PublicKey struct {
+	Bytes string `glu:"bytes"`
+	Path  string `glu:"path"`
+}
+
+func (k PublicKey) validate() error {
+	if (k.Bytes == "" && k.Path == "") || (k.Bytes != "" && k.Path != "") {
+		return errors.New("public_keys: please provide exclusively one of bytes or path")
+	}
+
 	return nil
 }
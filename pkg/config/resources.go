@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	_ validater = (*FileResources)(nil)
+	_ validater = (*KustomizeResources)(nil)
+	_ validater = (*HelmResources)(nil)
+)
+
+type Resources struct {
+	File      FileResources      `glu:"file"`
+	Kustomize KustomizeResources `glu:"kustomize"`
+	Helm      HelmResources      `glu:"helm"`
+}
+
+type FileResources map[string]*FileResource
+
+func (f FileResources) validate() error {
+	for name, resource := range f {
+		if err := resource.validate(); err != nil {
+			return fmt.Errorf("resources: file %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// FileResource configures a resource whose value is read from and written to
+// a path expression (e.g. .image.digest) within a YAML or JSON file.
+type FileResource struct {
+	Path       string `glu:"path"`
+	Expression string `glu:"expression"`
+	Format     string `glu:"format"`
+}
+
+func (f *FileResource) validate() error {
+	if f == nil {
+		return errFieldRequired("file")
+	}
+
+	if f.Path == "" {
+		return errFieldRequired("path")
+	}
+
+	if f.Expression == "" {
+		return errFieldRequired("expression")
+	}
+
+	switch f.Format {
+	case "", "yaml", "json":
+	default:
+		return errFieldWrap("format", errors.New(`must be one of "yaml" or "json"`))
+	}
+
+	return nil
+}
+
+type KustomizeResources map[string]*KustomizeResource
+
+func (k KustomizeResources) validate() error {
+	for name, resource := range k {
+		if err := resource.validate(); err != nil {
+			return fmt.Errorf("resources: kustomize %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// KustomizeResource configures a resource whose value is an image override
+// within the images entries of a kustomization.
+type KustomizeResource struct {
+	Image   string `glu:"image"`
+	Overlay string `glu:"overlay"`
+}
+
+func (k *KustomizeResource) validate() error {
+	if k == nil {
+		return errFieldRequired("kustomize")
+	}
+
+	if k.Image == "" {
+		return errFieldRequired("image")
+	}
+
+	return nil
+}
+
+type HelmResources map[string]*HelmResource
+
+func (h HelmResources) validate() error {
+	for name, resource := range h {
+		if err := resource.validate(); err != nil {
+			return fmt.Errorf("resources: helm %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// HelmResource configures a resource whose value is the image configured
+// within the values file of a Helm chart.
+type HelmResource struct {
+	Chart            string          `glu:"chart"`
+	ValuesFile       string          `glu:"values_file"`
+	Image            *HelmImagePaths `glu:"image"`
+	BumpChartVersion bool            `glu:"bump_chart_version"`
+	SetAppVersion    bool            `glu:"set_app_version"`
+}
+
+// HelmImagePaths overrides the path expressions of the image within the values file.
+type HelmImagePaths struct {
+	Repository string `glu:"repository"`
+	Tag        string `glu:"tag"`
+	Digest     string `glu:"digest"`
+}
+
+func (h *HelmResource) validate() error {
+	if h == nil {
+		return errFieldRequired("helm")
+	}
+
+	if h.Image != nil && h.Image.Repository == "" && h.Image.Tag == "" && h.Image.Digest == "" {
+		return errFieldWrap("image", errors.New("at-least one of repository, tag or digest is required"))
+	}
+
+	return nil
+}
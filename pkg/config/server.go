@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 var (
 	_ validater = (*Server)(nil)
@@ -20,6 +23,32 @@ type Server struct {
 	Protocol Protocol `glu:"protocol"`
 	CertFile string   `glu:"cert_file"`
 	KeyFile  string   `glu:"key_file"`
+	Webhooks Webhooks `glu:"webhooks"`
+}
+
+// Webhooks configures the inbound webhook receivers hosted by the server.
+type Webhooks struct {
+	OCI Webhook    `glu:"oci"`
+	Git GitWebhook `glu:"git"`
+}
+
+// Webhook configures an inbound webhook receiver.
+// The receiver is disabled (responding 404 Not Found) unless a secret is supplied.
+// Requests must either carry the secret as the Authorization header (optionally as a bearer token)
+// or X-Gitlab-Token header, or be signed with it using an HMAC-SHA256 signature of the body
+// hex encoded in the X-Hub-Signature-256 (prefixed with "sha256=") or X-Gitea-Signature header.
+type Webhook struct {
+	Secret string `glu:"secret"`
+}
+
+// GitWebhook configures the inbound git push webhook receiver.
+// Like Webhook, the receiver is disabled unless a secret is supplied.
+type GitWebhook struct {
+	Secret string `glu:"secret"`
+	// FallbackInterval is the polling interval of every git remote without an explicit
+	// interval, given pushes are delivered via webhook and polling is only a fallback.
+	// It requires a secret, given the receiver is otherwise disabled.
+	FallbackInterval time.Duration `glu:"fallback_interval"`
 }
 
 func (s *Server) validate() error {
@@ -31,6 +60,14 @@ func (s *Server) validate() error {
 		return errFieldRequired("protocol")
 	}
 
+	if s.Webhooks.Git.FallbackInterval < 0 {
+		return errFieldPositiveNonZero("server.webhooks.git.fallback_interval")
+	}
+
+	if s.Webhooks.Git.FallbackInterval > 0 && s.Webhooks.Git.Secret == "" {
+		return errFieldRequired("server.webhooks.git.secret")
+	}
+
 	if s.Protocol == ProtocolHTTPS {
 		if s.CertFile == "" {
 			return errFieldRequired("server.cert_file")
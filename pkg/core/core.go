@@ -13,6 +13,8 @@ var (
 	ErrAlreadyExists = errors.New("already exists")
 	// ErrNoChange is returned when an update produced zero changes
 	ErrNoChange = errors.New("update produced no change")
+	// ErrConflict is returned when an update conflicts with a concurrent change
+	ErrConflict = errors.New("conflict")
 )
 
 // Metadata contains the unique information used to identify
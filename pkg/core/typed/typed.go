@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/get-glu/glu/pkg/containers"
 	"github.com/get-glu/glu/pkg/core"
@@ -25,6 +26,23 @@ type PhaseLogger[R core.Resource] interface {
 	History(_ context.Context, phase core.Descriptor, opts ...containers.Option[core.HistoryOptions]) ([]core.State, error)
 }
 
+// BackfillPhaseLogger is a PhaseLogger which can also record versions at a time in the past.
+// It is used to backfill the history of a phase from an external source (e.g. a git log).
+type BackfillPhaseLogger[R core.Resource] interface {
+	PhaseLogger[R]
+	// RecordLatestAt behaves as RecordLatest with the version recorded at the provided time.
+	RecordLatestAt(_ context.Context, phase core.Descriptor, _ R, _ map[string]string, recordedAt time.Time) error
+}
+
+// StatefulPhaseLogger is a PhaseLogger which can also record a new version when the state
+// observed alongside a resource changes while its digest does not (e.g. the signatures of an image).
+type StatefulPhaseLogger[R core.Resource] interface {
+	PhaseLogger[R]
+	// RecordLatestState behaves as RecordLatest, except that a new version is also recorded
+	// when the digest is unchanged but the encoded resource or annotations have changed.
+	RecordLatestState(_ context.Context, phase core.Descriptor, _ R, _ map[string]string) error
+}
+
 // Phase is an interface around storage for resources.
 type Phase[R core.Resource] interface {
 	core.Phase
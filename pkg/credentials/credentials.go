@@ -5,8 +5,10 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/get-glu/glu/pkg/config"
@@ -144,6 +146,44 @@ func (c *Credential) GitAuthentication() (auth transport.AuthMethod, err error)
 	return nil, fmt.Errorf("git: unxpected credential type: %q", c.config.Type)
 }
 
+// Signer signs the encoded representation of a git object (e.g. a commit)
+// and returns the armored signature stored in the objects signature header.
+type Signer interface {
+	Sign(message io.Reader) ([]byte, error)
+}
+
+// CommitSigner returns a signer for git commits using the credentials key material.
+// Credentials of type gpg produce OpenPGP signatures and credentials of type ssh
+// produce SSH signatures.
+func (c *Credential) CommitSigner() (Signer, error) {
+	readKey := func(keyBytes, keyPath string) ([]byte, error) {
+		if keyBytes != "" {
+			return []byte(keyBytes), nil
+		}
+
+		return os.ReadFile(keyPath)
+	}
+
+	switch c.config.Type {
+	case config.CredentialTypeGPG:
+		key, err := readKey(c.config.GPG.PrivateKeyBytes, c.config.GPG.PrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return newGPGSigner(key, c.config.GPG.Passphrase)
+	case config.CredentialTypeSSH:
+		key, err := readKey(c.config.SSH.PrivateKeyBytes, c.config.SSH.PrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return newSSHSigner(key, c.config.SSH.Password)
+	}
+
+	return nil, fmt.Errorf("signing: credential type %q not supported", c.config.Type)
+}
+
 func (c *Credential) OCIClient(registry string) (_ *auth.Client, err error) {
 	if registry == "docker.io" {
 		// it is expected that traffic targeting "docker.io" will be redirected
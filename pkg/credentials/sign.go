@@ -0,0 +1,161 @@
+package credentials
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/ssh"
+)
+
+// newGPGSigner constructs a Signer which produces armored OpenPGP signatures
+// using the first entity found in the provided armored private key.
+// The passphrase is used to decrypt the private key when it is encrypted.
+func newGPGSigner(armoredKey []byte, passphrase string) (Signer, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return nil, fmt.Errorf("reading gpg key: %w", err)
+	}
+
+	if len(entities) == 0 {
+		return nil, errors.New("reading gpg key: no keys found")
+	}
+
+	entity := entities[0]
+	if entity.PrivateKey == nil {
+		return nil, errors.New("reading gpg key: private key not found")
+	}
+
+	if entity.PrivateKey.Encrypted {
+		if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("decrypting gpg key: %w", err)
+		}
+	}
+
+	return &gpgSigner{entity: entity}, nil
+}
+
+type gpgSigner struct {
+	entity *openpgp.Entity
+}
+
+func (g *gpgSigner) Sign(message io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, g.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("signing with gpg: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+const (
+	sshSigMagic     = "SSHSIG"
+	sshSigVersion   = 1
+	sshSigNamespace = "git"
+	sshSigHashAlg   = "sha512"
+)
+
+// newSSHSigner constructs a Signer which produces armored SSH signatures (SSHSIG)
+// as understood by git when configured with gpg.format=ssh.
+// The passphrase is used to decrypt the private key when it is encrypted.
+func newSSHSigner(privateKey []byte, passphrase string) (Signer, error) {
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if !errors.As(err, &missing) {
+			return nil, fmt.Errorf("reading ssh key: %w", err)
+		}
+
+		if signer, err = ssh.ParsePrivateKeyWithPassphrase(privateKey, []byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("reading ssh key: %w", err)
+		}
+	}
+
+	return &sshSigner{signer: signer}, nil
+}
+
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+// Sign produces a signature as described by the OpenSSH PROTOCOL.sshsig specification.
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	hash := sha512.New()
+	if _, err := io.Copy(hash, message); err != nil {
+		return nil, err
+	}
+
+	signed := sshSigBlob(
+		[]byte(sshSigMagic),
+		sshString([]byte(sshSigNamespace)),
+		sshString(nil), // reserved
+		sshString([]byte(sshSigHashAlg)),
+		sshString(hash.Sum(nil)),
+	)
+
+	var (
+		sig *ssh.Signature
+		err error
+	)
+
+	// rsa keys must use a sha2 based signature algorithm as ssh-rsa (sha1) is rejected
+	if as, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = as.SignWithAlgorithm(rand.Reader, signed, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, signed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing with ssh: %w", err)
+	}
+
+	version := make([]byte, 4)
+	binary.BigEndian.PutUint32(version, sshSigVersion)
+
+	blob := sshSigBlob(
+		[]byte(sshSigMagic),
+		version,
+		sshString(s.signer.PublicKey().Marshal()),
+		sshString([]byte(sshSigNamespace)),
+		sshString(nil), // reserved
+		sshString([]byte(sshSigHashAlg)),
+		sshString(ssh.Marshal(sig)),
+	)
+
+	return armorSSHSig(blob), nil
+}
+
+func sshSigBlob(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func sshString(b []byte) []byte {
+	s := make([]byte, 4, 4+len(b))
+	binary.BigEndian.PutUint32(s, uint32(len(b)))
+	return append(s, b...)
+}
+
+func armorSSHSig(blob []byte) []byte {
+	const lineLength = 70
+
+	var (
+		buf     bytes.Buffer
+		encoded = base64.StdEncoding.EncodeToString(blob)
+	)
+
+	buf.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > lineLength {
+		buf.WriteString(encoded[:lineLength] + "\n")
+		encoded = encoded[lineLength:]
+	}
+
+	buf.WriteString(encoded + "\n")
+	buf.WriteString("-----END SSH SIGNATURE-----\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,215 @@
+package credentials
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+const signedMessage = "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor glu <glu@getglu.dev> 0 +0000\n\nUpdate phase\n"
+
+func TestGPGSigner(t *testing.T) {
+	for _, passphrase := range []string{"", "secret"} {
+		t.Run("passphrase="+passphrase, func(t *testing.T) {
+			entity, err := openpgp.NewEntity("glu", "", "glu@getglu.dev", nil)
+			require.NoError(t, err)
+
+			signer, err := newGPGSigner(armoredPrivateKey(t, entity, passphrase), passphrase)
+			require.NoError(t, err)
+
+			signature, err := signer.Sign(strings.NewReader(signedMessage))
+			require.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(signature, []byte("-----BEGIN PGP SIGNATURE-----")))
+
+			signedBy, err := openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{entity}, strings.NewReader(signedMessage), bytes.NewReader(signature), nil)
+			require.NoError(t, err)
+			assert.Equal(t, entity.PrimaryKey.KeyId, signedBy.PrimaryKey.KeyId)
+
+			// the signature does not verify a different message
+			_, err = openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{entity}, strings.NewReader(signedMessage+"tampered"), bytes.NewReader(signature), nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGPGSigner_Invalid(t *testing.T) {
+	entity, err := openpgp.NewEntity("glu", "", "glu@getglu.dev", nil)
+	require.NoError(t, err)
+
+	_, err = newGPGSigner(armoredPrivateKey(t, entity, "secret"), "wrong")
+	assert.ErrorContains(t, err, "decrypting gpg key")
+
+	// public keys cannot be used to sign
+	var public bytes.Buffer
+	w, err := armor.Encode(&public, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	_, err = newGPGSigner(public.Bytes(), "")
+	assert.Error(t, err)
+}
+
+func TestSSHSigner(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name       string
+		key        any
+		passphrase string
+		// algorithm is the expected signature algorithm
+		algorithm string
+	}{
+		{name: "ed25519", key: ed25519Key, algorithm: ssh.KeyAlgoED25519},
+		{name: "ed25519 encrypted", key: ed25519Key, passphrase: "secret", algorithm: ssh.KeyAlgoED25519},
+		// rsa keys sign with sha512 given git rejects ssh-rsa (sha1) signatures
+		{name: "rsa", key: rsaKey, algorithm: ssh.KeyAlgoRSASHA512},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var block *pem.Block
+			if test.passphrase != "" {
+				block, err = ssh.MarshalPrivateKeyWithPassphrase(test.key, "", []byte(test.passphrase))
+			} else {
+				block, err = ssh.MarshalPrivateKey(test.key, "")
+			}
+			require.NoError(t, err)
+
+			signer, err := newSSHSigner(pem.EncodeToMemory(block), test.passphrase)
+			require.NoError(t, err)
+
+			armored, err := signer.Sign(strings.NewReader(signedMessage))
+			require.NoError(t, err)
+
+			sig := parseSSHSig(t, armored)
+			assert.Equal(t, uint32(sshSigVersion), sig.version)
+			assert.Equal(t, sshSigNamespace, sig.namespace)
+			assert.Equal(t, sshSigHashAlg, sig.hashAlg)
+			assert.Equal(t, test.algorithm, sig.signature.Format)
+
+			expected, err := ssh.NewSignerFromKey(test.key)
+			require.NoError(t, err)
+			assert.Equal(t, expected.PublicKey().Marshal(), sig.publicKey.Marshal())
+
+			// the signature covers the sha512 digest of the message
+			// wrapped as described by the PROTOCOL.sshsig specification
+			require.NoError(t, sig.publicKey.Verify(signedData(signedMessage), sig.signature))
+			assert.Error(t, sig.publicKey.Verify(signedData(signedMessage+"tampered"), sig.signature))
+		})
+	}
+}
+
+func TestSSHSigner_Invalid(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("secret"))
+	require.NoError(t, err)
+
+	_, err = newSSHSigner(pem.EncodeToMemory(block), "wrong")
+	assert.ErrorContains(t, err, "reading ssh key")
+
+	_, err = newSSHSigner([]byte("not a key"), "")
+	assert.ErrorContains(t, err, "reading ssh key")
+}
+
+func armoredPrivateKey(t *testing.T, entity *openpgp.Entity, passphrase string) []byte {
+	t.Helper()
+
+	if passphrase != "" {
+		require.NoError(t, entity.EncryptPrivateKeys([]byte(passphrase), nil))
+		// restore the decrypted keys for verification once serialized
+		defer func() { require.NoError(t, entity.DecryptPrivateKeys([]byte(passphrase))) }()
+	}
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivateWithoutSigning(w, nil))
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+type sshSig struct {
+	version   uint32
+	publicKey ssh.PublicKey
+	namespace string
+	hashAlg   string
+	signature *ssh.Signature
+}
+
+// parseSSHSig parses an armored signature as described by the PROTOCOL.sshsig specification.
+func parseSSHSig(t *testing.T, armored []byte) sshSig {
+	t.Helper()
+
+	body, ok := strings.CutPrefix(string(armored), "-----BEGIN SSH SIGNATURE-----\n")
+	require.True(t, ok, "missing signature header")
+	body, ok = strings.CutSuffix(body, "-----END SSH SIGNATURE-----\n")
+	require.True(t, ok, "missing signature footer")
+
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		assert.LessOrEqual(t, len(line), 70)
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\n", ""))
+	require.NoError(t, err)
+
+	magic, blob := blob[:len(sshSigMagic)], blob[len(sshSigMagic):]
+	require.Equal(t, sshSigMagic, string(magic))
+
+	var sig sshSig
+	sig.version, blob = binary.BigEndian.Uint32(blob), blob[4:]
+
+	readString := func() []byte {
+		t.Helper()
+
+		require.GreaterOrEqual(t, len(blob), 4)
+		n := binary.BigEndian.Uint32(blob)
+		require.GreaterOrEqual(t, uint32(len(blob)-4), n)
+
+		s := blob[4 : 4+n]
+		blob = blob[4+n:]
+		return s
+	}
+
+	sig.publicKey, err = ssh.ParsePublicKey(readString())
+	require.NoError(t, err)
+
+	sig.namespace = string(readString())
+	assert.Empty(t, readString(), "reserved")
+	sig.hashAlg = string(readString())
+
+	sig.signature = &ssh.Signature{}
+	require.NoError(t, ssh.Unmarshal(readString(), sig.signature))
+	assert.Empty(t, blob, "trailing data")
+
+	return sig
+}
+
+func signedData(message string) []byte {
+	hash := sha512.Sum512([]byte(message))
+	return sshSigBlob(
+		[]byte(sshSigMagic),
+		sshString([]byte(sshSigNamespace)),
+		sshString(nil),
+		sshString([]byte(sshSigHashAlg)),
+		sshString(hash[:]),
+	)
+}
@@ -12,9 +12,21 @@ import (
 
 var _ core.Edge = (*PromotionEdge[core.Resource])(nil)
 
-// ErrSkipped is returned when an edge skips performing because the operation
-// would be a no-op.
-var ErrSkipped = errors.New("skipped performing")
+var (
+	// ErrSkipped is returned when an edge skips performing because the operation
+	// would be a no-op.
+	ErrSkipped = errors.New("skipped performing")
+	// ErrGated is returned when an edge skips performing because the source
+	// phase did not permit the promotion of its current resource.
+	ErrGated = errors.New("promotion gated")
+)
+
+// Gate is an optional interface for source phases which can veto the
+// promotion of a resource (e.g. an image which failed signature verification).
+type Gate[R core.Resource] interface {
+	// Permit returns a non-nil error when the provided resource must not be promoted.
+	Permit(context.Context, R) error
+}
 
 // PromotionEdge is a type edge implementation which supports promoting
 // from a source phase to a destination phase.
@@ -69,6 +81,13 @@ func (s *PromotionEdge[R]) Perform(ctx context.Context) (r *core.Result, err err
 		return nil, ErrSkipped
 	}
 
+	if gate, ok := s.from.(Gate[R]); ok {
+		if err := gate.Permit(ctx, from); err != nil {
+			s.logger.Debug("skipping promotion", "reason", "Gated", "error", err)
+			return nil, fmt.Errorf("%w: %w", ErrGated, err)
+		}
+	}
+
 	return s.to.Update(ctx, from, typed.UpdateWithKind(typed.KindPromotion))
 }
 
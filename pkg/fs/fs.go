@@ -1,10 +1,15 @@
 package fs
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 // Filesystem is a subset of a filesystem API surface area
@@ -31,6 +36,33 @@ type Filesystem interface {
 	MkdirAll(filename string, perm os.FileMode) error
 }
 
+// RenameFilesystem is a Filesystem which can move files and directories.
+type RenameFilesystem interface {
+	Filesystem
+	// Rename renames (moves) oldpath to newpath.
+	// If newpath already exists and is not a directory, Rename replaces it.
+	Rename(oldpath, newpath string) error
+}
+
+// ChmodFilesystem is a Filesystem which can change the mode of files.
+type ChmodFilesystem interface {
+	Filesystem
+	// Chmod changes the mode of the named file to mode.
+	Chmod(name string, mode os.FileMode) error
+}
+
+// SymlinkFilesystem is a Filesystem which supports symbolic links.
+type SymlinkFilesystem interface {
+	Filesystem
+	// Lstat returns a FileInfo describing the named file.
+	// If the file is a symbolic link, the returned FileInfo describes the link.
+	Lstat(filename string) (os.FileInfo, error)
+	// Symlink creates newname as a symbolic link to oldname.
+	Symlink(oldname, newname string) error
+	// Readlink returns the destination of the named symbolic link.
+	Readlink(name string) (string, error)
+}
+
 type File interface {
 	Stat() (fs.FileInfo, error)
 	io.Writer
@@ -50,6 +82,139 @@ func (i fsAdaptor) Open(name string) (fs.File, error) {
 	return i.Filesystem.OpenFile(name, os.O_RDONLY, os.ModePerm)
 }
 
+// ReadDir implements fs.ReadDirFS such that the adapted filesystem
+// can be walked and globbed via the io/fs package.
+func (i fsAdaptor) ReadDir(name string) ([]fs.DirEntry, error) {
+	infos, err := i.Filesystem.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]fs.DirEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+// WalkDir walks the file tree rooted at root, calling fn for each file or
+// directory in the tree, including root. See io/fs.WalkDir for details.
+// Symbolic links are not followed.
+func WalkDir(fsys Filesystem, root string, fn fs.WalkDirFunc) error {
+	return fs.WalkDir(ToFS(fsys), clean(root), fn)
+}
+
+// Glob returns the names of all files matching pattern or nil if there is no matching file.
+// The syntax of patterns is the same as in path.Match.
+func Glob(fsys Filesystem, pattern string) ([]string, error) {
+	return fs.Glob(ToFS(fsys), clean(pattern))
+}
+
+// Lstat returns a FileInfo describing the named file without following symbolic links.
+// When the filesystem does not support symbolic links it falls back to Stat.
+func Lstat(fsys Filesystem, filename string) (os.FileInfo, error) {
+	if sfs, ok := fsys.(SymlinkFilesystem); ok {
+		return sfs.Lstat(filename)
+	}
+
+	return fsys.Stat(filename)
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+// It returns an error wrapping errors.ErrUnsupported when the filesystem does not support symbolic links.
+func Symlink(fsys Filesystem, oldname, newname string) error {
+	sfs, ok := fsys.(SymlinkFilesystem)
+	if !ok {
+		return fmt.Errorf("symlink %q: %w", newname, errors.ErrUnsupported)
+	}
+
+	return sfs.Symlink(oldname, newname)
+}
+
+// Readlink returns the destination of the named symbolic link.
+// It returns an error wrapping errors.ErrUnsupported when the filesystem does not support symbolic links.
+func Readlink(fsys Filesystem, name string) (string, error) {
+	sfs, ok := fsys.(SymlinkFilesystem)
+	if !ok {
+		return "", fmt.Errorf("readlink %q: %w", name, errors.ErrUnsupported)
+	}
+
+	return sfs.Readlink(name)
+}
+
+// Chmod changes the mode of the named file to mode.
+// It returns an error wrapping errors.ErrUnsupported when the filesystem does not support changing modes.
+func Chmod(fsys Filesystem, name string, mode os.FileMode) error {
+	cfs, ok := fsys.(ChmodFilesystem)
+	if !ok {
+		return fmt.Errorf("chmod %q: %w", name, errors.ErrUnsupported)
+	}
+
+	return cfs.Chmod(name, mode)
+}
+
+// Rename renames (moves) oldpath to newpath.
+// When the filesystem does not implement RenameFilesystem, regular files are
+// copied to newpath and then removed.
+func Rename(fsys Filesystem, oldpath, newpath string) error {
+	if rfs, ok := fsys.(RenameFilesystem); ok {
+		return rfs.Rename(oldpath, newpath)
+	}
+
+	info, err := fsys.Stat(oldpath)
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("rename %q: non-regular file: %w", oldpath, errors.ErrUnsupported)
+	}
+
+	src, err := fsys.OpenFile(oldpath, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	defer src.Close()
+
+	dst, err := fsys.OpenFile(newpath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return fsys.Remove(oldpath)
+}
+
+// clean converts a filesystem path into the unrooted form expected by io/fs.
+func clean(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+
+	return name
+}
+
+var (
+	_ RenameFilesystem  = (*subFilesystem)(nil)
+	_ ChmodFilesystem   = (*subFilesystem)(nil)
+	_ SymlinkFilesystem = (*subFilesystem)(nil)
+)
+
 type subFilesystem struct {
 	fs  Filesystem
 	dir string
@@ -96,3 +261,30 @@ func (s *subFilesystem) ReadDir(path string) ([]os.FileInfo, error) {
 func (s *subFilesystem) MkdirAll(filename string, perm os.FileMode) error {
 	return s.fs.MkdirAll(filepath.Join(s.dir, filename), perm)
 }
+
+// Rename renames (moves) oldpath to newpath.
+func (s *subFilesystem) Rename(oldpath, newpath string) error {
+	return Rename(s.fs, filepath.Join(s.dir, oldpath), filepath.Join(s.dir, newpath))
+}
+
+// Chmod changes the mode of the named file to mode.
+func (s *subFilesystem) Chmod(name string, mode os.FileMode) error {
+	return Chmod(s.fs, filepath.Join(s.dir, name), mode)
+}
+
+// Lstat returns a FileInfo describing the named file.
+// If the file is a symbolic link, the returned FileInfo describes the link.
+func (s *subFilesystem) Lstat(filename string) (os.FileInfo, error) {
+	return Lstat(s.fs, filepath.Join(s.dir, filename))
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+// The link destination is stored as provided and is therefore not scoped.
+func (s *subFilesystem) Symlink(oldname, newname string) error {
+	return Symlink(s.fs, oldname, filepath.Join(s.dir, newname))
+}
+
+// Readlink returns the destination of the named symbolic link.
+func (s *subFilesystem) Readlink(name string) (string, error) {
+	return Readlink(s.fs, filepath.Join(s.dir, name))
+}
@@ -0,0 +1,200 @@
+// Package memory provides an in-memory implementation of fs.Filesystem.
+// It is intended for testing resources (e.g. ReadFrom and WriteTo) without a git repository.
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/get-glu/glu/pkg/fs"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+)
+
+var (
+	_ fs.RenameFilesystem  = (*FS)(nil)
+	_ fs.ChmodFilesystem   = (*FS)(nil)
+	_ fs.SymlinkFilesystem = (*FS)(nil)
+)
+
+// FS is an in-memory filesystem.
+type FS struct {
+	fs billy.Filesystem
+}
+
+// New constructs a new empty in-memory filesystem.
+func New() *FS {
+	return &FS{fs: memfs.New()}
+}
+
+// NewWithFiles constructs a new in-memory filesystem populated with the provided
+// files (a map of path to contents).
+func NewWithFiles(files map[string]string) (*FS, error) {
+	fs := New()
+	for path, contents := range files {
+		if err := fs.WriteFile(path, []byte(contents)); err != nil {
+			return nil, err
+		}
+	}
+
+	return fs, nil
+}
+
+// OpenFile is the generalized open call; most users will use Open or Create
+// instead. It opens the named file with specified flag (O_RDONLY etc.) and
+// perm, (0666 etc.) if applicable. If successful, methods on the returned
+// File can be used for I/O.
+func (m *FS) OpenFile(filename string, flag int, perm os.FileMode) (fs.File, error) {
+	fi, err := m.fs.OpenFile(filename, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file{File: fi, fs: m.fs}, nil
+}
+
+// Stat returns a FileInfo describing the named file.
+func (m *FS) Stat(filename string) (os.FileInfo, error) {
+	return m.fs.Stat(filename)
+}
+
+// Remove removes the named file or directory.
+// Directories are removed along with their contents (as they are by git backed filesystems).
+func (m *FS) Remove(filename string) error {
+	info, err := m.fs.Lstat(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if info.IsDir() {
+		infos, err := m.fs.ReadDir(filename)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range infos {
+			if err := m.Remove(filepath.Join(filename, info.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return m.fs.Remove(filename)
+}
+
+// ReadDir reads the directory named by dirname and returns a list of
+// directory entries sorted by filename.
+func (m *FS) ReadDir(path string) ([]os.FileInfo, error) {
+	return m.fs.ReadDir(path)
+}
+
+// MkdirAll creates a directory named path, along with any necessary
+// parents, and returns nil, or else returns an error. The permission bits
+// perm are used for all directories that MkdirAll creates. If path is/
+// already a directory, MkdirAll does nothing and returns nil.
+func (m *FS) MkdirAll(filename string, perm os.FileMode) error {
+	return m.fs.MkdirAll(filename, perm)
+}
+
+// Rename renames (moves) oldpath to newpath.
+func (m *FS) Rename(oldpath, newpath string) error {
+	return m.fs.Rename(oldpath, newpath)
+}
+
+// Chmod changes the mode of the named file to mode.
+func (m *FS) Chmod(name string, mode os.FileMode) error {
+	info, err := m.fs.Lstat(name)
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("chmod %q: not a regular file", name)
+	}
+
+	data, err := m.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	// the underlying filesystem fixes the mode of a file on creation
+	// so the file is recreated with the new mode
+	if err := m.fs.Remove(name); err != nil {
+		return err
+	}
+
+	fi, err := m.fs.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := fi.Write(data); err != nil {
+		_ = fi.Close()
+		return err
+	}
+
+	return fi.Close()
+}
+
+// Lstat returns a FileInfo describing the named file.
+// If the file is a symbolic link, the returned FileInfo describes the link.
+func (m *FS) Lstat(filename string) (os.FileInfo, error) {
+	return m.fs.Lstat(filename)
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+func (m *FS) Symlink(oldname, newname string) error {
+	return m.fs.Symlink(oldname, newname)
+}
+
+// Readlink returns the destination of the named symbolic link.
+func (m *FS) Readlink(name string) (string, error) {
+	return m.fs.Readlink(name)
+}
+
+// ReadFile returns the contents of the named file.
+func (m *FS) ReadFile(filename string) ([]byte, error) {
+	fi, err := m.fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fi.Close()
+
+	return io.ReadAll(fi)
+}
+
+// WriteFile writes the contents to the named file, creating it (and any parent directories) if necessary.
+func (m *FS) WriteFile(filename string, data []byte) error {
+	if err := m.fs.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	fi, err := m.fs.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fi.Write(data); err != nil {
+		_ = fi.Close()
+		return err
+	}
+
+	return fi.Close()
+}
+
+type file struct {
+	billy.File
+	fs billy.Filesystem
+}
+
+func (f *file) Stat() (os.FileInfo, error) {
+	return f.fs.Stat(f.Name())
+}
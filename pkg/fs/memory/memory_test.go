@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFS(t *testing.T) {
+	fs, err := NewWithFiles(map[string]string{
+		"README.md":          "# readme",
+		"env/staging/a.yaml": "a",
+		"env/staging/b.yaml": "b",
+	})
+	require.NoError(t, err)
+
+	t.Run("read", func(t *testing.T) {
+		fi, err := fs.OpenFile("env/staging/a.yaml", os.O_RDONLY, 0)
+		require.NoError(t, err)
+
+		defer fi.Close()
+
+		data, err := io.ReadAll(fi)
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(data))
+
+		info, err := fi.Stat()
+		require.NoError(t, err)
+		assert.Equal(t, "a.yaml", info.Name())
+		assert.Equal(t, int64(1), info.Size())
+	})
+
+	t.Run("read dir", func(t *testing.T) {
+		infos, err := fs.ReadDir("env/staging")
+		require.NoError(t, err)
+
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name())
+		}
+
+		assert.Equal(t, []string{"a.yaml", "b.yaml"}, names)
+	})
+
+	t.Run("write", func(t *testing.T) {
+		fi, err := fs.OpenFile("README.md", os.O_WRONLY|os.O_TRUNC, 0644)
+		require.NoError(t, err)
+
+		_, err = io.WriteString(fi, "# updated")
+		require.NoError(t, err)
+		require.NoError(t, fi.Close())
+
+		data, err := fs.ReadFile("README.md")
+		require.NoError(t, err)
+		assert.Equal(t, "# updated", string(data))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := fs.OpenFile("missing.yaml", os.O_RDONLY, 0)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+
+		_, err = fs.Stat("missing.yaml")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestFS_Remove(t *testing.T) {
+	fs, err := NewWithFiles(map[string]string{
+		"env/staging/a.yaml":        "a",
+		"env/staging/nested/b.yaml": "b",
+		"env/production/a.yaml":     "a",
+	})
+	require.NoError(t, err)
+
+	// directories are removed along with their contents
+	require.NoError(t, fs.Remove("env/staging"))
+
+	_, err = fs.Stat("env/staging/nested/b.yaml")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = fs.Stat("env/staging")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = fs.Stat("env/production/a.yaml")
+	require.NoError(t, err)
+
+	// removing a missing file is not an error
+	require.NoError(t, fs.Remove("env/staging"))
+}
+
+func TestFS_Rename(t *testing.T) {
+	fs, err := NewWithFiles(map[string]string{"old.yaml": "contents"})
+	require.NoError(t, err)
+
+	require.NoError(t, fs.MkdirAll("new", 0755))
+	require.NoError(t, fs.Rename("old.yaml", "new/renamed.yaml"))
+
+	_, err = fs.Stat("old.yaml")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	data, err := fs.ReadFile("new/renamed.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(data))
+}
+
+func TestFS_Chmod(t *testing.T) {
+	fs, err := NewWithFiles(map[string]string{"bin/run.sh": "#!/bin/sh"})
+	require.NoError(t, err)
+
+	require.NoError(t, fs.Chmod("bin/run.sh", 0755))
+
+	info, err := fs.Stat("bin/run.sh")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	// the contents are preserved
+	data, err := fs.ReadFile("bin/run.sh")
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh", string(data))
+
+	assert.Error(t, fs.Chmod("bin", 0755))
+	assert.ErrorIs(t, fs.Chmod("missing.sh", 0755), os.ErrNotExist)
+}
+
+func TestFS_Symlink(t *testing.T) {
+	fs, err := NewWithFiles(map[string]string{"values.yaml": "contents"})
+	require.NoError(t, err)
+
+	require.NoError(t, fs.Symlink("values.yaml", "link.yaml"))
+
+	target, err := fs.Readlink("link.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "values.yaml", target)
+
+	info, err := fs.Lstat("link.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink)
+
+	// the link is followed when read
+	data, err := fs.ReadFile("link.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(data))
+}
@@ -0,0 +1,93 @@
+package glutest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+)
+
+// Edge returns the edge between the named phases in the pipeline.
+// It fails the test when no such edge exists.
+func Edge(t testing.TB, pipeline *core.Pipeline, from, to string) core.Edge {
+	t.Helper()
+
+	edge, ok := pipeline.EdgesFrom()[from][to]
+	if !ok {
+		t.Fatalf("pipeline %q: expected edge from %q to %q", pipeline.Metadata().Name, from, to)
+	}
+
+	return edge
+}
+
+// AssertEdge asserts that the pipeline contains an edge of the provided kind between the named phases.
+func AssertEdge(t testing.TB, pipeline *core.Pipeline, kind, from, to string) {
+	t.Helper()
+
+	if edge := Edge(t, pipeline, from, to); edge.Kind() != kind {
+		t.Errorf("pipeline %q: edge from %q to %q: expected kind %q, found %q", pipeline.Metadata().Name, from, to, kind, edge.Kind())
+	}
+}
+
+// AssertNoEdge asserts that the pipeline contains no edge between the named phases.
+func AssertNoEdge(t testing.TB, pipeline *core.Pipeline, from, to string) {
+	t.Helper()
+
+	if edge, ok := pipeline.EdgesFrom()[from][to]; ok {
+		t.Errorf("pipeline %q: unexpected %s edge from %q to %q", pipeline.Metadata().Name, edge.Kind(), from, to)
+	}
+}
+
+// Perform performs the edge between the named phases and returns its result.
+// It fails the test when the edge does not exist or returns an error.
+func Perform(ctx context.Context, t testing.TB, pipeline *core.Pipeline, from, to string) *core.Result {
+	t.Helper()
+
+	result, err := Edge(t, pipeline, from, to).Perform(ctx)
+	if err != nil {
+		t.Fatalf("pipeline %q: performing edge from %q to %q: %v", pipeline.Metadata().Name, from, to, err)
+	}
+
+	return result
+}
+
+// AssertDigest asserts that the current resource of the phase has the provided digest.
+func AssertDigest(ctx context.Context, t testing.TB, phase core.Phase, expected string) {
+	t.Helper()
+
+	resource, err := phase.Get(ctx)
+	if err != nil {
+		t.Fatalf("phase %q: getting resource: %v", phase.Descriptor().Metadata.Name, err)
+	}
+
+	digest, err := resource.Digest()
+	if err != nil {
+		t.Fatalf("phase %q: getting digest: %v", phase.Descriptor().Metadata.Name, err)
+	}
+
+	if digest != expected {
+		t.Errorf("phase %q: expected digest %q, found %q", phase.Descriptor().Metadata.Name, expected, digest)
+	}
+}
+
+// AssertHistory asserts that the history of the phase consists of the provided digests,
+// ordered from oldest to newest.
+func AssertHistory(ctx context.Context, t testing.TB, phase core.Phase, expected ...string) {
+	t.Helper()
+
+	history, err := phase.History(ctx)
+	if err != nil {
+		t.Fatalf("phase %q: getting history: %v", phase.Descriptor().Metadata.Name, err)
+	}
+
+	// history is returned newest first
+	digests := make([]string, 0, len(history))
+	for _, state := range slices.Backward(history) {
+		digests = append(digests, state.Digest)
+	}
+
+	if !slices.Equal(digests, expected) {
+		t.Errorf("phase %q: expected history %q, found %q", phase.Descriptor().Metadata.Name, expected, digests)
+	}
+}
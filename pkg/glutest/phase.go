@@ -0,0 +1,134 @@
+// Package glutest provides utilities for unit-testing glu resources and pipelines.
+// It contains fake phase and proposer implementations, an in-memory git remote
+// and helpers for asserting the edges and history of pipelines.
+package glutest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/core/typed"
+	"github.com/get-glu/glu/pkg/kv/memory"
+	"github.com/get-glu/glu/pkg/phases/logger"
+	"github.com/google/uuid"
+)
+
+// AnnotationUpdateKindKey is the annotation on results and history which
+// identifies the kind of update (update, promotion or rollback) performed on a fake phase.
+const AnnotationUpdateKindKey = "dev.getglu.glutest.update.kind"
+
+var (
+	_ typed.UpdatablePhase[core.Resource] = (*Phase[core.Resource])(nil)
+	_ core.RollbackPhase                  = (*Phase[core.Resource])(nil)
+)
+
+// Phase is an in-memory fake implementation of typed.UpdatablePhase.
+// It records the history of its resource and every update it receives.
+type Phase[R core.Resource] struct {
+	pipeline string
+	meta     core.Metadata
+	logger   *logger.PhaseLogger[R]
+
+	mu        sync.Mutex
+	updates   []R
+	updateErr error
+}
+
+// NewPhase constructs a new fake phase whose initial state is the provided resource.
+func NewPhase[R core.Resource](ctx context.Context, pipeline string, meta core.Metadata, initial R) (*Phase[R], error) {
+	p := &Phase[R]{
+		pipeline: pipeline,
+		meta:     meta,
+		logger:   logger.New[R](memory.New()),
+	}
+
+	if err := p.logger.CreateLog(ctx, p.Descriptor()); err != nil {
+		return nil, err
+	}
+
+	if err := p.Set(ctx, initial); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Descriptor returns the phases descriptor.
+func (p *Phase[R]) Descriptor() core.Descriptor {
+	return core.Descriptor{
+		Kind:     "glutest",
+		Pipeline: p.pipeline,
+		Metadata: p.meta,
+	}
+}
+
+// Get returns the current resource.
+func (p *Phase[R]) Get(ctx context.Context) (core.Resource, error) {
+	return p.GetResource(ctx)
+}
+
+// GetResource returns the current resource.
+func (p *Phase[R]) GetResource(ctx context.Context) (R, error) {
+	return p.logger.GetLatestResource(ctx, p.Descriptor())
+}
+
+// History returns the history of the phases resource.
+func (p *Phase[R]) History(ctx context.Context, opts ...containers.Option[core.HistoryOptions]) ([]core.State, error) {
+	return p.logger.History(ctx, p.Descriptor(), opts...)
+}
+
+// Set changes the current resource as if it had been changed outside of the pipeline.
+// It is not recorded as an update.
+func (p *Phase[R]) Set(ctx context.Context, r R) error {
+	return p.logger.RecordLatest(ctx, p.Descriptor(), r, nil)
+}
+
+// Update records the update and changes the current resource.
+// It returns the error configured via FailUpdates (if any) without changing the resource.
+func (p *Phase[R]) Update(ctx context.Context, to R, opts ...containers.Option[typed.UpdateOptions]) (*core.Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.updateErr != nil {
+		return nil, p.updateErr
+	}
+
+	options := typed.NewUpdateOptions(opts...)
+	annotations := map[string]string{AnnotationUpdateKindKey: options.Kind}
+	if err := p.logger.RecordLatest(ctx, p.Descriptor(), to, annotations); err != nil {
+		return nil, err
+	}
+
+	p.updates = append(p.updates, to)
+
+	return &core.Result{Annotations: annotations}, nil
+}
+
+// Rollback updates the phase to the resource at a previous version in its history.
+func (p *Phase[R]) Rollback(ctx context.Context, version uuid.UUID) (*core.Result, error) {
+	r, err := p.logger.GetResourceAtVersion(ctx, p.Descriptor(), version)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Update(ctx, r, typed.UpdateWithKind(typed.KindRollback))
+}
+
+// Updates returns the resources the phase has been updated with (in order).
+func (p *Phase[R]) Updates() []R {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]R(nil), p.updates...)
+}
+
+// FailUpdates configures subsequent calls to Update to return the provided error.
+// Passing nil restores successful updates.
+func (p *Phase[R]) FailUpdates(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.updateErr = err
+}
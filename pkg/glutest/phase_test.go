@@ -0,0 +1,125 @@
+package glutest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/core/typed"
+	"github.com/get-glu/glu/pkg/edges"
+	"github.com/get-glu/glu/pkg/fs"
+	"github.com/get-glu/glu/pkg/glutest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhase(t *testing.T) {
+	ctx := context.Background()
+
+	phase, err := glutest.NewPhase(ctx, "pipeline", core.Metadata{Name: "staging"}, &value{"one"})
+	require.NoError(t, err)
+
+	assert.Equal(t, core.Descriptor{Kind: "glutest", Pipeline: "pipeline", Metadata: core.Metadata{Name: "staging"}}, phase.Descriptor())
+	glutest.AssertDigest(ctx, t, phase, "one")
+
+	// changes made outside of the pipeline are not recorded as updates
+	require.NoError(t, phase.Set(ctx, &value{"two"}))
+
+	result, err := phase.Update(ctx, &value{"three"}, typed.UpdateWithKind(typed.KindPromotion))
+	require.NoError(t, err)
+	assert.Equal(t, typed.KindPromotion, result.Annotations[glutest.AnnotationUpdateKindKey])
+
+	glutest.AssertDigest(ctx, t, phase, "three")
+	glutest.AssertHistory(ctx, t, phase, "one", "two", "three")
+	assert.Equal(t, []*value{{"three"}}, phase.Updates())
+
+	// rollback to the initial version
+	history, err := phase.History(ctx)
+	require.NoError(t, err)
+
+	result, err = phase.Rollback(ctx, history[len(history)-1].Version)
+	require.NoError(t, err)
+	assert.Equal(t, typed.KindRollback, result.Annotations[glutest.AnnotationUpdateKindKey])
+
+	glutest.AssertDigest(ctx, t, phase, "one")
+	glutest.AssertHistory(ctx, t, phase, "one", "two", "three", "one")
+
+	// failed updates leave the resource unchanged
+	errFailed := errors.New("failed")
+	phase.FailUpdates(errFailed)
+
+	_, err = phase.Update(ctx, &value{"four"})
+	assert.ErrorIs(t, err, errFailed)
+	glutest.AssertDigest(ctx, t, phase, "one")
+
+	phase.FailUpdates(nil)
+
+	_, err = phase.Update(ctx, &value{"four"})
+	require.NoError(t, err)
+	assert.Equal(t, []*value{{"three"}, {"one"}, {"four"}}, phase.Updates())
+}
+
+func TestPerform(t *testing.T) {
+	ctx := context.Background()
+
+	staging, err := glutest.NewPhase(ctx, "pipeline", core.Metadata{Name: "staging"}, &value{"two"})
+	require.NoError(t, err)
+
+	production, err := glutest.NewPhase(ctx, "pipeline", core.Metadata{Name: "production"}, &value{"one"})
+	require.NoError(t, err)
+
+	pipeline := core.NewPipeline(core.Metadata{Name: "pipeline"})
+	require.NoError(t, pipeline.AddEdge(edges.Promotes[*value](staging, production)))
+
+	glutest.AssertEdge(t, pipeline, typed.KindPromotion, "staging", "production")
+	glutest.AssertNoEdge(t, pipeline, "production", "staging")
+
+	result := glutest.Perform(ctx, t, pipeline, "staging", "production")
+	assert.Equal(t, typed.KindPromotion, result.Annotations[glutest.AnnotationUpdateKindKey])
+
+	glutest.AssertDigest(ctx, t, production, "two")
+	glutest.AssertHistory(ctx, t, production, "one", "two")
+}
+
+// value is a resource stored in a single file.
+type value struct {
+	Value string
+}
+
+func (v *value) Digest() (string, error) {
+	return v.Value, nil
+}
+
+func (v *value) ReadFrom(_ context.Context, _ core.Descriptor, fs fs.Filesystem) error {
+	fi, err := fs.OpenFile("value.txt", os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	defer fi.Close()
+
+	data, err := io.ReadAll(fi)
+	if err != nil {
+		return err
+	}
+
+	v.Value = string(data)
+
+	return nil
+}
+
+func (v *value) WriteTo(_ context.Context, _ core.Descriptor, fs fs.Filesystem) error {
+	fi, err := fs.OpenFile("value.txt", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(fi, v.Value); err != nil {
+		return err
+	}
+
+	return fi.Close()
+}
@@ -0,0 +1,209 @@
+package glutest
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	srcgit "github.com/get-glu/glu/pkg/phases/git"
+)
+
+var _ srcgit.Proposer = (*Proposer)(nil)
+
+// Proposal is a proposal tracked by the fake Proposer.
+type Proposal struct {
+	srcgit.Proposal
+
+	Open     bool
+	Merged   bool
+	Labels   []string
+	Comments []string
+}
+
+// Proposer is an in-memory fake implementation of the git phase Proposer.
+// When constructed with a Remote, merging a proposal fast-forwards the base branch
+// on the remote to the head of the proposal branch.
+type Proposer struct {
+	remote *Remote
+
+	mu        sync.Mutex
+	proposals []*Proposal
+	status    srcgit.ProposalStatus
+}
+
+// NewProposer constructs a new fake proposer.
+// The remote is optional and when nil, merges are only recorded.
+func NewProposer(remote *Remote) *Proposer {
+	return &Proposer{
+		remote: remote,
+		status: srcgit.ProposalStatus{Mergeable: true},
+	}
+}
+
+// GetCurrentProposal returns the first open proposal against the base branch
+// whose branch has the provided prefix.
+func (p *Proposer) GetCurrentProposal(_ context.Context, baseBranch, branchPrefix string) (*srcgit.Proposal, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, proposal := range p.proposals {
+		if proposal.Open &&
+			proposal.BaseBranch == baseBranch &&
+			strings.HasPrefix(proposal.Branch, branchPrefix) {
+			current := proposal.Proposal
+			current.Digest = path.Base(proposal.Branch)
+			current.Annotations = map[string]string{}
+			return &current, nil
+		}
+	}
+
+	return nil, fmt.Errorf("base %q: prefix %q: %w", baseBranch, branchPrefix, srcgit.ErrProposalNotFound)
+}
+
+// IsProposalOpen returns true when the proposal exists and is open.
+func (p *Proposer) IsProposalOpen(_ context.Context, proposal *srcgit.Proposal) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, err := p.get(proposal)
+	return err == nil && existing.Open
+}
+
+// CreateProposal records a new open proposal.
+func (p *Proposer) CreateProposal(_ context.Context, proposal *srcgit.Proposal, opts srcgit.ProposalOption) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	proposal.ID = strconv.Itoa(len(p.proposals) + 1)
+	proposal.URL = "glutest://proposals/" + proposal.ID
+	proposal.Annotations = map[string]string{}
+
+	if p.remote != nil {
+		if base, err := p.remote.Head(proposal.BaseBranch); err == nil {
+			proposal.BaseRevision = base
+		}
+
+		if head, err := p.remote.Head(proposal.Branch); err == nil {
+			proposal.HeadRevision = head
+		}
+	}
+
+	p.proposals = append(p.proposals, &Proposal{
+		Proposal: *proposal,
+		Open:     true,
+		Labels:   opts.Labels,
+	})
+
+	return nil
+}
+
+// CloseProposal marks the proposal as closed.
+func (p *Proposer) CloseProposal(_ context.Context, proposal *srcgit.Proposal) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, err := p.get(proposal)
+	if err != nil {
+		return err
+	}
+
+	existing.Open = false
+
+	return nil
+}
+
+// CommentProposal records a comment on the proposal.
+func (p *Proposer) CommentProposal(_ context.Context, proposal *srcgit.Proposal, message string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, err := p.get(proposal)
+	if err != nil {
+		return err
+	}
+
+	existing.Comments = append(existing.Comments, message)
+
+	return nil
+}
+
+// GetProposalStatus returns the status configured via SetStatus.
+// By default proposals are mergeable and have no checks or approvals.
+func (p *Proposer) GetProposalStatus(_ context.Context, proposal *srcgit.Proposal) (*srcgit.ProposalStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.get(proposal); err != nil {
+		return nil, err
+	}
+
+	status := p.status
+	status.Checks = append([]srcgit.Check(nil), p.status.Checks...)
+
+	return &status, nil
+}
+
+// MergeProposal marks the proposal as merged and closed.
+// When the proposer was constructed with a remote, the base branch is
+// fast-forwarded to the head of the proposal branch.
+func (p *Proposer) MergeProposal(_ context.Context, proposal *srcgit.Proposal, _ srcgit.MergeMethod) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, err := p.get(proposal)
+	if err != nil {
+		return err
+	}
+
+	if !existing.Open {
+		return fmt.Errorf("proposal %q: already closed", existing.ID)
+	}
+
+	if p.remote != nil {
+		if err := p.remote.merge(existing.BaseBranch, existing.Branch); err != nil {
+			return fmt.Errorf("merging proposal %q: %w", existing.ID, err)
+		}
+	}
+
+	existing.Open = false
+	existing.Merged = true
+
+	return nil
+}
+
+// SetStatus configures the status returned for all proposals.
+func (p *Proposer) SetStatus(status srcgit.ProposalStatus) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.status = status
+}
+
+// Proposals returns a copy of every proposal created (in order).
+func (p *Proposer) Proposals() []Proposal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	proposals := make([]Proposal, 0, len(p.proposals))
+	for _, proposal := range p.proposals {
+		cpy := *proposal
+		cpy.Labels = append([]string(nil), proposal.Labels...)
+		cpy.Comments = append([]string(nil), proposal.Comments...)
+		proposals = append(proposals, cpy)
+	}
+
+	return proposals
+}
+
+func (p *Proposer) get(proposal *srcgit.Proposal) (*Proposal, error) {
+	for _, existing := range p.proposals {
+		if existing.ID == proposal.ID {
+			return existing, nil
+		}
+	}
+
+	return nil, fmt.Errorf("proposal %q: %w", proposal.ID, srcgit.ErrProposalNotFound)
+}
@@ -0,0 +1,120 @@
+package glutest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/glutest"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposer(t *testing.T) {
+	ctx := context.Background()
+
+	remote, err := glutest.NewRemote(map[string]string{"value.txt": "one"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+	proposer := glutest.NewProposer(remote)
+
+	phase, err := glutest.NewGitPhase(ctx, remote, "pipeline", core.Metadata{Name: "production"}, func() *value { return &value{} }, proposer,
+		git.ProposeChanges[*value](git.ProposalOption{
+			Labels: []string{"glu"},
+			// merges are only attempted via MergeIfReady
+			AutoMerge: &git.AutoMerge{Interval: -1},
+		}))
+	require.NoError(t, err)
+
+	_, err = phase.Update(ctx, &value{"two"})
+	require.NoError(t, err)
+
+	proposals := proposer.Proposals()
+	require.Len(t, proposals, 1)
+
+	proposal := proposals[0]
+	assert.Equal(t, "1", proposal.ID)
+	assert.Equal(t, "main", proposal.BaseBranch)
+	assert.True(t, proposal.Open)
+	assert.Equal(t, []string{"glu"}, proposal.Labels)
+
+	// the change is pushed to the proposal branch
+	contents, err := remote.ReadFile(proposal.Branch, "value.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "two", contents)
+
+	head, err := remote.Head(proposal.Branch)
+	require.NoError(t, err)
+	assert.Equal(t, head, proposal.HeadRevision)
+
+	current, err := proposer.GetCurrentProposal(ctx, "main", "glu/")
+	require.NoError(t, err)
+	assert.Equal(t, proposal.ID, current.ID)
+	assert.True(t, proposer.IsProposalOpen(ctx, current))
+
+	require.NoError(t, proposer.CommentProposal(ctx, current, "looks good"))
+	assert.Equal(t, []string{"looks good"}, proposer.Proposals()[0].Comments)
+
+	// proposals without checks are not merged
+	require.NoError(t, phase.MergeIfReady(ctx))
+	assert.True(t, proposer.Proposals()[0].Open)
+
+	status, err := proposer.GetProposalStatus(ctx, current)
+	require.NoError(t, err)
+	assert.Equal(t, &git.ProposalStatus{Mergeable: true}, status)
+
+	proposer.SetStatus(git.ProposalStatus{
+		Checks:    []git.Check{{Name: "ci", State: git.CheckStateSuccess}},
+		Mergeable: true,
+	})
+
+	// merging fast-forwards the base branch on the remote
+	require.NoError(t, phase.MergeIfReady(ctx))
+
+	merged := proposer.Proposals()[0]
+	assert.False(t, merged.Open)
+	assert.True(t, merged.Merged)
+
+	contents, err = remote.ReadFile("main", "value.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "two", contents)
+
+	glutest.AssertDigest(ctx, t, phase, "two")
+
+	_, err = proposer.GetCurrentProposal(ctx, "main", "glu/")
+	assert.ErrorIs(t, err, git.ErrProposalNotFound)
+
+	assert.Error(t, proposer.MergeProposal(ctx, current, git.MergeMethodMerge))
+}
+
+func TestProposer_WithoutRemote(t *testing.T) {
+	ctx := context.Background()
+
+	proposer := glutest.NewProposer(nil)
+
+	proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/production/one"}
+	require.NoError(t, proposer.CreateProposal(ctx, proposal, git.ProposalOption{}))
+	assert.Equal(t, "1", proposal.ID)
+
+	current, err := proposer.GetCurrentProposal(ctx, "main", "glu/production")
+	require.NoError(t, err)
+	assert.Equal(t, "one", current.Digest)
+
+	_, err = proposer.GetCurrentProposal(ctx, "develop", "glu/production")
+	assert.ErrorIs(t, err, git.ErrProposalNotFound)
+
+	// merges are only recorded
+	require.NoError(t, proposer.MergeProposal(ctx, current, git.MergeMethodSquash))
+	assert.True(t, proposer.Proposals()[0].Merged)
+
+	proposal = &git.Proposal{BaseBranch: "main", Branch: "glu/production/two"}
+	require.NoError(t, proposer.CreateProposal(ctx, proposal, git.ProposalOption{}))
+	require.NoError(t, proposer.CloseProposal(ctx, proposal))
+	assert.False(t, proposer.IsProposalOpen(ctx, proposal))
+	assert.False(t, proposer.Proposals()[1].Merged)
+
+	assert.ErrorIs(t, proposer.CloseProposal(ctx, &git.Proposal{ID: "3"}), git.ErrProposalNotFound)
+}
@@ -0,0 +1,324 @@
+package glutest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/get-glu/glu/internal/git"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	srcgit "github.com/get-glu/glu/pkg/phases/git"
+	"github.com/go-git/go-billy/v5/memfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/uuid"
+)
+
+// Scheme is the URL scheme under which in-memory remotes are served.
+const Scheme = "glutest"
+
+var (
+	remotes = &loader{storers: map[string]storer.Storer{}}
+	// the transport is installed once and left in place, as go-git protocols
+	// are a global map which is not safe to modify while other remotes are in use
+	install sync.Once
+)
+
+// loader serves in-memory remotes to the go-git transport by path.
+type loader struct {
+	mu      sync.RWMutex
+	storers map[string]storer.Storer
+}
+
+func (l *loader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	s, ok := l.storers[ep.Path]
+	if !ok {
+		return nil, transport.ErrRepositoryNotFound
+	}
+
+	return s, nil
+}
+
+// Remote is an in-memory git remote.
+// Phases built from the remote fetch from and push to it as they would a real remote,
+// which allows git phases to be exercised without network access or a filesystem.
+type Remote struct {
+	path          string
+	storage       *memory.Storage
+	defaultBranch string
+
+	mu    sync.Mutex
+	repos []*git.Repository
+}
+
+// NewRemote constructs a new in-memory remote whose default branch (main)
+// contains a single commit populated with the provided files (a map of path to contents).
+// The remote should be closed (e.g. via t.Cleanup) once it is no longer required.
+func NewRemote(files map[string]string) (*Remote, error) {
+	install.Do(func() {
+		client.InstallProtocol(Scheme, server.NewClient(remotes))
+	})
+
+	r := &Remote{
+		path:          "/" + uuid.NewString() + ".git",
+		storage:       memory.NewStorage(),
+		defaultBranch: "main",
+	}
+
+	if _, err := gogit.Init(r.storage, nil); err != nil {
+		return nil, err
+	}
+
+	remotes.mu.Lock()
+	remotes.storers[r.path] = r.storage
+	remotes.mu.Unlock()
+
+	if len(files) == 0 {
+		files = map[string]string{"README.md": "# Glu Test Repository"}
+	}
+
+	if err := r.Commit(r.defaultBranch, "initial commit", files); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// URL returns the URL of the remote.
+func (r *Remote) URL() string {
+	return Scheme + "://remote" + r.path
+}
+
+// NewGitPhase constructs a new git phase which tracks the default branch of the remote (unless configured otherwise).
+// The proposer is optional and can be a Proposer constructed with the same remote.
+// The repository underlying the phase is closed along with the remote.
+func NewGitPhase[R srcgit.Resource](
+	ctx context.Context,
+	remote *Remote,
+	pipeline string,
+	meta core.Metadata,
+	newFn func() R,
+	proposer srcgit.Proposer,
+	opts ...containers.Option[srcgit.Phase[R]],
+) (*srcgit.Phase[R], error) {
+	repo, err := remote.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return srcgit.New(ctx, pipeline, meta, newFn, repo, proposer, opts...)
+}
+
+// Close stops the repositories of the phases built from the remote
+// and removes the remote from the transport.
+func (r *Remote) Close() error {
+	remotes.mu.Lock()
+	delete(remotes.storers, r.path)
+	remotes.mu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for _, repo := range r.repos {
+		errs = append(errs, repo.Close())
+	}
+
+	r.repos = nil
+
+	return errors.Join(errs...)
+}
+
+// repository constructs a new repository which uses the remote as its origin.
+func (r *Remote) repository(ctx context.Context) (*git.Repository, error) {
+	repo, err := git.NewRepository(ctx, slog.Default(),
+		git.WithRemote("origin", r.URL()),
+		git.WithDefaultBranch(r.defaultBranch),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.repos = append(r.repos, repo)
+
+	return repo, nil
+}
+
+// Commit writes the provided files (a map of path to contents) to the branch of the remote
+// in a new commit, creating the branch from the default branch if it does not exist.
+// It can be used to simulate changes pushed to the remote by another party.
+func (r *Remote) Commit(branch, message string, files map[string]string) error {
+	repo, err := gogit.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		return err
+	}
+
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{r.URL()}}); err != nil {
+		return err
+	}
+
+	if err := repo.Fetch(&gogit.FetchOptions{}); err != nil &&
+		!errors.Is(err, gogit.NoErrAlreadyUpToDate) &&
+		!errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return fmt.Errorf("fetching remote: %w", err)
+	}
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	ref := plumbing.NewBranchReferenceName(branch)
+	checkout := &gogit.CheckoutOptions{Branch: ref, Create: true}
+	if base, err := r.resolve(branch); err == nil {
+		checkout.Hash = base
+	} else if base, err := r.resolve(r.defaultBranch); err == nil {
+		checkout.Hash = base
+	}
+
+	if checkout.Hash.IsZero() {
+		// the remote is empty so we start the branch from an orphan commit
+		if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, ref)); err != nil {
+			return err
+		}
+	} else if err := tree.Checkout(checkout); err != nil {
+		return err
+	}
+
+	for path, contents := range files {
+		if err := tree.Filesystem.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		fi, err := tree.Filesystem.Create(path)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(fi, contents); err != nil {
+			return err
+		}
+
+		if err := fi.Close(); err != nil {
+			return err
+		}
+
+		if _, err := tree.Add(path); err != nil {
+			return err
+		}
+	}
+
+	signature := &object.Signature{Name: "glutest", Email: "glutest@get-glu.dev", When: time.Now()}
+	if _, err := tree.Commit(message, &gogit.CommitOptions{Author: signature, AllowEmptyCommits: true}); err != nil {
+		return err
+	}
+
+	return repo.Push(&gogit.PushOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", ref, ref))},
+	})
+}
+
+// ReadFile returns the contents of the file at the head of the branch on the remote.
+func (r *Remote) ReadFile(branch, path string) (string, error) {
+	hash, err := r.resolve(branch)
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := object.GetCommit(r.storage, hash)
+	if err != nil {
+		return "", err
+	}
+
+	fi, err := commit.File(path)
+	if err != nil {
+		return "", err
+	}
+
+	return fi.Contents()
+}
+
+// Head returns the revision at the head of the branch on the remote.
+func (r *Remote) Head(branch string) (string, error) {
+	hash, err := r.resolve(branch)
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// Tag creates (or moves) the lightweight tag on the remote to the head of the branch.
+// It can be used to simulate tags pushed to the remote by another party.
+func (r *Remote) Tag(tag, branch string) error {
+	head, err := r.resolve(branch)
+	if err != nil {
+		return err
+	}
+
+	return r.storage.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(tag), head))
+}
+
+// TagRevision returns the revision identified by the tag on the remote.
+func (r *Remote) TagRevision(tag string) (string, error) {
+	ref, err := r.storage.Reference(plumbing.NewTagReferenceName(tag))
+	if err != nil {
+		return "", fmt.Errorf("tag %q: %w", tag, err)
+	}
+
+	return ref.Hash().String(), nil
+}
+
+// Branches returns the names of the branches on the remote.
+func (r *Remote) Branches() (branches []string, _ error) {
+	refs, err := r.storage.IterReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	return branches, refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsBranch() {
+			branches = append(branches, ref.Name().Short())
+		}
+
+		return nil
+	})
+}
+
+// merge fast-forwards the base branch on the remote to the head of the provided branch.
+func (r *Remote) merge(base, branch string) error {
+	head, err := r.resolve(branch)
+	if err != nil {
+		return err
+	}
+
+	return r.storage.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(base), head))
+}
+
+func (r *Remote) resolve(branch string) (plumbing.Hash, error) {
+	ref, err := r.storage.Reference(plumbing.NewBranchReferenceName(branch))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("branch %q: %w", branch, err)
+	}
+
+	return ref.Hash(), nil
+}
@@ -0,0 +1,115 @@
+package glutest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/glutest"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemote(t *testing.T) {
+	remote, err := glutest.NewRemote(map[string]string{"value.txt": "one", "dir/other.txt": "other"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+	initial, err := remote.Head("main")
+	require.NoError(t, err)
+
+	contents, err := remote.ReadFile("main", "dir/other.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "other", contents)
+
+	// new branches are created from the default branch
+	require.NoError(t, remote.Commit("feature", "update value", map[string]string{"value.txt": "two"}))
+
+	contents, err = remote.ReadFile("feature", "value.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "two", contents)
+
+	contents, err = remote.ReadFile("feature", "dir/other.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "other", contents)
+
+	contents, err = remote.ReadFile("main", "value.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "one", contents)
+
+	head, err := remote.Head("main")
+	require.NoError(t, err)
+	assert.Equal(t, initial, head)
+
+	branches, err := remote.Branches()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"main", "feature"}, branches)
+
+	require.NoError(t, remote.Tag("v1.0.0", "feature"))
+
+	revision, err := remote.TagRevision("v1.0.0")
+	require.NoError(t, err)
+
+	feature, err := remote.Head("feature")
+	require.NoError(t, err)
+	assert.Equal(t, feature, revision)
+
+	_, err = remote.Head("missing")
+	assert.Error(t, err)
+
+	_, err = remote.TagRevision("v2.0.0")
+	assert.Error(t, err)
+}
+
+func TestNewGitPhase(t *testing.T) {
+	ctx := context.Background()
+
+	remote, err := glutest.NewRemote(map[string]string{"value.txt": "one"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+	phase, err := glutest.NewGitPhase(ctx, remote, "pipeline", core.Metadata{Name: "production"}, func() *value { return &value{} }, nil)
+	require.NoError(t, err)
+
+	glutest.AssertDigest(ctx, t, phase, "one")
+
+	// updates are pushed to the remote
+	result, err := phase.Update(ctx, &value{"two"})
+	require.NoError(t, err)
+
+	head, err := remote.Head("main")
+	require.NoError(t, err)
+	assert.Equal(t, head, result.Annotations[git.AnnotationGitHeadSHAKey])
+
+	contents, err := remote.ReadFile("main", "value.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "two", contents)
+
+	// changes pushed by another party are fetched on update
+	require.NoError(t, remote.Commit("main", "external change", map[string]string{"value.txt": "three"}))
+
+	_, err = phase.Update(ctx, &value{"three"})
+	assert.ErrorIs(t, err, core.ErrNoChange)
+	glutest.AssertDigest(ctx, t, phase, "three")
+}
+
+func TestRemote_Close(t *testing.T) {
+	ctx := context.Background()
+
+	remote, err := glutest.NewRemote(map[string]string{"value.txt": "one"})
+	require.NoError(t, err)
+
+	_, err = glutest.NewGitPhase(ctx, remote, "pipeline", core.Metadata{Name: "production"}, func() *value { return &value{} }, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, remote.Close())
+
+	// the remote is no longer served once closed
+	_, err = glutest.NewGitPhase(ctx, remote, "pipeline", core.Metadata{Name: "production"}, func() *value { return &value{} }, nil)
+	assert.Error(t, err)
+
+	require.NoError(t, remote.Close())
+}
@@ -2,6 +2,7 @@ package bolt
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/fs"
 	"iter"
@@ -9,6 +10,7 @@ import (
 	"github.com/get-glu/glu/pkg/containers"
 	"github.com/get-glu/glu/pkg/kv"
 	"go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
 )
 
 var _ kv.DB = (*DB)(nil)
@@ -44,7 +46,7 @@ type Tx struct {
 
 func (t *Tx) Bucket(name []byte) (kv.Bucket, error) {
 	if bkt := t.tx.Bucket(name); bkt != nil {
-		return &Bucket{bucket: bkt}, nil
+		return &Bucket{name: string(name), bucket: bkt}, nil
 	}
 
 	return nil, fmt.Errorf("bucket %q: %w", string(name), kv.ErrNotFound)
@@ -56,7 +58,11 @@ func (t *Tx) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
 		return nil, err
 	}
 
-	return &Bucket{bucket: bkt}, nil
+	return &Bucket{name: string(name), bucket: bkt}, nil
+}
+
+func (t *Tx) DeleteBucket(name []byte) error {
+	return deleteBucket(t.tx.DeleteBucket, name)
 }
 
 type Bucket struct {
@@ -65,7 +71,7 @@ type Bucket struct {
 }
 
 func (b *Bucket) First() (k, v []byte, err error) {
-	if k, v := b.bucket.Cursor().First(); k != nil {
+	for k, v := range b.Range() {
 		return k, v, nil
 	}
 
@@ -73,7 +79,7 @@ func (b *Bucket) First() (k, v []byte, err error) {
 }
 
 func (b *Bucket) Last() (k, v []byte, err error) {
-	if k, v := b.bucket.Cursor().Last(); k != nil {
+	for k, v := range b.Range(kv.WithOrder(kv.Descending)) {
 		return k, v, nil
 	}
 
@@ -94,7 +100,23 @@ func (b *Bucket) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
 		return nil, err
 	}
 
-	return &Bucket{bucket: bkt}, nil
+	return &Bucket{name: string(name), bucket: bkt}, nil
+}
+
+func (b *Bucket) DeleteBucket(name []byte) error {
+	return deleteBucket(b.bucket.DeleteBucket, name)
+}
+
+func deleteBucket(fn func([]byte) error, name []byte) error {
+	if err := fn(name); err != nil {
+		if errors.Is(err, berrors.ErrBucketNotFound) {
+			return fmt.Errorf("bucket %q: %w", string(name), kv.ErrNotFound)
+		}
+
+		return fmt.Errorf("bucket %q: %w", string(name), err)
+	}
+
+	return nil
 }
 
 func (b *Bucket) Get(key []byte) ([]byte, error) {
@@ -109,45 +131,64 @@ func (b *Bucket) Put(k, v []byte) error {
 	return b.bucket.Put(k, v)
 }
 
+func (b *Bucket) Delete(k []byte) error {
+	return b.bucket.Delete(k)
+}
+
+func (b *Bucket) Count() (count int, _ error) {
+	cursor := b.bucket.Cursor()
+	for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+		// nested buckets have nil values
+		if v != nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
 func (b *Bucket) Range(opts ...containers.Option[kv.RangeOptions]) iter.Seq2[[]byte, []byte] {
 	var options kv.RangeOptions
 	containers.ApplyAll(&options, opts...)
 
-	var (
-		cursor = b.bucket.Cursor()
-		k, v   []byte
-	)
-
-	if options.Start != nil {
-		// Seek moves the cursor to a given key using a b-tree search and returns it. If the key does not exist then the next key is used. If no keys follow, a nil key is returned
-		if k, v = cursor.Seek(options.Start); options.Order == kv.Descending && bytes.Compare(k, options.Start) > 0 {
-			k, v = cursor.Prev()
+	return iter.Seq2[[]byte, []byte](func(yield func(k, v []byte) bool) {
+		var (
+			cursor = b.bucket.Cursor()
+			next   = cursor.Next
+			k, v   []byte
+		)
+
+		if options.Order == kv.Descending {
+			next = cursor.Prev
 		}
-	} else {
-		switch options.Order {
-		case kv.Descending:
+
+		if seek, inclusive := options.Seek(); seek != nil {
+			// Seek moves the cursor to a given key using a b-tree search and returns it. If the key does not exist then the next key is used. If no keys follow, a nil key is returned
+			k, v = cursor.Seek(seek)
+			if options.Order == kv.Descending {
+				switch {
+				case k == nil:
+					// every key precedes the seek key
+					k, v = cursor.Last()
+				case bytes.Compare(k, seek) > 0 || (!inclusive && bytes.Equal(k, seek)):
+					k, v = cursor.Prev()
+				}
+			}
+		} else if options.Order == kv.Descending {
 			k, v = cursor.Last()
-		default:
-			// ascending
+		} else {
 			k, v = cursor.First()
 		}
-	}
-
-	return iter.Seq2[[]byte, []byte](func(yield func(k, v []byte) bool) {
-		if k == nil {
-			return
-		}
 
-		if !yield(k, v) {
-			return
-		}
+		for ; k != nil && options.Contains(k); k, v = next() {
+			// nested buckets have nil values
+			if v == nil {
+				continue
+			}
 
-		switch options.Order {
-		case kv.Descending:
-			k, v = cursor.Prev()
-		default:
-			// ascending
-			k, v = cursor.Next()
+			if !yield(k, v) {
+				return
+			}
 		}
 	})
 }
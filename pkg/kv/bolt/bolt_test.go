@@ -0,0 +1,21 @@
+package bolt
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/kv"
+	"github.com/get-glu/glu/pkg/kv/kvtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB(t *testing.T) {
+	kvtest.Run(t, func(t *testing.T) kv.DB {
+		db, err := Open(filepath.Join(t.TempDir(), "glu.db"), 0600, nil)
+		require.NoError(t, err)
+
+		t.Cleanup(func() { _ = db.db.Close() })
+
+		return db
+	})
+}
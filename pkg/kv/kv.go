@@ -1,6 +1,7 @@
 package kv
 
 import (
+	"bytes"
 	"errors"
 	"iter"
 
@@ -23,17 +24,30 @@ type DB interface {
 type Tx interface {
 	Bucket([]byte) (Bucket, error)
 	CreateBucketIfNotExists([]byte) (Bucket, error)
+	// DeleteBucket removes the named bucket along with its contents and nested buckets.
+	// It returns ErrNotFound when the bucket does not exist.
+	DeleteBucket([]byte) error
 }
 
 // Bucket is an abstraction around KV database buckets
 type Bucket interface {
 	Bucket([]byte) (Bucket, error)
 	CreateBucketIfNotExists([]byte) (Bucket, error)
+	// DeleteBucket removes the named nested bucket along with its contents and nested buckets.
+	// It returns ErrNotFound when the bucket does not exist.
+	DeleteBucket([]byte) error
 	Get([]byte) ([]byte, error)
 	Put(k, v []byte) error
+	// Delete removes the key from the bucket.
+	// Deleting a key which does not exist is not an error.
+	Delete([]byte) error
 	First() (k, v []byte, _ error)
 	Last() (k, v []byte, _ error)
+	// Range returns the keys and values in the bucket (excluding nested buckets)
+	// within the bounds of the provided options.
 	Range(opts ...containers.Option[RangeOptions]) iter.Seq2[[]byte, []byte]
+	// Count returns the number of keys in the bucket (excluding nested buckets).
+	Count() (int, error)
 }
 
 // Order is a type which identifies a range order
@@ -49,7 +63,12 @@ const (
 // RangeOptions configures a call to Bucket.Range
 type RangeOptions struct {
 	Order Order
+	// Start is the first key (inclusive) in the order of the range
 	Start []byte
+	// End is the key (exclusive) at which the range stops in the order of the range
+	End []byte
+	// Prefix restricts the range to keys with the prefix
+	Prefix []byte
 }
 
 // WithOrder configures a call to Range with the provided order
@@ -65,3 +84,70 @@ func WithStart(k []byte) containers.Option[RangeOptions] {
 		ro.Start = k
 	}
 }
+
+// WithEnd configures a call to Range to stop at the provided key (exclusive)
+func WithEnd(k []byte) containers.Option[RangeOptions] {
+	return func(ro *RangeOptions) {
+		ro.End = k
+	}
+}
+
+// WithPrefix configures a call to Range to only return keys with the provided prefix
+func WithPrefix(p []byte) containers.Option[RangeOptions] {
+	return func(ro *RangeOptions) {
+		ro.Prefix = p
+	}
+}
+
+// Seek returns the key which implementations should position a range at before iterating.
+// A nil key means the range begins at the first (or last when descending) key in the bucket.
+// When inclusive is false, the key itself is not part of the range and should be skipped.
+func (o *RangeOptions) Seek() (k []byte, inclusive bool) {
+	if o.Order == Descending {
+		k, inclusive = o.Start, true
+		// the last possible key with the prefix precedes its successor
+		if succ := successor(o.Prefix); succ != nil && (k == nil || bytes.Compare(succ, k) <= 0) {
+			k, inclusive = succ, false
+		}
+
+		return k, inclusive
+	}
+
+	k = o.Start
+	if len(o.Prefix) > 0 && bytes.Compare(o.Prefix, k) > 0 {
+		k = o.Prefix
+	}
+
+	return k, true
+}
+
+// Contains reports whether the key is within the bounds of the range.
+// Once a range has been positioned using Seek, keys are visited in order and
+// iteration can stop at the first key which is not contained.
+func (o *RangeOptions) Contains(k []byte) bool {
+	if !bytes.HasPrefix(k, o.Prefix) {
+		return false
+	}
+
+	if o.Order == Descending {
+		return (o.Start == nil || bytes.Compare(k, o.Start) <= 0) &&
+			(o.End == nil || bytes.Compare(k, o.End) > 0)
+	}
+
+	return (o.Start == nil || bytes.Compare(k, o.Start) >= 0) &&
+		(o.End == nil || bytes.Compare(k, o.End) < 0)
+}
+
+// successor returns the first key which is greater than every key with the provided prefix.
+// It returns nil when no such key exists (the prefix is empty or consists entirely of 0xff).
+func successor(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			succ := bytes.Clone(prefix[:i+1])
+			succ[i]++
+			return succ
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,526 @@
+// Package kvtest provides a conformance test suite for implementations of kv.DB.
+//
+// Implementations run the suite from their own tests by supplying a constructor
+// for a new, empty database:
+//
+//	func TestDB(t *testing.T) {
+//		kvtest.Run(t, func(t *testing.T) kv.DB {
+//			return New()
+//		})
+//	}
+package kvtest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run runs the conformance suite against databases constructed using open.
+// Each test within the suite calls open to obtain a new, empty database.
+func Run(t *testing.T, open func(t *testing.T) kv.DB) {
+	t.Helper()
+
+	for _, test := range []struct {
+		name string
+		fn   func(*testing.T, kv.DB)
+	}{
+		{"Buckets", testBuckets},
+		{"DeleteBucket", testDeleteBucket},
+		{"PutGetDelete", testPutGetDelete},
+		{"FirstLast", testFirstLast},
+		{"Range", testRange},
+		{"RangeWithinUpdate", testRangeWithinUpdate},
+		{"Count", testCount},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.fn(t, open(t))
+		})
+	}
+}
+
+func testBuckets(t *testing.T, db kv.DB) {
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(nil)
+		require.Error(t, err, "creating a bucket with an empty name")
+
+		parent, err := tx.CreateBucketIfNotExists([]byte("parent"))
+		require.NoError(t, err)
+
+		child, err := parent.CreateBucketIfNotExists([]byte("child"))
+		require.NoError(t, err)
+		require.NoError(t, child.Put([]byte("key"), []byte("value")))
+
+		// creating an existing bucket returns the existing bucket
+		child, err = parent.CreateBucketIfNotExists([]byte("child"))
+		require.NoError(t, err)
+
+		v, err := child.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), v)
+
+		return nil
+	}))
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		_, err := tx.Bucket([]byte("missing"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		// nested buckets are not visible at the top-level
+		_, err = tx.Bucket([]byte("child"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		parent, err := tx.Bucket([]byte("parent"))
+		require.NoError(t, err)
+
+		_, err = parent.Bucket([]byte("missing"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		child, err := parent.Bucket([]byte("child"))
+		require.NoError(t, err)
+
+		v, err := child.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), v)
+
+		return nil
+	}))
+}
+
+func testDeleteBucket(t *testing.T, db kv.DB) {
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		for _, name := range []string{"keep", "delete"} {
+			parent, err := tx.CreateBucketIfNotExists([]byte(name))
+			require.NoError(t, err)
+			require.NoError(t, parent.Put([]byte("key"), []byte("value")))
+
+			child, err := parent.CreateBucketIfNotExists([]byte("child"))
+			require.NoError(t, err)
+			require.NoError(t, child.Put([]byte("key"), []byte("value")))
+
+			grandchild, err := child.CreateBucketIfNotExists([]byte("grandchild"))
+			require.NoError(t, err)
+			require.NoError(t, grandchild.Put([]byte("key"), []byte("value")))
+		}
+
+		return nil
+	}))
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		require.ErrorIs(t, tx.DeleteBucket([]byte("missing")), kv.ErrNotFound)
+		require.NoError(t, tx.DeleteBucket([]byte("delete")))
+
+		// deleted buckets are no longer visible within the update
+		_, err := tx.Bucket([]byte("delete"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+		require.ErrorIs(t, tx.DeleteBucket([]byte("delete")), kv.ErrNotFound)
+
+		keep, err := tx.Bucket([]byte("keep"))
+		require.NoError(t, err)
+		require.ErrorIs(t, keep.DeleteBucket([]byte("missing")), kv.ErrNotFound)
+
+		child, err := keep.Bucket([]byte("child"))
+		require.NoError(t, err)
+		require.NoError(t, child.DeleteBucket([]byte("grandchild")))
+
+		_, err = child.Bucket([]byte("grandchild"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		return nil
+	}))
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		_, err := tx.Bucket([]byte("delete"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		keep, err := tx.Bucket([]byte("keep"))
+		require.NoError(t, err)
+
+		child, err := keep.Bucket([]byte("child"))
+		require.NoError(t, err)
+
+		// sibling keys are retained
+		_, err = child.Get([]byte("key"))
+		require.NoError(t, err)
+
+		_, err = child.Bucket([]byte("grandchild"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		return nil
+	}))
+
+	// recreated buckets are empty
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		parent, err := tx.CreateBucketIfNotExists([]byte("delete"))
+		require.NoError(t, err)
+
+		_, err = parent.Get([]byte("key"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		_, err = parent.Bucket([]byte("child"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		count, err := parent.Count()
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		return nil
+	}))
+
+	// buckets can be deleted and recreated within the same update
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		require.NoError(t, tx.DeleteBucket([]byte("keep")))
+
+		keep, err := tx.CreateBucketIfNotExists([]byte("keep"))
+		require.NoError(t, err)
+
+		_, err = keep.Bucket([]byte("child"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		return keep.Put([]byte("other"), []byte("value"))
+	}))
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		keep, err := tx.Bucket([]byte("keep"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"other"}, collect(t, keep))
+
+		_, err = keep.Bucket([]byte("child"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		return nil
+	}))
+}
+
+func testPutGetDelete(t *testing.T, db kv.DB) {
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		require.NoError(t, err)
+
+		_, err = bkt.Get([]byte("key"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		require.NoError(t, bkt.Put([]byte("key"), []byte("one")))
+		require.NoError(t, bkt.Put([]byte("key"), []byte("two")))
+		require.NoError(t, bkt.Put([]byte("other"), []byte("value")))
+		require.NoError(t, bkt.Put([]byte("transient"), []byte("value")))
+
+		// writes are visible within the update
+		v, err := bkt.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("two"), v)
+
+		require.NoError(t, bkt.Delete([]byte("transient")))
+		_, err = bkt.Get([]byte("transient"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		// deleting a missing key is not an error
+		require.NoError(t, bkt.Delete([]byte("missing")))
+
+		return nil
+	}))
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.Bucket([]byte("bucket"))
+		require.NoError(t, err)
+
+		v, err := bkt.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("two"), v)
+
+		_, err = bkt.Get([]byte("transient"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		return bkt.Delete([]byte("other"))
+	}))
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		bkt, err := tx.Bucket([]byte("bucket"))
+		require.NoError(t, err)
+
+		_, err = bkt.Get([]byte("other"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		assert.Equal(t, []string{"key"}, collect(t, bkt))
+
+		return nil
+	}))
+}
+
+func testFirstLast(t *testing.T, db kv.DB) {
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		require.NoError(t, err)
+
+		_, _, err = bkt.First()
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		_, _, err = bkt.Last()
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		for _, k := range []string{"b", "c", "a"} {
+			require.NoError(t, bkt.Put([]byte(k), []byte("v"+k)))
+		}
+
+		// nested buckets are neither first nor last
+		for _, name := range []string{"0", "d"} {
+			_, err = bkt.CreateBucketIfNotExists([]byte(name))
+			require.NoError(t, err)
+		}
+
+		return nil
+	}))
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		bkt, err := tx.Bucket([]byte("bucket"))
+		require.NoError(t, err)
+
+		k, v, err := bkt.First()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("a"), k)
+		assert.Equal(t, []byte("va"), v)
+
+		k, v, err = bkt.Last()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("c"), k)
+		assert.Equal(t, []byte("vc"), v)
+
+		return nil
+	}))
+}
+
+func testRange(t *testing.T, db kv.DB) {
+	keys := []string{"a", "a/1", "a/2", "a/3", "b", "b/1", "c", "\xff", "\xff\x01", "\xff\xff"}
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		require.NoError(t, err)
+
+		// insert out of order
+		for _, k := range slices.Backward(keys) {
+			require.NoError(t, bkt.Put([]byte(k), []byte("v"+k)))
+		}
+
+		// nested buckets are not part of the range
+		_, err = bkt.CreateBucketIfNotExists([]byte("a/nested"))
+		require.NoError(t, err)
+
+		_, err = tx.CreateBucketIfNotExists([]byte("empty"))
+		require.NoError(t, err)
+
+		return nil
+	}))
+
+	asc := func(opts ...containers.Option[kv.RangeOptions]) []containers.Option[kv.RangeOptions] {
+		return opts
+	}
+
+	desc := func(opts ...containers.Option[kv.RangeOptions]) []containers.Option[kv.RangeOptions] {
+		return append(opts, kv.WithOrder(kv.Descending))
+	}
+
+	for _, test := range []struct {
+		name     string
+		opts     []containers.Option[kv.RangeOptions]
+		expected []string
+	}{
+		{"all", asc(), keys},
+		{"start", asc(kv.WithStart([]byte("b"))), keys[4:]},
+		{"start missing", asc(kv.WithStart([]byte("a/21"))), keys[3:]},
+		{"start after last", asc(kv.WithStart([]byte("\xff\xff\x01"))), nil},
+		{"end", asc(kv.WithEnd([]byte("b"))), keys[:4]},
+		{"end missing", asc(kv.WithEnd([]byte("a/21"))), keys[:3]},
+		{"start and end", asc(kv.WithStart([]byte("a/2")), kv.WithEnd([]byte("b/1"))), keys[2:5]},
+		{"prefix", asc(kv.WithPrefix([]byte("a/"))), keys[1:4]},
+		{"prefix missing", asc(kv.WithPrefix([]byte("d"))), nil},
+		{"prefix 0xff", asc(kv.WithPrefix([]byte("\xff"))), keys[7:]},
+		{"prefix and start", asc(kv.WithPrefix([]byte("a/")), kv.WithStart([]byte("a/2"))), keys[2:4]},
+		{"prefix and start before", asc(kv.WithPrefix([]byte("b")), kv.WithStart([]byte("a"))), keys[4:6]},
+		{"prefix and start after", asc(kv.WithPrefix([]byte("a/")), kv.WithStart([]byte("b"))), nil},
+		{"prefix and end", asc(kv.WithPrefix([]byte("a/")), kv.WithEnd([]byte("a/3"))), keys[1:3]},
+		{"descending", desc(), reversed(keys)},
+		{"descending start", desc(kv.WithStart([]byte("b"))), reversed(keys[:5])},
+		{"descending start missing", desc(kv.WithStart([]byte("a/21"))), reversed(keys[:3])},
+		{"descending start before first", desc(kv.WithStart([]byte("0"))), nil},
+		{"descending start after last", desc(kv.WithStart([]byte("\xff\xff\x01"))), reversed(keys)},
+		{"descending end", desc(kv.WithEnd([]byte("b"))), reversed(keys[5:])},
+		{"descending start and end", desc(kv.WithStart([]byte("b/1")), kv.WithEnd([]byte("a/2"))), reversed(keys[3:6])},
+		{"descending prefix", desc(kv.WithPrefix([]byte("a/"))), reversed(keys[1:4])},
+		{"descending prefix 0xff", desc(kv.WithPrefix([]byte("\xff"))), reversed(keys[7:])},
+		{"descending prefix and start", desc(kv.WithPrefix([]byte("a/")), kv.WithStart([]byte("a/2"))), reversed(keys[1:3])},
+		{"descending prefix and start after", desc(kv.WithPrefix([]byte("a")), kv.WithStart([]byte("c"))), reversed(keys[:4])},
+		{"descending prefix and start before", desc(kv.WithPrefix([]byte("b")), kv.WithStart([]byte("a/3"))), nil},
+		{"descending prefix and end", desc(kv.WithPrefix([]byte("a/")), kv.WithEnd([]byte("a/1"))), reversed(keys[2:4])},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, db.View(func(tx kv.Tx) error {
+				bkt, err := tx.Bucket([]byte("bucket"))
+				require.NoError(t, err)
+
+				assert.Equal(t, test.expected, collect(t, bkt, test.opts...))
+
+				return nil
+			}))
+		})
+	}
+
+	t.Run("values", func(t *testing.T) {
+		require.NoError(t, db.View(func(tx kv.Tx) error {
+			bkt, err := tx.Bucket([]byte("bucket"))
+			require.NoError(t, err)
+
+			for _, order := range []kv.Order{kv.Ascending, kv.Descending} {
+				for k, v := range bkt.Range(kv.WithOrder(order)) {
+					assert.Equal(t, "v"+string(k), string(v))
+				}
+			}
+
+			return nil
+		}))
+	})
+
+	t.Run("stop early", func(t *testing.T) {
+		require.NoError(t, db.View(func(tx kv.Tx) error {
+			bkt, err := tx.Bucket([]byte("bucket"))
+			require.NoError(t, err)
+
+			var found []string
+			for k := range bkt.Range() {
+				if found = append(found, string(k)); len(found) == 2 {
+					break
+				}
+			}
+
+			assert.Equal(t, keys[:2], found)
+
+			return nil
+		}))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		require.NoError(t, db.View(func(tx kv.Tx) error {
+			bkt, err := tx.Bucket([]byte("empty"))
+			require.NoError(t, err)
+
+			assert.Empty(t, collect(t, bkt))
+			assert.Empty(t, collect(t, bkt, kv.WithOrder(kv.Descending)))
+
+			return nil
+		}))
+	})
+}
+
+func testRangeWithinUpdate(t *testing.T, db kv.DB) {
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		require.NoError(t, err)
+
+		for _, k := range []string{"a", "b", "c", "d"} {
+			require.NoError(t, bkt.Put([]byte(k), []byte("v"+k)))
+		}
+
+		return nil
+	}))
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.Bucket([]byte("bucket"))
+		require.NoError(t, err)
+
+		require.NoError(t, bkt.Put([]byte("b"), []byte("updated")))
+		require.NoError(t, bkt.Put([]byte("bb"), []byte("vbb")))
+		require.NoError(t, bkt.Delete([]byte("c")))
+		require.NoError(t, bkt.Put([]byte("e"), []byte("ve")))
+
+		// writes are visible to ranges within the update
+		assert.Equal(t, []string{"a", "b", "bb", "d", "e"}, collect(t, bkt))
+		assert.Equal(t, []string{"e", "d", "bb", "b"}, collect(t, bkt, kv.WithOrder(kv.Descending), kv.WithEnd([]byte("a"))))
+		assert.Equal(t, []string{"b", "bb"}, collect(t, bkt, kv.WithPrefix([]byte("b"))))
+
+		v, err := bkt.Get([]byte("b"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("updated"), v)
+
+		k, _, err := bkt.Last()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("e"), k)
+
+		return nil
+	}))
+}
+
+func testCount(t *testing.T, db kv.DB) {
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		require.NoError(t, err)
+
+		count, err := bkt.Count()
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		for i := range 5 {
+			require.NoError(t, bkt.Put(fmt.Appendf(nil, "key-%d", i), []byte("value")))
+		}
+
+		// nested buckets are not counted
+		_, err = bkt.CreateBucketIfNotExists([]byte("nested"))
+		require.NoError(t, err)
+
+		count, err = bkt.Count()
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+
+		return nil
+	}))
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.Bucket([]byte("bucket"))
+		require.NoError(t, err)
+
+		// overwritten, deleted and new keys are reflected in the count
+		require.NoError(t, bkt.Put([]byte("key-0"), []byte("updated")))
+		require.NoError(t, bkt.Delete([]byte("key-1")))
+		require.NoError(t, bkt.Delete([]byte("missing")))
+		require.NoError(t, bkt.Put([]byte("key-5"), []byte("value")))
+		require.NoError(t, bkt.Put([]byte("key-6"), []byte("value")))
+
+		count, err := bkt.Count()
+		require.NoError(t, err)
+		assert.Equal(t, 6, count)
+
+		return nil
+	}))
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		bkt, err := tx.Bucket([]byte("bucket"))
+		require.NoError(t, err)
+
+		count, err := bkt.Count()
+		require.NoError(t, err)
+		assert.Equal(t, 6, count)
+
+		return nil
+	}))
+}
+
+// collect returns the keys in the range of the bucket.
+func collect(t *testing.T, bkt kv.Bucket, opts ...containers.Option[kv.RangeOptions]) (keys []string) {
+	t.Helper()
+
+	for k := range bkt.Range(opts...) {
+		keys = append(keys, string(k))
+	}
+
+	return keys
+}
+
+func reversed(s []string) []string {
+	r := slices.Clone(s)
+	slices.Reverse(r)
+	return r
+}
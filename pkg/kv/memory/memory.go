@@ -47,6 +47,10 @@ func (t *Tx) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
 	return t.bkt.CreateBucketIfNotExists(name)
 }
 
+func (t *Tx) DeleteBucket(name []byte) error {
+	return t.bkt.DeleteBucket(name)
+}
+
 type node struct {
 	k, v []byte
 }
@@ -91,6 +95,16 @@ func (b *Bucket) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
 	return bkt, nil
 }
 
+func (b *Bucket) DeleteBucket(name []byte) error {
+	if _, ok := b.buckets[string(name)]; !ok {
+		return fmt.Errorf("bucket %q: %w", string(name), kv.ErrNotFound)
+	}
+
+	delete(b.buckets, string(name))
+
+	return nil
+}
+
 func (b *Bucket) Get(key []byte) ([]byte, error) {
 	if node, ok := b.contents.Get(node{k: key}); ok {
 		return node.v, nil
@@ -104,6 +118,15 @@ func (b *Bucket) Put(k []byte, v []byte) error {
 	return nil
 }
 
+func (b *Bucket) Delete(k []byte) error {
+	_, _ = b.contents.Delete(node{k: k})
+	return nil
+}
+
+func (b *Bucket) Count() (int, error) {
+	return b.contents.Len(), nil
+}
+
 func (b *Bucket) First() (k []byte, v []byte, _ error) {
 	if node, ok := b.contents.Min(); ok {
 		return node.k, node.v, nil
@@ -124,58 +147,27 @@ func (b *Bucket) Range(opts ...containers.Option[kv.RangeOptions]) iter.Seq2[[]b
 	var options kv.RangeOptions
 	containers.ApplyAll(&options, opts...)
 
-	// sorry but here be dragons
-	//
-	// btree exposes its own iterator interface which is a pain
-	// to adapt into the new iter package approach
-	// btree forcibly drives its own iteration which means
-	// we can't yield when we want in our coro so we use
-	// a channel here to push back on the iterator and put our
-	// iterator back into the driving seat.
-
-	var (
-		ch = make(chan node, 0)
-		// stop signals for the btree iterator to return as we no longer
-		// want anymore nodes
-		stop = make(chan struct{}, 0)
-	)
-
-	fn := btree.ItemIteratorG[node](func(n node) bool {
-		select {
-		case ch <- n:
-			return true
-		case <-stop:
-			return false
-		}
-	})
-
-	go func() {
-		defer close(ch)
-
-		if options.Start != nil {
-			switch options.Order {
-			case kv.Descending:
-				b.contents.DescendLessOrEqual(node{k: options.Start}, fn)
-			default:
-				b.contents.AscendGreaterOrEqual(node{k: options.Start}, fn)
-			}
-		} else {
-			switch options.Order {
-			case kv.Descending:
-				b.contents.Descend(fn)
-			default:
-				b.contents.Ascend(fn)
-			}
-		}
-	}()
-
 	return iter.Seq2[[]byte, []byte](func(yield func(k, v []byte) bool) {
-		defer close(stop)
+		seek, inclusive := options.Seek()
 
-		for node := range ch {
-			if !yield(node.k, node.v) {
-				return
+		fn := btree.ItemIteratorG[node](func(n node) bool {
+			// the seek key itself is skipped when it is not part of the range
+			if !inclusive && bytes.Equal(n.k, seek) {
+				return true
 			}
+
+			return options.Contains(n.k) && yield(n.k, n.v)
+		})
+
+		switch {
+		case seek != nil && options.Order == kv.Descending:
+			b.contents.DescendLessOrEqual(node{k: seek}, fn)
+		case seek != nil:
+			b.contents.AscendGreaterOrEqual(node{k: seek}, fn)
+		case options.Order == kv.Descending:
+			b.contents.Descend(fn)
+		default:
+			b.contents.Ascend(fn)
 		}
 	})
 }
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/get-glu/glu/pkg/kv"
+	"github.com/get-glu/glu/pkg/kv/kvtest"
+)
+
+func TestDB(t *testing.T) {
+	kvtest.Run(t, func(t *testing.T) kv.DB {
+		return New()
+	})
+}
@@ -0,0 +1,646 @@
+// Package redis provides a kv.DB implementation backed by Redis.
+// It allows multiple glu replicas to share phase history.
+//
+// Each bucket is stored as a sorted set of its keys (all with score 0, such that
+// keys are ordered lexicographically byte-wise), a hash of its keys to values
+// and a set of the names of its nested buckets.
+// All keys share the hash tag {<prefix>} so that they reside in the same slot
+// when using Redis Cluster.
+//
+// Writes (including deletions) made within Update are buffered and applied atomically via MULTI/EXEC.
+// Updates are optimistically concurrent: should another update commit while fn is running,
+// the transaction is discarded and fn is called again (up to a fixed number of attempts).
+// Reads within View are not isolated from concurrent updates.
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/kv"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var (
+	_ kv.DB = (*DB)(nil)
+
+	// ErrConflict is returned when an update could not be applied due to
+	// repeated conflicts with concurrent updates
+	ErrConflict = errors.New("conflict")
+	// ErrReadOnly is returned when attempting to write within a read transaction
+	ErrReadOnly = errors.New("transaction is read-only")
+)
+
+const (
+	defaultPrefix      = "glu"
+	defaultMaxAttempts = 10
+	// rangeBatchSize is the number of keys fetched per round trip while ranging
+	rangeBatchSize = 100
+)
+
+// DB is a kv.DB backed by Redis.
+type DB struct {
+	client      goredis.UniversalClient
+	prefix      string
+	maxAttempts int
+}
+
+// New constructs a new DB which stores buckets using the provided client.
+func New(client goredis.UniversalClient, opts ...containers.Option[DB]) *DB {
+	db := &DB{
+		client:      client,
+		prefix:      defaultPrefix,
+		maxAttempts: defaultMaxAttempts,
+	}
+
+	containers.ApplyAll(db, opts...)
+
+	return db
+}
+
+// WithPrefix configures the prefix used for every key written by the DB.
+// This allows multiple independent histories to share a Redis database.
+func WithPrefix(prefix string) containers.Option[DB] {
+	return func(d *DB) {
+		d.prefix = prefix
+	}
+}
+
+// WithMaxAttempts configures the number of times an update is attempted
+// when it conflicts with concurrent updates.
+func WithMaxAttempts(n int) containers.Option[DB] {
+	return func(d *DB) {
+		d.maxAttempts = n
+	}
+}
+
+// View calls fn with a read-only transaction.
+func (d *DB) View(fn func(kv.Tx) error) error {
+	return fn(&Tx{db: d, client: d.client})
+}
+
+// Update calls fn with a writable transaction.
+// Writes are applied atomically once fn returns nil and discarded otherwise.
+// fn may be called more than once when the update conflicts with concurrent updates.
+func (d *DB) Update(fn func(kv.Tx) error) error {
+	ctx := context.Background()
+
+	for range max(d.maxAttempts, 1) {
+		err := d.client.Watch(ctx, func(rtx *goredis.Tx) error {
+			tx := &Tx{db: d, client: rtx, writes: &writes{
+				buckets: map[string][]byte{},
+				removed: map[string][]byte{},
+				deleted: map[string]struct{}{},
+				entries: map[string]map[string][]byte{},
+			}}
+
+			if err := fn(tx); err != nil {
+				return err
+			}
+
+			_, err := rtx.TxPipelined(ctx, func(p goredis.Pipeliner) error {
+				tx.writes.apply(ctx, p)
+				// every update changes the version so that concurrent updates
+				// (which watch the version) are aborted
+				p.Incr(ctx, d.versionKey())
+				return nil
+			})
+
+			return err
+		}, d.versionKey())
+		if errors.Is(err, goredis.TxFailedErr) {
+			continue
+		}
+
+		return err
+	}
+
+	return fmt.Errorf("update after %d attempts: %w", d.maxAttempts, ErrConflict)
+}
+
+func (d *DB) versionKey() string {
+	return "{" + d.prefix + "}:version"
+}
+
+// rootKey is the key of the set of top-level bucket names.
+func (d *DB) rootKey() string {
+	return "{" + d.prefix + "}:buckets"
+}
+
+// bucketKey returns the key which prefixes all keys for the bucket at the provided path.
+func (d *DB) bucketKey(path []string) string {
+	escaped := make([]string, 0, len(path))
+	for _, p := range path {
+		escaped = append(escaped, url.QueryEscape(p))
+	}
+
+	return "{" + d.prefix + "}:bucket:" + strings.Join(escaped, "/")
+}
+
+// writes are the changes buffered within an update.
+type writes struct {
+	// buckets are the names of created buckets keyed by the set of their parent
+	buckets map[string][]byte
+	// removed are the names of deleted buckets keyed by the set of their parent
+	removed map[string][]byte
+	// deleted are the keys of deleted buckets (including nested buckets)
+	// whose stored contents are cleared
+	deleted map[string]struct{}
+	// entries are the written key and values keyed by their bucket
+	// deleted keys have a nil value
+	entries map[string]map[string][]byte
+}
+
+func (w *writes) apply(ctx context.Context, p goredis.Pipeliner) {
+	// deletions are applied first such that buckets can be recreated within the same update
+	for _, bucket := range sortedKeys(w.deleted) {
+		p.Del(ctx, bucket+":keys", bucket+":values", bucket+":buckets")
+	}
+
+	for _, key := range sortedKeys(w.removed) {
+		parent, _, _ := strings.Cut(key, "\x00")
+		p.SRem(ctx, parent, w.removed[key])
+	}
+
+	for _, key := range sortedKeys(w.buckets) {
+		parent, _, _ := strings.Cut(key, "\x00")
+		p.SAdd(ctx, parent, w.buckets[key])
+	}
+
+	for _, bucket := range sortedKeys(w.entries) {
+		entries := w.entries[bucket]
+		for _, k := range sortedKeys(entries) {
+			if entries[k] == nil {
+				p.ZRem(ctx, bucket+":keys", k)
+				p.HDel(ctx, bucket+":values", k)
+				continue
+			}
+
+			p.ZAdd(ctx, bucket+":keys", goredis.Z{Member: k})
+			p.HSet(ctx, bucket+":values", k, entries[k])
+		}
+	}
+}
+
+// Tx is a transaction on a Redis backed DB.
+type Tx struct {
+	db     *DB
+	client goredis.Cmdable
+	// writes is nil for read-only transactions
+	writes *writes
+}
+
+// Bucket returns the top-level bucket with the provided name.
+func (t *Tx) Bucket(name []byte) (kv.Bucket, error) {
+	return t.bucket(t.db.rootKey(), nil, name)
+}
+
+// CreateBucketIfNotExists returns the top-level bucket with the provided name,
+// creating it when it does not exist.
+func (t *Tx) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
+	return t.createBucket(t.db.rootKey(), nil, name)
+}
+
+// DeleteBucket removes the top-level bucket with the provided name
+// along with its contents and nested buckets.
+func (t *Tx) DeleteBucket(name []byte) error {
+	return t.deleteBucket(t.db.rootKey(), nil, name)
+}
+
+func (t *Tx) bucket(parentKey string, parent []string, name []byte) (*Bucket, error) {
+	bkt := &Bucket{tx: t, name: string(name), path: append(slices.Clone(parent), string(name))}
+	bkt.key = t.db.bucketKey(bkt.path)
+
+	if t.writes != nil {
+		if _, ok := t.writes.buckets[parentKey+"\x00"+string(name)]; ok {
+			return bkt, nil
+		}
+
+		if _, ok := t.writes.deleted[bkt.key]; ok {
+			return nil, fmt.Errorf("bucket %q: %w", string(name), kv.ErrNotFound)
+		}
+	}
+
+	ok, err := t.client.SIsMember(context.Background(), parentKey, name).Result()
+	if err != nil {
+		return nil, fmt.Errorf("bucket %q: %w", string(name), err)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("bucket %q: %w", string(name), kv.ErrNotFound)
+	}
+
+	return bkt, nil
+}
+
+func (t *Tx) createBucket(parentKey string, parent []string, name []byte) (*Bucket, error) {
+	if len(name) == 0 {
+		return nil, errors.New("bucket name cannot be empty")
+	}
+
+	bkt, err := t.bucket(parentKey, parent, name)
+	if err == nil || !errors.Is(err, kv.ErrNotFound) {
+		return bkt, err
+	}
+
+	if t.writes == nil {
+		return nil, fmt.Errorf("creating bucket %q: %w", string(name), ErrReadOnly)
+	}
+
+	t.writes.buckets[parentKey+"\x00"+string(name)] = slices.Clone(name)
+
+	return t.bucket(parentKey, parent, name)
+}
+
+func (t *Tx) deleteBucket(parentKey string, parent []string, name []byte) error {
+	bkt, err := t.bucket(parentKey, parent, name)
+	if err != nil {
+		return err
+	}
+
+	if t.writes == nil {
+		return fmt.Errorf("deleting bucket %q: %w", string(name), ErrReadOnly)
+	}
+
+	if err := t.deleteTree(bkt.key, bkt.path); err != nil {
+		return fmt.Errorf("deleting bucket %q: %w", string(name), err)
+	}
+
+	delete(t.writes.buckets, parentKey+"\x00"+string(name))
+	t.writes.removed[parentKey+"\x00"+string(name)] = slices.Clone(name)
+
+	return nil
+}
+
+// deleteTree marks the bucket identified by key and every bucket nested within it as deleted
+// and discards any pending writes to them.
+func (t *Tx) deleteTree(key string, path []string) error {
+	if _, ok := t.writes.deleted[key]; !ok {
+		names, err := t.client.SMembers(context.Background(), key+":buckets").Result()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			child := append(slices.Clone(path), name)
+			if err := t.deleteTree(t.db.bucketKey(child), child); err != nil {
+				return err
+			}
+		}
+	}
+
+	for id, name := range t.writes.buckets {
+		if parent, _, _ := strings.Cut(id, "\x00"); parent == key+":buckets" {
+			delete(t.writes.buckets, id)
+
+			child := append(slices.Clone(path), string(name))
+			if err := t.deleteTree(t.db.bucketKey(child), child); err != nil {
+				return err
+			}
+		}
+	}
+
+	t.writes.deleted[key] = struct{}{}
+	delete(t.writes.entries, key)
+
+	return nil
+}
+
+// Bucket is a bucket within a Redis backed DB.
+type Bucket struct {
+	tx   *Tx
+	name string
+	path []string
+	key  string
+}
+
+// Bucket returns the nested bucket with the provided name.
+func (b *Bucket) Bucket(name []byte) (kv.Bucket, error) {
+	return b.tx.bucket(b.key+":buckets", b.path, name)
+}
+
+// CreateBucketIfNotExists returns the nested bucket with the provided name,
+// creating it when it does not exist.
+func (b *Bucket) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
+	return b.tx.createBucket(b.key+":buckets", b.path, name)
+}
+
+// DeleteBucket removes the nested bucket with the provided name
+// along with its contents and nested buckets.
+func (b *Bucket) DeleteBucket(name []byte) error {
+	return b.tx.deleteBucket(b.key+":buckets", b.path, name)
+}
+
+// Get returns the value for the provided key.
+func (b *Bucket) Get(key []byte) ([]byte, error) {
+	if v, ok := b.pending()[string(key)]; ok || b.cleared() {
+		if v == nil {
+			return nil, fmt.Errorf("bucket %q key %q: %w", b.name, string(key), kv.ErrNotFound)
+		}
+
+		return v, nil
+	}
+
+	v, err := b.tx.client.HGet(context.Background(), b.key+":values", string(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, fmt.Errorf("bucket %q key %q: %w", b.name, string(key), kv.ErrNotFound)
+		}
+
+		return nil, fmt.Errorf("bucket %q key %q: %w", b.name, string(key), err)
+	}
+
+	return v, nil
+}
+
+// Put sets the value for the provided key.
+// The write is applied when the enclosing update completes.
+func (b *Bucket) Put(k, v []byte) error {
+	if b.tx.writes == nil {
+		return fmt.Errorf("bucket %q key %q: %w", b.name, string(k), ErrReadOnly)
+	}
+
+	entries, ok := b.tx.writes.entries[b.key]
+	if !ok {
+		entries = map[string][]byte{}
+		b.tx.writes.entries[b.key] = entries
+	}
+
+	entries[string(k)] = append([]byte{}, v...)
+
+	return nil
+}
+
+// Delete removes the provided key.
+// The write is applied when the enclosing update completes.
+func (b *Bucket) Delete(k []byte) error {
+	if b.tx.writes == nil {
+		return fmt.Errorf("bucket %q key %q: %w", b.name, string(k), ErrReadOnly)
+	}
+
+	entries, ok := b.tx.writes.entries[b.key]
+	if !ok {
+		entries = map[string][]byte{}
+		b.tx.writes.entries[b.key] = entries
+	}
+
+	// a nil value marks the key as deleted
+	entries[string(k)] = nil
+
+	return nil
+}
+
+// Count returns the number of keys in the bucket.
+func (b *Bucket) Count() (int, error) {
+	ctx := context.Background()
+
+	var count int64
+	if !b.cleared() {
+		var err error
+		if count, err = b.tx.client.ZCard(ctx, b.key+":keys").Result(); err != nil {
+			return 0, fmt.Errorf("bucket %q count: %w", b.name, err)
+		}
+	}
+
+	// adjust the stored count by the pending writes
+	for k, v := range b.pending() {
+		var stored bool
+		if !b.cleared() {
+			var err error
+			if stored, err = b.tx.client.HExists(ctx, b.key+":values", k).Result(); err != nil {
+				return 0, fmt.Errorf("bucket %q count: %w", b.name, err)
+			}
+		}
+
+		switch {
+		case v == nil && stored:
+			count--
+		case v != nil && !stored:
+			count++
+		}
+	}
+
+	return int(count), nil
+}
+
+// First returns the first key and value in the bucket.
+func (b *Bucket) First() (k, v []byte, err error) {
+	for k, v := range b.Range() {
+		return k, v, nil
+	}
+
+	return nil, nil, fmt.Errorf("bucket %q first key: %w", b.name, kv.ErrNotFound)
+}
+
+// Last returns the last key and value in the bucket.
+func (b *Bucket) Last() (k, v []byte, err error) {
+	for k, v := range b.Range(kv.WithOrder(kv.Descending)) {
+		return k, v, nil
+	}
+
+	return nil, nil, fmt.Errorf("bucket %q last key: %w", b.name, kv.ErrNotFound)
+}
+
+// Range returns a sequence of the keys and values in the bucket.
+// Keys are fetched in batches as the sequence is consumed.
+// Iteration stops early (and the error is logged) should a request to Redis fail.
+func (b *Bucket) Range(opts ...containers.Option[kv.RangeOptions]) iter.Seq2[[]byte, []byte] {
+	var options kv.RangeOptions
+	containers.ApplyAll(&options, opts...)
+
+	var (
+		descending      = options.Order == kv.Descending
+		seek, inclusive = options.Seek()
+	)
+
+	// compare orders keys in the direction of the range
+	compare := func(a, b string) int {
+		if descending {
+			return strings.Compare(b, a)
+		}
+
+		return strings.Compare(a, b)
+	}
+
+	// pending writes are merged with the stored keys
+	pending := b.pending()
+	keys := make([]string, 0, len(pending))
+	for k := range pending {
+		if options.Contains([]byte(k)) {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.SortFunc(keys, compare)
+
+	return iter.Seq2[[]byte, []byte](func(yield func(k, v []byte) bool) {
+		keys := keys
+
+		// emit yields a pending key unless it has been deleted
+		emit := func(k string) bool {
+			if v := pending[k]; v != nil {
+				return yield([]byte(k), v)
+			}
+
+			return true
+		}
+
+		for stored, err := range b.stored(seek, inclusive, descending) {
+			if err != nil {
+				slog.Error("ranging over bucket", "bucket", b.name, "error", err)
+				return
+			}
+
+			if !options.Contains([]byte(stored.k)) {
+				break
+			}
+
+			// yield pending keys which precede the next stored key
+			var replaced bool
+			for len(keys) > 0 && compare(keys[0], stored.k) <= 0 {
+				k := keys[0]
+				keys = keys[1:]
+
+				if !emit(k) {
+					return
+				}
+
+				// the pending write replaces the stored value
+				replaced = k == stored.k
+			}
+
+			if replaced {
+				continue
+			}
+
+			if !yield([]byte(stored.k), stored.v) {
+				return
+			}
+		}
+
+		for _, k := range keys {
+			if !emit(k) {
+				return
+			}
+		}
+	})
+}
+
+type entry struct {
+	k string
+	v []byte
+}
+
+// stored returns a sequence of the keys and values persisted in Redis
+// in batches of rangeBatchSize, beginning from the seek key.
+func (b *Bucket) stored(seek []byte, inclusive, descending bool) iter.Seq2[entry, error] {
+	ctx := context.Background()
+
+	// lexicographical bounds are inclusive when prefixed with "["
+	// and exclusive when prefixed with "("
+	lo, hi := "-", "+"
+	if seek != nil {
+		bound := "[" + string(seek)
+		if !inclusive {
+			bound = "(" + string(seek)
+		}
+
+		if descending {
+			hi = bound
+		} else {
+			lo = bound
+		}
+	}
+
+	return func(yield func(entry, error) bool) {
+		// the contents of deleted buckets are cleared when the update is applied
+		if b.cleared() {
+			return
+		}
+
+		for {
+			by := &goredis.ZRangeBy{Min: lo, Max: hi, Count: rangeBatchSize}
+
+			var (
+				keys []string
+				err  error
+			)
+			if descending {
+				keys, err = b.tx.client.ZRevRangeByLex(ctx, b.key+":keys", by).Result()
+			} else {
+				keys, err = b.tx.client.ZRangeByLex(ctx, b.key+":keys", by).Result()
+			}
+
+			if err != nil {
+				yield(entry{}, err)
+				return
+			}
+
+			if len(keys) == 0 {
+				return
+			}
+
+			values, err := b.tx.client.HMGet(ctx, b.key+":values", keys...).Result()
+			if err != nil {
+				yield(entry{}, err)
+				return
+			}
+
+			for i, k := range keys {
+				v, _ := values[i].(string)
+				if !yield(entry{k: k, v: []byte(v)}, nil) {
+					return
+				}
+			}
+
+			if len(keys) < rangeBatchSize {
+				return
+			}
+
+			// continue from the last key (exclusive)
+			if last := keys[len(keys)-1]; descending {
+				hi = "(" + last
+			} else {
+				lo = "(" + last
+			}
+		}
+	}
+}
+
+// pending returns the writes made to the bucket within the current update.
+func (b *Bucket) pending() map[string][]byte {
+	if b.tx.writes == nil {
+		return nil
+	}
+
+	return b.tx.writes.entries[b.key]
+}
+
+// cleared returns true when the bucket has been deleted (and possibly recreated)
+// within the current update, such that its stored contents are no longer visible.
+func (b *Bucket) cleared() bool {
+	if b.tx.writes == nil {
+		return false
+	}
+
+	_, ok := b.tx.writes.deleted[b.key]
+	return ok
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,292 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/kv"
+	"github.com/get-glu/glu/pkg/kv/kvtest"
+	"github.com/get-glu/glu/pkg/phases/logger"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDB(t *testing.T, opts ...containers.Option[DB]) (*DB, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return New(client, opts...), server
+}
+
+func TestDB(t *testing.T) {
+	kvtest.Run(t, func(t *testing.T) kv.DB {
+		db, _ := newDB(t)
+		return db
+	})
+}
+
+func TestDB_Buckets(t *testing.T) {
+	db, _ := newDB(t)
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		parent, err := tx.CreateBucketIfNotExists([]byte("parent"))
+		require.NoError(t, err)
+
+		// nested buckets are visible within the update which creates them
+		_, err = parent.CreateBucketIfNotExists([]byte("child/with:separators"))
+		require.NoError(t, err)
+
+		child, err := parent.Bucket([]byte("child/with:separators"))
+		require.NoError(t, err)
+
+		return child.Put([]byte("key"), []byte("value"))
+	}))
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		_, err := tx.Bucket([]byte("missing"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		parent, err := tx.Bucket([]byte("parent"))
+		require.NoError(t, err)
+
+		// buckets are scoped to their parent
+		_, err = tx.Bucket([]byte("child/with:separators"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		child, err := parent.Bucket([]byte("child/with:separators"))
+		require.NoError(t, err)
+
+		v, err := child.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), v)
+
+		_, err = child.Get([]byte("missing"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		// read-only transactions reject writes
+		require.ErrorIs(t, child.Put([]byte("key"), []byte("other")), ErrReadOnly)
+		_, err = tx.CreateBucketIfNotExists([]byte("other"))
+		require.ErrorIs(t, err, ErrReadOnly)
+
+		return nil
+	}))
+}
+
+func TestDB_Range(t *testing.T) {
+	db, _ := newDB(t)
+
+	// more keys than a single batch to exercise pagination
+	var all []string
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("range"))
+		require.NoError(t, err)
+
+		for i := range 2*rangeBatchSize + 5 {
+			k := fmt.Sprintf("key-%04d", i)
+			all = append(all, k)
+			require.NoError(t, bkt.Put([]byte(k), []byte("v"+k)))
+		}
+
+		return nil
+	}))
+
+	collect := func(bkt kv.Bucket, opts ...containers.Option[kv.RangeOptions]) (keys []string) {
+		for k, v := range bkt.Range(opts...) {
+			assert.Equal(t, "v"+string(k), string(v))
+			keys = append(keys, string(k))
+		}
+		return
+	}
+
+	reversed := func(s []string) (r []string) {
+		for i := len(s) - 1; i >= 0; i-- {
+			r = append(r, s[i])
+		}
+		return
+	}
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		bkt, err := tx.Bucket([]byte("range"))
+		require.NoError(t, err)
+
+		assert.Equal(t, all, collect(bkt))
+		assert.Equal(t, reversed(all), collect(bkt, kv.WithOrder(kv.Descending)))
+		assert.Equal(t, all[150:], collect(bkt, kv.WithStart([]byte("key-0150"))))
+		assert.Equal(t, reversed(all[:151]), collect(bkt, kv.WithStart([]byte("key-0150")), kv.WithOrder(kv.Descending)))
+		// start keys which do not exist begin from the next key in order
+		assert.Equal(t, all[151:], collect(bkt, kv.WithStart([]byte("key-0150a"))))
+		assert.Equal(t, reversed(all[:151]), collect(bkt, kv.WithStart([]byte("key-0150a")), kv.WithOrder(kv.Descending)))
+
+		// iteration can be stopped early
+		var count int
+		for range bkt.Range() {
+			if count++; count == 3 {
+				break
+			}
+		}
+		assert.Equal(t, 3, count)
+
+		k, v, err := bkt.First()
+		require.NoError(t, err)
+		assert.Equal(t, "key-0000", string(k))
+		assert.Equal(t, "vkey-0000", string(v))
+
+		k, _, err = bkt.Last()
+		require.NoError(t, err)
+		assert.Equal(t, all[len(all)-1], string(k))
+
+		return nil
+	}))
+
+	// pending writes are merged with stored keys within an update
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("range"))
+		require.NoError(t, err)
+
+		require.NoError(t, bkt.Put([]byte("key-0001"), []byte("vkey-0001")))
+		require.NoError(t, bkt.Put([]byte("key-0000a"), []byte("vkey-0000a")))
+		require.NoError(t, bkt.Put([]byte("zzz"), []byte("vzzz")))
+
+		keys := collect(bkt)
+		assert.Equal(t, []string{"key-0000", "key-0000a", "key-0001", "key-0002"}, keys[:4])
+		assert.Len(t, keys, len(all)+2)
+
+		k, _, err := bkt.Last()
+		require.NoError(t, err)
+		assert.Equal(t, "zzz", string(k))
+
+		return nil
+	}))
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		empty, err := tx.CreateBucketIfNotExists([]byte("empty"))
+		require.NoError(t, err)
+
+		_, _, err = empty.First()
+		require.ErrorIs(t, err, kv.ErrNotFound)
+		_, _, err = empty.Last()
+		require.ErrorIs(t, err, kv.ErrNotFound)
+
+		return nil
+	}))
+}
+
+func TestDB_UpdateRollback(t *testing.T) {
+	db, _ := newDB(t)
+
+	errBoom := errors.New("boom")
+	err := db.Update(func(tx kv.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		require.NoError(t, err)
+		require.NoError(t, bkt.Put([]byte("key"), []byte("value")))
+
+		return errBoom
+	})
+	require.ErrorIs(t, err, errBoom)
+
+	// nothing is written when the update fails
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		_, err := tx.Bucket([]byte("bucket"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+		return nil
+	}))
+}
+
+func TestDB_UpdateConflict(t *testing.T) {
+	db, server := newDB(t, WithMaxAttempts(2))
+
+	var attempts int
+	err := db.Update(func(tx kv.Tx) error {
+		attempts++
+		// simulate another replica committing an update concurrently
+		_, err := server.Incr(db.versionKey(), 1)
+		require.NoError(t, err)
+
+		_, err = tx.CreateBucketIfNotExists([]byte("bucket"))
+		return err
+	})
+	require.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestDB_Prefix(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	var (
+		one = New(client, WithPrefix("one"))
+		two = New(client, WithPrefix("two"))
+	)
+
+	require.NoError(t, one.Update(func(tx kv.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		return err
+	}))
+
+	require.NoError(t, two.View(func(tx kv.Tx) error {
+		_, err := tx.Bucket([]byte("bucket"))
+		require.ErrorIs(t, err, kv.ErrNotFound)
+		return nil
+	}))
+
+	assert.True(t, server.Exists("{one}:buckets"))
+}
+
+type resource struct {
+	Value string
+}
+
+func (r *resource) Digest() (string, error) {
+	return r.Value, nil
+}
+
+func TestDB_SharedHistory(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = miniredis.RunT(t)
+		phase  = core.Descriptor{Kind: "test", Pipeline: "pipeline", Metadata: core.Metadata{Name: "phase"}}
+	)
+
+	// two replicas sharing history through the same redis
+	replicas := make([]*logger.PhaseLogger[*resource], 2)
+	for i := range replicas {
+		client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+
+		replicas[i] = logger.New[*resource](New(client))
+		require.NoError(t, replicas[i].CreateLog(ctx, phase))
+	}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, replicas[i%2].RecordLatest(ctx, phase, &resource{Value: fmt.Sprintf("v%d", i)}, nil))
+		}()
+	}
+
+	wg.Wait()
+
+	for _, replica := range replicas {
+		history, err := replica.History(ctx, phase)
+		require.NoError(t, err)
+		assert.Len(t, history, 10)
+
+		latest, err := replica.GetLatestResource(ctx, phase)
+		require.NoError(t, err)
+		assert.Equal(t, history[0].Digest, latest.Value)
+	}
+}
@@ -0,0 +1,399 @@
+// Package sqlite provides a kv.DB implementation backed by a SQLite database.
+//
+// Buckets and their entries are stored in two tables, such that history
+// can be inspected using SQL and the database file can be backed up using standard tooling:
+//
+//	buckets (id INTEGER, parent_id INTEGER, name BLOB)
+//	entries (bucket_id INTEGER, key BLOB, value BLOB)
+//
+// Top-level buckets have a parent_id of 0.
+// Keys are compared byte-wise, which matches the ordering of the other kv implementations.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/url"
+
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/kv"
+	_ "modernc.org/sqlite"
+)
+
+var _ kv.DB = (*DB)(nil)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS buckets (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	parent_id INTEGER NOT NULL,
+	name      BLOB NOT NULL,
+	UNIQUE (parent_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS entries (
+	bucket_id INTEGER NOT NULL REFERENCES buckets (id),
+	key       BLOB NOT NULL,
+	value     BLOB NOT NULL,
+	PRIMARY KEY (bucket_id, key)
+) WITHOUT ROWID;
+`
+
+// root is the parent identifier of top-level buckets
+const root = 0
+
+// DB is a kv.DB backed by a SQLite database.
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the SQLite database at the provided path
+// and ensures the schema exists.
+func Open(path string) (*DB, error) {
+	dsn := (&url.URL{
+		Scheme: "file",
+		Opaque: path,
+		RawQuery: url.Values{"_pragma": []string{
+			"busy_timeout(5000)",
+			"journal_mode(WAL)",
+			"foreign_keys(1)",
+		}}.Encode(),
+	}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+
+	return &DB{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// View calls fn within a read transaction.
+// Attempts to write within fn return an error.
+func (d *DB) View(fn func(kv.Tx) error) error {
+	return d.transact(false, fn)
+}
+
+// Update calls fn within a write transaction.
+// The transaction is committed when fn returns nil and rolled back otherwise.
+func (d *DB) Update(fn func(kv.Tx) error) error {
+	return d.transact(true, fn)
+}
+
+func (d *DB) transact(writable bool, fn func(kv.Tx) error) (err error) {
+	ctx := context.Background()
+
+	// a dedicated connection is used (as opposed to sql.Tx) in order
+	// to control the type of transaction which is started
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	// write transactions take the write lock upfront to avoid
+	// failing to upgrade from a read lock when in contention
+	begin := "BEGIN IMMEDIATE"
+	if !writable {
+		if _, err := conn.ExecContext(ctx, "PRAGMA query_only = 1"); err != nil {
+			return err
+		}
+
+		// the connection is returned to the pool so it must be made writable again
+		defer func() {
+			if _, qerr := conn.ExecContext(ctx, "PRAGMA query_only = 0"); qerr != nil {
+				err = errors.Join(err, qerr)
+			}
+		}()
+
+		begin = "BEGIN"
+	}
+
+	if _, err := conn.ExecContext(ctx, begin); err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if _, rerr := conn.ExecContext(ctx, "ROLLBACK"); rerr != nil {
+				err = errors.Join(err, fmt.Errorf("rolling back transaction: %w", rerr))
+			}
+		}
+	}()
+
+	if err := fn(&Tx{conn: conn}); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Tx is a transaction on a SQLite database.
+type Tx struct {
+	conn *sql.Conn
+}
+
+// Bucket returns the top-level bucket with the provided name.
+func (t *Tx) Bucket(name []byte) (kv.Bucket, error) {
+	return getBucket(t.conn, root, name)
+}
+
+// CreateBucketIfNotExists returns the top-level bucket with the provided name,
+// creating it when it does not exist.
+func (t *Tx) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
+	return createBucket(t.conn, root, name)
+}
+
+// DeleteBucket removes the top-level bucket with the provided name
+// along with its contents and nested buckets.
+func (t *Tx) DeleteBucket(name []byte) error {
+	return deleteBucket(t.conn, root, name)
+}
+
+// Bucket is a bucket within a SQLite database.
+type Bucket struct {
+	conn *sql.Conn
+	id   int64
+	name string
+}
+
+func getBucket(conn *sql.Conn, parent int64, name []byte) (*Bucket, error) {
+	bkt := &Bucket{conn: conn, name: string(name)}
+	if err := conn.QueryRowContext(context.Background(),
+		`SELECT id FROM buckets WHERE parent_id = ? AND name = ?`,
+		parent, name,
+	).Scan(&bkt.id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("bucket %q: %w", string(name), kv.ErrNotFound)
+		}
+
+		return nil, fmt.Errorf("bucket %q: %w", string(name), err)
+	}
+
+	return bkt, nil
+}
+
+func createBucket(conn *sql.Conn, parent int64, name []byte) (*Bucket, error) {
+	if len(name) == 0 {
+		return nil, errors.New("bucket name cannot be empty")
+	}
+
+	if _, err := conn.ExecContext(context.Background(),
+		`INSERT INTO buckets (parent_id, name) VALUES (?, ?) ON CONFLICT (parent_id, name) DO NOTHING`,
+		parent, name,
+	); err != nil {
+		return nil, fmt.Errorf("creating bucket %q: %w", string(name), err)
+	}
+
+	return getBucket(conn, parent, name)
+}
+
+// descendants selects the identifiers of a bucket and every bucket nested within it.
+const descendants = `WITH RECURSIVE tree (id) AS (
+	SELECT ?
+	UNION ALL
+	SELECT buckets.id FROM buckets JOIN tree ON buckets.parent_id = tree.id
+)`
+
+func deleteBucket(conn *sql.Conn, parent int64, name []byte) error {
+	bkt, err := getBucket(conn, parent, name)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx,
+		descendants+` DELETE FROM entries WHERE bucket_id IN (SELECT id FROM tree)`,
+		bkt.id,
+	); err != nil {
+		return fmt.Errorf("deleting bucket %q: %w", string(name), err)
+	}
+
+	if _, err := conn.ExecContext(ctx,
+		descendants+` DELETE FROM buckets WHERE id IN (SELECT id FROM tree)`,
+		bkt.id,
+	); err != nil {
+		return fmt.Errorf("deleting bucket %q: %w", string(name), err)
+	}
+
+	return nil
+}
+
+// Bucket returns the nested bucket with the provided name.
+func (b *Bucket) Bucket(name []byte) (kv.Bucket, error) {
+	return getBucket(b.conn, b.id, name)
+}
+
+// CreateBucketIfNotExists returns the nested bucket with the provided name,
+// creating it when it does not exist.
+func (b *Bucket) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
+	return createBucket(b.conn, b.id, name)
+}
+
+// DeleteBucket removes the nested bucket with the provided name
+// along with its contents and nested buckets.
+func (b *Bucket) DeleteBucket(name []byte) error {
+	return deleteBucket(b.conn, b.id, name)
+}
+
+// Get returns the value for the provided key.
+func (b *Bucket) Get(key []byte) ([]byte, error) {
+	var v []byte
+	if err := b.conn.QueryRowContext(context.Background(),
+		`SELECT value FROM entries WHERE bucket_id = ? AND key = ?`,
+		b.id, key,
+	).Scan(&v); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("bucket %q key %q: %w", b.name, string(key), kv.ErrNotFound)
+		}
+
+		return nil, fmt.Errorf("bucket %q key %q: %w", b.name, string(key), err)
+	}
+
+	return nonNil(v), nil
+}
+
+// Put sets the value for the provided key.
+func (b *Bucket) Put(k, v []byte) error {
+	if _, err := b.conn.ExecContext(context.Background(),
+		`INSERT INTO entries (bucket_id, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (bucket_id, key) DO UPDATE SET value = excluded.value`,
+		b.id, k, nonNil(v),
+	); err != nil {
+		return fmt.Errorf("bucket %q key %q: %w", b.name, string(k), err)
+	}
+
+	return nil
+}
+
+// Delete removes the provided key.
+func (b *Bucket) Delete(k []byte) error {
+	if _, err := b.conn.ExecContext(context.Background(),
+		`DELETE FROM entries WHERE bucket_id = ? AND key = ?`,
+		b.id, k,
+	); err != nil {
+		return fmt.Errorf("bucket %q key %q: %w", b.name, string(k), err)
+	}
+
+	return nil
+}
+
+// Count returns the number of keys in the bucket.
+func (b *Bucket) Count() (count int, _ error) {
+	if err := b.conn.QueryRowContext(context.Background(),
+		`SELECT COUNT(*) FROM entries WHERE bucket_id = ?`,
+		b.id,
+	).Scan(&count); err != nil {
+		return 0, fmt.Errorf("bucket %q count: %w", b.name, err)
+	}
+
+	return count, nil
+}
+
+// First returns the first key and value in the bucket.
+func (b *Bucket) First() (k, v []byte, err error) {
+	return b.edge("first", "ASC")
+}
+
+// Last returns the last key and value in the bucket.
+func (b *Bucket) Last() (k, v []byte, err error) {
+	return b.edge("last", "DESC")
+}
+
+func (b *Bucket) edge(which, order string) (k, v []byte, err error) {
+	if err := b.conn.QueryRowContext(context.Background(),
+		`SELECT key, value FROM entries WHERE bucket_id = ? ORDER BY key `+order+` LIMIT 1`,
+		b.id,
+	).Scan(&k, &v); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("bucket %q %s key: %w", b.name, which, kv.ErrNotFound)
+		}
+
+		return nil, nil, fmt.Errorf("bucket %q %s key: %w", b.name, which, err)
+	}
+
+	return k, nonNil(v), nil
+}
+
+// Range returns a sequence of the keys and values in the bucket.
+// Iteration stops early (and the error is logged) should the underlying query fail.
+func (b *Bucket) Range(opts ...containers.Option[kv.RangeOptions]) iter.Seq2[[]byte, []byte] {
+	var options kv.RangeOptions
+	containers.ApplyAll(&options, opts...)
+
+	var (
+		query = `SELECT key, value FROM entries WHERE bucket_id = ?`
+		args  = []any{b.id}
+		order = "ASC"
+		cmp   = ">="
+	)
+
+	seek, inclusive := options.Seek()
+	if options.Order == kv.Descending {
+		order, cmp = "DESC", "<="
+		if !inclusive {
+			cmp = "<"
+		}
+	}
+
+	if seek != nil {
+		query += ` AND key ` + cmp + ` ?`
+		args = append(args, seek)
+	}
+
+	query += ` ORDER BY key ` + order
+
+	return iter.Seq2[[]byte, []byte](func(yield func(k, v []byte) bool) {
+		rows, err := b.conn.QueryContext(context.Background(), query, args...)
+		if err != nil {
+			slog.Error("ranging over bucket", "bucket", b.name, "error", err)
+			return
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			var k, v []byte
+			if err := rows.Scan(&k, &v); err != nil {
+				slog.Error("ranging over bucket", "bucket", b.name, "error", err)
+				return
+			}
+
+			if !options.Contains(k) || !yield(k, nonNil(v)) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			slog.Error("ranging over bucket", "bucket", b.name, "error", err)
+		}
+	})
+}
+
+// nonNil returns an empty slice in place of nil such that
+// empty values are stored and returned as they are by the other implementations.
+func nonNil(v []byte) []byte {
+	if v == nil {
+		return []byte{}
+	}
+
+	return v
+}
@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"iter"
+	"path/filepath"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/kv"
+	"github.com/get-glu/glu/pkg/kv/kvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB(t *testing.T) {
+	kvtest.Run(t, func(t *testing.T) kv.DB {
+		return open(t, filepath.Join(t.TempDir(), "glu.db"))
+	})
+}
+
+func TestDB_NestedBuckets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glu.db")
+	db := open(t, path)
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		pipeline, err := tx.CreateBucketIfNotExists([]byte("pipeline"))
+		require.NoError(t, err)
+		require.NoError(t, pipeline.Put([]byte("key"), []byte("pipeline")))
+
+		// buckets of the same name are distinct beneath different parents
+		for _, name := range []string{"staging", "production"} {
+			phase, err := pipeline.CreateBucketIfNotExists([]byte(name))
+			require.NoError(t, err)
+
+			versions, err := phase.CreateBucketIfNotExists([]byte("versions"))
+			require.NoError(t, err)
+			require.NoError(t, versions.Put([]byte("key"), []byte(name)))
+		}
+
+		// creating an existing bucket returns the existing bucket
+		again, err := tx.CreateBucketIfNotExists([]byte("pipeline"))
+		require.NoError(t, err)
+
+		value, err := again.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("pipeline"), value)
+
+		return nil
+	}))
+
+	require.NoError(t, db.Close())
+
+	// buckets and entries are persisted in the database file
+	db = open(t, path)
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		pipeline, err := tx.Bucket([]byte("pipeline"))
+		require.NoError(t, err)
+
+		// nested buckets are not entries of their parent
+		count, err := pipeline.Count()
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		for _, name := range []string{"staging", "production"} {
+			phase, err := pipeline.Bucket([]byte(name))
+			require.NoError(t, err)
+
+			versions, err := phase.Bucket([]byte("versions"))
+			require.NoError(t, err)
+
+			value, err := versions.Get([]byte("key"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte(name), value)
+		}
+
+		_, err = pipeline.Bucket([]byte("versions"))
+		assert.ErrorIs(t, err, kv.ErrNotFound)
+
+		_, err = tx.Bucket([]byte("staging"))
+		assert.ErrorIs(t, err, kv.ErrNotFound)
+
+		return nil
+	}))
+}
+
+func TestDB_Order(t *testing.T) {
+	db := open(t, filepath.Join(t.TempDir(), "glu.db"))
+
+	// keys are compared byte-wise (as opposed to by text collation or length)
+	keys := [][]byte{
+		{0x00},
+		[]byte("A"),
+		[]byte("a"),
+		[]byte("a\x00"),
+		[]byte("ab"),
+		[]byte("b"),
+		{0x7f},
+		{0x80},
+		{0xff},
+		{0xff, 0x00},
+	}
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		require.NoError(t, err)
+
+		// inserted in reverse order
+		for i := len(keys) - 1; i >= 0; i-- {
+			require.NoError(t, bucket.Put(keys[i], []byte{byte(i)}))
+		}
+
+		return nil
+	}))
+
+	require.NoError(t, db.View(func(tx kv.Tx) error {
+		bucket, err := tx.Bucket([]byte("bucket"))
+		require.NoError(t, err)
+
+		assert.Equal(t, keys, collect(bucket.Range()))
+
+		reversed := make([][]byte, 0, len(keys))
+		for i := len(keys) - 1; i >= 0; i-- {
+			reversed = append(reversed, keys[i])
+		}
+
+		assert.Equal(t, reversed, collect(bucket.Range(kv.WithOrder(kv.Descending))))
+
+		assert.Equal(t, [][]byte{[]byte("a"), []byte("a\x00"), []byte("ab")},
+			collect(bucket.Range(kv.WithPrefix([]byte("a")))))
+		assert.Equal(t, [][]byte{{0xff, 0x00}, {0xff}},
+			collect(bucket.Range(kv.WithPrefix([]byte{0xff}), kv.WithOrder(kv.Descending))))
+		assert.Equal(t, [][]byte{[]byte("b"), {0x7f}},
+			collect(bucket.Range(kv.WithStart([]byte("b")), kv.WithEnd([]byte{0x80}))))
+
+		k, v, err := bucket.First()
+		require.NoError(t, err)
+		assert.Equal(t, keys[0], k)
+		assert.Equal(t, []byte{0}, v)
+
+		k, v, err = bucket.Last()
+		require.NoError(t, err)
+		assert.Equal(t, keys[len(keys)-1], k)
+		assert.Equal(t, []byte{byte(len(keys) - 1)}, v)
+
+		return nil
+	}))
+}
+
+func TestDB_FirstLast_Empty(t *testing.T) {
+	db := open(t, filepath.Join(t.TempDir(), "glu.db"))
+
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte("bucket"))
+		require.NoError(t, err)
+
+		// entries of nested buckets are not considered
+		nested, err := bucket.CreateBucketIfNotExists([]byte("nested"))
+		require.NoError(t, err)
+		require.NoError(t, nested.Put([]byte("key"), []byte("value")))
+
+		_, _, err = bucket.First()
+		assert.ErrorIs(t, err, kv.ErrNotFound)
+
+		_, _, err = bucket.Last()
+		assert.ErrorIs(t, err, kv.ErrNotFound)
+
+		// empty values are returned as empty (not nil)
+		require.NoError(t, bucket.Put([]byte("key"), []byte{}))
+
+		k, v, err := bucket.First()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("key"), k)
+		assert.Equal(t, []byte{}, v)
+
+		return nil
+	}))
+}
+
+func open(t *testing.T, path string) *DB {
+	t.Helper()
+
+	db, err := Open(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func collect(seq iter.Seq2[[]byte, []byte]) (keys [][]byte) {
+	for k := range seq {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -7,7 +7,7 @@ import (
 	"log/slog"
 	"maps"
 	"path"
-	"strings"
+	"sync"
 
 	"github.com/get-glu/glu/internal/git"
 	"github.com/get-glu/glu/pkg/containers"
@@ -16,9 +16,10 @@ import (
 	"github.com/get-glu/glu/pkg/fs"
 	"github.com/get-glu/glu/pkg/kv/memory"
 	"github.com/get-glu/glu/pkg/phases/logger"
+	"github.com/get-glu/glu/pkg/scm/links"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/google/uuid"
-	giturls "github.com/whilp/git-urls"
 )
 
 const (
@@ -26,6 +27,13 @@ const (
 	AnnotationGitHeadSHAKey   = "dev.getglu.git.head_sha"
 	AnnotationGitCommitURLKey = "dev.getglu.git.commit.url"
 	AnnotationProposalURLKey  = "dev.getglu.git.proposal.url"
+	AnnotationCompareURLKey   = "dev.getglu.git.compare.url"
+	AnnotationGitTagKey       = "dev.getglu.git.tag"
+
+	AnnotationProposalChecksKey    = "dev.getglu.git.proposal.checks"
+	AnnotationProposalReviewKey    = "dev.getglu.git.proposal.review"
+	AnnotationProposalApprovalsKey = "dev.getglu.git.proposal.approvals"
+	AnnotationProposalMergeableKey = "dev.getglu.git.proposal.mergeable"
 )
 
 var (
@@ -52,21 +60,42 @@ type Proposer interface {
 	CommentProposal(context.Context, *Proposal, string) error
 }
 
+// MergingProposer is a Proposer which can also report the status of proposals and merge them.
+// Proposers which implement it have their proposal status reported and support auto-merge.
+type MergingProposer interface {
+	Proposer
+	GetProposalStatus(context.Context, *Proposal) (*ProposalStatus, error)
+	MergeProposal(context.Context, *Proposal, MergeMethod) error
+}
+
+// LinkProvider generates URLs for viewing git objects within an SCM web UI.
+// Implementations return an empty string when a URL cannot be generated.
+type LinkProvider interface {
+	CommitURL(sha string) string
+	BranchURL(branch string) string
+	CompareURL(base, head string) string
+	FileURL(revision, path string) string
+}
+
 // Proposal contains the fields necessary to propose a resource update
 // to a Repository.
 type Proposal struct {
-	ID  string
-	URL string
-
-	BaseRevision string
-	BaseBranch   string
-	Branch       string
-	HeadRevision string
-	Digest       string
-	Title        string
-	Body         string
-
-	Annotations map[string]string
+	ID  string `json:"id"`
+	URL string `json:"url"`
+
+	BaseRevision string `json:"base_revision"`
+	BaseBranch   string `json:"base_branch"`
+	Branch       string `json:"branch"`
+	HeadRevision string `json:"head_revision"`
+	Digest       string `json:"digest"`
+	Title        string `json:"title,omitempty"`
+	Body         string `json:"body,omitempty"`
+
+	// Status is the latest reported state of the proposals checks and reviews.
+	// It is nil until the status has been requested from the proposer.
+	Status *ProposalStatus `json:"status,omitempty"`
+
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // Phase is a Git storage backed phase implementation.
@@ -78,10 +107,31 @@ type Phase[R Resource] struct {
 	repo     *git.Repository
 	logger   typed.PhaseLogger[R]
 
+	links LinkProvider
+
+	// tags configures the phase to read and update its state via tags
+	tags *tagOptions
+
+	// backfillLimit is the maximum number of commits read when
+	// backfilling an empty phase history from the git log
+	backfillLimit int
+
 	proposer        Proposer
 	proposeChange   bool
 	proposalOptions ProposalOption
+
+	// mu guards the current proposal while proposing and merging
+	mu              sync.Mutex
 	currentProposal *Proposal
+	// annotationsMu guards a snapshot of the annotations of the current proposal,
+	// such that they can be read without waiting on proposals being created
+	annotationsMu       sync.RWMutex
+	proposalAnnotations map[string]string
+	// mergeMu serializes attempts to merge the current proposal
+	mergeMu sync.Mutex
+	// refresh signals a background refresh of the current proposals status,
+	// such that notifications are not blocked on calls to the proposer
+	refresh chan struct{}
 }
 
 // Descriptor returns the phases descriptor.
@@ -96,6 +146,9 @@ func (p *Phase[A]) Descriptor() core.Descriptor {
 // ProposalOption configures calls to create proposals
 type ProposalOption struct {
 	Labels []string
+	// AutoMerge configures merging proposals once their checks
+	// succeed and required approvals are present.
+	AutoMerge *AutoMerge
 }
 
 // ProposeChanges configures the phase to propose the change (via PR or MR)
@@ -114,6 +167,23 @@ func WithLogger[R Resource](log typed.PhaseLogger[R]) containers.Option[Phase[R]
 	}
 }
 
+// WithHistoryBackfill configures the maximum number of commits from the phases branch
+// which are read to reconstruct its history when the configured logger is empty on startup.
+// A limit of zero (or less) disables backfilling history.
+func WithHistoryBackfill[R Resource](limit int) containers.Option[Phase[R]] {
+	return func(p *Phase[R]) {
+		p.backfillLimit = limit
+	}
+}
+
+// WithLinkProvider sets the provider used to generate commit and compare URL annotations.
+// When not configured, a provider is detected from the remote URL for well-known SCM hosts.
+func WithLinkProvider[R Resource](links LinkProvider) containers.Option[Phase[R]] {
+	return func(p *Phase[R]) {
+		p.links = links
+	}
+}
+
 // New constructs and configures a new phase.
 func New[R Resource](
 	ctx context.Context,
@@ -131,11 +201,26 @@ func New[R Resource](
 		repo:     repo,
 		proposer: proposer,
 		// logger defaults to in-memory logger
-		logger: logger.New[R](memory.New()),
+		logger:        logger.New[R](memory.New()),
+		backfillLimit: defaultBackfillLimit,
 	}
 
 	containers.ApplyAll(phase, opts...)
 
+	if phase.links == nil {
+		phase.links = detectLinkProvider(repo.Remote())
+	}
+
+	if phase.tags != nil && phase.proposeChange {
+		return nil, errors.New("proposing changes is not supported for phases configured with tags")
+	}
+
+	if phase.proposeChange && phase.proposalOptions.AutoMerge != nil {
+		if _, ok := phase.proposer.(MergingProposer); !ok {
+			return nil, errors.New("auto-merge requires a proposer which supports merging proposals")
+		}
+	}
+
 	if err := phase.logger.CreateLog(ctx, phase.Descriptor()); err != nil {
 		return nil, err
 	}
@@ -144,9 +229,19 @@ func New[R Resource](
 	// an update for the phases associated base branch
 	phase.repo.Subscribe(phase)
 
-	// record initial phase state for base branch
-	if err := phase.recordPhaseState(ctx, git.WithBranch(phase.branch())); err != nil {
-		return nil, err
+	// record initial phase state for base branch (or tag)
+	if phase.tags != nil {
+		if err := phase.recordTagState(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := phase.backfillHistory(ctx); err != nil {
+			return nil, err
+		}
+
+		if err := phase.recordPhaseState(ctx, nil, git.WithBranch(phase.branch())); err != nil {
+			return nil, err
+		}
 	}
 
 	// attempt to populate cache with current proposal
@@ -154,6 +249,14 @@ func New[R Resource](
 		return nil, err
 	}
 
+	if _, ok := phase.proposer.(MergingProposer); ok && phase.proposeChange {
+		phase.startProposalRefresh(ctx)
+	}
+
+	if phase.proposeChange && phase.proposalOptions.AutoMerge != nil {
+		phase.startAutoMerge(ctx)
+	}
+
 	return phase, nil
 }
 
@@ -199,7 +302,23 @@ func (p *Phase[R]) branch() string {
 	return p.repo.DefaultBranch()
 }
 
+// Remote returns the URL of the phases upstream repository
+// or an empty string when the repository has no remote.
+func (p *Phase[R]) Remote() string {
+	remote := p.repo.Remote()
+	if remote == nil || len(remote.URLs) == 0 {
+		return ""
+	}
+
+	return remote.URLs[0]
+}
+
 func (p *Phase[R]) Branches() []string {
+	if p.tags != nil {
+		// phases configured with tags are notified of tag updates only
+		return nil
+	}
+
 	return []string{p.branch()}
 }
 
@@ -208,16 +327,27 @@ func (p *Phase[R]) Branches() []string {
 // This is required and called by repo.Subscribe whenever the phases matching branch
 // is updated.
 func (p *Phase[R]) Notify(ctx context.Context, refs map[string]string) error {
+	if p.tags != nil {
+		return p.recordTagState(ctx)
+	}
+
 	ref, ok := refs[p.branch()]
 	if !ok {
 		slog.Debug("reference not found on notify", "branch", p.branch(), "refs", refs)
 		return nil
 	}
 
-	return p.recordPhaseState(ctx, git.WithRevision(plumbing.NewHash(ref)))
+	if err := p.recordPhaseState(ctx, nil, git.WithRevision(plumbing.NewHash(ref))); err != nil {
+		return err
+	}
+
+	// the base of the current proposal may have moved, affecting its mergeability
+	p.scheduleProposalRefresh()
+
+	return nil
 }
 
-func (p *Phase[R]) recordPhaseState(ctx context.Context, opts ...containers.Option[git.BranchOptions]) (err error) {
+func (p *Phase[R]) recordPhaseState(ctx context.Context, annotations map[string]string, opts ...containers.Option[git.BranchOptions]) (err error) {
 	var (
 		r    = p.newFn()
 		hash plumbing.Hash
@@ -230,10 +360,13 @@ func (p *Phase[R]) recordPhaseState(ctx context.Context, opts ...containers.Opti
 		return err
 	}
 
-	annotations := map[string]string{
-		AnnotationGitHeadSHAKey: hash.String(),
+	annotations = maps.Clone(annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
 	}
 
+	annotations[AnnotationGitHeadSHAKey] = hash.String()
+
 	p.annotateCommitURL(annotations, hash)
 
 	// record latest
@@ -241,42 +374,63 @@ func (p *Phase[R]) recordPhaseState(ctx context.Context, opts ...containers.Opti
 }
 
 func (p *Phase[R]) annotateCommitURL(annotations map[string]string, hash plumbing.Hash) {
-	repoURL, err := giturls.Parse(p.repo.Remote().URLs[0])
-	if err != nil {
-		slog.Warn("while attempting to parse remote URL", "error", err)
-		return
+	if url := p.links.CommitURL(hash.String()); url != "" {
+		annotations[AnnotationGitCommitURLKey] = url
 	}
+}
 
-	// TODO: abstract this and support other SCM providers and self-hosted solutions
-	if !strings.HasPrefix(repoURL.Host, "github.com") {
-		slog.Warn("unsupported remote host", "host", repoURL.Host)
-		return
+// Links returns the phases link provider.
+func (p *Phase[R]) Links() LinkProvider {
+	return p.links
+}
+
+// detectLinkProvider returns the builtin link provider for remotes
+// hosted on well-known SCM hosts, or a provider which generates no links.
+func detectLinkProvider(remote *gitconfig.RemoteConfig) LinkProvider {
+	if remote == nil || len(remote.URLs) == 0 {
+		return noLinks{}
 	}
 
-	parts := strings.SplitN(strings.TrimPrefix(repoURL.Path, "/"), "/", 2)
-	if len(parts) < 2 {
-		slog.Warn("unexpected repository URL path", "path", repoURL.Path)
-		return
+	info, err := links.ParseRepository(remote.URLs[0])
+	if err != nil {
+		slog.Warn("while attempting to parse remote URL", "error", err)
+		return noLinks{}
 	}
 
-	var (
-		repoOwner = parts[0]
-		repoName  = strings.TrimSuffix(parts[1], ".git")
-	)
+	provider, ok := links.DetectProvider(info)
+	if !ok {
+		slog.Debug("link provider could not be detected", "host", info.BaseURL)
+		return noLinks{}
+	}
 
-	if repoOwner != "" && repoName != "" {
-		annotations[AnnotationGitCommitURLKey] = fmt.Sprintf("https://github.com/%s/%s/commit/%s", repoOwner, repoName, hash)
+	l, err := links.NewBuiltin(provider, info, links.Templates{})
+	if err != nil {
+		slog.Warn("while building link provider", "error", err)
+		return noLinks{}
 	}
 
-	return
+	return l
 }
 
+type noLinks struct{}
+
+func (noLinks) CommitURL(string) string          { return "" }
+func (noLinks) BranchURL(string) string          { return "" }
+func (noLinks) CompareURL(string, string) string { return "" }
+func (noLinks) FileURL(string, string) string    { return "" }
+
 // Update sets the phases state to the provided resource using the resource types
 // ReadFrom and WriteTo methods to update state accordingly.
 // Given a propose is configured, a proposal will be made and the update will be asynchronous.
 func (p *Phase[R]) Update(ctx context.Context, to R, opts ...containers.Option[typed.UpdateOptions]) (*core.Result, error) {
 	// inital fetch to ensure we're up to date and avoid conflicts
-	if err := p.repo.Fetch(ctx, p.branch()); err != nil {
+	fetch := []string{p.branch()}
+	if p.tags != nil {
+		// fetch all tracked branches and tags
+		fetch = nil
+	}
+
+	if err := p.repo.Fetch(ctx, fetch...); err != nil {
 		return nil, fmt.Errorf("fetching upstream during update: %w", err)
 	}
 
@@ -285,6 +439,15 @@ func (p *Phase[R]) Update(ctx context.Context, to R, opts ...containers.Option[t
 		return nil, err
 	}
 
+	if p.tags != nil {
+		annotations, err := p.updateTag(ctx, from, to, typed.NewUpdateOptions(opts...))
+		if err != nil {
+			return nil, err
+		}
+
+		return &core.Result{Annotations: annotations}, nil
+	}
+
 	annotations := map[string]string{
 		AnnotationGitBaseRefKey: p.branch(),
 	}
@@ -303,11 +466,15 @@ func (p *Phase[R]) Update(ctx context.Context, to R, opts ...containers.Option[t
 		return nil, errors.New("proposal requested but not configured")
 	}
 
+	p.mu.Lock()
 	annotations, err = p.propose(ctx, from, to, updateOpts)
+	p.mu.Unlock()
 	if err != nil {
 		return nil, err
 	}
 
+	p.refreshCurrentProposal(ctx)
+
 	return &core.Result{Annotations: annotations}, nil
 }
 
@@ -369,7 +536,7 @@ func (p *Phase[R]) propose(ctx context.Context, from, to R, updateOpts *typed.Up
 
 			// we're updating the head position of an existing proposal
 			// so we need to update the value of head in the returned annotations
-			annotations := annotations(proposal)
+			annotations := p.annotations(proposal)
 			annotations[AnnotationGitHeadSHAKey] = head
 
 			return annotations, nil
@@ -377,7 +544,7 @@ func (p *Phase[R]) propose(ctx context.Context, from, to R, updateOpts *typed.Up
 			// nothing has changed since the last promotion and proposals
 			slog.Debug("skipping proposal", "reason", "AlreadyExistsAndUpToDate")
 
-			return annotations(proposal), nil
+			return p.annotations(proposal), nil
 		}
 
 		// close the proposal as we're creating a new branch for the new proposal
@@ -425,9 +592,9 @@ func (p *Phase[R]) propose(ctx context.Context, from, to R, updateOpts *typed.Up
 	}
 
 	// set current proposal
-	p.currentProposal = proposal
+	p.setCurrentProposal(proposal)
 
-	return annotations(proposal), makeComment(proposal)
+	return p.annotations(proposal), makeComment(proposal)
 }
 
 func (p *Phase[R]) getCurrentProposal(ctx context.Context) (*Proposal, error) {
@@ -437,7 +604,7 @@ func (p *Phase[R]) getCurrentProposal(ctx context.Context) (*Proposal, error) {
 
 	if p.currentProposal != nil {
 		if !p.proposer.IsProposalOpen(ctx, p.currentProposal) {
-			p.currentProposal = nil
+			p.setCurrentProposal(nil)
 
 			return nil, ErrProposalNotFound
 		}
@@ -450,11 +617,181 @@ func (p *Phase[R]) getCurrentProposal(ctx context.Context) (*Proposal, error) {
 		return nil, err
 	}
 
-	p.currentProposal = proposal
+	p.setCurrentProposal(proposal)
 
 	return proposal, nil
 }
 
+// Proposal returns the phases current open proposal along with its latest status
+// (checks, reviews and mergeability) as reported by the proposer.
+// It returns ErrProposalNotFound when there is no open proposal.
+func (p *Phase[R]) Proposal(ctx context.Context) (*Proposal, error) {
+	current, proposal, err := p.snapshotProposal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.refreshProposalStatus(ctx, current, proposal); err != nil {
+		return nil, err
+	}
+
+	proposal.Annotations = p.annotations(proposal)
+
+	return proposal, nil
+}
+
+// Annotations returns annotations describing the phases current proposal
+// (including its last known status) or nil when there is no open proposal.
+func (p *Phase[R]) Annotations() map[string]string {
+	p.annotationsMu.RLock()
+	defer p.annotationsMu.RUnlock()
+
+	return maps.Clone(p.proposalAnnotations)
+}
+
+// setCurrentProposal replaces the current proposal along with the snapshot of its annotations.
+// It expects the caller to hold p.mu.
+func (p *Phase[R]) setCurrentProposal(proposal *Proposal) {
+	p.currentProposal = proposal
+
+	var annotations map[string]string
+	if proposal != nil {
+		annotations = p.annotations(proposal)
+	}
+
+	p.annotationsMu.Lock()
+	defer p.annotationsMu.Unlock()
+
+	p.proposalAnnotations = annotations
+}
+
+// refreshCurrentProposal refreshes the status of the current proposal (if any), such that
+// its annotations reflect the latest checks and reviews. Failures are logged rather than
+// returned, given the status is refreshed again on the next update or fetch.
+func (p *Phase[R]) refreshCurrentProposal(ctx context.Context) {
+	if _, ok := p.proposer.(MergingProposer); !ok || !p.proposeChange {
+		return
+	}
+
+	current, proposal, err := p.snapshotProposal(ctx)
+	if err != nil {
+		if !errors.Is(err, ErrProposalNotFound) {
+			slog.Warn("refreshing proposal status", "phase", p.meta.Name, "error", err)
+		}
+
+		return
+	}
+
+	if err := p.refreshProposalStatus(ctx, current, proposal); err != nil {
+		slog.Warn("refreshing proposal status", "phase", p.meta.Name, "proposal_url", proposal.URL, "error", err)
+	}
+}
+
+// startProposalRefresh starts refreshing the status of the current proposal in the
+// background whenever a refresh is scheduled, until the context is cancelled.
+func (p *Phase[R]) startProposalRefresh(ctx context.Context) {
+	p.refresh = make(chan struct{}, 1)
+
+	go func() {
+		for {
+			select {
+			case <-p.refresh:
+				p.refreshCurrentProposal(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// scheduleProposalRefresh schedules a background refresh of the current proposals status
+// without waiting on it. Refreshes scheduled while one is pending are coalesced into it.
+func (p *Phase[R]) scheduleProposalRefresh() {
+	if p.refresh == nil {
+		return
+	}
+
+	select {
+	case p.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// snapshotProposal returns the current proposal along with a copy of it which is safe to
+// use without holding the phases lock. The lock is only held while reading and updating the
+// cached proposal, such that calls to the proposer do not block concurrent updates.
+func (p *Phase[R]) snapshotProposal(ctx context.Context) (current, snapshot *Proposal, _ error) {
+	if p.proposer == nil {
+		return nil, nil, ErrProposalNotFound
+	}
+
+	p.mu.Lock()
+	current = p.currentProposal
+	var cpy Proposal
+	if current != nil {
+		cpy = *current
+	}
+	p.mu.Unlock()
+
+	if current == nil {
+		proposal, err := p.proposer.GetCurrentProposal(ctx, p.branch(), p.branchPrefix())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if p.currentProposal == nil {
+			p.setCurrentProposal(proposal)
+		}
+
+		cpy = *p.currentProposal
+
+		return p.currentProposal, &cpy, nil
+	}
+
+	if !p.proposer.IsProposalOpen(ctx, &cpy) {
+		p.mu.Lock()
+		if p.currentProposal == current {
+			p.setCurrentProposal(nil)
+		}
+		p.mu.Unlock()
+
+		return nil, nil, ErrProposalNotFound
+	}
+
+	return current, &cpy, nil
+}
+
+// refreshProposalStatus requests the status of the proposal snapshot and records it on the
+// current proposal, given it has not been replaced in the meantime. It is a no-op when the
+// proposer is unable to report the status of proposals.
+func (p *Phase[R]) refreshProposalStatus(ctx context.Context, current, proposal *Proposal) error {
+	merger, ok := p.proposer.(MergingProposer)
+	if !ok {
+		return nil
+	}
+
+	status, err := merger.GetProposalStatus(ctx, proposal)
+	if err != nil {
+		return fmt.Errorf("getting proposal status: %w", err)
+	}
+
+	proposal.Status = status
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.currentProposal == current {
+		current.Status = status
+		current.HeadRevision = proposal.HeadRevision
+		p.setCurrentProposal(current)
+	}
+
+	return nil
+}
+
 func (p *Phase[R]) branchPrefix() string {
 	return fmt.Sprintf("glu/%s/%s", p.pipeline, p.meta.Name)
 }
@@ -481,13 +818,21 @@ func (p *Phase[R]) updateAndPush(ctx context.Context, from, to R, updateOpts *ty
 	return head.String(), nil
 }
 
-func annotations(proposal *Proposal) map[string]string {
+func (p *Phase[R]) annotations(proposal *Proposal) map[string]string {
 	a := map[string]string{
 		AnnotationProposalURLKey: proposal.URL,
 		AnnotationGitBaseRefKey:  proposal.BaseBranch,
 		AnnotationGitHeadSHAKey:  proposal.HeadRevision,
 	}
 
+	if url := p.links.CompareURL(proposal.BaseBranch, proposal.Branch); url != "" {
+		a[AnnotationCompareURLKey] = url
+	}
+
+	if proposal.Status != nil {
+		maps.Insert(a, maps.All(proposal.Status.Annotations()))
+	}
+
 	maps.Insert(a, maps.All(proposal.Annotations))
 	return a
 }
@@ -0,0 +1,121 @@
+package git_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/glutest"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhase_ProposalAnnotations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	remote, err := glutest.NewRemote(map[string]string{"value.txt": "one"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+	proposer := glutest.NewProposer(remote)
+
+	phase, err := glutest.NewGitPhase(ctx, remote, "pipeline", core.Metadata{Name: "prod"}, func() *value { return &value{} }, proposer,
+		git.ProposeChanges[*value](git.ProposalOption{}))
+	require.NoError(t, err)
+
+	// there are no annotations until a proposal is opened
+	assert.Nil(t, phase.Annotations())
+
+	_, err = phase.Proposal(ctx)
+	assert.ErrorIs(t, err, git.ErrProposalNotFound)
+
+	proposer.SetStatus(git.ProposalStatus{
+		Checks:    []git.Check{{Name: "build", State: git.CheckStatePending}},
+		Mergeable: true,
+	})
+
+	_, err = phase.Update(ctx, &value{"two"})
+	require.NoError(t, err)
+
+	// the status is populated once the proposal is opened
+	assert.Equal(t, map[string]string{
+		git.AnnotationProposalChecksKey:    "pending",
+		git.AnnotationProposalReviewKey:    "review_required",
+		git.AnnotationProposalApprovalsKey: "0",
+		git.AnnotationProposalMergeableKey: "true",
+	}, statusAnnotations(phase.Annotations()))
+
+	proposer.SetStatus(git.ProposalStatus{
+		Checks:    []git.Check{{Name: "build", State: git.CheckStateSuccess}},
+		Approvals: 1,
+		Mergeable: true,
+	})
+
+	// the status is refreshed in the background when the base branch is notified
+	head, err := remote.Head("main")
+	require.NoError(t, err)
+
+	require.NoError(t, phase.Notify(ctx, map[string]string{"main": head}))
+
+	expected := map[string]string{
+		git.AnnotationProposalChecksKey:    "success",
+		git.AnnotationProposalReviewKey:    "approved",
+		git.AnnotationProposalApprovalsKey: "1",
+		git.AnnotationProposalMergeableKey: "true",
+	}
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Equal(t, expected, statusAnnotations(phase.Annotations()))
+	}, time.Second, 10*time.Millisecond)
+
+	proposer.SetStatus(git.ProposalStatus{
+		Checks:           []git.Check{{Name: "build", State: git.CheckStateFailure}},
+		Approvals:        1,
+		ChangesRequested: true,
+	})
+
+	// the proposal reports the latest status on request
+	proposal, err := phase.Proposal(ctx)
+	require.NoError(t, err)
+
+	expected = map[string]string{
+		git.AnnotationProposalChecksKey:    "failure",
+		git.AnnotationProposalReviewKey:    "changes_requested",
+		git.AnnotationProposalApprovalsKey: "1",
+		git.AnnotationProposalMergeableKey: "false",
+	}
+
+	assert.Equal(t, "glutest://proposals/1", proposal.URL)
+	assert.Equal(t, []git.Check{{Name: "build", State: git.CheckStateFailure}}, proposal.Status.Checks)
+	assert.Equal(t, expected, statusAnnotations(proposal.Annotations))
+	assert.Equal(t, expected, statusAnnotations(phase.Annotations()))
+	assert.Equal(t, "glutest://proposals/1", phase.Annotations()[git.AnnotationProposalURLKey])
+
+	// annotations are cleared once the proposal is closed
+	require.NoError(t, proposer.CloseProposal(ctx, proposal))
+
+	_, err = phase.Proposal(ctx)
+	assert.ErrorIs(t, err, git.ErrProposalNotFound)
+	assert.Nil(t, phase.Annotations())
+}
+
+// statusAnnotations returns the subset of the annotations which describe the proposals status.
+func statusAnnotations(annotations map[string]string) map[string]string {
+	status := map[string]string{}
+	for _, key := range []string{
+		git.AnnotationProposalChecksKey,
+		git.AnnotationProposalReviewKey,
+		git.AnnotationProposalApprovalsKey,
+		git.AnnotationProposalMergeableKey,
+	} {
+		if value, ok := annotations[key]; ok {
+			status[key] = value
+		}
+	}
+
+	return status
+}
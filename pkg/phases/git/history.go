@@ -0,0 +1,96 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/get-glu/glu/internal/git"
+	"github.com/get-glu/glu/pkg/core/typed"
+	"github.com/get-glu/glu/pkg/fs"
+	"github.com/get-glu/glu/pkg/phases/logger"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// defaultBackfillLimit is the default maximum number of commits read when backfilling history.
+const defaultBackfillLimit = 100
+
+// backfillHistory reconstructs the phases history from the commit log of its branch.
+// It is a no-op when the phases logger already contains history (e.g. it is persistent)
+// or when it cannot record versions in the past (see typed.BackfillPhaseLogger).
+// Commits are replayed oldest first and recorded at their commit time, where commits
+// which do not change the resource are skipped by the logger.
+// Recorded times are kept strictly increasing as history is ordered by the time it was recorded
+// and commit times have second precision (and are not guaranteed to be ordered).
+func (p *Phase[R]) backfillHistory(ctx context.Context) error {
+	if p.backfillLimit <= 0 {
+		return nil
+	}
+
+	backfill, ok := p.logger.(typed.BackfillPhaseLogger[R])
+	if !ok {
+		slog.Debug("logger does not support backfilling history", "pipeline", p.pipeline, "phase", p.meta.Name)
+		return nil
+	}
+
+	if _, err := backfill.GetLatestResource(ctx, p.Descriptor()); err == nil {
+		// history already exists
+		return nil
+	} else if !errors.Is(err, logger.ErrNotFound) {
+		return fmt.Errorf("reading latest resource before backfilling history: %w", err)
+	}
+
+	commits, err := p.repo.ListCommits(ctx, p.branch(), "", nil)
+	if err != nil {
+		return err
+	}
+
+	var log []*object.Commit
+	for commit := range commits {
+		log = append(log, commit)
+		if len(log) >= p.backfillLimit {
+			break
+		}
+	}
+
+	slices.Reverse(log)
+
+	var last time.Time
+	for _, commit := range log {
+		r := p.newFn()
+		if err := p.repo.View(ctx, func(_ plumbing.Hash, fs fs.Filesystem) error {
+			return r.ReadFrom(ctx, p.Descriptor(), fs)
+		}, git.WithRevision(commit.Hash)); err != nil {
+			// the resource may not be readable from commits preceding its introduction
+			slog.Debug("skipping commit while backfilling history",
+				"pipeline", p.pipeline,
+				"phase", p.meta.Name,
+				"commit", commit.Hash,
+				"error", err)
+			continue
+		}
+
+		annotations := map[string]string{
+			AnnotationGitHeadSHAKey: commit.Hash.String(),
+		}
+
+		p.annotateCommitURL(annotations, commit.Hash)
+
+		recordedAt := commit.Committer.When
+		if !recordedAt.After(last) {
+			recordedAt = last.Add(time.Millisecond)
+		}
+
+		last = recordedAt
+
+		if err := backfill.RecordLatestAt(ctx, p.Descriptor(), r, annotations, recordedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package git_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/core/typed"
+	"github.com/get-glu/glu/pkg/glutest"
+	"github.com/get-glu/glu/pkg/kv/memory"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/get-glu/glu/pkg/phases/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhase_BackfillHistory(t *testing.T) {
+	ctx := context.Background()
+
+	// the resource is not readable from the initial commit
+	remote, err := glutest.NewRemote(map[string]string{"README.md": "# Test"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+	var heads []string
+	for _, files := range []map[string]string{
+		{"value.txt": "one"},
+		{"value.txt": "two"},
+		// commits which do not change the resource are skipped
+		{"README.md": "# Updated"},
+		{"value.txt": "three"},
+	} {
+		require.NoError(t, remote.Commit("main", "update", files))
+
+		head, err := remote.Head("main")
+		require.NoError(t, err)
+
+		heads = append(heads, head)
+	}
+
+	t.Run("backfilled", func(t *testing.T) {
+		phase := newPhase(t, remote)
+
+		assertHistory(ctx, t, phase, map[string]string{
+			"three": heads[3],
+			"two":   heads[1],
+			"one":   heads[0],
+		}, "three", "two", "one")
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		phase := newPhase(t, remote, git.WithHistoryBackfill[*value](2))
+
+		// only the two most recent commits are read
+		assertHistory(ctx, t, phase, map[string]string{
+			"three": heads[3],
+			"two":   heads[2],
+		}, "three", "two")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		phase := newPhase(t, remote, git.WithHistoryBackfill[*value](0))
+
+		assertHistory(ctx, t, phase, map[string]string{"three": heads[3]}, "three")
+	})
+
+	t.Run("existing history", func(t *testing.T) {
+		log := logger.New[*value](memory.New())
+		require.NoError(t, log.CreateLog(ctx, descriptor))
+		require.NoError(t, log.RecordLatest(ctx, descriptor, &value{"two"}, nil))
+
+		phase := newPhase(t, remote, git.WithLogger(typed.PhaseLogger[*value](log)))
+
+		assertHistory(ctx, t, phase, map[string]string{"three": heads[3], "two": ""}, "three", "two")
+	})
+
+	t.Run("unsupported logger", func(t *testing.T) {
+		log := basicLogger{logger.New[*value](memory.New())}
+
+		phase := newPhase(t, remote, git.WithLogger[*value](log))
+
+		assertHistory(ctx, t, phase, map[string]string{"three": heads[3]}, "three")
+	})
+
+	t.Run("logger unavailable", func(t *testing.T) {
+		log := unavailableLogger{logger.New[*value](memory.New())}
+
+		_, err := glutest.NewGitPhase(ctx, remote, "pipeline", core.Metadata{Name: "prod"}, func() *value { return &value{} }, nil, git.WithLogger[*value](log))
+		assert.ErrorIs(t, err, errUnavailable)
+	})
+}
+
+var descriptor = core.Descriptor{Kind: "git", Pipeline: "pipeline", Metadata: core.Metadata{Name: "prod"}}
+
+// assertHistory asserts the digests of the phases history (newest first)
+// and the head commit annotated on each version (when not empty).
+func assertHistory(ctx context.Context, t *testing.T, phase *git.Phase[*value], heads map[string]string, digests ...string) {
+	t.Helper()
+
+	history, err := phase.History(ctx)
+	require.NoError(t, err)
+
+	var actual []string
+	for _, state := range history {
+		actual = append(actual, state.Digest)
+
+		if head := heads[state.Digest]; head != "" {
+			assert.Equal(t, head, state.Annotations[git.AnnotationGitHeadSHAKey], state.Digest)
+		}
+	}
+
+	assert.Equal(t, digests, actual)
+}
+
+// basicLogger is a logger which does not support recording versions in the past.
+type basicLogger struct {
+	typed.PhaseLogger[*value]
+}
+
+var errUnavailable = errors.New("unavailable")
+
+// unavailableLogger is a logger which fails to read the latest resource.
+type unavailableLogger struct {
+	*logger.PhaseLogger[*value]
+}
+
+func (unavailableLogger) GetLatestResource(context.Context, core.Descriptor) (*value, error) {
+	return nil, errUnavailable
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"testing"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLinkProvider(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		remote *gitconfig.RemoteConfig
+		commit string
+	}{
+		{
+			name: "no remote",
+		},
+		{
+			name:   "no remote urls",
+			remote: &gitconfig.RemoteConfig{Name: "origin"},
+		},
+		{
+			name:   "github",
+			remote: &gitconfig.RemoteConfig{Name: "origin", URLs: []string{"git@github.com:owner/repo.git"}},
+			commit: "https://github.com/owner/repo/commit/abc123",
+		},
+		{
+			name:   "gitlab",
+			remote: &gitconfig.RemoteConfig{Name: "origin", URLs: []string{"https://gitlab.com/group/sub/repo.git"}},
+			commit: "https://gitlab.com/group/sub/repo/-/commit/abc123",
+		},
+		{
+			name:   "codeberg",
+			remote: &gitconfig.RemoteConfig{Name: "origin", URLs: []string{"https://codeberg.org/owner/repo.git"}},
+			commit: "https://codeberg.org/owner/repo/commit/abc123",
+		},
+		{
+			name:   "bitbucket",
+			remote: &gitconfig.RemoteConfig{Name: "origin", URLs: []string{"git@bitbucket.org:owner/repo.git"}},
+			commit: "https://bitbucket.org/owner/repo/commits/abc123",
+		},
+		{
+			name:   "unknown host",
+			remote: &gitconfig.RemoteConfig{Name: "origin", URLs: []string{"https://git.example.com/owner/repo.git"}},
+		},
+		{
+			name:   "unparseable path",
+			remote: &gitconfig.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/owner"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			links := detectLinkProvider(test.remote)
+			assert.Equal(t, test.commit, links.CommitURL("abc123"))
+
+			if test.commit == "" {
+				assert.Equal(t, noLinks{}, links)
+			}
+		})
+	}
+}
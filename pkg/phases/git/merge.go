@@ -0,0 +1,259 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MergeMethod is the strategy used to integrate a proposal into its base branch.
+type MergeMethod string
+
+const (
+	MergeMethodMerge  = MergeMethod("merge")
+	MergeMethodSquash = MergeMethod("squash")
+	MergeMethodRebase = MergeMethod("rebase")
+)
+
+// CheckState is the state of a status check (CI job, check run, commit status etc.).
+type CheckState string
+
+const (
+	CheckStatePending = CheckState("pending")
+	CheckStateSuccess = CheckState("success")
+	CheckStateFailure = CheckState("failure")
+)
+
+// ErrUnsupportedMergeMethod is returned when a proposer does not support the requested merge method.
+var ErrUnsupportedMergeMethod = errors.New("unsupported merge method")
+
+// Check is a single status check reported against the head of a proposal.
+type Check struct {
+	Name  string     `json:"name"`
+	State CheckState `json:"state"`
+}
+
+// ProposalStatus describes the state of the checks and reviews of a proposal.
+// Proposers refresh the HeadRevision of the proposal when reporting its status,
+// such that a subsequent merge is guarded against the head having moved.
+type ProposalStatus struct {
+	Checks           []Check `json:"checks,omitempty"`
+	Approvals        int     `json:"approvals"`
+	ChangesRequested bool    `json:"changes_requested"`
+	// Mergeable is true when the provider reports the proposal
+	// can be merged (e.g. there are no conflicts with the base).
+	Mergeable bool `json:"mergeable"`
+}
+
+// ChecksState aggregates the state of the checks identified by name.
+// When no names are provided all reported checks are considered.
+// Required checks which have not been reported are considered pending.
+func (s *ProposalStatus) ChecksState(required ...string) CheckState {
+	state := CheckStateSuccess
+	update := func(st CheckState) {
+		switch {
+		case st == CheckStateFailure:
+			state = CheckStateFailure
+		case st == CheckStatePending && state != CheckStateFailure:
+			state = CheckStatePending
+		}
+	}
+
+	if len(required) == 0 {
+		for _, check := range s.Checks {
+			update(check.State)
+		}
+
+		return state
+	}
+
+	for _, name := range required {
+		i := slices.IndexFunc(s.Checks, func(c Check) bool { return c.Name == name })
+		if i < 0 {
+			update(CheckStatePending)
+			continue
+		}
+
+		update(s.Checks[i].State)
+	}
+
+	return state
+}
+
+// ReviewDecision summarizes the reviews of a proposal.
+type ReviewDecision string
+
+const (
+	ReviewDecisionRequired         = ReviewDecision("review_required")
+	ReviewDecisionApproved         = ReviewDecision("approved")
+	ReviewDecisionChangesRequested = ReviewDecision("changes_requested")
+)
+
+// ReviewDecision returns changes_requested when any reviewer has requested changes,
+// approved when at least one reviewer has approved and otherwise review_required.
+func (s *ProposalStatus) ReviewDecision() ReviewDecision {
+	switch {
+	case s.ChangesRequested:
+		return ReviewDecisionChangesRequested
+	case s.Approvals > 0:
+		return ReviewDecisionApproved
+	default:
+		return ReviewDecisionRequired
+	}
+}
+
+// Annotations returns the status as a set of proposal annotations.
+func (s *ProposalStatus) Annotations() map[string]string {
+	return map[string]string{
+		AnnotationProposalChecksKey:    string(s.ChecksState()),
+		AnnotationProposalReviewKey:    string(s.ReviewDecision()),
+		AnnotationProposalApprovalsKey: strconv.Itoa(s.Approvals),
+		AnnotationProposalMergeableKey: strconv.FormatBool(s.Mergeable),
+	}
+}
+
+// AutoMerge configures the phase to merge its proposals once they are ready.
+type AutoMerge struct {
+	// Method is the merge strategy (defaults to merge).
+	Method MergeMethod
+	// RequiredChecks restricts the checks which must succeed to those named.
+	// When empty, every reported check must succeed.
+	RequiredChecks []string
+	// RequiredApprovals is the minimum number of approving reviews.
+	RequiredApprovals int
+	// AllowNoChecks permits merging proposals which have no checks reported against them.
+	// By default such proposals are considered pending, given providers often report
+	// checks some time after a proposal has been opened or updated.
+	AllowNoChecks bool
+	// Interval is the period between checking whether the current proposal is
+	// ready to merge (defaults to 30s). An interval below zero disables polling,
+	// in which case merging is only attempted via calls to MergeIfReady
+	// (e.g. when the server receives a proposal or check event via the git webhook).
+	Interval time.Duration
+}
+
+// ready returns nil when the status satisfies the auto-merge requirements
+// or otherwise an error describing the outstanding requirement.
+func (a *AutoMerge) ready(status *ProposalStatus) error {
+	if status.ChangesRequested {
+		return errors.New("changes requested")
+	}
+
+	if status.Approvals < a.RequiredApprovals {
+		return fmt.Errorf("waiting on approvals (%d/%d)", status.Approvals, a.RequiredApprovals)
+	}
+
+	if len(status.Checks) == 0 && !a.AllowNoChecks {
+		return fmt.Errorf("checks %s", CheckStatePending)
+	}
+
+	if state := status.ChecksState(a.RequiredChecks...); state != CheckStateSuccess {
+		return fmt.Errorf("checks %s", state)
+	}
+
+	if !status.Mergeable {
+		return errors.New("not mergeable")
+	}
+
+	return nil
+}
+
+func (p *Phase[R]) startAutoMerge(ctx context.Context) {
+	interval := p.proposalOptions.AutoMerge.Interval
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+
+	if interval < 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.MergeIfReady(ctx); err != nil {
+					slog.Error("auto-merging proposal", "type", "git", "phase", p.meta.Name, "error", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// MergeIfReady merges the phases current proposal given auto-merge is configured and the
+// proposals checks, approvals and mergeability satisfy the configured requirements.
+// It returns nil if there is no proposal or it is not yet ready to be merged.
+func (p *Phase[R]) MergeIfReady(ctx context.Context) error {
+	opts := p.proposalOptions.AutoMerge
+	merger, ok := p.proposer.(MergingProposer)
+	if !p.proposeChange || opts == nil || !ok {
+		return nil
+	}
+
+	// serialize merge attempts (e.g. polling and webhooks) while leaving the
+	// current proposal available to updates during calls to the proposer
+	p.mergeMu.Lock()
+	defer p.mergeMu.Unlock()
+
+	current, proposal, err := p.snapshotProposal(ctx)
+	if err != nil {
+		if errors.Is(err, ErrProposalNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := p.refreshProposalStatus(ctx, current, proposal); err != nil {
+		return err
+	}
+
+	status := proposal.Status
+
+	slog := slog.With("phase", p.meta.Name, "proposal_url", proposal.URL)
+	if err := opts.ready(status); err != nil {
+		slog.Debug("proposal not ready to merge", "reason", err)
+		return nil
+	}
+
+	method := opts.Method
+	if method == "" {
+		method = MergeMethodMerge
+	}
+
+	// the merge is guarded by the head revision reported alongside the status,
+	// such that a proposal updated in the meantime is not merged
+	if err := merger.MergeProposal(ctx, proposal, method); err != nil {
+		return fmt.Errorf("merging proposal: %w", err)
+	}
+
+	slog.Debug("auto-merged proposal", "method", method, "checks", checkSummary(status.Checks))
+
+	p.mu.Lock()
+	if p.currentProposal == current {
+		p.setCurrentProposal(nil)
+	}
+	p.mu.Unlock()
+
+	// fetch the base branch so that the phase observes the merged state
+	return p.repo.Fetch(ctx, p.branch())
+}
+
+func checkSummary(checks []Check) string {
+	summary := make([]string, 0, len(checks))
+	for _, check := range checks {
+		summary = append(summary, check.Name+"="+string(check.State))
+	}
+
+	return strings.Join(summary, ",")
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoMerge_Ready(t *testing.T) {
+	var (
+		success = []Check{{Name: "build", State: CheckStateSuccess}}
+		pending = []Check{{Name: "build", State: CheckStateSuccess}, {Name: "lint", State: CheckStatePending}}
+	)
+
+	for _, test := range []struct {
+		name      string
+		autoMerge AutoMerge
+		status    ProposalStatus
+		reason    string
+	}{
+		{
+			name:   "ready",
+			status: ProposalStatus{Checks: success, Mergeable: true},
+		},
+		{
+			name:   "no checks reported",
+			status: ProposalStatus{Mergeable: true},
+			reason: "checks pending",
+		},
+		{
+			name:      "no checks reported allowed",
+			autoMerge: AutoMerge{AllowNoChecks: true},
+			status:    ProposalStatus{Mergeable: true},
+		},
+		{
+			name:   "checks pending",
+			status: ProposalStatus{Checks: pending, Mergeable: true},
+			reason: "checks pending",
+		},
+		{
+			name:      "required checks succeeded",
+			autoMerge: AutoMerge{RequiredChecks: []string{"build"}},
+			status:    ProposalStatus{Checks: pending, Mergeable: true},
+		},
+		{
+			name:      "required check not reported",
+			autoMerge: AutoMerge{RequiredChecks: []string{"test"}, AllowNoChecks: true},
+			status:    ProposalStatus{Mergeable: true},
+			reason:    "checks pending",
+		},
+		{
+			name:      "waiting on approvals",
+			autoMerge: AutoMerge{RequiredApprovals: 2},
+			status:    ProposalStatus{Checks: success, Approvals: 1, Mergeable: true},
+			reason:    "waiting on approvals (1/2)",
+		},
+		{
+			name:   "changes requested",
+			status: ProposalStatus{Checks: success, Approvals: 1, ChangesRequested: true, Mergeable: true},
+			reason: "changes requested",
+		},
+		{
+			name:   "not mergeable",
+			status: ProposalStatus{Checks: success},
+			reason: "not mergeable",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.autoMerge.ready(&test.status)
+			if test.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, test.reason)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/get-glu/glu/internal/git"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core/typed"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/mod/semver"
+)
+
+// initialVersion is the version of the first tag created by a phase configured with semver tags.
+const initialVersion = "0.1.0"
+
+type tagOptions struct {
+	name   string
+	prefix string
+	semver bool
+}
+
+// WithTag configures the phase to read its state from the named tag (e.g. release/prod)
+// as opposed to a branch. Updates are committed on top of the tagged commit and the tag
+// is moved to the new commit. These commits are not added to any branch and are only
+// reachable from the tag. When the tag does not yet exist, the first update is based
+// on the head of the phases branch. An update fails with core.ErrConflict when the tag
+// was changed upstream since it was last fetched.
+func WithTag[R Resource](tag string) containers.Option[Phase[R]] {
+	return func(p *Phase[R]) {
+		p.tags = &tagOptions{name: tag}
+	}
+}
+
+// WithSemverTags configures the phase to read its state from the highest semantic version
+// tag with the provided prefix (e.g. "v" for v1.2.3 or "release/" for release/1.2.3).
+// Pre-release and build versions are ignored. Updates are committed on top of the highest
+// version and tagged with its next patch version (or <prefix>0.1.0 when no version exists).
+// Existing versions are never moved.
+func WithSemverTags[R Resource](prefix string) containers.Option[Phase[R]] {
+	return func(p *Phase[R]) {
+		p.tags = &tagOptions{prefix: prefix, semver: true}
+	}
+}
+
+// Tags returns the tags (or tag patterns) which the phase reads its state from.
+// This is used by the repository to fetch tags and notify the phase of updates to them.
+func (p *Phase[R]) Tags() []string {
+	switch {
+	case p.tags == nil:
+		return nil
+	case p.tags.semver:
+		return []string{p.tags.prefix + "*"}
+	default:
+		return []string{p.tags.name}
+	}
+}
+
+// currentTag returns the tag from which the phases state is read
+// and false when no such tag exists (yet).
+func (p *Phase[R]) currentTag() (string, bool, error) {
+	if !p.tags.semver {
+		if _, err := p.repo.ResolveTag(p.tags.name); err != nil {
+			if errors.Is(err, plumbing.ErrReferenceNotFound) {
+				return p.tags.name, false, nil
+			}
+
+			return "", false, err
+		}
+
+		return p.tags.name, true, nil
+	}
+
+	tags, err := p.repo.Tags(p.tags.prefix + "*")
+	if err != nil {
+		return "", false, err
+	}
+
+	var highest, highestVersion string
+	for _, tag := range tags {
+		version, ok := parseVersion(strings.TrimPrefix(tag, p.tags.prefix))
+		if !ok {
+			continue
+		}
+
+		if highest == "" || semver.Compare(version, highestVersion) > 0 {
+			highest, highestVersion = tag, version
+		}
+	}
+
+	return highest, highest != "", nil
+}
+
+// nextTag returns the tag created (or moved) when updating the phase.
+func (p *Phase[R]) nextTag(current string, exists bool) string {
+	switch {
+	case !p.tags.semver:
+		return p.tags.name
+	case !exists:
+		return p.tags.prefix + initialVersion
+	}
+
+	version := strings.TrimPrefix(current, p.tags.prefix)
+	parsed, _ := parseVersion(version)
+
+	parts := strings.SplitN(strings.TrimPrefix(semver.Canonical(parsed), "v"), ".", 3)
+	patch, _ := strconv.Atoi(parts[2])
+
+	next := fmt.Sprintf("%s.%s.%d", parts[0], parts[1], patch+1)
+	if strings.HasPrefix(version, "v") {
+		next = "v" + next
+	}
+
+	return p.tags.prefix + next
+}
+
+// parseVersion returns the version in the form expected by the semver package
+// (with a leading v) and false if it is not a valid release version.
+func parseVersion(version string) (string, bool) {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	return version, semver.IsValid(version) && semver.Prerelease(version) == "" && semver.Build(version) == ""
+}
+
+// recordTagState records the phases state as identified by its current tag.
+// When the tag does not yet exist, the state is recorded from the phases branch
+// on which the first tag will be based.
+func (p *Phase[R]) recordTagState(ctx context.Context) error {
+	tag, exists, err := p.currentTag()
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return p.recordPhaseState(ctx, nil, git.WithBranch(p.branch()))
+	}
+
+	return p.recordPhaseState(ctx, map[string]string{AnnotationGitTagKey: tag}, git.WithTag(tag))
+}
+
+func (p *Phase[R]) updateTag(ctx context.Context, from, to R, updateOpts *typed.UpdateOptions) (map[string]string, error) {
+	current, exists, err := p.currentTag()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tag  = p.nextTag(current, exists)
+		opts = []containers.Option[git.BranchOptions]{git.WithTag(tag), git.WithBase(p.branch())}
+	)
+
+	switch {
+	case !exists:
+		// create the tag even when the resource matches the head of the branch
+		opts = append(opts, git.WithPushIfEmpty)
+	case tag != current:
+		// a new version is based on the current highest version
+		rev, err := p.repo.ResolveTag(current)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, git.WithRevision(rev))
+	}
+
+	head, err := p.updateAndPush(ctx, from, to, updateOpts, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{
+		AnnotationGitTagKey:     tag,
+		AnnotationGitHeadSHAKey: head,
+	}
+
+	p.annotateCommitURL(annotations, plumbing.NewHash(head))
+
+	return annotations, nil
+}
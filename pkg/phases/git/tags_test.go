@@ -0,0 +1,207 @@
+package git_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/fs"
+	"github.com/get-glu/glu/pkg/glutest"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhase_Tag(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		remote, err = glutest.NewRemote(map[string]string{"value.txt": "initial"})
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+	phase := newPhase(t, remote, git.WithTag[*value]("release/prod"))
+
+	// the state is read from the branch until the tag is created
+	assertValue(ctx, t, phase, "initial")
+
+	result, err := phase.Update(ctx, &value{"one"})
+	require.NoError(t, err)
+	assert.Equal(t, "release/prod", result.Annotations[git.AnnotationGitTagKey])
+
+	first, err := remote.TagRevision("release/prod")
+	require.NoError(t, err)
+	assert.Equal(t, first, result.Annotations[git.AnnotationGitHeadSHAKey])
+	assertValue(ctx, t, phase, "one")
+
+	// the tag is moved to a commit on top of the previously tagged commit
+	result, err = phase.Update(ctx, &value{"two"})
+	require.NoError(t, err)
+
+	second, err := remote.TagRevision("release/prod")
+	require.NoError(t, err)
+	assert.Equal(t, second, result.Annotations[git.AnnotationGitHeadSHAKey])
+	assert.NotEqual(t, first, second)
+	assertValue(ctx, t, phase, "two")
+
+	// the commits are only reachable from the tag
+	contents, err := remote.ReadFile("main", "value.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "initial", contents)
+
+	branches, err := remote.Branches()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main"}, branches)
+
+	// a tag moved upstream is picked up by the fetch preceding the update
+	require.NoError(t, remote.Tag("release/prod", "main"))
+
+	_, err = phase.Update(ctx, &value{"three"})
+	require.NoError(t, err)
+	assertValue(ctx, t, phase, "three")
+
+	_, err = phase.Update(ctx, &value{"three"})
+	assert.ErrorIs(t, err, core.ErrNoChange)
+}
+
+func TestPhase_SemverTags(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		prefix   string
+		existing []string
+		expected string
+	}{
+		{
+			name:     "initial version",
+			prefix:   "v",
+			expected: "v0.1.0",
+		},
+		{
+			name:     "patch bump",
+			prefix:   "v",
+			existing: []string{"v1.2.3"},
+			expected: "v1.2.4",
+		},
+		{
+			name:   "highest version",
+			prefix: "v",
+			// versions are compared semantically and pre-release,
+			// build and invalid versions are ignored
+			existing: []string{"v1.9.0", "v1.10.0", "v1.2.30", "v2.0.0-rc.1", "v2.0.0+build", "vnext"},
+			expected: "v1.10.1",
+		},
+		{
+			name:     "prefix without v",
+			prefix:   "release/",
+			existing: []string{"release/1.0.9"},
+			expected: "release/1.0.10",
+		},
+		{
+			name:     "prefix with v",
+			prefix:   "release/",
+			existing: []string{"release/v1.0.0", "other/v2.0.0"},
+			expected: "release/v1.0.1",
+		},
+		{
+			name:     "initial version with prefix",
+			prefix:   "release/",
+			existing: []string{"v1.0.0"},
+			expected: "release/0.1.0",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			remote, err := glutest.NewRemote(map[string]string{"value.txt": "initial"})
+			require.NoError(t, err)
+
+			t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+			for _, tag := range test.existing {
+				require.NoError(t, remote.Tag(tag, "main"))
+			}
+
+			phase := newPhase(t, remote, git.WithSemverTags[*value](test.prefix))
+
+			result, err := phase.Update(ctx, &value{"updated"})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result.Annotations[git.AnnotationGitTagKey])
+
+			revision, err := remote.TagRevision(test.expected)
+			require.NoError(t, err)
+			assert.Equal(t, revision, result.Annotations[git.AnnotationGitHeadSHAKey])
+			assertValue(ctx, t, phase, "updated")
+
+			// existing versions are left where they were
+			head, err := remote.Head("main")
+			require.NoError(t, err)
+
+			for _, tag := range test.existing {
+				revision, err := remote.TagRevision(tag)
+				require.NoError(t, err)
+				assert.Equal(t, head, revision)
+			}
+		})
+	}
+}
+
+func newPhase(t *testing.T, remote *glutest.Remote, opts ...containers.Option[git.Phase[*value]]) *git.Phase[*value] {
+	t.Helper()
+
+	phase, err := glutest.NewGitPhase(context.Background(), remote, "pipeline", core.Metadata{Name: "prod"}, func() *value { return &value{} }, nil, opts...)
+	require.NoError(t, err)
+
+	return phase
+}
+
+func assertValue(ctx context.Context, t *testing.T, phase *git.Phase[*value], expected string) {
+	t.Helper()
+
+	r, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, expected, r.Value)
+}
+
+// value is a resource stored in a single file.
+type value struct {
+	Value string
+}
+
+func (v *value) Digest() (string, error) {
+	return v.Value, nil
+}
+
+func (v *value) ReadFrom(_ context.Context, _ core.Descriptor, fs fs.Filesystem) error {
+	fi, err := fs.OpenFile("value.txt", os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	defer fi.Close()
+
+	data, err := io.ReadAll(fi)
+	if err != nil {
+		return err
+	}
+
+	v.Value = string(data)
+
+	return nil
+}
+
+func (v *value) WriteTo(_ context.Context, _ core.Descriptor, fs fs.Filesystem) error {
+	fi, err := fs.OpenFile("value.txt", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(fi, v.Value); err != nil {
+		return err
+	}
+
+	return fi.Close()
+}
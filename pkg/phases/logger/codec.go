@@ -0,0 +1,283 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Resources are stored with a header which identifies the format version,
+// the codec and the compression used to encode them:
+//
+//	magic (4 bytes) | format version (1 byte) | codec ID (1 byte) | compression ID (1 byte) | payload
+//
+// Resources recorded before the header was introduced are plain JSON.
+// These are identified by the absence of the magic bytes (which JSON can never begin with).
+const formatVersion = 1
+
+var (
+	magic     = []byte{0x00, 'g', 'l', 'u'}
+	headerLen = len(magic) + 3
+)
+
+var (
+	// JSON encodes resources using encoding/json.
+	// It is the default codec and only encodes exported fields.
+	JSON Codec = jsonCodec{}
+	// Gob encodes resources using encoding/gob.
+	Gob Codec = gobCodec{}
+	// CBOR encodes resources using CBOR (RFC 8949).
+	// It respects json struct tags and is typically more compact than JSON.
+	CBOR Codec = cborCodec{}
+	// Binary encodes resources using their own encoding.BinaryMarshaler
+	// and encoding.BinaryUnmarshaler implementations.
+	// This allows resources to persist state which the other codecs cannot
+	// (e.g. unexported fields).
+	Binary Codec = binaryCodec{}
+
+	// Gzip compresses encoded resources using gzip.
+	Gzip Compression = gzipCompression{}
+	// Zstd compresses encoded resources using zstd.
+	Zstd Compression = zstdCompression{}
+
+	codecs = map[byte]Codec{
+		JSON.ID():   JSON,
+		Gob.ID():    Gob,
+		CBOR.ID():   CBOR,
+		Binary.ID(): Binary,
+	}
+
+	compressions = map[byte]Compression{
+		Gzip.ID(): Gzip,
+		Zstd.ID(): Zstd,
+	}
+)
+
+// Codec encodes and decodes the resources recorded by a PhaseLogger.
+type Codec interface {
+	// ID identifies the codec within the header of encoded resources.
+	// IDs below 128 are reserved for the codecs provided by this package.
+	ID() byte
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// Compression compresses the encoded resources recorded by a PhaseLogger.
+type Compression interface {
+	// ID identifies the compression within the header of encoded resources.
+	// Zero identifies uncompressed resources and IDs below 128 are reserved
+	// for the compressions provided by this package.
+	ID() byte
+	Compress([]byte) ([]byte, error)
+	Decompress([]byte) ([]byte, error)
+}
+
+// encode encodes the resource using the loggers codec and compression
+// and prefixes it with the header.
+func (l *PhaseLogger[R]) encode(r R) ([]byte, error) {
+	data, err := l.codec.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("encoding resource: %w", err)
+	}
+
+	var compression byte
+	if l.compression != nil {
+		compression = l.compression.ID()
+		if data, err = l.compression.Compress(data); err != nil {
+			return nil, fmt.Errorf("compressing resource: %w", err)
+		}
+	}
+
+	header := append(bytes.Clone(magic), formatVersion, l.codec.ID(), compression)
+
+	return append(header, data...), nil
+}
+
+// decode decodes the resource using the codec and compression identified by its header.
+// Data without a header is decoded as JSON.
+func (l *PhaseLogger[R]) decode(data []byte, r *R) error {
+	if !bytes.HasPrefix(data, magic) {
+		return json.Unmarshal(data, r)
+	}
+
+	if len(data) < headerLen {
+		return errors.New("decoding resource: truncated header")
+	}
+
+	version, codecID, compressionID := data[len(magic)], data[len(magic)+1], data[len(magic)+2]
+	if version != formatVersion {
+		return fmt.Errorf("decoding resource: unsupported format version %d", version)
+	}
+
+	data = data[headerLen:]
+
+	if compressionID != 0 {
+		compression, ok := compressions[compressionID]
+		if l.compression != nil && l.compression.ID() == compressionID {
+			compression, ok = l.compression, true
+		}
+
+		if !ok {
+			return fmt.Errorf("decoding resource: unknown compression %d", compressionID)
+		}
+
+		var err error
+		if data, err = compression.Decompress(data); err != nil {
+			return fmt.Errorf("decompressing resource: %w", err)
+		}
+	}
+
+	codec, ok := codecs[codecID]
+	if l.codec.ID() == codecID {
+		codec, ok = l.codec, true
+	}
+
+	if !ok {
+		return fmt.Errorf("decoding resource: unknown codec %d", codecID)
+	}
+
+	if err := codec.Unmarshal(data, r); err != nil {
+		return fmt.Errorf("decoding resource: %w", err)
+	}
+
+	return nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte { return 1 }
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) ID() byte { return 2 }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type cborCodec struct{}
+
+func (cborCodec) ID() byte { return 3 }
+
+func (cborCodec) Marshal(v any) ([]byte, error) { return cbor.Marshal(v) }
+
+func (cborCodec) Unmarshal(data []byte, v any) error { return cbor.Unmarshal(data, v) }
+
+type binaryCodec struct{}
+
+func (binaryCodec) ID() byte { return 4 }
+
+func (binaryCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement encoding.BinaryMarshaler", v)
+	}
+
+	return m.MarshalBinary()
+}
+
+func (binaryCodec) Unmarshal(data []byte, v any) error {
+	// resources are typically pointers, in which case v is a pointer
+	// to a nil pointer which must be allocated before unmarshalling into it
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if elem := rv.Elem(); elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				elem.Set(reflect.New(elem.Type().Elem()))
+			}
+
+			v = elem.Interface()
+		}
+	}
+
+	u, ok := v.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("%T does not implement encoding.BinaryUnmarshaler", v)
+	}
+
+	return u.UnmarshalBinary(data)
+}
+
+type gzipCompression struct{}
+
+func (gzipCompression) ID() byte { return 1 }
+
+func (gzipCompression) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gzipCompression) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// the zstd encoder and decoder are safe for concurrent use and
+// are created once on first use
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil)
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil)
+	})
+)
+
+type zstdCompression struct{}
+
+func (zstdCompression) ID() byte { return 2 }
+
+func (zstdCompression) Compress(data []byte) ([]byte, error) {
+	enc, err := zstdEncoder()
+	if err != nil {
+		return nil, err
+	}
+
+	return enc.EncodeAll(data, nil), nil
+}
+
+func (zstdCompression) Decompress(data []byte) ([]byte, error) {
+	dec, err := zstdDecoder()
+	if err != nil {
+		return nil, err
+	}
+
+	return dec.DecodeAll(data, nil)
+}
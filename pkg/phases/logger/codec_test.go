@@ -0,0 +1,207 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/kv"
+	"github.com/get-glu/glu/pkg/kv/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhaseLogger_Codecs(t *testing.T) {
+	ctx := context.Background()
+
+	for _, codec := range []struct {
+		name  string
+		codec Codec
+	}{
+		{"json", JSON},
+		{"gob", Gob},
+		{"cbor", CBOR},
+	} {
+		for _, compression := range []struct {
+			name        string
+			compression Compression
+		}{
+			{"uncompressed", nil},
+			{"gzip", Gzip},
+			{"zstd", Zstd},
+		} {
+			t.Run(codec.name+"/"+compression.name, func(t *testing.T) {
+				var (
+					db       = memory.New()
+					logger   = New(db, WithCodec[*testResource](codec.codec), WithCompression[*testResource](compression.compression))
+					resource = &testResource{Value: "one", Labels: map[string]string{"app": "glu"}}
+				)
+
+				require.NoError(t, logger.CreateLog(ctx, testPhase))
+				require.NoError(t, logger.RecordLatest(ctx, testPhase, resource, nil))
+
+				// the blob is prefixed with a header identifying the codec and compression
+				var compressionID byte
+				if compression.compression != nil {
+					compressionID = compression.compression.ID()
+				}
+
+				blob := getBlob(t, db, "one")
+				assert.Equal(t, append(bytes.Clone(magic), formatVersion, codec.codec.ID(), compressionID), blob[:headerLen])
+
+				latest, err := logger.GetLatestResource(ctx, testPhase)
+				require.NoError(t, err)
+				assert.Equal(t, resource, latest)
+
+				// resources can be read regardless of the codec and compression configured
+				latest, err = New[*testResource](db).GetLatestResource(ctx, testPhase)
+				require.NoError(t, err)
+				assert.Equal(t, resource, latest)
+			})
+		}
+	}
+}
+
+func TestPhaseLogger_BinaryCodec(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		db       = memory.New()
+		logger   = New(db, WithCodec[*binaryResource](Binary), WithCompression[*binaryResource](Zstd))
+		resource = &binaryResource{value: "one"}
+	)
+
+	require.NoError(t, logger.CreateLog(ctx, testPhase))
+	require.NoError(t, logger.RecordLatest(ctx, testPhase, resource, nil))
+
+	// unexported state is retained by the resources own encoding
+	latest, err := logger.GetLatestResource(ctx, testPhase)
+	require.NoError(t, err)
+	assert.Equal(t, resource, latest)
+
+	// resources which do not implement the binary encoding are not recorded
+	unsupported := New(memory.New(), WithCodec[*testResource](Binary))
+	require.NoError(t, unsupported.CreateLog(ctx, testPhase))
+
+	err = unsupported.RecordLatest(ctx, testPhase, &testResource{Value: "one"}, nil)
+	assert.ErrorContains(t, err, "does not implement encoding.BinaryMarshaler")
+}
+
+func TestPhaseLogger_LegacyJSON(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		db     = memory.New()
+		logger = New(db, WithCodec[*testResource](CBOR), WithCompression[*testResource](Gzip))
+	)
+
+	require.NoError(t, logger.CreateLog(ctx, testPhase))
+	require.NoError(t, logger.RecordLatest(ctx, testPhase, &testResource{Value: "one"}, nil))
+
+	// resources recorded before the header was introduced are plain JSON
+	require.NoError(t, db.Update(func(tx kv.Tx) error {
+		blobs, err := getBlobBucket(testPhase, tx)
+		if err != nil {
+			return err
+		}
+
+		return blobs.Put([]byte("one"), []byte(`{"value":"one","labels":{"app":"legacy"}}`))
+	}))
+
+	latest, err := logger.GetLatestResource(ctx, testPhase)
+	require.NoError(t, err)
+	assert.Equal(t, &testResource{Value: "one", Labels: map[string]string{"app": "legacy"}}, latest)
+
+	// newly recorded resources use the configured codec
+	require.NoError(t, logger.RecordLatest(ctx, testPhase, &testResource{Value: "two"}, nil))
+	assert.Equal(t, CBOR.ID(), getBlob(t, db, "two")[len(magic)+1])
+
+	history, err := logger.History(ctx, testPhase)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	previous, err := logger.GetResourceAtVersion(ctx, testPhase, history[1].Version)
+	require.NoError(t, err)
+	assert.Equal(t, &testResource{Value: "one", Labels: map[string]string{"app": "legacy"}}, previous)
+}
+
+func TestPhaseLogger_Decode(t *testing.T) {
+	logger := New[*testResource](memory.New())
+
+	header := func(version, codec, compression byte) []byte {
+		return append(bytes.Clone(magic), version, codec, compression)
+	}
+
+	payload, err := json.Marshal(&testResource{Value: "one"})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name   string
+		data   []byte
+		errMsg string
+	}{
+		{name: "legacy json", data: payload},
+		{name: "json", data: append(header(formatVersion, JSON.ID(), 0), payload...)},
+		{name: "truncated header", data: magic, errMsg: "truncated header"},
+		{name: "unsupported version", data: append(header(2, JSON.ID(), 0), payload...), errMsg: "unsupported format version 2"},
+		{name: "unknown codec", data: append(header(formatVersion, 200, 0), payload...), errMsg: "unknown codec 200"},
+		{name: "unknown compression", data: append(header(formatVersion, JSON.ID(), 200), payload...), errMsg: "unknown compression 200"},
+		{name: "corrupt compression", data: append(header(formatVersion, JSON.ID(), Zstd.ID()), payload...), errMsg: "decompressing resource"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var r *testResource
+			err := logger.decode(test.data, &r)
+			if test.errMsg != "" {
+				assert.ErrorContains(t, err, test.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, &testResource{Value: "one"}, r)
+		})
+	}
+}
+
+func getBlob(t *testing.T, db kv.DB, digest string) (blob []byte) {
+	t.Helper()
+
+	require.NoError(t, db.View(func(tx kv.Tx) (err error) {
+		blobs, err := getBlobBucket(testPhase, tx)
+		if err != nil {
+			return err
+		}
+
+		blob, err = blobs.Get([]byte(digest))
+		return err
+	}))
+
+	return blob
+}
+
+var _ core.Resource = (*binaryResource)(nil)
+
+// binaryResource is a resource whose state is unexported
+// and only retained by its binary encoding.
+type binaryResource struct {
+	value string
+}
+
+func (r *binaryResource) Digest() (string, error) {
+	return r.value, nil
+}
+
+func (r *binaryResource) MarshalBinary() ([]byte, error) {
+	return []byte("binary:" + r.value), nil
+}
+
+func (r *binaryResource) UnmarshalBinary(data []byte) error {
+	value, ok := bytes.CutPrefix(data, []byte("binary:"))
+	if !ok {
+		return errors.New("invalid binary resource")
+	}
+
+	r.value = string(value)
+
+	return nil
+}
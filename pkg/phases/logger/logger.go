@@ -3,10 +3,12 @@ package logger
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"time"
 
 	"github.com/get-glu/glu/pkg/containers"
@@ -25,26 +27,61 @@ const (
 var (
 	ErrNotFound = errors.New("not found")
 
-	_ typed.PhaseLogger[core.Resource] = (*PhaseLogger[core.Resource])(nil)
+	_ typed.PhaseLogger[core.Resource]         = (*PhaseLogger[core.Resource])(nil)
+	_ typed.BackfillPhaseLogger[core.Resource] = (*PhaseLogger[core.Resource])(nil)
+	_ typed.StatefulPhaseLogger[core.Resource] = (*PhaseLogger[core.Resource])(nil)
 )
 
 type PhaseLogger[R core.Resource] struct {
-	db      kv.DB
-	encoder func(any) ([]byte, error)
-	decoder func([]byte, any) error
+	db          kv.DB
+	codec       Codec
+	compression Compression
 }
 
-func New[R core.Resource](db kv.DB) *PhaseLogger[R] {
-	return &PhaseLogger[R]{
-		db:      db,
-		encoder: json.Marshal,
-		decoder: json.Unmarshal,
+// New constructs a new PhaseLogger which records history in the provided db.
+// Resources are encoded as uncompressed JSON unless configured otherwise.
+func New[R core.Resource](db kv.DB, opts ...containers.Option[PhaseLogger[R]]) *PhaseLogger[R] {
+	l := &PhaseLogger[R]{
+		db:    db,
+		codec: JSON,
+	}
+
+	containers.ApplyAll(l, opts...)
+
+	return l
+}
+
+// WithCodec configures the codec used to encode recorded resources.
+// Resources recorded using other codecs can still be read.
+func WithCodec[R core.Resource](codec Codec) containers.Option[PhaseLogger[R]] {
+	return func(l *PhaseLogger[R]) {
+		l.codec = codec
+	}
+}
+
+// WithCompression configures the compression applied to encoded resources.
+// Resources recorded using other compressions (or none) can still be read.
+func WithCompression[R core.Resource](compression Compression) containers.Option[PhaseLogger[R]] {
+	return func(l *PhaseLogger[R]) {
+		l.compression = compression
 	}
 }
 
 type version struct {
 	Digest      []byte
 	Annotations map[string]string
+	// Blob is the key of the encoded resource when it differs from the digest,
+	// which is the case when the state of an already recorded digest changes
+	Blob []byte `json:",omitempty"`
+}
+
+// blobKey returns the key of the encoded resource within the blobs bucket.
+func (v version) blobKey() []byte {
+	if len(v.Blob) > 0 {
+		return v.Blob
+	}
+
+	return v.Digest
 }
 
 func (l *PhaseLogger[R]) CreateLog(ctx context.Context, phase core.Descriptor) error {
@@ -59,11 +96,35 @@ func (l *PhaseLogger[R]) CreateLog(ctx context.Context, phase core.Descriptor) e
 }
 
 func (l *PhaseLogger[R]) RecordLatest(ctx context.Context, phase core.Descriptor, resource R, annotations map[string]string) error {
+	return l.recordLatest(ctx, phase, resource, annotations, time.Time{}, false)
+}
+
+// RecordLatestAt records the resource as the latest version at the provided time,
+// as opposed to the current time.
+func (l *PhaseLogger[R]) RecordLatestAt(ctx context.Context, phase core.Descriptor, resource R, annotations map[string]string, recordedAt time.Time) error {
+	return l.recordLatest(ctx, phase, resource, annotations, recordedAt, false)
+}
+
+// RecordLatestState records the resource as the latest version when either its digest,
+// its encoded state or the annotations differ from those of the current latest version.
+func (l *PhaseLogger[R]) RecordLatestState(ctx context.Context, phase core.Descriptor, resource R, annotations map[string]string) error {
+	return l.recordLatest(ctx, phase, resource, annotations, time.Time{}, true)
+}
+
+func (l *PhaseLogger[R]) recordLatest(ctx context.Context, phase core.Descriptor, resource R, annotations map[string]string, recordedAt time.Time, compareState bool) error {
 	digest, err := resource.Digest()
 	if err != nil {
 		return err
 	}
 
+	// the encoded resource is only required upfront when its state is compared
+	var data []byte
+	if compareState {
+		if data, err = l.encode(resource); err != nil {
+			return err
+		}
+	}
+
 	// check if we can skip the write if we're already up to date
 	var upToDate bool
 	if err := l.db.View(func(tx kv.Tx) error {
@@ -72,7 +133,12 @@ func (l *PhaseLogger[R]) RecordLatest(ctx context.Context, phase core.Descriptor
 			return err
 		}
 
-		upToDate = l.isUpToDate(refs, phase, digest)
+		blobs, err := getBlobBucket(phase, tx)
+		if err != nil {
+			return err
+		}
+
+		upToDate = l.isUpToDate(refs, blobs, phase, digest, annotations, data)
 
 		return nil
 	}); err != nil || upToDate {
@@ -85,34 +151,46 @@ func (l *PhaseLogger[R]) RecordLatest(ctx context.Context, phase core.Descriptor
 			return err
 		}
 
-		// check again now that we have a write lock if we can skip the update
-		if l.isUpToDate(refs, phase, digest) {
-			return nil
-		}
-
 		blobs, err := getBlobBucket(phase, tx)
 		if err != nil {
 			return err
 		}
 
+		// check again now that we have a write lock if we can skip the update
+		if l.isUpToDate(refs, blobs, phase, digest, annotations, data) {
+			return nil
+		}
+
+		v := version{Digest: []byte(digest), Annotations: annotations}
+
 		// insert encoded resource if digest not already persisted
-		if _, err := blobs.Get([]byte(digest)); errors.Is(err, kv.ErrNotFound) {
-			data, err := l.encoder(resource)
-			if err != nil {
-				return err
+		existing, err := blobs.Get(v.Digest)
+		switch {
+		case errors.Is(err, kv.ErrNotFound):
+			if data == nil {
+				if data, err = l.encode(resource); err != nil {
+					return err
+				}
 			}
 
-			if err := blobs.Put([]byte(digest), data); err != nil {
+			if err := blobs.Put(v.Digest, data); err != nil {
+				return err
+			}
+		case err == nil && data != nil && !bytes.Equal(existing, data):
+			// the state of the resource has changed since its digest was first
+			// recorded, so it is stored under a key derived from its content
+			v.Blob = []byte(fmt.Sprintf("%s@sha256:%x", digest, sha256.Sum256(data)))
+			if err := blobs.Put(v.Blob, data); err != nil {
 				return err
 			}
 		}
 
-		encoded, err := l.encoder(version{[]byte(digest), annotations})
+		encoded, err := json.Marshal(v)
 		if err != nil {
 			return err
 		}
 
-		id, err := uuid.NewV7()
+		id, err := newVersionID(recordedAt)
 		if err != nil {
 			return err
 		}
@@ -126,16 +204,45 @@ func (l *PhaseLogger[R]) RecordLatest(ctx context.Context, phase core.Descriptor
 	})
 }
 
-func (l *PhaseLogger[R]) isUpToDate(refs kv.Bucket, phase core.Descriptor, digest string) bool {
+// newVersionID returns a new time-ordered (v7) UUID.
+// When recordedAt is non-zero, the UUIDs timestamp is set to it.
+func newVersionID(recordedAt time.Time) (uuid.UUID, error) {
+	id, err := uuid.NewV7()
+	if err != nil || recordedAt.IsZero() {
+		return id, err
+	}
+
+	// the first 48 bits of a v7 UUID contain the unix timestamp in milliseconds
+	ms := uint64(recordedAt.UnixMilli())
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+
+	return id, nil
+}
+
+// isUpToDate returns true when the latest version has the provided digest.
+// When data is non-nil, the annotations and encoded resource must also be unchanged.
+func (l *PhaseLogger[R]) isUpToDate(refs, blobs kv.Bucket, phase core.Descriptor, digest string, annotations map[string]string, data []byte) bool {
 	slog := slog.With("pipeline", phase.Pipeline, "phase", phase.Metadata.Name)
 
 	curLatest, ok := l.getLatestVersion(refs)
-	if ok && bytes.Equal(curLatest.Digest, []byte(digest)) {
-		slog.Debug("skipped recording latest", "reason", "NoChange")
-		return true
+	if !ok || !bytes.Equal(curLatest.Digest, []byte(digest)) {
+		return false
+	}
+
+	if data != nil {
+		if !maps.Equal(curLatest.Annotations, annotations) {
+			return false
+		}
+
+		if blob, err := blobs.Get(curLatest.blobKey()); err != nil || !bytes.Equal(blob, data) {
+			return false
+		}
 	}
 
-	return false
+	slog.Debug("skipped recording latest", "reason", "NoChange")
+	return true
 }
 
 // GetLatestResource returns the state of the latest resource recorded.
@@ -156,12 +263,12 @@ func (l *PhaseLogger[R]) GetLatestResource(ctx context.Context, phase core.Descr
 			return err
 		}
 
-		blob, err := blobs.Get(curLatest.Digest)
+		blob, err := blobs.Get(curLatest.blobKey())
 		if err != nil {
 			return fmt.Errorf("version data for %q: %w", curLatest.Digest, err)
 		}
 
-		return l.decoder(blob, &r)
+		return l.decode(blob, &r)
 	})
 }
 
@@ -189,16 +296,16 @@ func (l *PhaseLogger[R]) GetResourceAtVersion(ctx context.Context, phase core.De
 		}
 
 		var version version
-		if err := l.decoder(versionData, &version); err != nil {
+		if err := json.Unmarshal(versionData, &version); err != nil {
 			return err
 		}
 
-		blob, err := blobs.Get(version.Digest)
+		blob, err := blobs.Get(version.blobKey())
 		if err != nil {
 			return fmt.Errorf("version data for %q: %w", v, err)
 		}
 
-		return l.decoder(blob, &r)
+		return l.decode(blob, &r)
 	})
 }
 
@@ -208,7 +315,7 @@ func (l *PhaseLogger[R]) getLatestVersion(refs kv.Bucket) (v version, _ bool) {
 		return v, false
 	}
 
-	if err := l.decoder(data, &v); err != nil {
+	if err := json.Unmarshal(data, &v); err != nil {
 		return v, false
 	}
 
@@ -249,17 +356,17 @@ func (l *PhaseLogger[R]) History(ctx context.Context, phase core.Descriptor, opt
 			}
 
 			var version version
-			if err := l.decoder(v, &version); err != nil {
+			if err := json.Unmarshal(v, &version); err != nil {
 				return err
 			}
 
-			blob, err := blobs.Get(version.Digest)
+			blob, err := blobs.Get(version.blobKey())
 			if err != nil {
 				return err
 			}
 
 			var r R
-			if err := l.decoder(blob, &r); err != nil {
+			if err := l.decode(blob, &r); err != nil {
 				return err
 			}
 
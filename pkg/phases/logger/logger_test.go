@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/kv/memory"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPhase = core.Descriptor{Kind: "test", Pipeline: "pipeline", Metadata: core.Metadata{Name: "phase"}}
+
+func TestNewVersionID(t *testing.T) {
+	t.Run("now", func(t *testing.T) {
+		before := time.Now().Truncate(time.Millisecond)
+
+		id, err := newVersionID(time.Time{})
+		require.NoError(t, err)
+
+		assert.Equal(t, uuid.Version(7), id.Version())
+		assert.WithinRange(t, versionTime(id), before, time.Now())
+	})
+
+	t.Run("recorded at", func(t *testing.T) {
+		recordedAt := time.Date(2024, time.March, 1, 12, 30, 15, int(123456*time.Microsecond), time.UTC)
+
+		id, err := newVersionID(recordedAt)
+		require.NoError(t, err)
+
+		assert.Equal(t, uuid.Version(7), id.Version())
+		assert.Equal(t, uuid.RFC4122, id.Variant())
+		// the timestamp has millisecond precision
+		assert.Equal(t, recordedAt.Truncate(time.Millisecond), versionTime(id))
+
+		// ids for the same time remain unique
+		other, err := newVersionID(recordedAt)
+		require.NoError(t, err)
+		assert.NotEqual(t, id, other)
+	})
+}
+
+func TestPhaseLogger_RecordLatestAt(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		logger = New[*testResource](memory.New())
+		start  = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	require.NoError(t, logger.CreateLog(ctx, testPhase))
+
+	for i, value := range []string{"one", "two", "two", "three"} {
+		require.NoError(t, logger.RecordLatestAt(ctx, testPhase, &testResource{Value: value}, nil, start.Add(time.Duration(i)*time.Minute)))
+	}
+
+	// versions recorded now follow those recorded in the past
+	require.NoError(t, logger.RecordLatest(ctx, testPhase, &testResource{Value: "four"}, nil))
+
+	history, err := logger.History(ctx, testPhase)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+
+	var digests []string
+	for _, state := range history {
+		digests = append(digests, state.Digest)
+	}
+
+	// unchanged resources are not recorded again
+	assert.Equal(t, []string{"four", "three", "two", "one"}, digests)
+	assert.Equal(t, start.Add(3*time.Minute), history[1].RecordedAt)
+	assert.Equal(t, start.Add(time.Minute), history[2].RecordedAt)
+	assert.Equal(t, start, history[3].RecordedAt)
+}
+
+func TestPhaseLogger_RecordLatestState(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		logger = New[*testResource](memory.New())
+	)
+
+	require.NoError(t, logger.CreateLog(ctx, testPhase))
+
+	for _, record := range []struct {
+		resource    *testResource
+		annotations map[string]string
+	}{
+		{resource: &testResource{Value: "one"}},
+		// unchanged
+		{resource: &testResource{Value: "one"}, annotations: map[string]string{}},
+		// annotations changed
+		{resource: &testResource{Value: "one"}, annotations: map[string]string{"verified": "true"}},
+		// unchanged
+		{resource: &testResource{Value: "one"}, annotations: map[string]string{"verified": "true"}},
+		// encoded state changed
+		{resource: &testResource{Value: "one", Labels: map[string]string{"signed": "true"}}, annotations: map[string]string{"verified": "true"}},
+		// unchanged
+		{resource: &testResource{Value: "one", Labels: map[string]string{"signed": "true"}}, annotations: map[string]string{"verified": "true"}},
+	} {
+		require.NoError(t, logger.RecordLatestState(ctx, testPhase, record.resource, record.annotations))
+	}
+
+	// versions recorded without comparing state are skipped for the same digest
+	require.NoError(t, logger.RecordLatest(ctx, testPhase, &testResource{Value: "one"}, nil))
+
+	history, err := logger.History(ctx, testPhase)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	assert.Equal(t, &testResource{Value: "one", Labels: map[string]string{"signed": "true"}}, history[0].Resource)
+	assert.Equal(t, map[string]string{"verified": "true"}, history[0].Annotations)
+	assert.Equal(t, &testResource{Value: "one"}, history[1].Resource)
+	assert.Equal(t, map[string]string{"verified": "true"}, history[1].Annotations)
+	assert.Equal(t, &testResource{Value: "one"}, history[2].Resource)
+	assert.Empty(t, history[2].Annotations)
+
+	for _, state := range history {
+		assert.Equal(t, "one", state.Digest)
+	}
+
+	latest, err := logger.GetLatestResource(ctx, testPhase)
+	require.NoError(t, err)
+	assert.Equal(t, &testResource{Value: "one", Labels: map[string]string{"signed": "true"}}, latest)
+
+	// earlier versions of the same digest retain their own state
+	resource, err := logger.GetResourceAtVersion(ctx, testPhase, history[1].Version)
+	require.NoError(t, err)
+	assert.Equal(t, &testResource{Value: "one"}, resource)
+}
+
+// versionTime returns the unix timestamp (in milliseconds) stored in a v7 UUID.
+func versionTime(id uuid.UUID) time.Time {
+	var ms int64
+	for _, b := range id[:6] {
+		ms = ms<<8 | int64(b)
+	}
+
+	return time.UnixMilli(ms).UTC()
+}
+
+type testResource struct {
+	Value  string            `json:"value"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func (r *testResource) Digest() (string, error) {
+	return r.Value, nil
+}
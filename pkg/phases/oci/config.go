@@ -0,0 +1,129 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// AnnotationOCIImageName reports the name (registry and repository) of the resolved image.
+	AnnotationOCIImageName = "dev.getglu.oci.image.name"
+	// AnnotationOCIImageTag reports the tag of the resolved image (when resolved by tag).
+	AnnotationOCIImageTag = "dev.getglu.oci.image.tag"
+
+	// wellKnownLabelPrefix is the prefix of the pre-defined
+	// annotation keys from the OCI image specification
+	// (e.g. org.opencontainers.image.revision).
+	wellKnownLabelPrefix = "org.opencontainers.image."
+)
+
+// ResourceFromConfig is a Resource which consumes the image config
+// of the resolved manifest (or the first selected platform of an index).
+type ResourceFromConfig interface {
+	Resource
+	ReadFromOCIConfig(v1.Descriptor, v1.Image) error
+}
+
+// ResourceFromReference is a Resource which consumes the name and tag
+// of the reference resolved by the phase.
+type ResourceFromReference interface {
+	Resource
+	ReadFromOCIReference(name, tag string) error
+}
+
+// WellKnownLabels returns the subset of the provided labels which are pre-defined
+// by the OCI image specification (org.opencontainers.image.*).
+func WellKnownLabels(labels map[string]string) map[string]string {
+	wellKnown := map[string]string{}
+	for k, v := range labels {
+		if strings.HasPrefix(k, wellKnownLabelPrefix) {
+			wellKnown[k] = v
+		}
+	}
+
+	if len(wellKnown) == 0 {
+		return nil
+	}
+
+	return wellKnown
+}
+
+func fetchConfig(ctx context.Context, fetcher content.Fetcher, desc v1.Descriptor) (image v1.Image, _ error) {
+	data, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return image, fmt.Errorf("fetching config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &image); err != nil {
+		return image, fmt.Errorf("decoding config: %w", err)
+	}
+
+	return image, nil
+}
+
+// configFetcher returns the image config described by the provided descriptor.
+type configFetcher func(v1.Descriptor) (v1.Image, error)
+
+// readConfig fetches the config described by the manifest and passes it to the resource.
+func readConfig(fetchConfig configFetcher, r ResourceFromConfig, manifest v1.Manifest) error {
+	image, err := fetchConfig(manifest.Config)
+	if err != nil {
+		return err
+	}
+
+	return r.ReadFromOCIConfig(manifest.Config, image)
+}
+
+// readPlatformLabels fetches the config of each resolved platform and records its well-known labels.
+func readPlatformLabels(fetchConfig configFetcher, platforms []PlatformManifest) error {
+	for i, platform := range platforms {
+		image, err := fetchConfig(platform.manifest.Config)
+		if err != nil {
+			return fmt.Errorf("platform %q: %w", platform.Platform, err)
+		}
+
+		platforms[i].Labels = WellKnownLabels(image.Config.Labels)
+	}
+
+	return nil
+}
+
+// parseNameAndTag returns the name and tag of the provided reference
+// (the tag is empty when the reference is a digest).
+func parseNameAndTag(reference string) (name, tag string, _ error) {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return "", "", err
+	}
+
+	name = ref.Registry + "/" + ref.Repository
+	if ref.ValidateReferenceAsDigest() != nil {
+		tag = ref.ReferenceOrDefault()
+	}
+
+	return name, tag, nil
+}
+
+func imageAnnotations(name, tag string, labels map[string]string) map[string]string {
+	annotations := maps.Clone(labels)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	if name != "" {
+		annotations[AnnotationOCIImageName] = name
+	}
+
+	if tag != "" {
+		annotations[AnnotationOCIImageTag] = tag
+	}
+
+	return annotations
+}
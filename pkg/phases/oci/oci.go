@@ -3,22 +3,35 @@ package oci
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/get-glu/glu/pkg/containers"
 	"github.com/get-glu/glu/pkg/core"
 	"github.com/get-glu/glu/pkg/core/typed"
+	"github.com/get-glu/glu/pkg/edges"
 	"github.com/get-glu/glu/pkg/kv/memory"
 	"github.com/get-glu/glu/pkg/phases/logger"
+	"github.com/get-glu/glu/pkg/phases/oci/verify"
+	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
 )
 
 const ANNOTATION_OCI_IMAGE_URL = "dev.getglu.oci.image.url"
 
-var _ typed.Phase[Resource] = (*Phase[Resource])(nil)
+var (
+	_ typed.Phase[Resource] = (*Phase[Resource])(nil)
+	_ edges.Gate[Resource]  = (*Phase[Resource])(nil)
+)
 
 type Resource interface {
 	core.Resource
@@ -35,6 +48,13 @@ type ResourceFromIndex interface {
 	ReadFromOCIIndex(v1.Descriptor, v1.Index) error
 }
 
+// ResourceFromVerification is a Resource which consumes the result of verifying
+// the signatures and attestations of the resolved digest.
+type ResourceFromVerification interface {
+	Resource
+	ReadFromOCIVerification(*verify.Result) error
+}
+
 type Resolver interface {
 	Resolve(_ context.Context) (v1.Descriptor, io.ReadCloser, error)
 	Reference() string
@@ -47,6 +67,73 @@ type Phase[R Resource] struct {
 	resolver Resolver
 	logger   typed.PhaseLogger[R]
 	interval time.Duration
+	verifier *verify.Verifier
+
+	platforms        []v1.Platform
+	requirePlatforms bool
+
+	mu      sync.Mutex
+	current *resolution
+}
+
+// resolution is the state observed for the most recently resolved descriptor.
+// It is replaced whenever the resolved digest changes, which discards
+// any state observed for previously resolved digests.
+type resolution struct {
+	desc v1.Descriptor
+	// digest is the digest reported by the resource read from desc
+	// which is used to match resources passed to Permit
+	digest       string
+	verification *verify.Result
+	// referrers are listed once per resolution and
+	// only listed again while verification is unsuccessful
+	referrers       []v1.Descriptor
+	referrersListed bool
+	// platforms are resolved once per resolution, where missingPlatforms
+	// are the selected platforms which could not be resolved
+	platforms         []PlatformManifest
+	missingPlatforms  []string
+	platformsResolved bool
+	// configs are the image configs fetched for the resolution by digest
+	configs map[digest.Digest]v1.Image
+}
+
+// WithInterval sets the period between automatic resolutions of the phases reference.
+// An interval of zero disables polling, in which case the phase is only updated
+// via calls to Refresh (e.g. when triggered by a registry webhook).
+func WithInterval[R Resource](interval time.Duration) containers.Option[Phase[R]] {
+	return func(p *Phase[R]) {
+		p.interval = interval
+	}
+}
+
+// WithVerifier configures the phase to verify signatures and attestations
+// for each resolved digest using the provided verifier.
+// Once configured, the phase gates promotions of resources which have not
+// passed verification.
+// The phases resolver must implement content.ReadOnlyGraphStorage.
+func WithVerifier[R Resource](v *verify.Verifier) containers.Option[Phase[R]] {
+	return func(p *Phase[R]) {
+		p.verifier = v
+	}
+}
+
+// WithPlatforms selects the platforms (os/arch/variant) to resolve from the phases reference.
+// When the reference resolves to an image index, the child manifest and config
+// of each selected platform is resolved and reported on the resource.
+// The phases resolver must implement content.Fetcher.
+func WithPlatforms[R Resource](platforms ...v1.Platform) containers.Option[Phase[R]] {
+	return func(p *Phase[R]) {
+		p.platforms = platforms
+	}
+}
+
+// WithRequirePlatforms configures the phase to gate promotions of resources
+// which do not provide every platform selected via WithPlatforms.
+func WithRequirePlatforms[R Resource]() containers.Option[Phase[R]] {
+	return func(p *Phase[R]) {
+		p.requirePlatforms = true
+	}
 }
 
 func New[R Resource](
@@ -83,6 +170,11 @@ func New[R Resource](
 		return nil, err
 	}
 
+	if phase.interval <= 0 {
+		slog.Debug("polling disabled", "type", "oci", "phase", meta.Name)
+		return phase, nil
+	}
+
 	ticker := time.NewTicker(phase.interval)
 	go func() {
 		for {
@@ -113,24 +205,73 @@ func (p *Phase[R]) Get(ctx context.Context) (core.Resource, error) {
 }
 
 func (p *Phase[R]) GetResource(ctx context.Context) (R, error) {
-	return p.logger.GetLatestResource(ctx, p.Descriptor())
+	r, err := p.logger.GetLatestResource(ctx, p.Descriptor())
+	if err != nil {
+		return r, err
+	}
+
+	rr, ok := Resource(r).(ResourceFromReferrers)
+	if !ok {
+		return r, nil
+	}
+
+	dgst, err := r.Digest()
+	if err != nil {
+		return r, err
+	}
+
+	// referrers are often attached some time after the resolved digest
+	// was first recorded, so we decorate the latest resource with the
+	// most recently observed set
+	p.mu.Lock()
+	current := p.current
+	matches := current != nil && current.digest == dgst
+	var referrers []v1.Descriptor
+	if matches {
+		referrers = current.referrers
+	}
+	p.mu.Unlock()
+
+	if matches {
+		if err := rr.ReadFromOCIReferrers(summarizeReferrers(referrers)); err != nil {
+			return r, err
+		}
+	}
+
+	return r, nil
+}
+
+// Reference returns the OCI reference resolved by the phase.
+func (p *Phase[R]) Reference() string {
+	return p.resolver.Reference()
+}
+
+// Refresh immediately resolves the phases reference and records the result.
+func (p *Phase[R]) Refresh(ctx context.Context) error {
+	return p.updateResource(ctx)
 }
 
 func (p *Phase[R]) updateResource(ctx context.Context) error {
-	r, err := p.fetchResource(ctx)
+	r, annotations, err := p.fetchResource(ctx)
 	if err != nil {
 		return err
 	}
 
-	return p.logger.RecordLatest(ctx, p.Descriptor(), r, nil)
+	// the verification and referrers of a digest can change after it is first
+	// recorded, so a new version is recorded whenever they do (where supported)
+	if stateful, ok := p.logger.(typed.StatefulPhaseLogger[R]); ok {
+		return stateful.RecordLatestState(ctx, p.Descriptor(), r, annotations)
+	}
+
+	return p.logger.RecordLatest(ctx, p.Descriptor(), r, annotations)
 }
 
-func (p *Phase[R]) fetchResource(ctx context.Context) (R, error) {
+func (p *Phase[R]) fetchResource(ctx context.Context) (R, map[string]string, error) {
 	r := p.newFn()
 
 	desc, reader, err := p.resolver.Resolve(ctx)
 	if err != nil {
-		return r, err
+		return r, nil, err
 	}
 
 	defer func() {
@@ -139,44 +280,355 @@ func (p *Phase[R]) fetchResource(ctx context.Context) (R, error) {
 		reader.Close()
 	}()
 
+	payload, err := content.ReadAll(reader, desc)
+	if err != nil {
+		return r, nil, err
+	}
+
+	res := p.resolutionFor(desc)
+	annotations, err := p.readResource(ctx, r, res, payload)
+	if err != nil {
+		return r, nil, err
+	}
+
+	dgst, err := r.Digest()
+	if err != nil {
+		return r, nil, err
+	}
+
+	p.mu.Lock()
+	res.digest = dgst
+	p.current = res
+	p.mu.Unlock()
+
+	return r, annotations, nil
+}
+
+// resolutionFor returns the current resolution when it was observed for the
+// same digest as the provided descriptor and otherwise a new resolution.
+func (p *Phase[R]) resolutionFor(desc v1.Descriptor) *resolution {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.current != nil && p.current.desc.Digest == desc.Digest {
+		return p.current
+	}
+
+	return &resolution{desc: desc}
+}
+
+// readResource reads the resolved payload and any associated content into the resource
+// and returns the annotations to record alongside it.
+func (p *Phase[R]) readResource(ctx context.Context, r R, res *resolution, payload []byte) (map[string]string, error) {
+	desc := res.desc
+	if rr, ok := Resource(r).(ResourceFromReference); ok {
+		name, tag, err := parseNameAndTag(p.resolver.Reference())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := rr.ReadFromOCIReference(name, tag); err != nil {
+			return nil, err
+		}
+	}
+
+	annotations := map[string]string{}
+	if p.verifier != nil {
+		result, err := p.verify(ctx, res)
+		if err != nil {
+			return nil, err
+		}
+
+		// resources which consume the result are expected to report it
+		// via their own annotations, otherwise we record it alongside them
+		if rv, ok := Resource(r).(ResourceFromVerification); ok {
+			if err := rv.ReadFromOCIVerification(result); err != nil {
+				return nil, err
+			}
+		} else {
+			maps.Insert(annotations, maps.All(result.Annotations()))
+		}
+	}
+
+	if store, ok := p.resolver.(content.ReadOnlyGraphStorage); ok {
+		descs, err := p.listReferrers(ctx, store, res, false)
+		if err != nil {
+			// not all registries support discovering referrers
+			// so we continue without them
+			slog.Warn("discovering referrers", "type", "oci", "phase", p.meta.Name, "error", err)
+		}
+
+		referrers := summarizeReferrers(descs)
+		maps.Insert(annotations, maps.All(ReferrerAnnotations(referrers)))
+
+		if rr, ok := Resource(r).(ResourceFromReferrers); ok {
+			if err := rr.ReadFromOCIReferrers(referrers); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	switch desc.MediaType {
 	case v1.MediaTypeImageIndex:
+		var index v1.Index
+		if err := json.Unmarshal(payload, &index); err != nil {
+			return nil, err
+		}
+
+		if len(p.platforms) > 0 {
+			if err := p.readPlatforms(ctx, r, res, annotations, func(fetcher content.Fetcher, _ configFetcher) ([]PlatformManifest, []string, error) {
+				return resolvePlatforms(ctx, fetcher, index, p.platforms)
+			}); err != nil {
+				return nil, err
+			}
+		}
+
 		ri, ok := Resource(r).(ResourceFromIndex)
 		if !ok {
 			break
 		}
 
-		var index v1.Index
-		if err := json.NewDecoder(reader).Decode(&index); err != nil {
-			return r, err
+		return annotations, ri.ReadFromOCIIndex(desc, index)
+	case v1.MediaTypeImageManifest:
+		var manifest v1.Manifest
+		if err := json.Unmarshal(payload, &manifest); err != nil {
+			return nil, err
+		}
+
+		if len(p.platforms) > 0 {
+			if err := p.readPlatforms(ctx, r, res, annotations, func(_ content.Fetcher, fetchConfig configFetcher) ([]PlatformManifest, []string, error) {
+				return resolveManifestPlatform(fetchConfig, desc, manifest, p.platforms)
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if rc, ok := Resource(r).(ResourceFromConfig); ok {
+			if fetcher, ok := p.resolver.(content.Fetcher); ok {
+				if err := readConfig(p.configFetcher(ctx, fetcher, res), rc, manifest); err != nil {
+					return nil, err
+				}
+			} else {
+				slog.Debug("skipping image config", "type", "oci", "phase", p.meta.Name, "reason", "resolver does not support fetching content")
+			}
 		}
 
-		return r, ri.ReadFromOCIIndex(desc, index)
-	case v1.MediaTypeImageManifest:
 		rm, ok := Resource(r).(ResourceFromManifest)
 		if !ok {
 			break
 		}
 
-		var manifest v1.Manifest
-		if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
-			return r, err
+		return annotations, rm.ReadFromOCIManifest(desc, manifest)
+	default:
+	}
+
+	if err := json.Unmarshal(payload, &desc); err != nil {
+		return nil, err
+	}
+
+	return annotations, r.ReadFromOCIDescriptor(desc)
+}
+
+// readPlatforms resolves the selected platforms using the provided function, records any missing
+// platforms for the resolution (in order to gate promotions) and passes the results to the resource.
+// Platforms are only resolved once per resolution and are otherwise read from the previous result.
+func (p *Phase[R]) readPlatforms(ctx context.Context, r R, res *resolution, annotations map[string]string, resolve func(content.Fetcher, configFetcher) ([]PlatformManifest, []string, error)) error {
+	fetcher, ok := p.resolver.(content.Fetcher)
+	if !ok {
+		return errors.New("platform selection requires a resolver which supports fetching content")
+	}
+
+	fetchConfig := p.configFetcher(ctx, fetcher, res)
+
+	p.mu.Lock()
+	platforms, missing, resolved := res.platforms, res.missingPlatforms, res.platformsResolved
+	p.mu.Unlock()
+
+	if !resolved {
+		var err error
+		if platforms, missing, err = resolve(fetcher, fetchConfig); err != nil {
+			return err
 		}
 
-		return r, rm.ReadFromOCIManifest(desc, manifest)
-	default:
+		if err := readPlatformLabels(fetchConfig, platforms); err != nil {
+			return err
+		}
+
+		p.mu.Lock()
+		res.platforms, res.missingPlatforms, res.platformsResolved = platforms, missing, true
+		p.mu.Unlock()
+	}
+
+	// single manifests read their own config directly, while indexes
+	// are represented by the config of the first selected platform
+	if rc, ok := Resource(r).(ResourceFromConfig); ok && len(platforms) > 0 && res.desc.MediaType == v1.MediaTypeImageIndex {
+		if err := readConfig(fetchConfig, rc, platforms[0].manifest); err != nil {
+			return err
+		}
+	}
+
+	maps.Insert(annotations, maps.All(PlatformAnnotations(platforms, missing)))
+
+	if rp, ok := Resource(r).(ResourceFromPlatforms); ok {
+		return rp.ReadFromOCIPlatforms(slices.Clone(platforms))
+	}
+
+	return nil
+}
+
+// configFetcher returns a configFetcher which fetches each image config
+// at most once for the lifetime of the resolution.
+func (p *Phase[R]) configFetcher(ctx context.Context, fetcher content.Fetcher, res *resolution) configFetcher {
+	return func(desc v1.Descriptor) (v1.Image, error) {
+		p.mu.Lock()
+		image, ok := res.configs[desc.Digest]
+		p.mu.Unlock()
+
+		if ok {
+			return image, nil
+		}
+
+		image, err := fetchConfig(ctx, fetcher, desc)
+		if err != nil {
+			return image, err
+		}
+
+		p.mu.Lock()
+		if res.configs == nil {
+			res.configs = map[digest.Digest]v1.Image{}
+		}
+
+		res.configs[desc.Digest] = image
+		p.mu.Unlock()
+
+		return image, nil
+	}
+}
+
+// verify returns the verification result for the provided resolution.
+// Successful results are kept for the lifetime of the resolution, while unsuccessful
+// results are re-evaluated on each call as signatures are often attached after push.
+func (p *Phase[R]) verify(ctx context.Context, res *resolution) (*verify.Result, error) {
+	p.mu.Lock()
+	result := res.verification
+	p.mu.Unlock()
+
+	if result != nil && result.Verified {
+		return result, nil
+	}
+
+	desc := res.desc
+
+	store, ok := p.resolver.(content.ReadOnlyGraphStorage)
+	if !ok {
+		return nil, errors.New("verification requires a resolver which supports referrers")
 	}
 
-	rest, err := content.ReadAll(reader, desc)
+	// signatures may have been attached since the referrers were last listed
+	referrers, err := p.listReferrers(ctx, store, res, true)
 	if err != nil {
-		return r, err
+		return nil, fmt.Errorf("verifying %q: %w", desc.Digest, err)
 	}
 
-	if err := json.Unmarshal(rest, &desc); err != nil {
-		return r, err
+	result, err = p.verifier.VerifyReferrers(ctx, store, desc, referrers)
+	if err != nil {
+		return nil, fmt.Errorf("verifying %q: %w", desc.Digest, err)
+	}
+
+	p.mu.Lock()
+	res.verification = result
+	p.mu.Unlock()
+
+	return result, nil
+}
+
+// listReferrers returns the referrers of the resolved descriptor.
+// They are listed from the store on first use for the resolution (or when refresh is true)
+// and are otherwise returned from the previous listing.
+func (p *Phase[R]) listReferrers(ctx context.Context, store content.ReadOnlyGraphStorage, res *resolution, refresh bool) ([]v1.Descriptor, error) {
+	p.mu.Lock()
+	referrers, listed := res.referrers, res.referrersListed
+	p.mu.Unlock()
+
+	if listed && !refresh {
+		return referrers, nil
+	}
+
+	referrers, err := registry.Referrers(ctx, store, res.desc, "")
+	if err != nil {
+		return nil, fmt.Errorf("listing referrers: %w", err)
+	}
+
+	p.mu.Lock()
+	res.referrers, res.referrersListed = referrers, true
+	p.mu.Unlock()
+
+	return referrers, nil
+}
+
+// Permit implements edges.Gate and prevents the promotion of resources
+// which have not passed verification (given a verifier has been configured)
+// or which are missing required platforms (given platforms are required).
+func (p *Phase[R]) Permit(ctx context.Context, r R) error {
+	dgst, err := r.Digest()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	current := p.current
+	p.mu.Unlock()
+
+	// only the most recently resolved digest is eligible for promotion
+	resolved := current != nil && current.digest == dgst
+
+	if err := p.permitPlatforms(dgst, current, resolved); err != nil {
+		return err
+	}
+
+	if p.verifier == nil {
+		return nil
+	}
+
+	if !resolved {
+		return fmt.Errorf("digest %q: has not been resolved: %w", dgst, verify.ErrNotVerified)
+	}
+
+	// attempt to re-verify the current descriptor as it may
+	// have been signed since we last observed it
+	result, err := p.verify(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	if !result.Verified {
+		return fmt.Errorf("digest %q: %s: %w", dgst, result.Reason, verify.ErrNotVerified)
+	}
+
+	return nil
+}
+
+func (p *Phase[R]) permitPlatforms(dgst string, current *resolution, resolved bool) error {
+	if !p.requirePlatforms || len(p.platforms) == 0 {
+		return nil
+	}
+
+	var missing []string
+	if resolved {
+		p.mu.Lock()
+		missing, resolved = current.missingPlatforms, current.platformsResolved
+		p.mu.Unlock()
+	}
+
+	if !resolved {
+		return fmt.Errorf("digest %q: platforms have not been resolved: %w", dgst, ErrMissingPlatforms)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("digest %q: %s: %w", dgst, strings.Join(missing, ","), ErrMissingPlatforms)
 	}
 
-	return r, r.ReadFromOCIDescriptor(desc)
+	return nil
 }
 
 func (p *Phase[A]) History(ctx context.Context, opts ...containers.Option[core.HistoryOptions]) ([]core.State, error) {
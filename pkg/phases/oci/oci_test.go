@@ -0,0 +1,523 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/get-glu/glu/internal/oci/platform"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/phases/oci/verify"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+const testTag = "latest"
+
+// resolver resolves a tag from an in-memory store which supports
+// fetching content and discovering referrers.
+type resolver struct {
+	*memory.Store
+
+	predecessors int
+	fetches      map[digest.Digest]int
+}
+
+// Fetch counts the calls made to fetch each piece of content.
+func (r *resolver) Fetch(ctx context.Context, target v1.Descriptor) (io.ReadCloser, error) {
+	r.fetches[target.Digest]++
+	return r.Store.Fetch(ctx, target)
+}
+
+// Predecessors counts the calls made to list the referrers of a subject.
+func (r *resolver) Predecessors(ctx context.Context, node v1.Descriptor) ([]v1.Descriptor, error) {
+	r.predecessors++
+	return r.Store.Predecessors(ctx, node)
+}
+
+func newResolver() *resolver {
+	return &resolver{Store: memory.New(), fetches: map[digest.Digest]int{}}
+}
+
+func (r *resolver) Resolve(ctx context.Context) (v1.Descriptor, io.ReadCloser, error) {
+	desc, err := r.Store.Resolve(ctx, testTag)
+	if err != nil {
+		return desc, nil, err
+	}
+
+	rc, err := r.Store.Fetch(ctx, desc)
+	return desc, rc, err
+}
+
+func (r *resolver) Reference() string {
+	return "registry.local/app:" + testTag
+}
+
+// descriptorResource is a minimal resource which only consumes the resolved descriptor.
+type descriptorResource struct {
+	Desc v1.Descriptor
+}
+
+func (r *descriptorResource) Digest() (string, error) {
+	return r.Desc.Digest.Encoded(), nil
+}
+
+func (r *descriptorResource) ReadFromOCIDescriptor(desc v1.Descriptor) error {
+	r.Desc = desc
+	return nil
+}
+
+func TestPhase_Permit_Verification(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		key   = generateKey(t)
+		first = pushImage(t, store, "first")
+	)
+
+	phase := newPhase(t, store, func() *BaseResource { return &BaseResource{} }, WithVerifier[*BaseResource](newVerifier(t, key)))
+
+	r, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	require.Equal(t, first.Digest, r.ImageDigest)
+
+	// unsigned digests are not permitted
+	require.ErrorIs(t, phase.Permit(ctx, r), verify.ErrNotVerified)
+
+	// signatures attached after resolution are observed by permit
+	pushCosignSignature(t, store, first, key)
+	require.NoError(t, phase.Permit(ctx, r))
+
+	// once a new digest is resolved the previous one is no longer permitted
+	second := pushImage(t, store, "second")
+	pushCosignSignature(t, store, second, key)
+	require.NoError(t, phase.Refresh(ctx))
+
+	require.ErrorIs(t, phase.Permit(ctx, r), verify.ErrNotVerified)
+
+	latest, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	require.Equal(t, second.Digest, latest.ImageDigest)
+	require.NoError(t, phase.Permit(ctx, latest))
+
+	// only the state for the current digest is retained
+	assert.Equal(t, second.Digest, phase.current.desc.Digest)
+
+	// resources which consume the verification report it via their own annotations
+	assert.Equal(t, "true", latest.Annotations()[verify.AnnotationVerified])
+	history, err := phase.History(ctx)
+	require.NoError(t, err)
+	for _, state := range history {
+		assert.NotContains(t, state.Annotations, verify.AnnotationVerified)
+	}
+}
+
+func TestPhase_Annotations_Verification(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		key   = generateKey(t)
+	)
+
+	pushCosignSignature(t, store, pushImage(t, store, "first"), key)
+
+	phase := newPhase(t, store, func() *descriptorResource { return &descriptorResource{} }, WithVerifier[*descriptorResource](newVerifier(t, key)))
+
+	// resources which do not consume the verification have it recorded alongside them
+	history, err := phase.History(ctx)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "true", history[0].Annotations[verify.AnnotationVerified])
+}
+
+func TestPhase_History_Verification(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		key   = generateKey(t)
+		first = pushImage(t, store, "first")
+	)
+
+	phase := newPhase(t, store, func() *BaseResource { return &BaseResource{} }, WithVerifier[*BaseResource](newVerifier(t, key)))
+
+	// refreshing an unchanged digest does not record a new version
+	require.NoError(t, phase.Refresh(ctx))
+
+	history, err := phase.History(ctx)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	// signing the same digest records a new version reflecting the verification
+	pushCosignSignature(t, store, first, key)
+	require.NoError(t, phase.Refresh(ctx))
+	require.NoError(t, phase.Refresh(ctx))
+
+	history, err = phase.History(ctx)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	for i, verified := range []string{"true", "false"} {
+		assert.Equal(t, first.Digest.Encoded(), history[i].Digest)
+		assert.Equal(t, verified, history[i].Resource.(*BaseResource).Annotations()[verify.AnnotationVerified])
+	}
+
+	latest, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "true", latest.Annotations()[verify.AnnotationVerified])
+	require.Len(t, latest.Referrers, 1)
+}
+
+func TestPhase_History_Referrers(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		key   = generateKey(t)
+		first = pushImage(t, store, "first")
+	)
+
+	// resources which do not consume the state have it recorded via annotations
+	phase := newPhase(t, store, func() *descriptorResource { return &descriptorResource{} }, WithVerifier[*descriptorResource](newVerifier(t, key)))
+
+	pushCosignSignature(t, store, first, key)
+	require.NoError(t, phase.Refresh(ctx))
+
+	history, err := phase.History(ctx)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	assert.Equal(t, "true", history[0].Annotations[verify.AnnotationVerified])
+	assert.Contains(t, history[0].Annotations, AnnotationOCIReferrersPrefix+string(ReferrerKindSignature))
+	assert.Equal(t, "false", history[1].Annotations[verify.AnnotationVerified])
+	assert.NotContains(t, history[1].Annotations, AnnotationOCIReferrersPrefix+string(ReferrerKindSignature))
+}
+
+func TestPhase_Referrers(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		first = pushImage(t, store, "first")
+	)
+
+	pushReferrer(t, store, first, "application/spdx+json", pushBlob(t, store, "application/spdx+json", []byte("sbom"), nil))
+
+	phase := newPhase(t, store, func() *BaseResource { return &BaseResource{} })
+	assert.Equal(t, 1, store.predecessors)
+
+	r, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	require.Len(t, r.Referrers, 1)
+	assert.Equal(t, ReferrerKindSBOM, r.Referrers[0].Kind)
+
+	// referrers are not listed again while the digest is unchanged
+	require.NoError(t, phase.Refresh(ctx))
+	require.NoError(t, phase.Refresh(ctx))
+	assert.Equal(t, 1, store.predecessors)
+
+	// they are listed once for each newly resolved digest
+	second := pushImage(t, store, "second")
+	require.NoError(t, phase.Refresh(ctx))
+	assert.Equal(t, 2, store.predecessors)
+
+	r, err = phase.GetResource(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second.Digest, r.ImageDigest)
+	assert.Empty(t, r.Referrers)
+}
+
+func TestPhase_Referrers_Verification(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		key   = generateKey(t)
+		first = pushImage(t, store, "first")
+	)
+
+	// verification and referrer discovery share a single listing
+	phase := newPhase(t, store, func() *BaseResource { return &BaseResource{} }, WithVerifier[*BaseResource](newVerifier(t, key)))
+	assert.Equal(t, 1, store.predecessors)
+
+	// unverified digests are listed again as they may be signed later
+	pushCosignSignature(t, store, first, key)
+	require.NoError(t, phase.Refresh(ctx))
+	assert.Equal(t, 2, store.predecessors)
+
+	r, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	require.NoError(t, phase.Permit(ctx, r))
+	require.Len(t, r.Referrers, 1)
+	assert.Equal(t, ReferrerKindSignature, r.Referrers[0].Kind)
+
+	// once verified the listing is retained
+	require.NoError(t, phase.Refresh(ctx))
+	require.NoError(t, phase.Permit(ctx, r))
+	assert.Equal(t, 2, store.predecessors)
+}
+
+func TestPhase_Permit_Platforms(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		amd64 = v1.Platform{OS: "linux", Architecture: "amd64"}
+		arm64 = v1.Platform{OS: "linux", Architecture: "arm64"}
+	)
+
+	pushIndex(t, store, amd64)
+
+	phase := newPhase(t, store, func() *BaseResource { return &BaseResource{} },
+		WithPlatforms[*BaseResource](amd64, arm64),
+		WithRequirePlatforms[*BaseResource](),
+	)
+
+	r, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	require.Len(t, r.Platforms, 1)
+	assert.Equal(t, "linux/amd64", r.Platforms[0].Platform)
+	assert.Equal(t, map[string]string{"org.opencontainers.image.revision": "linux/amd64"}, r.Platforms[0].Labels)
+
+	err = phase.Permit(ctx, r)
+	require.ErrorIs(t, err, ErrMissingPlatforms)
+	assert.Contains(t, err.Error(), "linux/arm64")
+
+	history, err := phase.History(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "linux/arm64", history[0].Annotations[AnnotationOCIPlatformsMissing])
+
+	// once a digest providing every platform is resolved it is permitted
+	// and the previous digest is no longer eligible for promotion
+	pushIndex(t, store, amd64, arm64)
+	require.NoError(t, phase.Refresh(ctx))
+
+	latest, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	require.Len(t, latest.Platforms, 2)
+	require.NoError(t, phase.Permit(ctx, latest))
+	require.ErrorIs(t, phase.Permit(ctx, r), ErrMissingPlatforms)
+
+	// only the state for the current digest is retained
+	assert.Empty(t, phase.current.missingPlatforms)
+}
+
+func TestPhase_Config(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		amd64 = v1.Platform{OS: "linux", Architecture: "amd64"}
+		arm64 = v1.Platform{OS: "linux", Architecture: "arm64"}
+	)
+
+	desc := pushImage(t, store, "first")
+	manifest, err := fetchManifest(ctx, store.Store, desc)
+	require.NoError(t, err)
+
+	// the config is used to resolve the platform, the platform labels and the resource
+	// labels, but is only fetched once for the resolved digest
+	phase := newPhase(t, store, func() *BaseResource { return &BaseResource{} }, WithPlatforms[*BaseResource](amd64))
+	assert.Equal(t, 1, store.fetches[manifest.Config.Digest])
+
+	r, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"org.opencontainers.image.revision": "first"}, r.Labels)
+	assert.Empty(t, r.Platforms)
+
+	require.NoError(t, phase.Refresh(ctx))
+	assert.Equal(t, 1, store.fetches[manifest.Config.Digest])
+
+	// indexes fetch each selected platforms config once
+	index := pushIndex(t, store, amd64, arm64)
+	phase = newPhase(t, store, func() *BaseResource { return &BaseResource{} }, WithPlatforms[*BaseResource](amd64, arm64))
+	require.NoError(t, phase.Refresh(ctx))
+
+	r, err = phase.GetResource(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, index.Digest, r.ImageDigest)
+	assert.Equal(t, map[string]string{"org.opencontainers.image.revision": "linux/amd64"}, r.Labels)
+	require.Len(t, r.Platforms, 2)
+
+	for _, platform := range r.Platforms {
+		assert.Equal(t, 1, store.fetches[platform.Config], platform.Platform)
+	}
+}
+
+// resolveOnly is a resolver which does not support fetching content.
+type resolveOnly struct {
+	resolver *resolver
+}
+
+func (r resolveOnly) Resolve(ctx context.Context) (v1.Descriptor, io.ReadCloser, error) {
+	return r.resolver.Resolve(ctx)
+}
+
+func (r resolveOnly) Reference() string {
+	return r.resolver.Reference()
+}
+
+func TestPhase_Config_WithoutFetcher(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = newResolver()
+		desc  = pushImage(t, store, "first")
+	)
+
+	// the image config is skipped when the resolver cannot fetch it
+	phase := newPhase(t, resolveOnly{store}, func() *BaseResource { return &BaseResource{} })
+
+	r, err := phase.GetResource(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, desc.Digest, r.ImageDigest)
+	assert.Empty(t, r.Labels)
+}
+
+func newPhase[R Resource](t *testing.T, resolver Resolver, newFn func() R, opts ...containers.Option[Phase[R]]) *Phase[R] {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	phase, err := New(ctx, "pipeline", core.Metadata{Name: "phase"}, newFn, resolver, append([]containers.Option[Phase[R]]{WithInterval[R](0)}, opts...)...)
+	require.NoError(t, err)
+
+	return phase
+}
+
+func newVerifier(t *testing.T, key *ecdsa.PrivateKey) *verify.Verifier {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	pub, err := verify.ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	return verify.New(verify.WithPublicKeys(pub))
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func pushBlob(t *testing.T, store *resolver, mediaType string, data []byte, annotations map[string]string) v1.Descriptor {
+	t.Helper()
+
+	desc := content.NewDescriptorFromBytes(mediaType, data)
+	desc.Annotations = annotations
+
+	if exists, err := store.Exists(context.Background(), desc); err == nil && exists {
+		return desc
+	}
+
+	require.NoError(t, store.Push(context.Background(), desc, bytes.NewReader(data)))
+
+	return desc
+}
+
+// pushImage pushes a single layer image with the provided layer contents
+// and tags it as the reference resolved by the phase.
+func pushImage(t *testing.T, store *resolver, contents string) v1.Descriptor {
+	t.Helper()
+
+	config, err := json.Marshal(v1.Image{
+		Platform: v1.Platform{OS: "linux", Architecture: "arm64"},
+		Config: v1.ImageConfig{Labels: map[string]string{
+			"org.opencontainers.image.revision": contents,
+		}},
+	})
+	require.NoError(t, err)
+
+	desc, err := oras.PackManifest(context.Background(), store, oras.PackManifestVersion1_1, "", oras.PackManifestOptions{
+		ConfigDescriptor: ptr(pushBlob(t, store, v1.MediaTypeImageConfig, config, nil)),
+		Layers:           []v1.Descriptor{pushBlob(t, store, v1.MediaTypeImageLayer, []byte(contents), nil)},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Tag(context.Background(), desc, testTag))
+
+	return desc
+}
+
+func pushIndex(t *testing.T, store *resolver, platforms ...v1.Platform) v1.Descriptor {
+	t.Helper()
+
+	index := v1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: v1.MediaTypeImageIndex,
+	}
+
+	for _, p := range platforms {
+		config, err := json.Marshal(v1.Image{
+			Platform: p,
+			Config: v1.ImageConfig{Labels: map[string]string{
+				"org.opencontainers.image.revision": platform.String(p),
+				"other":                             "label",
+			}},
+		})
+		require.NoError(t, err)
+
+		manifest, err := oras.PackManifest(context.Background(), store, oras.PackManifestVersion1_1, "", oras.PackManifestOptions{
+			ConfigDescriptor: ptr(pushBlob(t, store, v1.MediaTypeImageConfig, config, nil)),
+			Layers:           []v1.Descriptor{pushBlob(t, store, v1.MediaTypeImageLayer, []byte(platform.String(p)), nil)},
+		})
+		require.NoError(t, err)
+
+		manifest.Platform = &p
+		index.Manifests = append(index.Manifests, manifest)
+	}
+
+	data, err := json.Marshal(index)
+	require.NoError(t, err)
+
+	desc := pushBlob(t, store, v1.MediaTypeImageIndex, data, nil)
+	require.NoError(t, store.Tag(context.Background(), desc, testTag))
+
+	return desc
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func pushReferrer(t *testing.T, store *resolver, subject v1.Descriptor, artifactType string, layer v1.Descriptor) {
+	t.Helper()
+
+	_, err := oras.PackManifest(context.Background(), store, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Subject: &subject,
+		Layers:  []v1.Descriptor{layer},
+	})
+	require.NoError(t, err)
+}
+
+func pushCosignSignature(t *testing.T, store *resolver, subject v1.Descriptor, key *ecdsa.PrivateKey) {
+	t.Helper()
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"registry.local/app"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"}}`, subject.Digest))
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	require.NoError(t, err)
+
+	layer := pushBlob(t, store, verify.MediaTypeCosignSimpleSigning, payload, map[string]string{
+		verify.AnnotationCosignSignature: base64.StdEncoding.EncodeToString(sig),
+	})
+
+	pushReferrer(t, store, subject, "application/vnd.dev.cosign.artifact.sig.v1+json", layer)
+}
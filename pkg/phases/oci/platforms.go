@@ -0,0 +1,141 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/get-glu/glu/internal/oci/platform"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+const (
+	// AnnotationOCIPlatformsPrefix prefixes the annotations which report the manifest
+	// digest resolved for each selected platform (e.g. dev.getglu.oci.platforms.linux/amd64).
+	AnnotationOCIPlatformsPrefix = "dev.getglu.oci.platforms."
+	// AnnotationOCIPlatformsMissing reports a comma separated list of the selected
+	// platforms which could not be resolved.
+	AnnotationOCIPlatformsMissing = "dev.getglu.oci.platforms.missing"
+)
+
+// ErrMissingPlatforms is returned when a resource does not provide all required platforms.
+var ErrMissingPlatforms = errors.New("missing required platforms")
+
+// PlatformManifest describes the manifest (and its config) resolved
+// for a single platform of a (potentially multi-arch) image.
+type PlatformManifest struct {
+	Platform string        `json:"platform"`
+	Digest   digest.Digest `json:"digest"`
+	Config   digest.Digest `json:"config,omitempty"`
+	// Labels are the well-known labels from the platforms image config.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	manifest v1.Manifest
+}
+
+// ResourceFromPlatforms is a Resource which consumes the manifests resolved
+// for each of the platforms selected on the phase.
+type ResourceFromPlatforms interface {
+	Resource
+	ReadFromOCIPlatforms([]PlatformManifest) error
+}
+
+// PlatformAnnotations reports the resolved digest for each platform
+// along with any platforms which could not be resolved.
+func PlatformAnnotations(platforms []PlatformManifest, missing []string) map[string]string {
+	annotations := map[string]string{}
+	for _, p := range platforms {
+		annotations[AnnotationOCIPlatformsPrefix+p.Platform] = p.Digest.String()
+	}
+
+	if len(missing) > 0 {
+		annotations[AnnotationOCIPlatformsMissing] = strings.Join(missing, ",")
+	}
+
+	return annotations
+}
+
+// platformMatches returns true if the candidate satisfies the selector.
+// The variant is only considered when the selector specifies one.
+func platformMatches(selector v1.Platform, candidate *v1.Platform) bool {
+	if candidate == nil {
+		return false
+	}
+
+	return candidate.OS == selector.OS &&
+		candidate.Architecture == selector.Architecture &&
+		(selector.Variant == "" || candidate.Variant == selector.Variant)
+}
+
+// resolvePlatforms resolves the child manifest of the index for each of the selected platforms.
+// It returns the resolved manifests along with the selected platforms which could not be found.
+func resolvePlatforms(ctx context.Context, fetcher content.Fetcher, index v1.Index, selected []v1.Platform) (resolved []PlatformManifest, missing []string, _ error) {
+	for _, selector := range selected {
+		i := slices.IndexFunc(index.Manifests, func(desc v1.Descriptor) bool {
+			return platformMatches(selector, desc.Platform)
+		})
+
+		if i < 0 {
+			missing = append(missing, platform.String(selector))
+			continue
+		}
+
+		desc := index.Manifests[i]
+		manifest, err := fetchManifest(ctx, fetcher, desc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("platform %q: %w", platform.String(selector), err)
+		}
+
+		resolved = append(resolved, PlatformManifest{
+			Platform: platform.String(selector),
+			Digest:   desc.Digest,
+			Config:   manifest.Config.Digest,
+			manifest: manifest,
+		})
+	}
+
+	return resolved, missing, nil
+}
+
+func fetchManifest(ctx context.Context, fetcher content.Fetcher, desc v1.Descriptor) (manifest v1.Manifest, _ error) {
+	data, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return manifest, fmt.Errorf("fetching manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// resolveManifestPlatform determines which of the selected platforms are provided by a
+// single (non-index) manifest by consulting the platform described in its image config.
+func resolveManifestPlatform(fetchConfig configFetcher, desc v1.Descriptor, manifest v1.Manifest, selected []v1.Platform) (resolved []PlatformManifest, missing []string, _ error) {
+	image, err := fetchConfig(manifest.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, selector := range selected {
+		if !platformMatches(selector, &image.Platform) {
+			missing = append(missing, platform.String(selector))
+			continue
+		}
+
+		resolved = append(resolved, PlatformManifest{
+			Platform: platform.String(selector),
+			Digest:   desc.Digest,
+			Config:   manifest.Config.Digest,
+			manifest: manifest,
+		})
+	}
+
+	return resolved, missing, nil
+}
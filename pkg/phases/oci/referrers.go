@@ -0,0 +1,94 @@
+package oci
+
+import (
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ReferrerKind classifies a referrer artifact by its purpose.
+type ReferrerKind string
+
+const (
+	ReferrerKindSBOM              = ReferrerKind("sbom")
+	ReferrerKindVulnerabilityScan = ReferrerKind("vulnerability-scan")
+	ReferrerKindProvenance        = ReferrerKind("provenance")
+	ReferrerKindAttestation       = ReferrerKind("attestation")
+	ReferrerKindSignature         = ReferrerKind("signature")
+	ReferrerKindOther             = ReferrerKind("other")
+)
+
+// AnnotationOCIReferrersPrefix prefixes the annotations which summarize
+// the referrers of a resource by kind (e.g. dev.getglu.oci.referrers.sbom).
+const AnnotationOCIReferrersPrefix = "dev.getglu.oci.referrers."
+
+// Referrer is a summary of an artifact (SBOM, scan report, provenance etc.)
+// which references the resolved digest of a phase.
+type Referrer struct {
+	Kind         ReferrerKind      `json:"kind"`
+	ArtifactType string            `json:"artifact_type,omitempty"`
+	MediaType    string            `json:"media_type,omitempty"`
+	Digest       digest.Digest     `json:"digest"`
+	Size         int64             `json:"size,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// ResourceFromReferrers is a Resource which consumes the summaries
+// of artifacts referencing the resolved digest.
+type ResourceFromReferrers interface {
+	Resource
+	ReadFromOCIReferrers([]Referrer) error
+}
+
+// ReferrerAnnotations summarizes the provided referrers as a set of annotations
+// with a key per kind and a comma separated list of digests as the value.
+func ReferrerAnnotations(referrers []Referrer) map[string]string {
+	annotations := map[string]string{}
+	for _, referrer := range referrers {
+		key := AnnotationOCIReferrersPrefix + string(referrer.Kind)
+		if existing, ok := annotations[key]; ok {
+			annotations[key] = existing + "," + referrer.Digest.String()
+			continue
+		}
+
+		annotations[key] = referrer.Digest.String()
+	}
+
+	return annotations
+}
+
+// summarizeReferrers summarizes the descriptors of the referrers of a subject.
+func summarizeReferrers(descs []v1.Descriptor) []Referrer {
+	referrers := make([]Referrer, 0, len(descs))
+	for _, desc := range descs {
+		referrers = append(referrers, Referrer{
+			Kind:         referrerKind(desc.ArtifactType),
+			ArtifactType: desc.ArtifactType,
+			MediaType:    desc.MediaType,
+			Digest:       desc.Digest,
+			Size:         desc.Size,
+			Annotations:  desc.Annotations,
+		})
+	}
+
+	return referrers
+}
+
+func referrerKind(artifactType string) ReferrerKind {
+	t := strings.ToLower(artifactType)
+	switch {
+	case strings.Contains(t, "spdx"), strings.Contains(t, "cyclonedx"), strings.Contains(t, "syft"), strings.Contains(t, "sbom"):
+		return ReferrerKindSBOM
+	case strings.Contains(t, "sarif"), strings.Contains(t, "vuln"), strings.Contains(t, "trivy"), strings.Contains(t, "grype"):
+		return ReferrerKindVulnerabilityScan
+	case strings.Contains(t, "slsa"), strings.Contains(t, "provenance"):
+		return ReferrerKindProvenance
+	case strings.Contains(t, "in-toto"), strings.Contains(t, "dsse"), strings.Contains(t, "cosign.artifact.att"):
+		return ReferrerKindAttestation
+	case strings.Contains(t, "cosign.artifact.sig"), strings.Contains(t, "notary.signature"):
+		return ReferrerKindSignature
+	default:
+		return ReferrerKindOther
+	}
+}
@@ -1,19 +1,34 @@
 package oci
 
 import (
+	"maps"
+
+	"github.com/get-glu/glu/pkg/phases/oci/verify"
 	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 var (
-	_ ResourceFromIndex    = (*BaseResource)(nil)
-	_ ResourceFromManifest = (*BaseResource)(nil)
+	_ ResourceFromIndex        = (*BaseResource)(nil)
+	_ ResourceFromManifest     = (*BaseResource)(nil)
+	_ ResourceFromVerification = (*BaseResource)(nil)
+	_ ResourceFromReferrers    = (*BaseResource)(nil)
+	_ ResourceFromPlatforms    = (*BaseResource)(nil)
+	_ ResourceFromConfig       = (*BaseResource)(nil)
+	_ ResourceFromReference    = (*BaseResource)(nil)
 )
 
 type BaseResource struct {
-	// ImageName   string // TODO: add this when we have a use case for it
-	ImageDigest digest.Digest `json:"image_digest,omitempty"`
-	annotations map[string]string
+	ImageName    string             `json:"image_name,omitempty"`
+	ImageTag     string             `json:"image_tag,omitempty"`
+	ImageDigest  digest.Digest      `json:"image_digest,omitempty"`
+	Labels       map[string]string  `json:"labels,omitempty"`
+	Verification *verify.Result     `json:"verification,omitempty"`
+	Referrers    []Referrer         `json:"referrers,omitempty"`
+	Platforms    []PlatformManifest `json:"platforms,omitempty"`
+	// ManifestAnnotations are the annotations of the resolved descriptor, manifest or index.
+	// They are exported such that they are retained by every logger codec.
+	ManifestAnnotations map[string]string `json:"manifest_annotations,omitempty"`
 }
 
 func (r *BaseResource) Digest() (string, error) {
@@ -21,24 +36,61 @@ func (r *BaseResource) Digest() (string, error) {
 }
 
 func (r *BaseResource) Annotations() map[string]string {
-	return r.annotations
+	// manifest annotations take precedence over config labels
+	annotations := imageAnnotations(r.ImageName, r.ImageTag, r.Labels)
+	maps.Insert(annotations, maps.All(r.ManifestAnnotations))
+
+	if r.Verification != nil {
+		maps.Insert(annotations, maps.All(r.Verification.Annotations()))
+	}
+
+	maps.Insert(annotations, maps.All(ReferrerAnnotations(r.Referrers)))
+	maps.Insert(annotations, maps.All(PlatformAnnotations(r.Platforms, nil)))
+
+	return annotations
 }
 
 func (r *BaseResource) ReadFromOCIDescriptor(desc v1.Descriptor) error {
 	r.ImageDigest = desc.Digest
-	r.annotations = desc.Annotations
+	r.ManifestAnnotations = desc.Annotations
 	return nil
 }
 
 func (r *BaseResource) ReadFromOCIManifest(desc v1.Descriptor, manifest v1.Manifest) error {
 	r.ImageDigest = desc.Digest
-	r.annotations = manifest.Annotations
+	r.ManifestAnnotations = manifest.Annotations
 
 	return nil
 }
 
 func (r *BaseResource) ReadFromOCIIndex(desc v1.Descriptor, index v1.Index) error {
 	r.ImageDigest = desc.Digest
-	r.annotations = index.Annotations
+	r.ManifestAnnotations = index.Annotations
+	return nil
+}
+
+func (r *BaseResource) ReadFromOCIVerification(result *verify.Result) error {
+	r.Verification = result
+	return nil
+}
+
+func (r *BaseResource) ReadFromOCIReferrers(referrers []Referrer) error {
+	r.Referrers = referrers
+	return nil
+}
+
+func (r *BaseResource) ReadFromOCIPlatforms(platforms []PlatformManifest) error {
+	r.Platforms = platforms
+	return nil
+}
+
+func (r *BaseResource) ReadFromOCIReference(name, tag string) error {
+	r.ImageName = name
+	r.ImageTag = tag
+	return nil
+}
+
+func (r *BaseResource) ReadFromOCIConfig(_ v1.Descriptor, image v1.Image) error {
+	r.Labels = WellKnownLabels(image.Config.Labels)
 	return nil
 }
@@ -0,0 +1,56 @@
+package oci
+
+import (
+	"context"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	kvmemory "github.com/get-glu/glu/pkg/kv/memory"
+	"github.com/get-glu/glu/pkg/phases/logger"
+	"github.com/get-glu/glu/pkg/phases/oci/verify"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseResource_Codecs(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		phase = core.Descriptor{Kind: "oci", Pipeline: "pipeline", Metadata: core.Metadata{Name: "registry"}}
+		desc  = v1.Descriptor{Digest: digest.FromString("manifest")}
+	)
+
+	resource := &BaseResource{}
+	require.NoError(t, resource.ReadFromOCIReference("ghcr.io/get-glu/app", "v1.0.0"))
+	require.NoError(t, resource.ReadFromOCIManifest(desc, v1.Manifest{Annotations: map[string]string{
+		v1.AnnotationRevision: "abc123",
+	}}))
+	require.NoError(t, resource.ReadFromOCIVerification(&verify.Result{Verified: true, Signatures: 1}))
+
+	for _, test := range []struct {
+		name        string
+		codec       logger.Codec
+		compression logger.Compression
+	}{
+		{name: "json", codec: logger.JSON},
+		{name: "gob", codec: logger.Gob, compression: logger.Gzip},
+		{name: "cbor", codec: logger.CBOR, compression: logger.Zstd},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			log := logger.New(kvmemory.New(),
+				logger.WithCodec[*BaseResource](test.codec),
+				logger.WithCompression[*BaseResource](test.compression))
+
+			require.NoError(t, log.CreateLog(ctx, phase))
+			require.NoError(t, log.RecordLatest(ctx, phase, resource, nil))
+
+			latest, err := log.GetLatestResource(ctx, phase)
+			require.NoError(t, err)
+
+			// the manifest annotations are retained across history
+			assert.Equal(t, resource, latest)
+			assert.Equal(t, "abc123", latest.Annotations()[v1.AnnotationRevision])
+		})
+	}
+}
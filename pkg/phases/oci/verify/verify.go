@@ -0,0 +1,430 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/get-glu/glu/pkg/containers"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// MediaTypeCosignSimpleSigning is the layer media type used by cosign for signature payloads
+	MediaTypeCosignSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// MediaTypeNotationJWS is the layer media type used by notation for JWS signature envelopes
+	MediaTypeNotationJWS = "application/jose+json"
+	// MediaTypeDSSEEnvelope is the layer media type used for DSSE wrapped in-toto attestations
+	MediaTypeDSSEEnvelope = "application/vnd.dsse.envelope.v1+json"
+
+	// AnnotationCosignSignature is the layer annotation which carries the base64 encoded cosign signature
+	AnnotationCosignSignature = "dev.cosignproject.cosign/signature"
+
+	// AnnotationVerified is set to "true" or "false" depending on the outcome of verification
+	AnnotationVerified = "dev.getglu.oci.verification.verified"
+	// AnnotationSignatures is the number of valid signatures found for the subject
+	AnnotationSignatures = "dev.getglu.oci.verification.signatures"
+	// AnnotationPredicateTypes is a comma separated list of verified attestation predicate types
+	AnnotationPredicateTypes = "dev.getglu.oci.verification.predicate_types"
+	// AnnotationReason describes why verification failed
+	AnnotationReason = "dev.getglu.oci.verification.reason"
+
+	payloadTypeInToto = "application/vnd.in-toto+json"
+)
+
+// ErrNotVerified is returned when a subject has not passed verification
+var ErrNotVerified = errors.New("not verified")
+
+// Verifier verifies signatures and in-toto attestations attached to an OCI
+// subject via the referrers API.
+// Signatures are accepted in both the cosign simple signing format and
+// the notation JWS envelope format. Attestations are expected as DSSE envelopes.
+type Verifier struct {
+	keys                   []crypto.PublicKey
+	requiredPredicateTypes []string
+}
+
+// New constructs and configures a new Verifier.
+func New(opts ...containers.Option[Verifier]) *Verifier {
+	v := &Verifier{}
+	containers.ApplyAll(v, opts...)
+	return v
+}
+
+// WithPublicKeys adds the provided public keys to the set of trusted keys.
+func WithPublicKeys(keys ...crypto.PublicKey) containers.Option[Verifier] {
+	return func(v *Verifier) {
+		v.keys = append(v.keys, keys...)
+	}
+}
+
+// WithRequiredPredicateTypes configures the in-toto predicate types which must be
+// present as verified attestations for a subject to be considered verified.
+func WithRequiredPredicateTypes(types ...string) containers.Option[Verifier] {
+	return func(v *Verifier) {
+		v.requiredPredicateTypes = append(v.requiredPredicateTypes, types...)
+	}
+}
+
+// ParsePublicKey parses a PEM encoded PKIX public key.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key: no PEM block found")
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// Result is the outcome of verifying a subject.
+type Result struct {
+	Verified       bool     `json:"verified"`
+	Signatures     int      `json:"signatures,omitempty"`
+	PredicateTypes []string `json:"predicate_types,omitempty"`
+	Reason         string   `json:"reason,omitempty"`
+}
+
+// Annotations returns the result represented as a set of annotations.
+func (r *Result) Annotations() map[string]string {
+	if r == nil {
+		return nil
+	}
+
+	annotations := map[string]string{
+		AnnotationVerified:   strconv.FormatBool(r.Verified),
+		AnnotationSignatures: strconv.Itoa(r.Signatures),
+	}
+
+	if len(r.PredicateTypes) > 0 {
+		annotations[AnnotationPredicateTypes] = strings.Join(r.PredicateTypes, ",")
+	}
+
+	if r.Reason != "" {
+		annotations[AnnotationReason] = r.Reason
+	}
+
+	return annotations
+}
+
+// Verify discovers referrers for the provided subject from the store and verifies any
+// signatures and attestations found against the configured set of public keys.
+func (v *Verifier) Verify(ctx context.Context, store content.ReadOnlyGraphStorage, subject v1.Descriptor) (*Result, error) {
+	referrers, err := registry.Referrers(ctx, store, subject, "")
+	if err != nil {
+		return nil, fmt.Errorf("listing referrers: %w", err)
+	}
+
+	return v.VerifyReferrers(ctx, store, subject, referrers)
+}
+
+// VerifyReferrers verifies any signatures and attestations found within the provided
+// referrers of the subject against the configured set of public keys.
+// It allows callers which have already listed the referrers of the subject to reuse them.
+func (v *Verifier) VerifyReferrers(ctx context.Context, fetcher content.Fetcher, subject v1.Descriptor, referrers []v1.Descriptor) (*Result, error) {
+	slog := slog.With("subject", subject.Digest)
+
+	result := &Result{}
+	for _, referrer := range referrers {
+		data, err := content.FetchAll(ctx, fetcher, referrer)
+		if err != nil {
+			return nil, fmt.Errorf("fetching referrer %q: %w", referrer.Digest, err)
+		}
+
+		var manifest v1.Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			slog.Debug("skipping referrer", "referrer", referrer.Digest, "reason", "NotAManifest")
+			continue
+		}
+
+		for _, layer := range manifest.Layers {
+			switch layer.MediaType {
+			case MediaTypeCosignSimpleSigning, MediaTypeNotationJWS, MediaTypeDSSEEnvelope:
+			default:
+				continue
+			}
+
+			payload, err := content.FetchAll(ctx, fetcher, layer)
+			if err != nil {
+				return nil, fmt.Errorf("fetching layer %q: %w", layer.Digest, err)
+			}
+
+			switch layer.MediaType {
+			case MediaTypeCosignSimpleSigning:
+				err = v.verifyCosign(subject, layer, payload)
+			case MediaTypeNotationJWS:
+				err = v.verifyJWS(subject, payload)
+			case MediaTypeDSSEEnvelope:
+				var predicateType string
+				if predicateType, err = v.verifyAttestation(subject, payload); err == nil &&
+					!slices.Contains(result.PredicateTypes, predicateType) {
+					result.PredicateTypes = append(result.PredicateTypes, predicateType)
+				}
+			}
+
+			if err != nil {
+				slog.Debug("skipping layer", "referrer", referrer.Digest, "layer", layer.Digest, "reason", err)
+				continue
+			}
+
+			if layer.MediaType != MediaTypeDSSEEnvelope {
+				result.Signatures++
+			}
+		}
+	}
+
+	slices.Sort(result.PredicateTypes)
+
+	var missing []string
+	for _, predicateType := range v.requiredPredicateTypes {
+		if !slices.Contains(result.PredicateTypes, predicateType) {
+			missing = append(missing, predicateType)
+		}
+	}
+
+	switch {
+	case result.Signatures == 0:
+		result.Reason = "no valid signatures found"
+	case len(missing) > 0:
+		result.Reason = fmt.Sprintf("missing attestations for predicate types: %s", strings.Join(missing, ","))
+	default:
+		result.Verified = true
+	}
+
+	return result, nil
+}
+
+func (v *Verifier) verifyCosign(subject, layer v1.Descriptor, payload []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(layer.Annotations[AnnotationCosignSignature])
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	if !v.verifyAny(payload, sig) {
+		return errors.New("signature does not match any trusted key")
+	}
+
+	var simple struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+
+	if err := json.Unmarshal(payload, &simple); err != nil {
+		return fmt.Errorf("decoding payload: %w", err)
+	}
+
+	if simple.Critical.Image.DockerManifestDigest != subject.Digest.String() {
+		return fmt.Errorf("payload digest %q does not match subject", simple.Critical.Image.DockerManifestDigest)
+	}
+
+	return nil
+}
+
+func (v *Verifier) verifyJWS(subject v1.Descriptor, envelope []byte) error {
+	var jws struct {
+		Payload   string `json:"payload"`
+		Protected string `json:"protected"`
+		Signature string `json:"signature"`
+	}
+
+	if err := json.Unmarshal(envelope, &jws); err != nil {
+		return fmt.Errorf("decoding envelope: %w", err)
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if err != nil {
+		return fmt.Errorf("decoding protected header: %w", err)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return fmt.Errorf("decoding protected header: %w", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(jws.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	signed := []byte(jws.Protected + "." + jws.Payload)
+	if !slices.ContainsFunc(v.keys, func(key crypto.PublicKey) bool {
+		return verifyJWSSignature(key, header.Alg, signed, sig)
+	}) {
+		return errors.New("signature does not match any trusted key")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return fmt.Errorf("decoding payload: %w", err)
+	}
+
+	var target struct {
+		TargetArtifact v1.Descriptor `json:"targetArtifact"`
+	}
+
+	if err := json.Unmarshal(payload, &target); err != nil {
+		return fmt.Errorf("decoding payload: %w", err)
+	}
+
+	if target.TargetArtifact.Digest != subject.Digest {
+		return fmt.Errorf("payload digest %q does not match subject", target.TargetArtifact.Digest)
+	}
+
+	return nil
+}
+
+func (v *Verifier) verifyAttestation(subject v1.Descriptor, envelope []byte) (string, error) {
+	var dsse struct {
+		PayloadType string          `json:"payloadType"`
+		Payload     string          `json:"payload"`
+		Signatures  []dsseSignature `json:"signatures"`
+	}
+
+	if err := json.Unmarshal(envelope, &dsse); err != nil {
+		return "", fmt.Errorf("decoding envelope: %w", err)
+	}
+
+	if dsse.PayloadType != payloadTypeInToto {
+		return "", fmt.Errorf("unexpected payload type %q", dsse.PayloadType)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(dsse.Payload)
+	if err != nil {
+		return "", fmt.Errorf("decoding payload: %w", err)
+	}
+
+	pae := preAuthEncoding(dsse.PayloadType, payload)
+	if !slices.ContainsFunc(dsse.Signatures, func(s dsseSignature) bool {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		return err == nil && v.verifyAny(pae, sig)
+	}) {
+		return "", errors.New("signature does not match any trusted key")
+	}
+
+	var statement struct {
+		PredicateType string          `json:"predicateType"`
+		Subject       []inTotoSubject `json:"subject"`
+	}
+
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return "", fmt.Errorf("decoding statement: %w", err)
+	}
+
+	if !slices.ContainsFunc(statement.Subject, func(s inTotoSubject) bool {
+		return s.Digest[subject.Digest.Algorithm().String()] == subject.Digest.Encoded()
+	}) {
+		return "", errors.New("statement does not reference subject")
+	}
+
+	return statement.PredicateType, nil
+}
+
+type dsseSignature struct {
+	Sig string `json:"sig"`
+}
+
+type inTotoSubject struct {
+	Digest map[string]string `json:"digest"`
+}
+
+func (v *Verifier) verifyAny(payload, sig []byte) bool {
+	return slices.ContainsFunc(v.keys, func(key crypto.PublicKey) bool {
+		return verifySignature(key, payload, sig)
+	})
+}
+
+// preAuthEncoding returns the DSSE v1 pre-authentication encoding of the payload.
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+func verifySignature(key crypto.PublicKey, payload, sig []byte) bool {
+	digest := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil ||
+			rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, nil) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, sig)
+	default:
+		return false
+	}
+}
+
+func verifyJWSSignature(key crypto.PublicKey, alg string, signed, sig []byte) bool {
+	if len(alg) != 5 {
+		return false
+	}
+
+	var (
+		h      hash.Hash
+		hashFn crypto.Hash
+	)
+
+	switch alg[2:] {
+	case "256":
+		h, hashFn = sha256.New(), crypto.SHA256
+	case "384":
+		h, hashFn = sha512.New384(), crypto.SHA384
+	case "512":
+		h, hashFn = sha512.New(), crypto.SHA512
+	default:
+		return false
+	}
+
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") || len(sig)%2 != 0 {
+			return false
+		}
+
+		// JWS encodes ECDSA signatures as the concatenation of r and s
+		var (
+			r = new(big.Int).SetBytes(sig[:len(sig)/2])
+			s = new(big.Int).SetBytes(sig[len(sig)/2:])
+		)
+
+		return ecdsa.Verify(k, digest, r, s)
+	case *rsa.PublicKey:
+		switch {
+		case strings.HasPrefix(alg, "PS"):
+			return rsa.VerifyPSS(k, hashFn, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		case strings.HasPrefix(alg, "RS"):
+			return rsa.VerifyPKCS1v15(k, hashFn, digest, sig) == nil
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,237 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+const predicateSLSA = "https://slsa.dev/provenance/v1"
+
+func TestVerifier_Verify(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		trusted      = generateKey(t)
+		untrusted    = generateKey(t)
+		trustedKey   = parseKey(t, trusted)
+		untrustedKey = parseKey(t, untrusted)
+	)
+
+	for _, test := range []struct {
+		name     string
+		setup    func(*testing.T, *memory.Store, v1.Descriptor)
+		opts     []containers.Option[Verifier]
+		expected Result
+	}{
+		{
+			name:     "unsigned",
+			setup:    func(*testing.T, *memory.Store, v1.Descriptor) {},
+			opts:     []containers.Option[Verifier]{WithPublicKeys(trustedKey)},
+			expected: Result{Reason: "no valid signatures found"},
+		},
+		{
+			name: "signed with trusted key",
+			setup: func(t *testing.T, store *memory.Store, subject v1.Descriptor) {
+				pushCosignSignature(t, store, subject, trusted)
+			},
+			opts:     []containers.Option[Verifier]{WithPublicKeys(trustedKey)},
+			expected: Result{Verified: true, Signatures: 1},
+		},
+		{
+			name: "signed with untrusted key",
+			setup: func(t *testing.T, store *memory.Store, subject v1.Descriptor) {
+				pushCosignSignature(t, store, subject, untrusted)
+			},
+			opts:     []containers.Option[Verifier]{WithPublicKeys(trustedKey)},
+			expected: Result{Reason: "no valid signatures found"},
+		},
+		{
+			name: "signed with either trusted key",
+			setup: func(t *testing.T, store *memory.Store, subject v1.Descriptor) {
+				pushCosignSignature(t, store, subject, untrusted)
+			},
+			opts:     []containers.Option[Verifier]{WithPublicKeys(trustedKey, untrustedKey)},
+			expected: Result{Verified: true, Signatures: 1},
+		},
+		{
+			name: "missing required attestation",
+			setup: func(t *testing.T, store *memory.Store, subject v1.Descriptor) {
+				pushCosignSignature(t, store, subject, trusted)
+			},
+			opts: []containers.Option[Verifier]{
+				WithPublicKeys(trustedKey),
+				WithRequiredPredicateTypes(predicateSLSA),
+			},
+			expected: Result{
+				Signatures: 1,
+				Reason:     "missing attestations for predicate types: " + predicateSLSA,
+			},
+		},
+		{
+			name: "required attestation signed with untrusted key",
+			setup: func(t *testing.T, store *memory.Store, subject v1.Descriptor) {
+				pushCosignSignature(t, store, subject, trusted)
+				pushAttestation(t, store, subject, untrusted, predicateSLSA)
+			},
+			opts: []containers.Option[Verifier]{
+				WithPublicKeys(trustedKey),
+				WithRequiredPredicateTypes(predicateSLSA),
+			},
+			expected: Result{
+				Signatures: 1,
+				Reason:     "missing attestations for predicate types: " + predicateSLSA,
+			},
+		},
+		{
+			name: "signed with required attestation",
+			setup: func(t *testing.T, store *memory.Store, subject v1.Descriptor) {
+				pushCosignSignature(t, store, subject, trusted)
+				pushAttestation(t, store, subject, trusted, predicateSLSA)
+			},
+			opts: []containers.Option[Verifier]{
+				WithPublicKeys(trustedKey),
+				WithRequiredPredicateTypes(predicateSLSA),
+			},
+			expected: Result{
+				Verified:       true,
+				Signatures:     1,
+				PredicateTypes: []string{predicateSLSA},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			store := memory.New()
+			subject := pushImage(t, store)
+
+			test.setup(t, store, subject)
+
+			result, err := New(test.opts...).Verify(ctx, store, subject)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, *result)
+		})
+	}
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func parseKey(t *testing.T, key *ecdsa.PrivateKey) any {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	pub, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	return pub
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, payload []byte) []byte {
+	t.Helper()
+
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	require.NoError(t, err)
+
+	return sig
+}
+
+func pushBlob(t *testing.T, store *memory.Store, mediaType string, data []byte, annotations map[string]string) v1.Descriptor {
+	t.Helper()
+
+	desc := content.NewDescriptorFromBytes(mediaType, data)
+	desc.Annotations = annotations
+
+	require.NoError(t, store.Push(context.Background(), desc, bytes.NewReader(data)))
+
+	return desc
+}
+
+func pushImage(t *testing.T, store *memory.Store) v1.Descriptor {
+	t.Helper()
+
+	layer := pushBlob(t, store, v1.MediaTypeImageLayer, []byte("layer"), nil)
+	desc, err := oras.PackManifest(context.Background(), store, oras.PackManifestVersion1_1, "application/vnd.test.image", oras.PackManifestOptions{
+		Layers: []v1.Descriptor{layer},
+	})
+	require.NoError(t, err)
+
+	return desc
+}
+
+func pushReferrer(t *testing.T, store *memory.Store, subject v1.Descriptor, artifactType string, layer v1.Descriptor) {
+	t.Helper()
+
+	_, err := oras.PackManifest(context.Background(), store, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Subject: &subject,
+		Layers:  []v1.Descriptor{layer},
+	})
+	require.NoError(t, err)
+}
+
+func pushCosignSignature(t *testing.T, store *memory.Store, subject v1.Descriptor, key *ecdsa.PrivateKey) {
+	t.Helper()
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"registry.local/app"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"}}`, subject.Digest))
+	layer := pushBlob(t, store, MediaTypeCosignSimpleSigning, payload, map[string]string{
+		AnnotationCosignSignature: base64.StdEncoding.EncodeToString(sign(t, key, payload)),
+	})
+
+	pushReferrer(t, store, subject, "application/vnd.dev.cosign.artifact.sig.v1+json", layer)
+}
+
+func pushAttestation(t *testing.T, store *memory.Store, subject v1.Descriptor, key *ecdsa.PrivateKey, predicateType string) {
+	t.Helper()
+
+	statement, err := json.Marshal(map[string]any{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"predicateType": predicateType,
+		"predicate":     map[string]any{},
+		"subject": []map[string]any{
+			{
+				"name":   "registry.local/app",
+				"digest": map[string]string{digest.SHA256.String(): subject.Digest.Encoded()},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	envelope, err := json.Marshal(map[string]any{
+		"payloadType": payloadTypeInToto,
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures": []map[string]string{
+			{"sig": base64.StdEncoding.EncodeToString(sign(t, key, preAuthEncoding(payloadTypeInToto, statement)))},
+		},
+	})
+	require.NoError(t, err)
+
+	layer := pushBlob(t, store, MediaTypeDSSEEnvelope, envelope, nil)
+
+	pushReferrer(t, store, subject, "application/vnd.in-toto+json", layer)
+}
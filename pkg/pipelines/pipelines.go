@@ -169,6 +169,15 @@ func GitPhase[R srcgit.Resource](meta glu.Metadata, srcName string, opts ...cont
 			defaultOpts = append(defaultOpts, srcgit.WithLogger(logger))
 		}
 
+		links, err := builder.Configuration().GitLinkProvider(srcName)
+		if err != nil {
+			return nil, err
+		}
+
+		if links != nil {
+			defaultOpts = append(defaultOpts, srcgit.WithLinkProvider[R](links))
+		}
+
 		phase, err := srcgit.New(
 			builder.Context(),
 			builder.PipelineName(),
@@ -194,26 +203,69 @@ func OCIPhase[R srcoci.Resource](meta glu.Metadata, srcName string, opts ...cont
 			return nil, err
 		}
 
+		defaultOpts := []containers.Option[srcoci.Phase[R]]{
+			srcoci.WithInterval[R](repo.Interval()),
+		}
+
+		if verifier := repo.Verifier(); verifier != nil {
+			defaultOpts = append(defaultOpts, srcoci.WithVerifier[R](verifier))
+		}
+
+		if platforms, required := repo.Platforms(); len(platforms) > 0 {
+			defaultOpts = append(defaultOpts, srcoci.WithPlatforms[R](platforms...))
+			if required {
+				defaultOpts = append(defaultOpts, srcoci.WithRequirePlatforms[R]())
+			}
+		}
+
 		return srcoci.New(
 			builder.Context(),
 			builder.PipelineName(),
 			meta,
 			builder.New,
 			repo,
-			opts...,
+			append(defaultOpts, opts...)...,
 		)
 	}
 }
 
 // FileLogger returns an instance of type.PhaseLogger which writes to a file db
 // as configured by the provided name.
-func FileLogger[R glu.Resource](name string) func(Builder[R]) (typed.PhaseLogger[R], error) {
+// The options configure the logger (e.g. its codec and compression).
+func FileLogger[R glu.Resource](name string, opts ...containers.Option[logger.PhaseLogger[R]]) func(Builder[R]) (typed.PhaseLogger[R], error) {
 	return func(b Builder[R]) (typed.PhaseLogger[R], error) {
 		db, err := b.Configuration().FileDB(name)
 		if err != nil {
 			return nil, err
 		}
 
-		return logger.New[R](db), nil
+		return logger.New[R](db, opts...), nil
+	}
+}
+
+// SQLiteLogger returns an instance of type.PhaseLogger which writes to a SQLite db
+// as configured by the provided name.
+func SQLiteLogger[R glu.Resource](name string, opts ...containers.Option[logger.PhaseLogger[R]]) func(Builder[R]) (typed.PhaseLogger[R], error) {
+	return func(b Builder[R]) (typed.PhaseLogger[R], error) {
+		db, err := b.Configuration().SQLiteDB(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return logger.New[R](db, opts...), nil
+	}
+}
+
+// RedisLogger returns an instance of type.PhaseLogger which writes to a Redis db
+// as configured by the provided name.
+// Replicas configured with the same Redis db share their phase history.
+func RedisLogger[R glu.Resource](name string, opts ...containers.Option[logger.PhaseLogger[R]]) func(Builder[R]) (typed.PhaseLogger[R], error) {
+	return func(b Builder[R]) (typed.PhaseLogger[R], error) {
+		db, err := b.Configuration().RedisDB(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return logger.New[R](db, opts...), nil
 	}
 }
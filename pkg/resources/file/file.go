@@ -0,0 +1,178 @@
+// Package file provides a generic resource which reads and writes a single value
+// at a path expression within a YAML or JSON file (e.g. .image.digest in values.yaml).
+// This avoids having to hand-write ReadFrom and WriteTo for the common case of
+// promoting a single field (such as an image digest or tag) between phases.
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/get-glu/glu/internal/yamledit"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/fs"
+	"github.com/get-glu/glu/pkg/resources"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var (
+	// ErrNotFound is returned when the path expression does not resolve to a value
+	ErrNotFound = yamledit.ErrNotFound
+	// ErrNotScalar is returned when the path expression resolves to a mapping or sequence
+	ErrNotScalar = yamledit.ErrNotScalar
+)
+
+// Format is the encoding of the file a resource is read from and written to.
+type Format string
+
+const (
+	// FormatAuto detects the format from the files extension (.json or otherwise YAML)
+	FormatAuto = Format("")
+	FormatYAML = Format("yaml")
+	FormatJSON = Format("json")
+)
+
+// Resource is a resource whose state is a single scalar value found at
+// a path expression within a YAML or JSON file.
+// The path is a text/template which is executed with the descriptor of the calling phase,
+// such that a single resource can address a file per phase (e.g. env/{{ .Metadata.Name }}/values.yaml).
+// When writing, only the value itself is replaced, such that comments and formatting are preserved.
+type Resource struct {
+	Value      string `json:"value,omitempty"`
+	Path       string `json:"path"`
+	Expression string `json:"expression"`
+	Format     Format `json:"format,omitempty"`
+}
+
+// New returns a function which constructs resources for the provided path and expression.
+// It is intended to be supplied as the constructor when building a pipeline.
+func New(path, expression string, opts ...containers.Option[Resource]) func() *Resource {
+	return func() *Resource {
+		r := &Resource{Path: path, Expression: expression}
+		containers.ApplyAll(r, opts...)
+		return r
+	}
+}
+
+// WithFormat overrides the detected format of the file.
+func WithFormat(format Format) containers.Option[Resource] {
+	return func(r *Resource) {
+		r.Format = format
+	}
+}
+
+// Digest returns the value as the resources digest.
+func (r *Resource) Digest() (string, error) {
+	return r.Value, nil
+}
+
+// ReadFromOCIDescriptor sets the value to the digest of the descriptor.
+// This allows the resource to be used in pipelines which promote from an OCI phase.
+func (r *Resource) ReadFromOCIDescriptor(desc v1.Descriptor) error {
+	r.Value = desc.Digest.String()
+	return nil
+}
+
+// ReadFrom reads the value from the file at the configured path and expression.
+func (r *Resource) ReadFrom(_ context.Context, phase core.Descriptor, fs fs.Filesystem) error {
+	path, err := resources.ExpandPath("path", r.Path, phase)
+	if err != nil {
+		return err
+	}
+
+	data, err := readFile(fs, path)
+	if err != nil {
+		return err
+	}
+
+	doc, err := r.parse(data, r.format(path))
+	if err != nil {
+		return fmt.Errorf("reading %q: %w", path, err)
+	}
+
+	if r.Value, err = doc.Get(r.Expression); err != nil {
+		return fmt.Errorf("reading %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// WriteTo replaces the value in the file at the configured path and expression.
+// The path expression must already exist in the target file.
+func (r *Resource) WriteTo(_ context.Context, phase core.Descriptor, fs fs.Filesystem) error {
+	path, err := resources.ExpandPath("path", r.Path, phase)
+	if err != nil {
+		return err
+	}
+
+	data, err := readFile(fs, path)
+	if err != nil {
+		return err
+	}
+
+	doc, err := r.parse(data, r.format(path))
+	if err != nil {
+		return fmt.Errorf("writing %q: %w", path, err)
+	}
+
+	// the expression must already resolve to a value in the target file
+	if _, err := doc.Get(r.Expression); err != nil {
+		return fmt.Errorf("writing %q: %w", path, err)
+	}
+
+	if err := doc.Set(r.Expression, r.Value); err != nil {
+		return fmt.Errorf("writing %q: %w", path, err)
+	}
+
+	updated := doc.Bytes()
+	if bytes.Equal(data, updated) {
+		return nil
+	}
+
+	fi, err := fs.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	defer fi.Close()
+
+	_, err = fi.Write(updated)
+	return err
+}
+
+func (r *Resource) format(path string) Format {
+	if r.Format != FormatAuto {
+		return r.Format
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+
+	return FormatYAML
+}
+
+func (r *Resource) parse(data []byte, format Format) (*yamledit.Document, error) {
+	if format == FormatJSON {
+		return yamledit.ParseJSON(data)
+	}
+
+	return yamledit.Parse(data)
+}
+
+func readFile(fs fs.Filesystem, path string) ([]byte, error) {
+	fi, err := fs.OpenFile(path, os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fi.Close()
+
+	return io.ReadAll(fi)
+}
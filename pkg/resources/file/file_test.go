@@ -0,0 +1,192 @@
+package file
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/fs/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	staging    = core.Descriptor{Kind: "git", Pipeline: "app", Metadata: core.Metadata{Name: "staging"}}
+	production = core.Descriptor{Kind: "git", Pipeline: "app", Metadata: core.Metadata{Name: "production"}}
+)
+
+const values = `# the image deployed
+image:
+  repository: ghcr.io/get-glu/app # pinned by glu
+  digest: sha256:aaa
+replicas: 2
+`
+
+func TestResource_ReadFrom(t *testing.T) {
+	ctx := context.Background()
+
+	fs, err := memory.NewWithFiles(map[string]string{
+		"env/staging/values.yaml":    values,
+		"env/production/values.yaml": "image:\n  digest: sha256:bbb\n",
+		"env/staging/config.json":    `{"image": {"digest": "sha256:ccc"}, "tags": ["a"]}`,
+		"env/staging/config.txt":     `{"image": {"digest": "sha256:ddd"}}`,
+	})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name     string
+		resource func() *Resource
+		phase    core.Descriptor
+		expected string
+		err      error
+		errMsg   string
+	}{
+		{
+			name:     "yaml",
+			resource: New("env/staging/values.yaml", ".image.digest"),
+			phase:    staging,
+			expected: "sha256:aaa",
+		},
+		{
+			name:     "templated path",
+			resource: New("env/{{ .Metadata.Name }}/values.yaml", ".image.digest"),
+			phase:    production,
+			expected: "sha256:bbb",
+		},
+		{
+			name:     "json",
+			resource: New("env/staging/config.json", ".image.digest"),
+			phase:    staging,
+			expected: "sha256:ccc",
+		},
+		{
+			name:     "json format",
+			resource: New("env/staging/config.txt", ".image.digest", WithFormat(FormatJSON)),
+			phase:    staging,
+			expected: "sha256:ddd",
+		},
+		{
+			name:     "integer",
+			resource: New("env/staging/values.yaml", ".replicas"),
+			phase:    staging,
+			expected: "2",
+		},
+		{
+			name:     "missing expression",
+			resource: New("env/staging/values.yaml", ".image.tag"),
+			phase:    staging,
+			err:      ErrNotFound,
+		},
+		{
+			name:     "not scalar",
+			resource: New("env/staging/values.yaml", ".image"),
+			phase:    staging,
+			err:      ErrNotScalar,
+		},
+		{
+			name:     "missing file",
+			resource: New("env/{{ .Metadata.Name }}/config.json", ".image.digest"),
+			phase:    production,
+			err:      os.ErrNotExist,
+		},
+		{
+			name:     "invalid template",
+			resource: New("env/{{ .Metadata.Zone }}/values.yaml", ".image.digest"),
+			phase:    staging,
+			errMsg:   "executing path template",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := test.resource()
+
+			err := r.ReadFrom(ctx, test.phase, fs)
+			switch {
+			case test.err != nil:
+				assert.ErrorIs(t, err, test.err)
+			case test.errMsg != "":
+				assert.ErrorContains(t, err, test.errMsg)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, r.Value)
+			}
+		})
+	}
+}
+
+func TestResource_WriteTo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("yaml", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{"env/staging/values.yaml": values})
+		require.NoError(t, err)
+
+		r := New("env/{{ .Metadata.Name }}/values.yaml", ".image.digest")()
+		r.Value = "sha256:fff"
+
+		require.NoError(t, r.WriteTo(ctx, staging, fs))
+
+		// only the value is replaced and comments are preserved
+		data, err := fs.ReadFile("env/staging/values.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `# the image deployed
+image:
+  repository: ghcr.io/get-glu/app # pinned by glu
+  digest: sha256:fff
+replicas: 2
+`, string(data))
+
+		read := New("env/{{ .Metadata.Name }}/values.yaml", ".image.digest")()
+		require.NoError(t, read.ReadFrom(ctx, staging, fs))
+		assert.Equal(t, "sha256:fff", read.Value)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{"config.json": "{\n  \"image\": {\n    \"digest\": \"sha256:aaa\"\n  }\n}\n"})
+		require.NoError(t, err)
+
+		r := New("config.json", ".image.digest")()
+		r.Value = "sha256:fff"
+
+		require.NoError(t, r.WriteTo(ctx, staging, fs))
+
+		read := New("config.json", ".image.digest")()
+		require.NoError(t, read.ReadFrom(ctx, staging, fs))
+		assert.Equal(t, "sha256:fff", read.Value)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{"values.yaml": values})
+		require.NoError(t, err)
+
+		r := New("values.yaml", ".image.digest")()
+		r.Value = "sha256:aaa"
+
+		require.NoError(t, r.WriteTo(ctx, staging, fs))
+
+		data, err := fs.ReadFile("values.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, values, string(data))
+	})
+
+	t.Run("missing expression", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{"values.yaml": values})
+		require.NoError(t, err)
+
+		r := New("values.yaml", ".image.tag")()
+		r.Value = "v1.0.0"
+
+		assert.ErrorIs(t, r.WriteTo(ctx, staging, fs), ErrNotFound)
+
+		data, err := fs.ReadFile("values.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, values, string(data))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		r := New("values.yaml", ".image.digest")()
+		r.Value = "sha256:fff"
+
+		assert.ErrorIs(t, r.WriteTo(ctx, staging, memory.New()), os.ErrNotExist)
+	})
+}
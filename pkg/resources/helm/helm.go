@@ -0,0 +1,436 @@
+// Package helm provides a resource which manages the image of a Helm chart deployment in git.
+// It updates the image repository, tag and digest within the charts values file and can
+// optionally bump the version and appVersion of the charts Chart.yaml.
+package helm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/get-glu/glu/internal/yamledit"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/core/typed"
+	"github.com/get-glu/glu/pkg/fs"
+	"github.com/get-glu/glu/pkg/resources"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	chartFile = "Chart.yaml"
+
+	DefaultValuesFile     = "values.yaml"
+	DefaultRepositoryPath = ".image.repository"
+	DefaultTagPath        = ".image.tag"
+	DefaultDigestPath     = ".image.digest"
+)
+
+// Resource is a resource whose state is the image of a Helm chart as configured in its values file.
+// The chart is a text/template which is executed with the descriptor of the calling phase,
+// such that a single resource can address a chart (or values file) per phase (e.g. env/{{ .Metadata.Name }}).
+// Values are replaced in place, such that comments and formatting are preserved.
+type Resource struct {
+	Repository   string `json:"repository,omitempty"`
+	Tag          string `json:"tag,omitempty"`
+	ImageDigest  string `json:"digest,omitempty"`
+	ChartVersion string `json:"chart_version,omitempty"`
+	AppVersion   string `json:"app_version,omitempty"`
+
+	Chart            string `json:"chart,omitempty"`
+	ValuesFile       string `json:"values_file,omitempty"`
+	RepositoryPath   string `json:"repository_path,omitempty"`
+	TagPath          string `json:"tag_path,omitempty"`
+	DigestPath       string `json:"digest_path,omitempty"`
+	BumpChartVersion bool   `json:"bump_chart_version,omitempty"`
+	SetAppVersion    bool   `json:"set_app_version,omitempty"`
+}
+
+// New returns a function which constructs resources for the chart in the provided directory.
+// It is intended to be supplied as the constructor when building a pipeline.
+func New(chart string, opts ...containers.Option[Resource]) func() *Resource {
+	return func() *Resource {
+		r := &Resource{
+			Chart:          chart,
+			ValuesFile:     DefaultValuesFile,
+			RepositoryPath: DefaultRepositoryPath,
+			TagPath:        DefaultTagPath,
+			DigestPath:     DefaultDigestPath,
+		}
+
+		containers.ApplyAll(r, opts...)
+
+		return r
+	}
+}
+
+// WithValuesFile overrides the path (relative to the chart) of the values file (defaults to values.yaml).
+// The path can be used to select a per-phase values file (e.g. values-{{ .Metadata.Name }}.yaml).
+func WithValuesFile(path string) containers.Option[Resource] {
+	return func(r *Resource) {
+		r.ValuesFile = path
+	}
+}
+
+// WithImagePaths overrides the path expressions of the image repository, tag and digest within the values file
+// (defaults to .image.repository, .image.tag and .image.digest).
+// An empty expression configures the resource to neither read nor write the associated field.
+func WithImagePaths(repository, tag, digest string) containers.Option[Resource] {
+	return func(r *Resource) {
+		r.RepositoryPath = repository
+		r.TagPath = tag
+		r.DigestPath = digest
+	}
+}
+
+// WithChartVersionBump configures the resource to bump the patch version
+// of the Chart.yaml version whenever the values are updated.
+func WithChartVersionBump() containers.Option[Resource] {
+	return func(r *Resource) {
+		r.BumpChartVersion = true
+	}
+}
+
+// WithAppVersion configures the resource to set the Chart.yaml appVersion
+// to the image tag whenever the values are updated.
+func WithAppVersion() containers.Option[Resource] {
+	return func(r *Resource) {
+		r.SetAppVersion = true
+	}
+}
+
+// Digest returns the digest of the image.
+// When the values do not pin a digest, the repository and tag are returned instead.
+func (r *Resource) Digest() (string, error) {
+	if r.ImageDigest != "" {
+		return r.ImageDigest, nil
+	}
+
+	return r.Repository + ":" + r.Tag, nil
+}
+
+// ReadFromOCIDescriptor sets the image digest to that of the descriptor.
+// This allows the resource to be used in pipelines which promote from an OCI phase.
+func (r *Resource) ReadFromOCIDescriptor(desc v1.Descriptor) error {
+	r.ImageDigest = desc.Digest.String()
+	return nil
+}
+
+// ReadFromOCIReference sets the image repository and tag to those resolved by an OCI phase.
+func (r *Resource) ReadFromOCIReference(name, tag string) error {
+	r.Repository = name
+	r.Tag = tag
+	return nil
+}
+
+// ReadFrom reads the image from the values file and the versions from Chart.yaml (when present).
+func (r *Resource) ReadFrom(_ context.Context, phase core.Descriptor, filesystem fs.Filesystem) error {
+	filesystem, err := r.filesystem(phase, filesystem)
+	if err != nil {
+		return err
+	}
+
+	values, err := r.valuesFile(phase)
+	if err != nil {
+		return err
+	}
+
+	doc, err := readDocument(filesystem, values)
+	if err != nil {
+		return err
+	}
+
+	for expr, value := range r.values() {
+		if *value, err = doc.Get(expr); err != nil && !errors.Is(err, yamledit.ErrNotFound) {
+			return fmt.Errorf("reading %q: %w", values, err)
+		}
+	}
+
+	chart, err := readDocument(filesystem, chartFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	return r.readVersions(chart)
+}
+
+// WriteTo updates the image in the values file.
+// Fields which are empty on the resource are left unchanged.
+// When the values change, the Chart.yaml version and appVersion are updated as configured.
+// The resources chart version and app version are updated to reflect the target Chart.yaml.
+func (r *Resource) WriteTo(_ context.Context, phase core.Descriptor, filesystem fs.Filesystem) error {
+	filesystem, err := r.filesystem(phase, filesystem)
+	if err != nil {
+		return err
+	}
+
+	values, err := r.valuesFile(phase)
+	if err != nil {
+		return err
+	}
+
+	doc, err := readDocument(filesystem, values)
+	if err != nil {
+		return err
+	}
+
+	// versions are derived from the target chart as opposed to the source of the resource
+	r.ChartVersion, r.AppVersion = "", ""
+
+	chart, err := readDocument(filesystem, chartFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if chart != nil {
+		if err := r.readVersions(chart); err != nil {
+			return err
+		}
+	}
+
+	original := doc.Bytes()
+	for expr, value := range r.values() {
+		if *value == "" {
+			continue
+		}
+
+		if err := doc.Set(expr, *value); err != nil {
+			return fmt.Errorf("writing %q: %w", values, err)
+		}
+	}
+
+	if bytes.Equal(original, doc.Bytes()) {
+		return nil
+	}
+
+	if err := writeFile(filesystem, values, doc.Bytes()); err != nil {
+		return err
+	}
+
+	if !r.BumpChartVersion && !r.SetAppVersion {
+		return nil
+	}
+
+	if chart == nil {
+		return fmt.Errorf("updating %q: %w", chartFile, os.ErrNotExist)
+	}
+
+	if r.BumpChartVersion {
+		if r.ChartVersion, err = bumpPatch(r.ChartVersion); err != nil {
+			return fmt.Errorf("bumping %q version: %w", chartFile, err)
+		}
+
+		if err := chart.Set(".version", r.ChartVersion); err != nil {
+			return fmt.Errorf("writing %q: %w", chartFile, err)
+		}
+	}
+
+	if r.SetAppVersion && r.Tag != "" {
+		r.AppVersion = r.Tag
+		if err := chart.Set(".appVersion", r.AppVersion); err != nil {
+			return fmt.Errorf("writing %q: %w", chartFile, err)
+		}
+	}
+
+	return writeFile(filesystem, chartFile, chart.Bytes())
+}
+
+// CommitMessage describes the change in image and chart version.
+func (r *Resource) CommitMessage(phase core.Descriptor, from *Resource, opts *typed.UpdateOptions) (string, error) {
+	changes := []string{}
+	if image := describe(from.image(), r.image()); image != "" {
+		changes = append(changes, image)
+	}
+
+	if chart := describe(from.ChartVersion, r.ChartVersion); chart != "" {
+		changes = append(changes, "chart "+chart)
+	}
+
+	message := opts.DefaultMessage(phase)
+	if len(changes) > 0 {
+		message += ": " + strings.Join(changes, ", ")
+	}
+
+	return message, nil
+}
+
+// ProposalTitle describes the change in image and chart version.
+func (r *Resource) ProposalTitle(phase core.Descriptor, from *Resource, opts *typed.UpdateOptions) (string, error) {
+	return r.CommitMessage(phase, from, opts)
+}
+
+// ProposalBody returns a table which describes the change to each of the managed fields.
+func (r *Resource) ProposalBody(phase core.Descriptor, from *Resource, opts *typed.UpdateOptions) (string, error) {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\n\n| field | from | to |\n| ----- | ---- | -- |\n", opts.DefaultMessage(phase))
+
+	for _, field := range []struct {
+		name     string
+		from, to string
+	}{
+		{"repository", from.Repository, r.Repository},
+		{"tag", from.Tag, r.Tag},
+		{"digest", from.ImageDigest, r.ImageDigest},
+		{"chart version", from.ChartVersion, r.ChartVersion},
+		{"app version", from.AppVersion, r.AppVersion},
+	} {
+		if field.from == "" && field.to == "" {
+			continue
+		}
+
+		fmt.Fprintf(&body, "| %s | %s | %s |\n", field.name, field.from, field.to)
+	}
+
+	return body.String(), nil
+}
+
+// image returns a short human readable identifier for the image
+// (the tag, or the abbreviated digest when no tag is present).
+func (r *Resource) image() string {
+	if r.Tag != "" {
+		return r.Tag
+	}
+
+	if _, encoded, ok := strings.Cut(r.ImageDigest, ":"); ok && len(encoded) > 12 {
+		return encoded[:12]
+	}
+
+	return r.ImageDigest
+}
+
+func (r *Resource) readVersions(chart *yamledit.Document) (err error) {
+	for _, field := range []struct {
+		expr  string
+		value *string
+	}{
+		{".version", &r.ChartVersion},
+		{".appVersion", &r.AppVersion},
+	} {
+		if *field.value, err = chart.Get(field.expr); err != nil && !errors.Is(err, yamledit.ErrNotFound) {
+			return fmt.Errorf("reading %q: %w", chartFile, err)
+		}
+	}
+
+	return nil
+}
+
+// values returns the managed values fields (in order) keyed by their configured path expression.
+func (r *Resource) values() iter.Seq2[string, *string] {
+	return func(yield func(string, *string) bool) {
+		for _, field := range []struct {
+			expr  string
+			value *string
+		}{
+			{r.RepositoryPath, &r.Repository},
+			{r.TagPath, &r.Tag},
+			{r.DigestPath, &r.ImageDigest},
+		} {
+			if field.expr != "" && !yield(field.expr, field.value) {
+				return
+			}
+		}
+	}
+}
+
+func (r *Resource) filesystem(phase core.Descriptor, filesystem fs.Filesystem) (fs.Filesystem, error) {
+	dir, err := resources.ExpandPath("chart", r.Chart, phase)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.SubFilesystem(filesystem, dir), nil
+}
+
+func (r *Resource) valuesFile(phase core.Descriptor) (string, error) {
+	values := r.ValuesFile
+	if values == "" {
+		values = DefaultValuesFile
+	}
+
+	return resources.ExpandPath("values file", values, phase)
+}
+
+// describe returns a description of the change from one value to another
+// or the empty string when the value is unchanged.
+func describe(from, to string) string {
+	switch {
+	case from == to:
+		return ""
+	case from == "":
+		return to
+	default:
+		return from + " -> " + to
+	}
+}
+
+// bumpPatch increments the patch version of the semantic version (preserving any leading v).
+// Pre-release and build metadata are dropped.
+func bumpPatch(version string) (string, error) {
+	v := version
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+
+	if !semver.IsValid(v) {
+		return "", fmt.Errorf("invalid semantic version %q", version)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(semver.Canonical(v), "v"), ".", 3)
+	patch, err := strconv.Atoi(strings.FieldsFunc(parts[2], func(r rune) bool { return r == '-' || r == '+' })[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid semantic version %q: %w", version, err)
+	}
+
+	next := fmt.Sprintf("%s.%s.%d", parts[0], parts[1], patch+1)
+	if strings.HasPrefix(version, "v") {
+		next = "v" + next
+	}
+
+	return next, nil
+}
+
+func readDocument(filesystem fs.Filesystem, path string) (*yamledit.Document, error) {
+	fi, err := filesystem.OpenFile(path, os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fi.Close()
+
+	data, err := io.ReadAll(fi)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := yamledit.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", path, err)
+	}
+
+	return doc, nil
+}
+
+func writeFile(filesystem fs.Filesystem, path string, data []byte) error {
+	fi, err := filesystem.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fi.Write(data); err != nil {
+		_ = fi.Close()
+		return err
+	}
+
+	return fi.Close()
+}
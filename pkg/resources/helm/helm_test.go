@@ -0,0 +1,240 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/fs/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	staging    = core.Descriptor{Kind: "git", Pipeline: "app", Metadata: core.Metadata{Name: "staging"}}
+	production = core.Descriptor{Kind: "git", Pipeline: "app", Metadata: core.Metadata{Name: "production"}}
+)
+
+const (
+	chart = `apiVersion: v2
+name: app
+# bumped on every release
+version: 0.1.9
+appVersion: v1.0.0
+`
+
+	values = `replicaCount: 1
+image:
+  repository: ghcr.io/get-glu/app # the application image
+  tag: v1.0.0
+  digest: sha256:aaa
+`
+)
+
+func TestResource_ReadFrom(t *testing.T) {
+	ctx := context.Background()
+
+	fs, err := memory.NewWithFiles(map[string]string{
+		"charts/app/Chart.yaml":              chart,
+		"charts/app/values.yaml":             values,
+		"charts/app/values-production.yaml":  "image:\n  repository: ghcr.io/get-glu/app\n  tag: v0.9.0\n",
+		"env/staging/values.yaml":            "app:\n  image: ghcr.io/get-glu/app\n  version: v1.1.0\n",
+		"charts/invalid/values.yaml":         "image: [\n",
+		"charts/missing-values/Chart.yaml":   chart,
+		"charts/missing-values/values.other": values,
+	})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name     string
+		resource func() *Resource
+		phase    core.Descriptor
+		expected func(*Resource)
+		err      error
+		errMsg   string
+	}{
+		{
+			name:     "values and chart",
+			resource: New("charts/app"),
+			phase:    staging,
+			expected: func(r *Resource) {
+				r.Repository = "ghcr.io/get-glu/app"
+				r.Tag = "v1.0.0"
+				r.ImageDigest = "sha256:aaa"
+				r.ChartVersion = "0.1.9"
+				r.AppVersion = "v1.0.0"
+			},
+		},
+		{
+			name:     "values file per phase",
+			resource: New("charts/app", WithValuesFile("values-{{ .Metadata.Name }}.yaml")),
+			phase:    production,
+			expected: func(r *Resource) {
+				r.Repository = "ghcr.io/get-glu/app"
+				r.Tag = "v0.9.0"
+				r.ChartVersion = "0.1.9"
+				r.AppVersion = "v1.0.0"
+			},
+		},
+		{
+			name:     "templated chart without Chart.yaml",
+			resource: New("env/{{ .Metadata.Name }}", WithImagePaths(".app.image", ".app.version", "")),
+			phase:    staging,
+			expected: func(r *Resource) {
+				r.Repository = "ghcr.io/get-glu/app"
+				r.Tag = "v1.1.0"
+			},
+		},
+		{
+			name:     "missing values file",
+			resource: New("charts/missing-values"),
+			phase:    staging,
+			err:      os.ErrNotExist,
+		},
+		{
+			name:     "invalid values file",
+			resource: New("charts/invalid"),
+			phase:    staging,
+			errMsg:   `reading "values.yaml"`,
+		},
+		{
+			name:     "invalid template",
+			resource: New("charts/{{ .Metadata.Zone }}"),
+			phase:    staging,
+			errMsg:   "executing chart template",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := test.resource()
+
+			err := r.ReadFrom(ctx, test.phase, fs)
+			switch {
+			case test.err != nil:
+				assert.ErrorIs(t, err, test.err)
+			case test.errMsg != "":
+				assert.ErrorContains(t, err, test.errMsg)
+			default:
+				require.NoError(t, err)
+
+				expected := test.resource()
+				test.expected(expected)
+				assert.Equal(t, expected, r)
+			}
+		})
+	}
+}
+
+func TestResource_WriteTo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("values", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{
+			"charts/app/Chart.yaml":  chart,
+			"charts/app/values.yaml": values,
+		})
+		require.NoError(t, err)
+
+		r := New("charts/app")()
+		r.Tag = "v1.1.0"
+		r.ImageDigest = "sha256:fff"
+
+		require.NoError(t, r.WriteTo(ctx, staging, fs))
+
+		// the versions are read from the target chart
+		assert.Equal(t, "0.1.9", r.ChartVersion)
+		assert.Equal(t, "v1.0.0", r.AppVersion)
+
+		data, err := fs.ReadFile("charts/app/values.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `replicaCount: 1
+image:
+  repository: ghcr.io/get-glu/app # the application image
+  tag: v1.1.0
+  digest: sha256:fff
+`, string(data))
+
+		// the chart is left unchanged
+		data, err = fs.ReadFile("charts/app/Chart.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, chart, string(data))
+	})
+
+	t.Run("chart version bump and app version", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{
+			"env/production/Chart.yaml":  chart,
+			"env/production/values.yaml": values,
+		})
+		require.NoError(t, err)
+
+		r := New("env/{{ .Metadata.Name }}", WithChartVersionBump(), WithAppVersion())()
+		r.Tag = "v1.1.0"
+
+		require.NoError(t, r.WriteTo(ctx, production, fs))
+
+		assert.Equal(t, "0.1.10", r.ChartVersion)
+		assert.Equal(t, "v1.1.0", r.AppVersion)
+
+		data, err := fs.ReadFile("env/production/Chart.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: v2
+name: app
+# bumped on every release
+version: 0.1.10
+appVersion: v1.1.0
+`, string(data))
+
+		read := New("env/{{ .Metadata.Name }}")()
+		require.NoError(t, read.ReadFrom(ctx, production, fs))
+		assert.Equal(t, "v1.1.0", read.Tag)
+		assert.Equal(t, "sha256:aaa", read.ImageDigest)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{
+			"Chart.yaml":  chart,
+			"values.yaml": values,
+		})
+		require.NoError(t, err)
+
+		r := New(".", WithChartVersionBump())()
+		r.Tag = "v1.0.0"
+
+		require.NoError(t, r.WriteTo(ctx, staging, fs))
+
+		// the chart version is only bumped when the values change
+		data, err := fs.ReadFile("Chart.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, chart, string(data))
+	})
+
+	t.Run("values file per phase", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{
+			"values-staging.yaml":    values,
+			"values-production.yaml": values,
+		})
+		require.NoError(t, err)
+
+		r := New(".", WithValuesFile("values-{{ .Metadata.Name }}.yaml"))()
+		r.Tag = "v1.1.0"
+
+		require.NoError(t, r.WriteTo(ctx, staging, fs))
+
+		read := New(".", WithValuesFile("values-{{ .Metadata.Name }}.yaml"))()
+		require.NoError(t, read.ReadFrom(ctx, staging, fs))
+		assert.Equal(t, "v1.1.0", read.Tag)
+
+		require.NoError(t, read.ReadFrom(ctx, production, fs))
+		assert.Equal(t, "v1.0.0", read.Tag)
+	})
+
+	t.Run("bump without chart", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{"values.yaml": values})
+		require.NoError(t, err)
+
+		r := New(".", WithChartVersionBump())()
+		r.Tag = "v1.1.0"
+
+		assert.ErrorIs(t, r.WriteTo(ctx, staging, fs), os.ErrNotExist)
+	})
+}
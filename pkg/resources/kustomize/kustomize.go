@@ -0,0 +1,226 @@
+// Package kustomize provides a resource which reads and updates image overrides
+// (the images: entries) within a kustomization file.
+package kustomize
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/get-glu/glu/internal/yamledit"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/fs"
+	"github.com/get-glu/glu/pkg/resources"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrKustomizationNotFound is returned when no kustomization file exists in the target directory
+	ErrKustomizationNotFound = errors.New("kustomization not found")
+	// ErrImageNotFound is returned when the kustomization contains no images entry with the configured name
+	ErrImageNotFound = errors.New("image not found")
+
+	// kustomizationFiles are the file names recognized by kustomize in order of precedence
+	kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+)
+
+// Resource is a resource whose state is a single image override within a kustomization.
+// The override is selected by its name field, which is the image name as it appears
+// in the manifests being kustomized.
+// The optional overlay is a text/template which is executed with the descriptor of the calling phase,
+// such that a single resource can address an overlay per phase (e.g. overlays/{{ .Metadata.Name }}).
+type Resource struct {
+	Name        string `json:"name"`
+	NewName     string `json:"new_name,omitempty"`
+	NewTag      string `json:"new_tag,omitempty"`
+	ImageDigest string `json:"digest,omitempty"`
+	Overlay     string `json:"overlay,omitempty"`
+}
+
+// New returns a function which constructs resources for the image override with the provided name.
+// It is intended to be supplied as the constructor when building a pipeline.
+func New(name string, opts ...containers.Option[Resource]) func() *Resource {
+	return func() *Resource {
+		r := &Resource{Name: name}
+		containers.ApplyAll(r, opts...)
+		return r
+	}
+}
+
+// WithOverlay configures the directory (relative to the repository root)
+// which contains the kustomization file.
+func WithOverlay(dir string) containers.Option[Resource] {
+	return func(r *Resource) {
+		r.Overlay = dir
+	}
+}
+
+// Digest returns the digest of the selected image.
+// When the override does not pin a digest, the new name and tag are returned instead.
+func (r *Resource) Digest() (string, error) {
+	if r.ImageDigest != "" {
+		return r.ImageDigest, nil
+	}
+
+	return r.NewName + ":" + r.NewTag, nil
+}
+
+// ReadFromOCIDescriptor sets the digest of the override to that of the descriptor.
+// This allows the resource to be used in pipelines which promote from an OCI phase.
+func (r *Resource) ReadFromOCIDescriptor(desc v1.Descriptor) error {
+	r.ImageDigest = desc.Digest.String()
+	return nil
+}
+
+// ReadFrom reads the image override from the kustomization.
+func (r *Resource) ReadFrom(_ context.Context, phase core.Descriptor, fs fs.Filesystem) error {
+	fs, err := r.filesystem(phase, fs)
+	if err != nil {
+		return err
+	}
+
+	path, doc, err := readKustomization(fs)
+	if err != nil {
+		return err
+	}
+
+	index, err := r.index(doc)
+	if err != nil {
+		return fmt.Errorf("reading %q: %w", path, err)
+	}
+
+	for field, value := range r.fields() {
+		if *value, err = doc.Get(fmt.Sprintf(".images[%d].%s", index, field)); err != nil && !errors.Is(err, yamledit.ErrNotFound) {
+			return fmt.Errorf("reading %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteTo updates the image override in the kustomization.
+// Fields which are empty on the resource are left unchanged, such that a resource promoted
+// from an OCI phase only pins the digest of the image.
+// The override must already exist in the kustomization.
+func (r *Resource) WriteTo(_ context.Context, phase core.Descriptor, fs fs.Filesystem) error {
+	fs, err := r.filesystem(phase, fs)
+	if err != nil {
+		return err
+	}
+
+	path, doc, err := readKustomization(fs)
+	if err != nil {
+		return err
+	}
+
+	original := doc.Bytes()
+
+	index, err := r.index(doc)
+	if err != nil {
+		return fmt.Errorf("writing %q: %w", path, err)
+	}
+
+	for field, value := range r.fields() {
+		if *value == "" {
+			continue
+		}
+
+		if err := doc.Set(fmt.Sprintf(".images[%d].%s", index, field), *value); err != nil {
+			return fmt.Errorf("writing %q: %w", path, err)
+		}
+	}
+
+	if bytes.Equal(original, doc.Bytes()) {
+		return nil
+	}
+
+	fi, err := fs.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	defer fi.Close()
+
+	_, err = fi.Write(doc.Bytes())
+	return err
+}
+
+// fields returns the override fields in the order they conventionally appear.
+func (r *Resource) fields() iter.Seq2[string, *string] {
+	return func(yield func(string, *string) bool) {
+		_ = yield("newName", &r.NewName) &&
+			yield("newTag", &r.NewTag) &&
+			yield("digest", &r.ImageDigest)
+	}
+}
+
+// index returns the index of the images entry with the resources name.
+func (r *Resource) index(doc *yamledit.Document) (int, error) {
+	images, err := doc.Node(".images")
+	if err != nil {
+		if errors.Is(err, yamledit.ErrNotFound) {
+			return 0, fmt.Errorf("%q: %w", r.Name, ErrImageNotFound)
+		}
+
+		return 0, err
+	}
+
+	if images.Kind != yaml.SequenceNode {
+		return 0, errors.New("images: expected sequence")
+	}
+
+	for i := range images.Content {
+		if name, err := doc.Get(fmt.Sprintf(".images[%d].name", i)); err == nil && name == r.Name {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%q: %w", r.Name, ErrImageNotFound)
+}
+
+func (r *Resource) filesystem(phase core.Descriptor, filesystem fs.Filesystem) (fs.Filesystem, error) {
+	if r.Overlay == "" {
+		return filesystem, nil
+	}
+
+	dir, err := resources.ExpandPath("overlay", r.Overlay, phase)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.SubFilesystem(filesystem, dir), nil
+}
+
+func readKustomization(filesystem fs.Filesystem) (string, *yamledit.Document, error) {
+	for _, path := range kustomizationFiles {
+		fi, err := filesystem.OpenFile(path, os.O_RDONLY, 0644)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return "", nil, err
+		}
+
+		data, err := io.ReadAll(fi)
+		fi.Close()
+		if err != nil {
+			return "", nil, err
+		}
+
+		doc, err := yamledit.Parse(data)
+		if err != nil {
+			return "", nil, fmt.Errorf("reading %q: %w", path, err)
+		}
+
+		return path, doc, nil
+	}
+
+	return "", nil, ErrKustomizationNotFound
+}
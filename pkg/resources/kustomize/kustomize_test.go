@@ -0,0 +1,183 @@
+package kustomize
+
+import (
+	"context"
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/fs/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	staging    = core.Descriptor{Kind: "git", Pipeline: "app", Metadata: core.Metadata{Name: "staging"}}
+	production = core.Descriptor{Kind: "git", Pipeline: "app", Metadata: core.Metadata{Name: "production"}}
+)
+
+const kustomization = `resources:
+  - ../../base
+images:
+  - name: ghcr.io/get-glu/sidecar
+    newTag: v0.1.0
+  # the application image
+  - name: ghcr.io/get-glu/app
+    newName: registry.local/app
+    newTag: v1.0.0
+    digest: sha256:aaa
+`
+
+func TestResource_ReadFrom(t *testing.T) {
+	ctx := context.Background()
+
+	fs, err := memory.NewWithFiles(map[string]string{
+		"overlays/staging/kustomization.yaml": kustomization,
+		"overlays/production/kustomization.yml": `images:
+  - name: ghcr.io/get-glu/app
+    newTag: v0.9.0
+`,
+		"overlays/dev/Kustomization":  "resources:\n  - ../../base\n",
+		"overlays/empty/service.yaml": "kind: Service\n",
+	})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name     string
+		resource func() *Resource
+		phase    core.Descriptor
+		expected *Resource
+		err      error
+		errMsg   string
+	}{
+		{
+			name:     "overlay",
+			resource: New("ghcr.io/get-glu/app", WithOverlay("overlays/staging")),
+			phase:    staging,
+			expected: &Resource{
+				Name:        "ghcr.io/get-glu/app",
+				NewName:     "registry.local/app",
+				NewTag:      "v1.0.0",
+				ImageDigest: "sha256:aaa",
+				Overlay:     "overlays/staging",
+			},
+		},
+		{
+			name:     "templated overlay",
+			resource: New("ghcr.io/get-glu/app", WithOverlay("overlays/{{ .Metadata.Name }}")),
+			phase:    production,
+			expected: &Resource{
+				Name:    "ghcr.io/get-glu/app",
+				NewTag:  "v0.9.0",
+				Overlay: "overlays/{{ .Metadata.Name }}",
+			},
+		},
+		{
+			name:     "image not found",
+			resource: New("ghcr.io/get-glu/other", WithOverlay("overlays/staging")),
+			phase:    staging,
+			err:      ErrImageNotFound,
+		},
+		{
+			name:     "no images",
+			resource: New("ghcr.io/get-glu/app", WithOverlay("overlays/dev")),
+			phase:    staging,
+			err:      ErrImageNotFound,
+		},
+		{
+			name:     "kustomization not found",
+			resource: New("ghcr.io/get-glu/app", WithOverlay("overlays/empty")),
+			phase:    staging,
+			err:      ErrKustomizationNotFound,
+		},
+		{
+			name:     "invalid template",
+			resource: New("ghcr.io/get-glu/app", WithOverlay("overlays/{{ .Metadata.Zone }}")),
+			phase:    staging,
+			errMsg:   "executing overlay template",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := test.resource()
+
+			err := r.ReadFrom(ctx, test.phase, fs)
+			switch {
+			case test.err != nil:
+				assert.ErrorIs(t, err, test.err)
+			case test.errMsg != "":
+				assert.ErrorContains(t, err, test.errMsg)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, r)
+			}
+		})
+	}
+}
+
+func TestResource_WriteTo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("digest", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{"overlays/staging/kustomization.yaml": kustomization})
+		require.NoError(t, err)
+
+		// a resource promoted from an OCI phase only pins the digest
+		r := New("ghcr.io/get-glu/app", WithOverlay("overlays/{{ .Metadata.Name }}"))()
+		r.ImageDigest = "sha256:fff"
+
+		require.NoError(t, r.WriteTo(ctx, staging, fs))
+
+		data, err := fs.ReadFile("overlays/staging/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `resources:
+  - ../../base
+images:
+  - name: ghcr.io/get-glu/sidecar
+    newTag: v0.1.0
+  # the application image
+  - name: ghcr.io/get-glu/app
+    newName: registry.local/app
+    newTag: v1.0.0
+    digest: sha256:fff
+`, string(data))
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{"kustomization.yaml": kustomization})
+		require.NoError(t, err)
+
+		r := New("ghcr.io/get-glu/sidecar")()
+		r.NewTag = "v0.2.0"
+
+		require.NoError(t, r.WriteTo(ctx, staging, fs))
+
+		read := New("ghcr.io/get-glu/sidecar")()
+		require.NoError(t, read.ReadFrom(ctx, staging, fs))
+		assert.Equal(t, &Resource{Name: "ghcr.io/get-glu/sidecar", NewTag: "v0.2.0"}, read)
+
+		// the other override is left unchanged
+		other := New("ghcr.io/get-glu/app")()
+		require.NoError(t, other.ReadFrom(ctx, staging, fs))
+		assert.Equal(t, "sha256:aaa", other.ImageDigest)
+	})
+
+	t.Run("image not found", func(t *testing.T) {
+		fs, err := memory.NewWithFiles(map[string]string{"kustomization.yaml": kustomization})
+		require.NoError(t, err)
+
+		r := New("ghcr.io/get-glu/other")()
+		r.ImageDigest = "sha256:fff"
+
+		assert.ErrorIs(t, r.WriteTo(ctx, staging, fs), ErrImageNotFound)
+
+		data, err := fs.ReadFile("kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, kustomization, string(data))
+	})
+
+	t.Run("kustomization not found", func(t *testing.T) {
+		r := New("ghcr.io/get-glu/app")()
+		r.ImageDigest = "sha256:fff"
+
+		assert.ErrorIs(t, r.WriteTo(ctx, staging, memory.New()), ErrKustomizationNotFound)
+	})
+}
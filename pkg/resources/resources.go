@@ -0,0 +1,35 @@
+// Package resources contains functionality shared by the resource implementations
+// in its sub-packages (file, helm and kustomize).
+package resources
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/get-glu/glu/pkg/core"
+)
+
+// ExpandPath executes the path as a text/template with the descriptor of the calling phase
+// (e.g. env/{{ .Metadata.Name }}/values.yaml), such that a single resource can address a
+// path per phase. Paths which do not contain a template are returned unchanged.
+// The name identifies the path in returned errors (e.g. "values file").
+func ExpandPath(name, text string, phase core.Descriptor) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, phase); err != nil {
+		return "", fmt.Errorf("executing %s template: %w", name, err)
+	}
+
+	return path.Clean(buf.String()), nil
+}
@@ -0,0 +1,43 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandPath(t *testing.T) {
+	phase := core.Descriptor{
+		Kind:     "git",
+		Pipeline: "app",
+		Metadata: core.Metadata{Name: "staging", Labels: map[string]string{"region": "eu"}},
+	}
+
+	for _, test := range []struct {
+		name     string
+		path     string
+		expected string
+		err      string
+	}{
+		{name: "plain", path: "env/prod/values.yaml", expected: "env/prod/values.yaml"},
+		{name: "empty", path: "", expected: ""},
+		{name: "name", path: "env/{{ .Metadata.Name }}/values.yaml", expected: "env/staging/values.yaml"},
+		{name: "pipeline and label", path: "{{ .Pipeline }}/{{ .Metadata.Labels.region }}", expected: "app/eu"},
+		{name: "cleaned", path: "env/./{{ .Metadata.Name }}/../{{ .Metadata.Name }}/", expected: "env/staging"},
+		{name: "missing label", path: "{{ .Metadata.Labels.zone }}", err: "executing path template"},
+		{name: "unknown field", path: "{{ .Unknown }}", err: "executing path template"},
+		{name: "invalid template", path: "{{ .Metadata.Name", err: "parsing path template"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := ExpandPath("path", test.path, phase)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, path)
+		})
+	}
+}
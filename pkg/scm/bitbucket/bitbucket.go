@@ -0,0 +1,265 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/phases/git"
+)
+
+var _ git.MergingProposer = (*SCM)(nil)
+
+// pullRequest is the flavor agnostic representation of a Bitbucket pull request.
+type pullRequest struct {
+	ID         int
+	Version    int
+	URL        string
+	Open       bool
+	Branch     string
+	HeadSHA    string
+	BaseBranch string
+	BaseSHA    string
+}
+
+// api abstracts the differences between the Bitbucket Cloud and Data Center pull request APIs.
+type api interface {
+	list(_ context.Context, base string, fn func(*pullRequest) bool) error
+	get(_ context.Context, id int) (*pullRequest, error)
+	create(_ context.Context, _ *git.Proposal, reviewers []string) (*pullRequest, error)
+	decline(context.Context, *pullRequest) (*pullRequest, error)
+	comment(_ context.Context, id int, message string) error
+	defaultReviewers(context.Context, *git.Proposal) ([]string, error)
+	status(context.Context, *pullRequest) (*git.ProposalStatus, error)
+	merge(context.Context, *pullRequest, git.MergeMethod) error
+}
+
+// SCM is a git.Proposer which manages proposals as Bitbucket pull requests.
+type SCM struct {
+	api    api
+	flavor string
+
+	reviewers        []string
+	defaultReviewers bool
+}
+
+// WithReviewers configures the reviewers requested on each created pull request.
+// For Bitbucket Cloud these are user UUIDs (e.g. {abc-123}) or account IDs,
+// while for Bitbucket Data Center these are user slugs.
+func WithReviewers(reviewers ...string) containers.Option[SCM] {
+	return func(s *SCM) {
+		s.reviewers = reviewers
+	}
+}
+
+// WithDefaultReviewers configures the SCM to request the repositories
+// default reviewers on each created pull request.
+func WithDefaultReviewers() containers.Option[SCM] {
+	return func(s *SCM) {
+		s.defaultReviewers = true
+	}
+}
+
+func newSCM(api api, flavor string, opts ...containers.Option[SCM]) *SCM {
+	scm := &SCM{api: api, flavor: flavor}
+
+	containers.ApplyAll(scm, opts...)
+
+	return scm
+}
+
+func (s *SCM) GetCurrentProposal(ctx context.Context, baseBranch, branchPrefix string) (*git.Proposal, error) {
+	var proposal *git.Proposal
+	if err := s.api.list(ctx, baseBranch, func(pr *pullRequest) bool {
+		if !strings.HasPrefix(pr.Branch, branchPrefix) {
+			return true
+		}
+
+		proposal = pr.proposal()
+		return false
+	}); err != nil {
+		return nil, err
+	}
+
+	if proposal == nil {
+		return nil, fmt.Errorf("base %q: prefix %q: %w", baseBranch, branchPrefix, git.ErrProposalNotFound)
+	}
+
+	return proposal, nil
+}
+
+func (s *SCM) IsProposalOpen(ctx context.Context, proposal *git.Proposal) bool {
+	id, ok := prID(proposal)
+	if !ok {
+		return false
+	}
+
+	pr, err := s.api.get(ctx, id)
+	if err != nil {
+		slog.Warn("could not check if PR is open", "reason", "error getting PR", "error", err)
+		return false
+	}
+
+	return pr.Open
+}
+
+func (s *SCM) CreateProposal(ctx context.Context, proposal *git.Proposal, opts git.ProposalOption) error {
+	slog := slog.With(
+		"branch", proposal.Branch,
+		"base", proposal.BaseBranch,
+	)
+
+	if len(opts.Labels) > 0 {
+		slog.Warn("bitbucket does not support labels on pull requests", "labels", opts.Labels)
+	}
+
+	reviewers := slices.Clone(s.reviewers)
+	if s.defaultReviewers {
+		defaults, err := s.api.defaultReviewers(ctx, proposal)
+		if err != nil {
+			return fmt.Errorf("listing default reviewers: %w", err)
+		}
+
+		for _, reviewer := range defaults {
+			if !slices.Contains(reviewers, reviewer) {
+				reviewers = append(reviewers, reviewer)
+			}
+		}
+	}
+
+	pr, err := s.api.create(ctx, proposal, reviewers)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("proposal created", "scm_type", s.flavor, "proposal_url", pr.URL)
+
+	proposal.ID = strconv.Itoa(pr.ID)
+	proposal.URL = pr.URL
+	proposal.BaseRevision = pr.BaseSHA
+	proposal.HeadRevision = pr.HeadSHA
+	proposal.Annotations = map[string]string{}
+
+	return nil
+}
+
+func (s *SCM) CloseProposal(ctx context.Context, proposal *git.Proposal) error {
+	slog := slog.With(
+		"branch", proposal.Branch,
+		"base", proposal.BaseBranch,
+	)
+
+	id, ok := prID(proposal)
+	if !ok {
+		return nil
+	}
+
+	pr, err := s.api.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if pr, err = s.api.decline(ctx, pr); err != nil {
+		return err
+	}
+
+	slog.Info("proposal closed", "scm_type", s.flavor, "proposal_url", pr.URL)
+
+	proposal.BaseRevision = pr.BaseSHA
+	proposal.HeadRevision = pr.HeadSHA
+
+	return nil
+}
+
+func (s *SCM) CommentProposal(ctx context.Context, proposal *git.Proposal, message string) error {
+	id, ok := prID(proposal)
+	if !ok {
+		return nil
+	}
+
+	return s.api.comment(ctx, id, message)
+}
+
+func (s *SCM) GetProposalStatus(ctx context.Context, proposal *git.Proposal) (*git.ProposalStatus, error) {
+	id, ok := prID(proposal)
+	if !ok {
+		return nil, fmt.Errorf("proposal %q: %w", proposal.ID, git.ErrProposalNotFound)
+	}
+
+	pr, err := s.api.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal.HeadRevision = pr.HeadSHA
+
+	return s.api.status(ctx, pr)
+}
+
+func (s *SCM) MergeProposal(ctx context.Context, proposal *git.Proposal, method git.MergeMethod) error {
+	id, ok := prID(proposal)
+	if !ok {
+		return fmt.Errorf("proposal %q: %w", proposal.ID, git.ErrProposalNotFound)
+	}
+
+	pr, err := s.api.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// bitbucket does not accept an expected head on merge so guard
+	// against merging a head which has moved since the status was inspected
+	if proposal.HeadRevision != "" && pr.HeadSHA != proposal.HeadRevision {
+		return fmt.Errorf("%s: head of proposal %q has moved from %q to %q", s.flavor, proposal.ID, proposal.HeadRevision, pr.HeadSHA)
+	}
+
+	if err := s.api.merge(ctx, pr, method); err != nil {
+		return err
+	}
+
+	slog.Info("proposal merged", "scm_type", s.flavor, "proposal_url", pr.URL)
+
+	return nil
+}
+
+func (pr *pullRequest) proposal() *git.Proposal {
+	parts := strings.Split(pr.Branch, "/")
+	return &git.Proposal{
+		ID:  strconv.Itoa(pr.ID),
+		URL: pr.URL,
+
+		BaseBranch:   pr.BaseBranch,
+		BaseRevision: pr.BaseSHA,
+		Branch:       pr.Branch,
+		HeadRevision: pr.HeadSHA,
+		Digest:       parts[len(parts)-1],
+
+		Annotations: map[string]string{},
+	}
+}
+
+// buildState maps the build states reported by both Bitbucket flavors onto a check state.
+func buildState(state string) git.CheckState {
+	switch state {
+	case "SUCCESSFUL":
+		return git.CheckStateSuccess
+	case "FAILED", "STOPPED", "UNKNOWN":
+		return git.CheckStateFailure
+	default:
+		return git.CheckStatePending
+	}
+}
+
+func prID(proposal *git.Proposal) (int, bool) {
+	id, err := strconv.Atoi(proposal.ID)
+	if err != nil {
+		slog.Warn("could not check if PR is open", "reason", "missing PR ID on proposal", "error", err)
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,617 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/get-glu/glu/internal/scm/scmtest"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flavor constructs a flavor of the SCM against the fake
+// with the provided reviewers and default reviewers enabled.
+type flavor struct {
+	name string
+	new  func(f *fakeBitbucket, reviewers ...string) *SCM
+}
+
+var flavors = []flavor{
+	{
+		name: "cloud",
+		new: func(f *fakeBitbucket, reviewers ...string) *SCM {
+			return NewCloud(f.Client(), f.URL+"/2.0", "workspace", "app", WithReviewers(reviewers...), WithDefaultReviewers())
+		},
+	},
+	{
+		name: "data center",
+		new: func(f *fakeBitbucket, reviewers ...string) *SCM {
+			return NewDataCenter(f.Client(), f.URL, "PROJ", "app", WithReviewers(reviewers...), WithDefaultReviewers())
+		},
+	},
+}
+
+func TestSCM(t *testing.T) {
+	for _, flavor := range flavors {
+		t.Run(flavor.name, func(t *testing.T) {
+			scmtest.Run(t, func(t *testing.T) (git.Proposer, scmtest.Fake) {
+				server := newFakeBitbucket(t)
+				return flavor.new(server), server
+			})
+		})
+	}
+}
+
+func TestSCM_CreateProposal_Reviewers(t *testing.T) {
+	for _, test := range []struct {
+		flavor    string
+		reviewers []string
+		url       string
+		expected  []string
+	}{
+		{
+			flavor:    "cloud",
+			reviewers: []string{"{alice}", "bob-account-id"},
+			url:       "https://bitbucket.org/workspace/app/pull-requests/1",
+			// reviewers are identified by UUID (in braces) or account ID
+			expected: []string{"{alice}", "bob-account-id", "{carol}"},
+		},
+		{
+			flavor:    "data center",
+			reviewers: []string{"alice", "bob"},
+			url:       "https://bitbucket.example.com/projects/PROJ/repos/app/pull-requests/1",
+			expected:  []string{"alice", "bob", "carol"},
+		},
+	} {
+		t.Run(test.flavor, func(t *testing.T) {
+			var (
+				ctx    = context.Background()
+				server = newFakeBitbucket(t)
+				scm    = flavorSCM(t, test.flavor, server, test.reviewers...)
+			)
+
+			proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+			require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+			assert.Equal(t, test.url, proposal.URL)
+
+			// default reviewers are appended to those configured without duplicates
+			assert.Equal(t, test.expected, server.prs[1].reviewers)
+		})
+	}
+}
+
+func TestSCM_CloseProposal_DataCenterVersion(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeBitbucket(t)
+		scm    = flavorSCM(t, "data center", server)
+	)
+
+	proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+	require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+
+	// the pull request has been updated since it was created, so declining
+	// must supply the current version in order to avoid a conflict
+	server.prs[1].version = 3
+
+	require.NoError(t, scm.CloseProposal(ctx, proposal))
+	assert.Equal(t, "DECLINED", server.prs[1].state)
+}
+
+func TestSCM_GetProposalStatus_CloudMergeable(t *testing.T) {
+	for _, conflicted := range []bool{false, true} {
+		t.Run(fmt.Sprintf("conflicted=%t", conflicted), func(t *testing.T) {
+			var (
+				ctx    = context.Background()
+				server = newFakeBitbucket(t)
+				scm    = flavorSCM(t, "cloud", server)
+			)
+
+			proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+			require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+
+			server.prs[1].conflicted = conflicted
+
+			status, err := scm.GetProposalStatus(ctx, proposal)
+			require.NoError(t, err)
+			assert.Equal(t, !conflicted, status.Mergeable)
+		})
+	}
+}
+
+func flavorSCM(t *testing.T, name string, f *fakeBitbucket, reviewers ...string) *SCM {
+	t.Helper()
+
+	i := slices.IndexFunc(flavors, func(f flavor) bool { return f.name == name })
+	require.GreaterOrEqual(t, i, 0, "unknown flavor %q", name)
+
+	return flavors[i].new(f, reviewers...)
+}
+
+type fakePullRequest struct {
+	id        int
+	version   int
+	state     string
+	branch    string
+	base      string
+	reviewers []string
+	comments  []string
+	// conflicted reports a conflicting file in the diffstat of the pull request
+	conflicted bool
+	// statuses are the build states (e.g. SUCCESSFUL) of the head keyed by name
+	statuses map[string]string
+	// reviews are the latest review of each user (true when approved)
+	reviews map[string]bool
+	merged  git.MergeMethod
+}
+
+// fakeBitbucket is a minimal in-memory implementation of both the Bitbucket Cloud
+// (under /2.0) and Data Center (under /rest) pull request APIs used by the SCM.
+type fakeBitbucket struct {
+	*scmtest.Server
+
+	prs map[int]*fakePullRequest
+}
+
+func newFakeBitbucket(t *testing.T) *fakeBitbucket {
+	t.Helper()
+
+	f := &fakeBitbucket{Server: scmtest.NewServer(t), prs: map[int]*fakePullRequest{}}
+
+	const (
+		cloud      = "/2.0/repositories/workspace/app"
+		dataCenter = "/rest/api/1.0/projects/PROJ/repos/app"
+	)
+
+	// cloud
+	f.Handle("GET "+cloud+"/pullrequests", f.cloudList)
+	f.Handle("POST "+cloud+"/pullrequests", f.cloudCreate)
+	f.Handle("GET "+cloud+"/pullrequests/{id}", f.cloudGet)
+	f.Handle("POST "+cloud+"/pullrequests/{id}/decline", f.cloudDecline)
+	f.Handle("POST "+cloud+"/pullrequests/{id}/comments", f.cloudComment)
+	f.Handle("GET "+cloud+"/effective-default-reviewers", f.cloudDefaultReviewers)
+	f.Handle("GET "+cloud+"/pullrequests/{id}/statuses", f.cloudStatuses)
+	f.Handle("GET "+cloud+"/pullrequests/{id}/diffstat", f.cloudDiffstat)
+	f.Handle("POST "+cloud+"/pullrequests/{id}/merge", f.cloudMerge)
+	// data center
+	f.Handle("GET "+dataCenter, f.dcRepository)
+	f.Handle("GET "+dataCenter+"/pull-requests", f.dcList)
+	f.Handle("POST "+dataCenter+"/pull-requests", f.dcCreate)
+	f.Handle("GET "+dataCenter+"/pull-requests/{id}", f.dcGet)
+	f.Handle("POST "+dataCenter+"/pull-requests/{id}/decline", f.dcDecline)
+	f.Handle("POST "+dataCenter+"/pull-requests/{id}/comments", f.dcComment)
+	f.Handle("GET "+dataCenter+"/pull-requests/{id}/merge", f.dcMergeability)
+	f.Handle("POST "+dataCenter+"/pull-requests/{id}/merge", f.dcMerge)
+	f.Handle("GET /rest/default-reviewers/1.0/projects/PROJ/repos/app/reviewers", f.dcDefaultReviewers)
+	f.Handle("GET /rest/build-status/1.0/commits/{sha}", f.dcBuildStatus)
+
+	return f
+}
+
+func (f *fakeBitbucket) Comments(id string) []string {
+	i, _ := strconv.Atoi(id)
+	if pr, ok := f.prs[i]; ok {
+		return pr.comments
+	}
+
+	return nil
+}
+
+func (f *fakeBitbucket) Check(id, name string, state git.CheckState) {
+	i, _ := strconv.Atoi(id)
+	f.prs[i].statuses[name] = map[git.CheckState]string{
+		git.CheckStateSuccess: "SUCCESSFUL",
+		git.CheckStatePending: "INPROGRESS",
+		git.CheckStateFailure: "FAILED",
+	}[state]
+}
+
+func (f *fakeBitbucket) Review(id, user string, approve bool) {
+	i, _ := strconv.Atoi(id)
+	f.prs[i].reviews[user] = approve
+}
+
+func (f *fakeBitbucket) Merged(id string) (git.MergeMethod, bool) {
+	i, _ := strconv.Atoi(id)
+	pr := f.prs[i]
+	return pr.merged, pr.merged != ""
+}
+
+func (f *fakeBitbucket) add(branch, base string, reviewers []string) *fakePullRequest {
+	pr := &fakePullRequest{
+		id:        len(f.prs) + 1,
+		state:     "OPEN",
+		branch:    branch,
+		base:      base,
+		reviewers: reviewers,
+		statuses:  map[string]string{},
+		reviews:   map[string]bool{},
+	}
+	f.prs[pr.id] = pr
+	return pr
+}
+
+func (f *fakeBitbucket) open(base string) (prs []*fakePullRequest) {
+	for i := 1; i <= len(f.prs); i++ {
+		if pr := f.prs[i]; pr.state == "OPEN" && pr.base == base {
+			prs = append(prs, pr)
+		}
+	}
+
+	return prs
+}
+
+func (f *fakeBitbucket) lookup(w http.ResponseWriter, r *http.Request) (*fakePullRequest, bool) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	pr, ok := f.prs[id]
+	if !ok {
+		http.NotFound(w, r)
+	}
+
+	return pr, ok
+}
+
+func (pr *fakePullRequest) cloud() cloudPullRequest {
+	c := cloudPullRequest{ID: pr.id, State: pr.state}
+	c.Links.HTML.Href = fmt.Sprintf("https://bitbucket.org/workspace/app/pull-requests/%d", pr.id)
+	c.Source.Branch.Name = pr.branch
+	c.Source.Commit.Hash = scmtest.HeadRevision
+	c.Destination.Branch.Name = pr.base
+	c.Destination.Commit.Hash = scmtest.BaseRevision
+	return c
+}
+
+func (f *fakeBitbucket) cloudList(w http.ResponseWriter, r *http.Request) {
+	// parse the expected query in the form: state="OPEN" AND destination.branch.name="<base>"
+	q := r.URL.Query().Get("q")
+	_, base, _ := strings.Cut(q, `destination.branch.name="`)
+	if !strings.Contains(q, `state="OPEN"`) {
+		http.Error(w, "unexpected query", http.StatusBadRequest)
+		return
+	}
+
+	page := cloudPage[cloudPullRequest]{Values: []cloudPullRequest{}}
+	for _, pr := range f.open(strings.TrimSuffix(base, `"`)) {
+		page.Values = append(page.Values, pr.cloud())
+	}
+
+	scmtest.Encode(w, http.StatusOK, page)
+}
+
+func (f *fakeBitbucket) cloudCreate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Source      cloudRef `json:"source"`
+		Destination cloudRef `json:"destination"`
+		Reviewers   []struct {
+			UUID      string `json:"uuid"`
+			AccountID string `json:"account_id"`
+		} `json:"reviewers"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	var reviewers []string
+	for _, reviewer := range body.Reviewers {
+		reviewers = append(reviewers, reviewer.UUID+reviewer.AccountID)
+	}
+
+	pr := f.add(body.Source.Branch.Name, body.Destination.Branch.Name, reviewers)
+
+	scmtest.Encode(w, http.StatusCreated, pr.cloud())
+}
+
+// cloudGet reports the pull request along with the latest review of each participant.
+func (f *fakeBitbucket) cloudGet(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	type participant struct {
+		Approved bool   `json:"approved"`
+		State    string `json:"state"`
+	}
+
+	details := struct {
+		cloudPullRequest
+		Participants []participant `json:"participants"`
+	}{cloudPullRequest: pr.cloud()}
+
+	for _, approved := range pr.reviews {
+		if approved {
+			details.Participants = append(details.Participants, participant{Approved: true, State: "approved"})
+			continue
+		}
+
+		details.Participants = append(details.Participants, participant{State: "changes_requested"})
+	}
+
+	scmtest.Encode(w, http.StatusOK, details)
+}
+
+func (f *fakeBitbucket) cloudDecline(w http.ResponseWriter, r *http.Request) {
+	if pr, ok := f.lookup(w, r); ok {
+		pr.state = "DECLINED"
+		scmtest.Encode(w, http.StatusOK, pr.cloud())
+	}
+}
+
+func (f *fakeBitbucket) cloudComment(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Content struct {
+			Raw string `json:"raw"`
+		} `json:"content"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	pr.comments = append(pr.comments, body.Content.Raw)
+
+	scmtest.Encode(w, http.StatusCreated, map[string]any{"id": len(pr.comments)})
+}
+
+func (f *fakeBitbucket) cloudDefaultReviewers(w http.ResponseWriter, r *http.Request) {
+	// includes a configured reviewer to ensure reviewers are deduplicated
+	scmtest.Encode(w, http.StatusOK, map[string]any{
+		"values": []map[string]any{
+			{"user": map[string]string{"uuid": "{alice}"}},
+			{"user": map[string]string{"uuid": "{carol}"}},
+		},
+	})
+}
+
+func (f *fakeBitbucket) cloudStatuses(w http.ResponseWriter, r *http.Request) {
+	if pr, ok := f.lookup(w, r); ok {
+		scmtest.Encode(w, http.StatusOK, cloudPage[map[string]string]{Values: pr.buildStatuses()})
+	}
+}
+
+func (f *fakeBitbucket) cloudMerge(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		MergeStrategy string `json:"merge_strategy"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	pr.merge(map[string]git.MergeMethod{
+		"merge_commit":        git.MergeMethodMerge,
+		"squash":              git.MergeMethodSquash,
+		"rebase_fast_forward": git.MergeMethodRebase,
+	}[body.MergeStrategy])
+
+	scmtest.Encode(w, http.StatusOK, pr.cloud())
+}
+
+func (pr *fakePullRequest) buildStatuses() []map[string]string {
+	statuses := []map[string]string{}
+	for key, state := range pr.statuses {
+		statuses = append(statuses, map[string]string{"key": key, "state": state})
+	}
+
+	return statuses
+}
+
+func (pr *fakePullRequest) merge(method git.MergeMethod) {
+	pr.state = "MERGED"
+	pr.version++
+	pr.merged = method
+}
+
+// cloudDiffstat reports the diffstat of the pull request over two pages,
+// where the conflicted file (if any) is reported on the second page.
+func (f *fakeBitbucket) cloudDiffstat(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	page := cloudPage[map[string]string]{Values: []map[string]string{{"status": "modified"}}}
+	if r.URL.Query().Get("page") != "2" {
+		page.Next = r.URL.Path + "?page=2"
+	} else if pr.conflicted {
+		page.Values = append(page.Values, map[string]string{"status": "merge conflict"})
+	}
+
+	scmtest.Encode(w, http.StatusOK, page)
+}
+
+func (pr *fakePullRequest) dataCenter() dataCenterPullRequest {
+	d := dataCenterPullRequest{ID: pr.id, Version: pr.version, State: pr.state}
+	d.Links.Self = append(d.Links.Self, struct {
+		Href string `json:"href"`
+	}{Href: fmt.Sprintf("https://bitbucket.example.com/projects/PROJ/repos/app/pull-requests/%d", pr.id)})
+	d.FromRef = dataCenterRef{ID: "refs/heads/" + pr.branch, DisplayID: pr.branch, LatestCommit: scmtest.HeadRevision}
+	d.ToRef = dataCenterRef{ID: "refs/heads/" + pr.base, DisplayID: pr.base, LatestCommit: scmtest.BaseRevision}
+	return d
+}
+
+func (f *fakeBitbucket) dcRepository(w http.ResponseWriter, r *http.Request) {
+	scmtest.Encode(w, http.StatusOK, map[string]any{"id": 42, "slug": "app"})
+}
+
+func (f *fakeBitbucket) dcList(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("state") != "OPEN" || r.URL.Query().Get("direction") != "INCOMING" {
+		http.Error(w, "unexpected query", http.StatusBadRequest)
+		return
+	}
+
+	page := dataCenterPage[dataCenterPullRequest]{Values: []dataCenterPullRequest{}, IsLastPage: true}
+	for _, pr := range f.open(strings.TrimPrefix(r.URL.Query().Get("at"), "refs/heads/")) {
+		page.Values = append(page.Values, pr.dataCenter())
+	}
+
+	scmtest.Encode(w, http.StatusOK, page)
+}
+
+func (f *fakeBitbucket) dcCreate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		FromRef   dataCenterRef `json:"fromRef"`
+		ToRef     dataCenterRef `json:"toRef"`
+		Reviewers []struct {
+			User dataCenterUser `json:"user"`
+		} `json:"reviewers"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	var reviewers []string
+	for _, reviewer := range body.Reviewers {
+		reviewers = append(reviewers, reviewer.User.Name)
+	}
+
+	pr := f.add(
+		strings.TrimPrefix(body.FromRef.ID, "refs/heads/"),
+		strings.TrimPrefix(body.ToRef.ID, "refs/heads/"),
+		reviewers,
+	)
+
+	scmtest.Encode(w, http.StatusCreated, pr.dataCenter())
+}
+
+// dcGet reports the pull request along with the latest review of each reviewer.
+func (f *fakeBitbucket) dcGet(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	type reviewer struct {
+		Approved bool   `json:"approved"`
+		Status   string `json:"status"`
+	}
+
+	details := struct {
+		dataCenterPullRequest
+		Reviewers []reviewer `json:"reviewers"`
+	}{dataCenterPullRequest: pr.dataCenter()}
+
+	for _, approved := range pr.reviews {
+		if approved {
+			details.Reviewers = append(details.Reviewers, reviewer{Approved: true, Status: "APPROVED"})
+			continue
+		}
+
+		details.Reviewers = append(details.Reviewers, reviewer{Status: "NEEDS_WORK"})
+	}
+
+	scmtest.Encode(w, http.StatusOK, details)
+}
+
+func (f *fakeBitbucket) dcMergeability(w http.ResponseWriter, r *http.Request) {
+	if pr, ok := f.lookup(w, r); ok {
+		scmtest.Encode(w, http.StatusOK, map[string]bool{"canMerge": !pr.conflicted, "conflicted": pr.conflicted})
+	}
+}
+
+func (f *fakeBitbucket) dcMerge(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	if r.URL.Query().Get("version") != strconv.Itoa(pr.version) {
+		http.Error(w, "version mismatch", http.StatusConflict)
+		return
+	}
+
+	var body struct {
+		StrategyID string `json:"strategyId"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	pr.merge(map[string]git.MergeMethod{
+		"no-ff":        git.MergeMethodMerge,
+		"squash":       git.MergeMethodSquash,
+		"rebase-no-ff": git.MergeMethodRebase,
+	}[body.StrategyID])
+
+	scmtest.Encode(w, http.StatusOK, pr.dataCenter())
+}
+
+// dcBuildStatus reports the build statuses of the head of every pull request.
+func (f *fakeBitbucket) dcBuildStatus(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("sha") != scmtest.HeadRevision {
+		http.NotFound(w, r)
+		return
+	}
+
+	page := dataCenterPage[map[string]string]{Values: []map[string]string{}, IsLastPage: true}
+	for i := 1; i <= len(f.prs); i++ {
+		page.Values = append(page.Values, f.prs[i].buildStatuses()...)
+	}
+
+	scmtest.Encode(w, http.StatusOK, page)
+}
+
+func (f *fakeBitbucket) dcDecline(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	if r.URL.Query().Get("version") != strconv.Itoa(pr.version) {
+		http.Error(w, "version mismatch", http.StatusConflict)
+		return
+	}
+
+	pr.state = "DECLINED"
+	pr.version++
+
+	scmtest.Encode(w, http.StatusOK, pr.dataCenter())
+}
+
+func (f *fakeBitbucket) dcComment(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Text string `json:"text"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	pr.comments = append(pr.comments, body.Text)
+
+	scmtest.Encode(w, http.StatusCreated, map[string]any{"id": len(pr.comments)})
+}
+
+func (f *fakeBitbucket) dcDefaultReviewers(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("sourceRepoId") != "42" || r.URL.Query().Get("targetRefId") != "refs/heads/main" {
+		scmtest.Encode(w, http.StatusOK, []dataCenterUser{})
+		return
+	}
+
+	// includes a configured reviewer to ensure reviewers are deduplicated
+	scmtest.Encode(w, http.StatusOK, []dataCenterUser{{Name: "bob"}, {Name: "carol"}})
+}
@@ -0,0 +1,292 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/get-glu/glu/internal/scm"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/phases/git"
+)
+
+// CloudBaseURL is the root of the Bitbucket Cloud REST API.
+const CloudBaseURL = "https://api.bitbucket.org/2.0"
+
+// NewCloud constructs a new SCM for a repository in Bitbucket Cloud
+// identified by its workspace and repository slug.
+// The baseURL is the root of the REST API (see CloudBaseURL).
+// The client is expected to authenticate requests (e.g. via an access token or app password).
+func NewCloud(client *http.Client, baseURL, workspace, repoSlug string, opts ...containers.Option[SCM]) *SCM {
+	return newSCM(&cloud{
+		client:    scm.NewClient("bitbucket", client, baseURL),
+		workspace: workspace,
+		repoSlug:  repoSlug,
+	}, "bitbucket", opts...)
+}
+
+type cloud struct {
+	client    *scm.Client
+	workspace string
+	repoSlug  string
+}
+
+type cloudRef struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+}
+
+type cloudPullRequest struct {
+	ID    int    `json:"id"`
+	State string `json:"state"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+	Source      cloudRef `json:"source"`
+	Destination cloudRef `json:"destination"`
+}
+
+type cloudPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+func (pr *cloudPullRequest) pullRequest() *pullRequest {
+	return &pullRequest{
+		ID:         pr.ID,
+		URL:        pr.Links.HTML.Href,
+		Open:       pr.State == "OPEN",
+		Branch:     pr.Source.Branch.Name,
+		HeadSHA:    pr.Source.Commit.Hash,
+		BaseBranch: pr.Destination.Branch.Name,
+		BaseSHA:    pr.Destination.Commit.Hash,
+	}
+}
+
+func (c *cloud) repoPath() string {
+	return "/repositories/" + url.PathEscape(c.workspace) + "/" + url.PathEscape(c.repoSlug)
+}
+
+func (c *cloud) prPath(id int) string {
+	return c.repoPath() + "/pullrequests/" + strconv.Itoa(id)
+}
+
+func (c *cloud) list(ctx context.Context, base string, fn func(*pullRequest) bool) error {
+	for page := 1; ; page++ {
+		query := url.Values{
+			"q":       []string{fmt.Sprintf("state=%q AND destination.branch.name=%q", "OPEN", base)},
+			"page":    []string{strconv.Itoa(page)},
+			"pagelen": []string{"50"},
+		}
+
+		var prs cloudPage[cloudPullRequest]
+		if _, err := c.client.Do(ctx, http.MethodGet, c.repoPath()+"/pullrequests?"+query.Encode(), nil, &prs); err != nil {
+			return err
+		}
+
+		for _, pr := range prs.Values {
+			if !strings.HasPrefix(pr.Source.Branch.Name, "glu/") {
+				continue
+			}
+
+			if !fn(pr.pullRequest()) {
+				return nil
+			}
+		}
+
+		if prs.Next == "" {
+			return nil
+		}
+	}
+}
+
+func (c *cloud) get(ctx context.Context, id int) (*pullRequest, error) {
+	var pr cloudPullRequest
+	if _, err := c.client.Do(ctx, http.MethodGet, c.prPath(id), nil, &pr); err != nil {
+		return nil, err
+	}
+
+	return pr.pullRequest(), nil
+}
+
+func (c *cloud) create(ctx context.Context, proposal *git.Proposal, reviewers []string) (*pullRequest, error) {
+	body := map[string]any{
+		"title":       proposal.Title,
+		"description": proposal.Body,
+		"source": map[string]any{
+			"branch": map[string]string{"name": proposal.Branch},
+		},
+		"destination": map[string]any{
+			"branch": map[string]string{"name": proposal.BaseBranch},
+		},
+	}
+
+	if len(reviewers) > 0 {
+		var users []map[string]string
+		for _, reviewer := range reviewers {
+			// users are identified by either their UUID (wrapped in braces) or account ID
+			if strings.HasPrefix(reviewer, "{") {
+				users = append(users, map[string]string{"uuid": reviewer})
+				continue
+			}
+
+			users = append(users, map[string]string{"account_id": reviewer})
+		}
+
+		body["reviewers"] = users
+	}
+
+	var pr cloudPullRequest
+	if _, err := c.client.Do(ctx, http.MethodPost, c.repoPath()+"/pullrequests", body, &pr); err != nil {
+		return nil, err
+	}
+
+	return pr.pullRequest(), nil
+}
+
+func (c *cloud) decline(ctx context.Context, pr *pullRequest) (*pullRequest, error) {
+	var declined cloudPullRequest
+	if _, err := c.client.Do(ctx, http.MethodPost, c.prPath(pr.ID)+"/decline", nil, &declined); err != nil {
+		return nil, err
+	}
+
+	return declined.pullRequest(), nil
+}
+
+func (c *cloud) comment(ctx context.Context, id int, message string) error {
+	_, err := c.client.Do(ctx, http.MethodPost, c.prPath(id)+"/comments", map[string]any{
+		"content": map[string]string{"raw": message},
+	}, nil)
+
+	return err
+}
+
+func (c *cloud) defaultReviewers(ctx context.Context, _ *git.Proposal) (reviewers []string, _ error) {
+	type defaultReviewer struct {
+		User struct {
+			UUID string `json:"uuid"`
+		} `json:"user"`
+	}
+
+	for page := 1; ; page++ {
+		query := url.Values{"page": []string{strconv.Itoa(page)}}
+
+		var users cloudPage[defaultReviewer]
+		if _, err := c.client.Do(ctx, http.MethodGet, c.repoPath()+"/effective-default-reviewers?"+query.Encode(), nil, &users); err != nil {
+			return nil, err
+		}
+
+		for _, user := range users.Values {
+			reviewers = append(reviewers, user.User.UUID)
+		}
+
+		if users.Next == "" {
+			return reviewers, nil
+		}
+	}
+}
+
+func (c *cloud) status(ctx context.Context, pr *pullRequest) (*git.ProposalStatus, error) {
+	var details struct {
+		Participants []struct {
+			Approved bool   `json:"approved"`
+			State    string `json:"state"`
+		} `json:"participants"`
+	}
+
+	if _, err := c.client.Do(ctx, http.MethodGet, c.prPath(pr.ID), nil, &details); err != nil {
+		return nil, err
+	}
+
+	mergeable, err := c.mergeable(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &git.ProposalStatus{Mergeable: mergeable}
+	for _, participant := range details.Participants {
+		switch {
+		case participant.Approved:
+			status.Approvals++
+		case participant.State == "changes_requested":
+			status.ChangesRequested = true
+		}
+	}
+
+	type commitStatus struct {
+		Key   string `json:"key"`
+		Name  string `json:"name"`
+		State string `json:"state"`
+	}
+
+	for page := 1; ; page++ {
+		query := url.Values{"page": []string{strconv.Itoa(page)}, "pagelen": []string{"50"}}
+
+		var statuses cloudPage[commitStatus]
+		if _, err := c.client.Do(ctx, http.MethodGet, c.prPath(pr.ID)+"/statuses?"+query.Encode(), nil, &statuses); err != nil {
+			return nil, err
+		}
+
+		for _, st := range statuses.Values {
+			status.Checks = append(status.Checks, git.Check{Name: st.Key, State: buildState(st.State)})
+		}
+
+		if statuses.Next == "" {
+			return status, nil
+		}
+	}
+}
+
+// mergeable returns false when the diffstat of the pull request reports any conflicted files.
+// Bitbucket Cloud does not report the mergeability of a pull request directly, instead
+// conflicts are reported as files with a "merge conflict" status within its diffstat.
+func (c *cloud) mergeable(ctx context.Context, pr *pullRequest) (bool, error) {
+	type fileStat struct {
+		Status string `json:"status"`
+	}
+
+	for page := 1; ; page++ {
+		query := url.Values{"page": []string{strconv.Itoa(page)}, "pagelen": []string{"500"}}
+
+		var stats cloudPage[fileStat]
+		if _, err := c.client.Do(ctx, http.MethodGet, c.prPath(pr.ID)+"/diffstat?"+query.Encode(), nil, &stats); err != nil {
+			return false, err
+		}
+
+		for _, stat := range stats.Values {
+			if stat.Status == "merge conflict" {
+				return false, nil
+			}
+		}
+
+		if stats.Next == "" {
+			return true, nil
+		}
+	}
+}
+
+func (c *cloud) merge(ctx context.Context, pr *pullRequest, method git.MergeMethod) error {
+	strategy, ok := map[git.MergeMethod]string{
+		git.MergeMethodMerge:  "merge_commit",
+		git.MergeMethodSquash: "squash",
+		git.MergeMethodRebase: "rebase_fast_forward",
+	}[method]
+	if !ok {
+		return fmt.Errorf("bitbucket: %w: %q", git.ErrUnsupportedMergeMethod, method)
+	}
+
+	_, err := c.client.Do(ctx, http.MethodPost, c.prPath(pr.ID)+"/merge", map[string]any{
+		"merge_strategy": strategy,
+	}, nil)
+
+	return err
+}
@@ -0,0 +1,280 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/get-glu/glu/internal/scm"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/phases/git"
+)
+
+// NewDataCenter constructs a new SCM for a repository in Bitbucket Data Center (or Server)
+// identified by its project key and repository slug.
+// The baseURL is the root of the Bitbucket instance (e.g. https://bitbucket.example.com).
+// The client is expected to authenticate requests (e.g. via an HTTP access token).
+func NewDataCenter(client *http.Client, baseURL, projectKey, repoSlug string, opts ...containers.Option[SCM]) *SCM {
+	return newSCM(&dataCenter{
+		client:     scm.NewClient("bitbucket", client, baseURL),
+		projectKey: projectKey,
+		repoSlug:   repoSlug,
+	}, "bitbucket_datacenter", opts...)
+}
+
+type dataCenter struct {
+	client     *scm.Client
+	projectKey string
+	repoSlug   string
+}
+
+type dataCenterRef struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+type dataCenterPullRequest struct {
+	ID      int    `json:"id"`
+	Version int    `json:"version"`
+	State   string `json:"state"`
+	Links   struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+	FromRef dataCenterRef `json:"fromRef"`
+	ToRef   dataCenterRef `json:"toRef"`
+}
+
+type dataCenterPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+type dataCenterUser struct {
+	Name string `json:"name"`
+}
+
+func (pr *dataCenterPullRequest) pullRequest() *pullRequest {
+	p := &pullRequest{
+		ID:         pr.ID,
+		Version:    pr.Version,
+		Open:       pr.State == "OPEN",
+		Branch:     pr.FromRef.DisplayID,
+		HeadSHA:    pr.FromRef.LatestCommit,
+		BaseBranch: pr.ToRef.DisplayID,
+		BaseSHA:    pr.ToRef.LatestCommit,
+	}
+
+	if len(pr.Links.Self) > 0 {
+		p.URL = pr.Links.Self[0].Href
+	}
+
+	return p
+}
+
+func (d *dataCenter) repoPath() string {
+	return "/projects/" + url.PathEscape(d.projectKey) + "/repos/" + url.PathEscape(d.repoSlug)
+}
+
+func (d *dataCenter) prPath(id int) string {
+	return "/rest/api/1.0" + d.repoPath() + "/pull-requests/" + strconv.Itoa(id)
+}
+
+func (d *dataCenter) list(ctx context.Context, base string, fn func(*pullRequest) bool) error {
+	start := 0
+	for {
+		query := url.Values{
+			"state":     []string{"OPEN"},
+			"direction": []string{"INCOMING"},
+			"at":        []string{"refs/heads/" + base},
+			"start":     []string{strconv.Itoa(start)},
+			"limit":     []string{"50"},
+		}
+
+		var prs dataCenterPage[dataCenterPullRequest]
+		if _, err := d.client.Do(ctx, http.MethodGet, "/rest/api/1.0"+d.repoPath()+"/pull-requests?"+query.Encode(), nil, &prs); err != nil {
+			return err
+		}
+
+		for _, pr := range prs.Values {
+			if !strings.HasPrefix(pr.FromRef.DisplayID, "glu/") {
+				continue
+			}
+
+			if !fn(pr.pullRequest()) {
+				return nil
+			}
+		}
+
+		if prs.IsLastPage {
+			return nil
+		}
+
+		start = prs.NextPageStart
+	}
+}
+
+func (d *dataCenter) get(ctx context.Context, id int) (*pullRequest, error) {
+	var pr dataCenterPullRequest
+	if _, err := d.client.Do(ctx, http.MethodGet, d.prPath(id), nil, &pr); err != nil {
+		return nil, err
+	}
+
+	return pr.pullRequest(), nil
+}
+
+func (d *dataCenter) create(ctx context.Context, proposal *git.Proposal, reviewers []string) (*pullRequest, error) {
+	body := map[string]any{
+		"title":       proposal.Title,
+		"description": proposal.Body,
+		"fromRef":     map[string]string{"id": "refs/heads/" + proposal.Branch},
+		"toRef":       map[string]string{"id": "refs/heads/" + proposal.BaseBranch},
+	}
+
+	if len(reviewers) > 0 {
+		var users []map[string]dataCenterUser
+		for _, reviewer := range reviewers {
+			users = append(users, map[string]dataCenterUser{"user": {Name: reviewer}})
+		}
+
+		body["reviewers"] = users
+	}
+
+	var pr dataCenterPullRequest
+	if _, err := d.client.Do(ctx, http.MethodPost, "/rest/api/1.0"+d.repoPath()+"/pull-requests", body, &pr); err != nil {
+		return nil, err
+	}
+
+	return pr.pullRequest(), nil
+}
+
+func (d *dataCenter) decline(ctx context.Context, pr *pullRequest) (*pullRequest, error) {
+	// declining requires the current version of the pull request to guard against concurrent updates
+	query := url.Values{"version": []string{strconv.Itoa(pr.Version)}}
+
+	var declined dataCenterPullRequest
+	if _, err := d.client.Do(ctx, http.MethodPost, d.prPath(pr.ID)+"/decline?"+query.Encode(), map[string]any{}, &declined); err != nil {
+		return nil, err
+	}
+
+	return declined.pullRequest(), nil
+}
+
+func (d *dataCenter) comment(ctx context.Context, id int, message string) error {
+	_, err := d.client.Do(ctx, http.MethodPost, d.prPath(id)+"/comments", map[string]any{
+		"text": message,
+	}, nil)
+
+	return err
+}
+
+func (d *dataCenter) defaultReviewers(ctx context.Context, proposal *git.Proposal) (reviewers []string, _ error) {
+	var repo struct {
+		ID int `json:"id"`
+	}
+
+	if _, err := d.client.Do(ctx, http.MethodGet, "/rest/api/1.0"+d.repoPath(), nil, &repo); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"sourceRepoId": []string{strconv.Itoa(repo.ID)},
+		"targetRepoId": []string{strconv.Itoa(repo.ID)},
+		"sourceRefId":  []string{"refs/heads/" + proposal.Branch},
+		"targetRefId":  []string{"refs/heads/" + proposal.BaseBranch},
+	}
+
+	var users []dataCenterUser
+	if _, err := d.client.Do(ctx, http.MethodGet, "/rest/default-reviewers/1.0"+d.repoPath()+"/reviewers?"+query.Encode(), nil, &users); err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		reviewers = append(reviewers, user.Name)
+	}
+
+	return reviewers, nil
+}
+
+func (d *dataCenter) status(ctx context.Context, pr *pullRequest) (*git.ProposalStatus, error) {
+	var details struct {
+		Reviewers []struct {
+			Approved bool   `json:"approved"`
+			Status   string `json:"status"`
+		} `json:"reviewers"`
+	}
+
+	if _, err := d.client.Do(ctx, http.MethodGet, d.prPath(pr.ID), nil, &details); err != nil {
+		return nil, err
+	}
+
+	var merge struct {
+		CanMerge   bool `json:"canMerge"`
+		Conflicted bool `json:"conflicted"`
+	}
+
+	if _, err := d.client.Do(ctx, http.MethodGet, d.prPath(pr.ID)+"/merge", nil, &merge); err != nil {
+		return nil, err
+	}
+
+	status := &git.ProposalStatus{Mergeable: merge.CanMerge && !merge.Conflicted}
+	for _, reviewer := range details.Reviewers {
+		switch {
+		case reviewer.Approved:
+			status.Approvals++
+		case reviewer.Status == "NEEDS_WORK":
+			status.ChangesRequested = true
+		}
+	}
+
+	type buildStatus struct {
+		Key   string `json:"key"`
+		State string `json:"state"`
+	}
+
+	start := 0
+	for {
+		query := url.Values{"start": []string{strconv.Itoa(start)}, "limit": []string{"50"}}
+
+		var statuses dataCenterPage[buildStatus]
+		if _, err := d.client.Do(ctx, http.MethodGet, "/rest/build-status/1.0/commits/"+url.PathEscape(pr.HeadSHA)+"?"+query.Encode(), nil, &statuses); err != nil {
+			return nil, err
+		}
+
+		for _, st := range statuses.Values {
+			status.Checks = append(status.Checks, git.Check{Name: st.Key, State: buildState(st.State)})
+		}
+
+		if statuses.IsLastPage {
+			return status, nil
+		}
+
+		start = statuses.NextPageStart
+	}
+}
+
+func (d *dataCenter) merge(ctx context.Context, pr *pullRequest, method git.MergeMethod) error {
+	strategy, ok := map[git.MergeMethod]string{
+		git.MergeMethodMerge:  "no-ff",
+		git.MergeMethodSquash: "squash",
+		git.MergeMethodRebase: "rebase-no-ff",
+	}[method]
+	if !ok {
+		return fmt.Errorf("bitbucket: %w: %q", git.ErrUnsupportedMergeMethod, method)
+	}
+
+	// merging requires the current version of the pull request to guard against concurrent updates
+	query := url.Values{"version": []string{strconv.Itoa(pr.Version)}}
+
+	_, err := d.client.Do(ctx, http.MethodPost, d.prPath(pr.ID)+"/merge?"+query.Encode(), map[string]any{
+		"strategyId": strategy,
+	}, nil)
+
+	return err
+}
@@ -0,0 +1,403 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/get-glu/glu/internal/scm"
+	"github.com/get-glu/glu/pkg/phases/git"
+)
+
+const pageSize = 50
+
+var _ git.MergingProposer = (*SCM)(nil)
+
+// SCM is a git.Proposer which manages proposals as Gitea (or Forgejo) pull requests.
+type SCM struct {
+	client    *scm.Client
+	repoOwner string
+	repoName  string
+}
+
+// New constructs a new Gitea SCM for the identified repository.
+// The baseURL is the root of the Gitea REST API (e.g. https://gitea.example.com/api/v1).
+// The client is expected to authenticate requests (e.g. via an access token).
+func New(client *http.Client, baseURL, repoOwner, repoName string) *SCM {
+	return &SCM{
+		client:    scm.NewClient("gitea", client, baseURL),
+		repoOwner: repoOwner,
+		repoName:  repoName,
+	}
+}
+
+type branch struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+type pullRequest struct {
+	Number    int    `json:"number"`
+	HTMLURL   string `json:"html_url"`
+	State     string `json:"state"`
+	Mergeable bool   `json:"mergeable"`
+	Head      branch `json:"head"`
+	Base      branch `json:"base"`
+}
+
+type label struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (s *SCM) GetCurrentProposal(ctx context.Context, baseBranch, branchPrefix string) (*git.Proposal, error) {
+	var (
+		prs      = s.listPRs(ctx, baseBranch)
+		proposal *git.Proposal
+	)
+
+	for pr := range prs.All() {
+		if !strings.HasPrefix(pr.Head.Ref, branchPrefix) {
+			continue
+		}
+
+		parts := strings.Split(pr.Head.Ref, "/")
+		proposal = &git.Proposal{
+			ID:  strconv.Itoa(pr.Number),
+			URL: pr.HTMLURL,
+
+			BaseBranch:   pr.Base.Ref,
+			BaseRevision: pr.Base.SHA,
+			Branch:       pr.Head.Ref,
+			HeadRevision: pr.Head.SHA,
+			Digest:       parts[len(parts)-1],
+
+			Annotations: map[string]string{},
+		}
+		break
+	}
+
+	if err := prs.Err(); err != nil {
+		return nil, err
+	}
+
+	if proposal == nil {
+		return nil, fmt.Errorf("base %q: prefix %q: %w", baseBranch, branchPrefix, git.ErrProposalNotFound)
+	}
+
+	return proposal, nil
+}
+
+func (s *SCM) IsProposalOpen(ctx context.Context, proposal *git.Proposal) bool {
+	number, ok := prNumber(proposal)
+	if !ok {
+		return false
+	}
+
+	var pr pullRequest
+	if _, err := s.client.Do(ctx, http.MethodGet, s.pullPath(number), nil, &pr); err != nil {
+		slog.Warn("could not check if PR is open", "reason", "error getting PR", "error", err)
+		return false
+	}
+
+	return pr.State == "open"
+}
+
+func (s *SCM) CreateProposal(ctx context.Context, proposal *git.Proposal, opts git.ProposalOption) error {
+	slog := slog.With(
+		"branch", proposal.Branch,
+		"base", proposal.BaseBranch,
+	)
+
+	body := map[string]any{
+		"head":  proposal.Branch,
+		"base":  proposal.BaseBranch,
+		"title": proposal.Title,
+		"body":  proposal.Body,
+	}
+
+	if len(opts.Labels) > 0 {
+		// gitea identifies labels by ID when creating pull requests
+		ids, err := s.labelIDs(ctx, opts.Labels)
+		if err != nil {
+			return err
+		}
+
+		body["labels"] = ids
+	}
+
+	var pr pullRequest
+	if _, err := s.client.Do(ctx, http.MethodPost, s.repoPath()+"/pulls", body, &pr); err != nil {
+		return err
+	}
+
+	slog.Info("proposal created", "scm_type", "gitea", "proposal_url", pr.HTMLURL)
+
+	proposal.ID = strconv.Itoa(pr.Number)
+	proposal.URL = pr.HTMLURL
+	proposal.BaseRevision = pr.Base.SHA
+	proposal.HeadRevision = pr.Head.SHA
+	proposal.Annotations = map[string]string{}
+
+	return nil
+}
+
+func (s *SCM) CloseProposal(ctx context.Context, proposal *git.Proposal) error {
+	slog := slog.With(
+		"branch", proposal.Branch,
+		"base", proposal.BaseBranch,
+	)
+
+	number, ok := prNumber(proposal)
+	if !ok {
+		return nil
+	}
+
+	var pr pullRequest
+	if _, err := s.client.Do(ctx, http.MethodPatch, s.pullPath(number), map[string]any{
+		"state": "closed",
+	}, &pr); err != nil {
+		return err
+	}
+
+	slog.Info("proposal closed", "scm_type", "gitea", "proposal_url", pr.HTMLURL)
+
+	proposal.BaseRevision = pr.Base.SHA
+	proposal.HeadRevision = pr.Head.SHA
+
+	return nil
+}
+
+func (s *SCM) CommentProposal(ctx context.Context, proposal *git.Proposal, message string) error {
+	number, ok := prNumber(proposal)
+	if !ok {
+		return nil
+	}
+
+	// pull requests share their number with the underlying issue
+	_, err := s.client.Do(ctx, http.MethodPost, s.repoPath()+"/issues/"+strconv.Itoa(number)+"/comments", map[string]any{
+		"body": message,
+	}, nil)
+
+	return err
+}
+
+func (s *SCM) GetProposalStatus(ctx context.Context, proposal *git.Proposal) (*git.ProposalStatus, error) {
+	number, ok := prNumber(proposal)
+	if !ok {
+		return nil, fmt.Errorf("proposal %q: %w", proposal.ID, git.ErrProposalNotFound)
+	}
+
+	var pr pullRequest
+	if _, err := s.client.Do(ctx, http.MethodGet, s.pullPath(number), nil, &pr); err != nil {
+		return nil, err
+	}
+
+	proposal.HeadRevision = pr.Head.SHA
+
+	status := &git.ProposalStatus{Mergeable: pr.Mergeable}
+
+	var combined struct {
+		Statuses []struct {
+			Context string `json:"context"`
+			Status  string `json:"status"`
+		} `json:"statuses"`
+	}
+
+	if _, err := s.client.Do(ctx, http.MethodGet, s.repoPath()+"/commits/"+url.PathEscape(pr.Head.SHA)+"/status", nil, &combined); err != nil {
+		return nil, err
+	}
+
+	for _, st := range combined.Statuses {
+		state := git.CheckStatePending
+		switch st.Status {
+		case "success", "warning":
+			state = git.CheckStateSuccess
+		case "failure", "error":
+			state = git.CheckStateFailure
+		}
+
+		status.Checks = append(status.Checks, git.Check{Name: st.Context, State: state})
+	}
+
+	type review struct {
+		State     string `json:"state"`
+		Dismissed bool   `json:"dismissed"`
+		Stale     bool   `json:"stale"`
+		User      struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+
+	// only the latest review from each user is considered
+	latest := map[string]string{}
+	for page := 1; ; page++ {
+		var reviews []review
+		if _, err := s.client.Do(ctx, http.MethodGet, s.pullPath(number)+"/reviews?"+pageQuery(page).Encode(), nil, &reviews); err != nil {
+			return nil, err
+		}
+
+		for _, review := range reviews {
+			if review.Dismissed || review.Stale {
+				continue
+			}
+
+			switch review.State {
+			case "APPROVED", "REQUEST_CHANGES":
+				latest[review.User.Login] = review.State
+			}
+		}
+
+		if len(reviews) < pageSize {
+			break
+		}
+	}
+
+	for _, state := range latest {
+		switch state {
+		case "APPROVED":
+			status.Approvals++
+		case "REQUEST_CHANGES":
+			status.ChangesRequested = true
+		}
+	}
+
+	return status, nil
+}
+
+func (s *SCM) MergeProposal(ctx context.Context, proposal *git.Proposal, method git.MergeMethod) error {
+	number, ok := prNumber(proposal)
+	if !ok {
+		return fmt.Errorf("proposal %q: %w", proposal.ID, git.ErrProposalNotFound)
+	}
+
+	switch method {
+	case git.MergeMethodMerge, git.MergeMethodSquash, git.MergeMethodRebase:
+	default:
+		return fmt.Errorf("gitea: %w: %q", git.ErrUnsupportedMergeMethod, method)
+	}
+
+	if _, err := s.client.Do(ctx, http.MethodPost, s.pullPath(number)+"/merge", map[string]any{
+		"Do": string(method),
+		// guard against merging a head which has moved since the status was inspected
+		"head_commit_id": proposal.HeadRevision,
+	}, nil); err != nil {
+		return err
+	}
+
+	slog.Info("proposal merged", "scm_type", "gitea", "proposal_url", proposal.URL)
+
+	return nil
+}
+
+// labelIDs resolves the provided label names to their IDs in the repository.
+// Labels which do not exist in the repository are skipped.
+func (s *SCM) labelIDs(ctx context.Context, names []string) ([]int64, error) {
+	var (
+		ids    []int64
+		wanted = map[string]struct{}{}
+	)
+
+	for _, name := range names {
+		wanted[name] = struct{}{}
+	}
+
+	for page := 1; len(wanted) > 0; page++ {
+		var labels []label
+		if _, err := s.client.Do(ctx, http.MethodGet, s.repoPath()+"/labels?"+pageQuery(page).Encode(), nil, &labels); err != nil {
+			return nil, err
+		}
+
+		for _, label := range labels {
+			if _, ok := wanted[label.Name]; ok {
+				ids = append(ids, label.ID)
+				delete(wanted, label.Name)
+			}
+		}
+
+		if len(labels) < pageSize {
+			break
+		}
+	}
+
+	for name := range wanted {
+		slog.Warn("label not found", "scm_type", "gitea", "label", name)
+	}
+
+	return ids, nil
+}
+
+func (s *SCM) repoPath() string {
+	return "/repos/" + url.PathEscape(s.repoOwner) + "/" + url.PathEscape(s.repoName)
+}
+
+func (s *SCM) pullPath(number int) string {
+	return s.repoPath() + "/pulls/" + strconv.Itoa(number)
+}
+
+func pageQuery(page int) url.Values {
+	return url.Values{
+		"page":  []string{strconv.Itoa(page)},
+		"limit": []string{strconv.Itoa(pageSize)},
+	}
+}
+
+func prNumber(proposal *git.Proposal) (int, bool) {
+	number, err := strconv.Atoi(proposal.ID)
+	if err != nil {
+		slog.Warn("could not check if PR is open", "reason", "missing PR number on proposal", "error", err)
+		return 0, false
+	}
+
+	return number, true
+}
+
+type prs struct {
+	ctx  context.Context
+	scm  *SCM
+	base string
+
+	err error
+}
+
+func (s *SCM) listPRs(ctx context.Context, base string) *prs {
+	return &prs{ctx, s, base, nil}
+}
+
+func (p *prs) Err() error {
+	return p.err
+}
+
+func (p *prs) All() iter.Seq[*pullRequest] {
+	return iter.Seq[*pullRequest](func(yield func(*pullRequest) bool) {
+		for page := 1; ; page++ {
+			query := pageQuery(page)
+			query.Set("state", "open")
+
+			var prs []*pullRequest
+			if _, err := p.scm.client.Do(p.ctx, http.MethodGet, p.scm.repoPath()+"/pulls?"+query.Encode(), nil, &prs); err != nil {
+				p.err = err
+				return
+			}
+
+			for _, pr := range prs {
+				if pr.Base.Ref != p.base || !strings.HasPrefix(pr.Head.Ref, "glu/") {
+					continue
+				}
+
+				if !yield(pr) {
+					return
+				}
+			}
+
+			if len(prs) < pageSize {
+				return
+			}
+		}
+	})
+}
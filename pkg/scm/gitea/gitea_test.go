@@ -0,0 +1,289 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/get-glu/glu/internal/scm/scmtest"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSCM(t *testing.T) {
+	scmtest.Run(t, func(t *testing.T) (git.Proposer, scmtest.Fake) {
+		server := newFakeGitea(t)
+		return New(server.Client(), server.URL+"/api/v1", "org", "app"), server
+	})
+}
+
+func TestSCM_CreateProposal_Labels(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitea(t)
+		scm    = New(server.Client(), server.URL+"/api/v1", "org", "app")
+	)
+
+	// labels are resolved by name to their IDs across pages
+	// of repository labels, skipping those which do not exist
+	require.NoError(t, scm.CreateProposal(ctx, &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}, git.ProposalOption{Labels: []string{"label-2", "glu", "missing"}}))
+	assert.Equal(t, []int64{2, int64(len(server.labels))}, server.prs[1].labels)
+}
+
+func TestSCM_GetCurrentProposal_Pagination(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitea(t)
+		scm    = New(server.Client(), server.URL+"/api/v1", "org", "app")
+	)
+
+	// more pull requests than fit on a single page
+	for i := range pageSize + 1 {
+		require.NoError(t, scm.CreateProposal(ctx, &git.Proposal{BaseBranch: "main", Branch: fmt.Sprintf("glu/pipeline/phase-%d/abcdef", i)}, git.ProposalOption{}))
+	}
+
+	proposal, err := scm.GetCurrentProposal(ctx, "main", fmt.Sprintf("glu/pipeline/phase-%d", pageSize))
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(pageSize+1), proposal.ID)
+}
+
+type fakeReview struct {
+	State string `json:"state"`
+	User  struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+type fakeStatus struct {
+	Context string `json:"context"`
+	Status  string `json:"status"`
+}
+
+type fakePullRequest struct {
+	pullRequest
+	labels   []int64
+	comments []string
+	statuses []fakeStatus
+	reviews  []fakeReview
+	merged   string
+}
+
+// fakeGitea is a minimal in-memory implementation of the
+// Gitea pull requests API used by the SCM.
+type fakeGitea struct {
+	*scmtest.Server
+
+	labels []label
+	prs    map[int]*fakePullRequest
+}
+
+func newFakeGitea(t *testing.T) *fakeGitea {
+	t.Helper()
+
+	f := &fakeGitea{Server: scmtest.NewServer(t), prs: map[int]*fakePullRequest{}}
+
+	// more labels than fit on a single page with the glu label last
+	for i := 1; i <= pageSize; i++ {
+		f.labels = append(f.labels, label{ID: int64(i), Name: fmt.Sprintf("label-%d", i)})
+	}
+
+	f.labels = append(f.labels, label{ID: pageSize + 1, Name: "glu"})
+
+	f.Handle("GET /api/v1/repos/org/app/labels", f.listLabels)
+	f.Handle("GET /api/v1/repos/org/app/pulls", f.list)
+	f.Handle("POST /api/v1/repos/org/app/pulls", f.create)
+	f.Handle("GET /api/v1/repos/org/app/pulls/{number}", f.get)
+	f.Handle("PATCH /api/v1/repos/org/app/pulls/{number}", f.update)
+	f.Handle("POST /api/v1/repos/org/app/issues/{number}/comments", f.comment)
+	f.Handle("GET /api/v1/repos/org/app/pulls/{number}/reviews", f.listReviews)
+	f.Handle("POST /api/v1/repos/org/app/pulls/{number}/merge", f.merge)
+	f.Handle("GET /api/v1/repos/org/app/commits/{sha}/status", f.status)
+
+	return f
+}
+
+func (f *fakeGitea) Comments(id string) []string {
+	number, _ := strconv.Atoi(id)
+	if pr, ok := f.prs[number]; ok {
+		return pr.comments
+	}
+
+	return nil
+}
+
+func (f *fakeGitea) Check(id, name string, state git.CheckState) {
+	number, _ := strconv.Atoi(id)
+	f.prs[number].statuses = append(f.prs[number].statuses, fakeStatus{Context: name, Status: string(state)})
+}
+
+func (f *fakeGitea) Review(id, user string, approve bool) {
+	review := fakeReview{State: "REQUEST_CHANGES"}
+	if approve {
+		review.State = "APPROVED"
+	}
+
+	review.User.Login = user
+
+	number, _ := strconv.Atoi(id)
+	f.prs[number].reviews = append(f.prs[number].reviews, review)
+}
+
+func (f *fakeGitea) Merged(id string) (git.MergeMethod, bool) {
+	number, _ := strconv.Atoi(id)
+	pr := f.prs[number]
+	return git.MergeMethod(pr.merged), pr.merged != ""
+}
+
+// page returns the page of items identified by the page and limit query parameters.
+func page[T any](r *http.Request, items []T) []T {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	start := min(max(page-1, 0)*limit, len(items))
+	return items[start:min(start+limit, len(items))]
+}
+
+func (f *fakeGitea) listLabels(w http.ResponseWriter, r *http.Request) {
+	scmtest.Encode(w, http.StatusOK, page(r, f.labels))
+}
+
+func (f *fakeGitea) list(w http.ResponseWriter, r *http.Request) {
+	prs := []*fakePullRequest{}
+	for i := 1; i <= len(f.prs); i++ {
+		if pr := f.prs[i]; pr.State == r.URL.Query().Get("state") {
+			prs = append(prs, pr)
+		}
+	}
+
+	scmtest.Encode(w, http.StatusOK, page(r, prs))
+}
+
+func (f *fakeGitea) create(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Head   string  `json:"head"`
+		Base   string  `json:"base"`
+		Labels []int64 `json:"labels"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	pr := &fakePullRequest{labels: body.Labels}
+	pr.Number = len(f.prs) + 1
+	pr.HTMLURL = fmt.Sprintf("https://gitea.example.com/org/app/pulls/%d", pr.Number)
+	pr.State = "open"
+	pr.Mergeable = true
+	pr.Head = branch{Ref: body.Head, SHA: scmtest.HeadRevision}
+	pr.Base = branch{Ref: body.Base, SHA: scmtest.BaseRevision}
+
+	f.prs[pr.Number] = pr
+
+	scmtest.Encode(w, http.StatusCreated, pr)
+}
+
+func (f *fakeGitea) lookup(w http.ResponseWriter, r *http.Request) (*fakePullRequest, bool) {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	pr, ok := f.prs[number]
+	if !ok {
+		http.NotFound(w, r)
+	}
+
+	return pr, ok
+}
+
+func (f *fakeGitea) get(w http.ResponseWriter, r *http.Request) {
+	if pr, ok := f.lookup(w, r); ok {
+		scmtest.Encode(w, http.StatusOK, pr)
+	}
+}
+
+func (f *fakeGitea) update(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		State string `json:"state"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	if body.State != "" {
+		pr.State = body.State
+	}
+
+	scmtest.Encode(w, http.StatusOK, pr)
+}
+
+func (f *fakeGitea) comment(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Body string `json:"body"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	pr.comments = append(pr.comments, body.Body)
+
+	scmtest.Encode(w, http.StatusCreated, map[string]any{"id": len(pr.comments), "body": body.Body})
+}
+
+func (f *fakeGitea) listReviews(w http.ResponseWriter, r *http.Request) {
+	if pr, ok := f.lookup(w, r); ok {
+		scmtest.Encode(w, http.StatusOK, page(r, append([]fakeReview{}, pr.reviews...)))
+	}
+}
+
+func (f *fakeGitea) merge(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Do           string `json:"Do"`
+		HeadCommitID string `json:"head_commit_id"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	if body.HeadCommitID != pr.Head.SHA {
+		http.Error(w, "head out of date", http.StatusConflict)
+		return
+	}
+
+	pr.merged = body.Do
+	pr.State = "closed"
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// status reports the combined status of the head of the pull request.
+func (f *fakeGitea) status(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("sha") != scmtest.HeadRevision {
+		http.NotFound(w, r)
+		return
+	}
+
+	var statuses []fakeStatus
+	for i := 1; i <= len(f.prs); i++ {
+		statuses = append(statuses, f.prs[i].statuses...)
+	}
+
+	scmtest.Encode(w, http.StatusOK, map[string]any{"statuses": statuses})
+}
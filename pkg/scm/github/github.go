@@ -12,7 +12,7 @@ import (
 	"github.com/google/go-github/v64/github"
 )
 
-var _ git.Proposer = (*SCM)(nil)
+var _ git.MergingProposer = (*SCM)(nil)
 
 type SCM struct {
 	client    *github.Client
@@ -215,3 +215,137 @@ func (p *prs) All() iter.Seq[*github.PullRequest] {
 		}
 	})
 }
+
+func (s *SCM) GetProposalStatus(ctx context.Context, proposal *git.Proposal) (*git.ProposalStatus, error) {
+	number, ok := prNumber(proposal)
+	if !ok {
+		return nil, fmt.Errorf("proposal %q: %w", proposal.ID, git.ErrProposalNotFound)
+	}
+
+	pr, _, err := s.client.PullRequests.Get(ctx, s.repoOwner, s.repoName, number)
+	if err != nil {
+		return nil, err
+	}
+
+	// mergeable is computed asynchronously by GitHub and is nil until known
+	status := &git.ProposalStatus{Mergeable: pr.GetMergeable()}
+
+	sha := pr.Head.GetSHA()
+	proposal.HeadRevision = sha
+
+	// every page of checks and reviews is read, such that the proposal
+	// is never judged ready while some required checks are unseen
+	runOpts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		runs, resp, err := s.client.Checks.ListCheckRunsForRef(ctx, s.repoOwner, s.repoName, sha, runOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, run := range runs.CheckRuns {
+			state := git.CheckStatePending
+			if run.GetStatus() == "completed" {
+				switch run.GetConclusion() {
+				case "success", "neutral", "skipped":
+					state = git.CheckStateSuccess
+				default:
+					state = git.CheckStateFailure
+				}
+			}
+
+			status.Checks = append(status.Checks, git.Check{Name: run.GetName(), State: state})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		runOpts.Page = resp.NextPage
+	}
+
+	statusOpts := &github.ListOptions{PerPage: 100}
+	for {
+		combined, resp, err := s.client.Repositories.GetCombinedStatus(ctx, s.repoOwner, s.repoName, sha, statusOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, st := range combined.Statuses {
+			state := git.CheckStatePending
+			switch st.GetState() {
+			case "success":
+				state = git.CheckStateSuccess
+			case "failure", "error":
+				state = git.CheckStateFailure
+			}
+
+			status.Checks = append(status.Checks, git.Check{Name: st.GetContext(), State: state})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		statusOpts.Page = resp.NextPage
+	}
+
+	var (
+		reviews    []*github.PullRequestReview
+		reviewOpts = &github.ListOptions{PerPage: 100}
+	)
+
+	for {
+		page, resp, err := s.client.PullRequests.ListReviews(ctx, s.repoOwner, s.repoName, number, reviewOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		reviewOpts.Page = resp.NextPage
+	}
+
+	// only the latest approving or blocking review of each reviewer counts
+	latest := map[string]string{}
+	for _, review := range reviews {
+		switch state := review.GetState(); state {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[review.GetUser().GetLogin()] = state
+		}
+	}
+
+	for _, state := range latest {
+		switch state {
+		case "APPROVED":
+			status.Approvals++
+		case "CHANGES_REQUESTED":
+			status.ChangesRequested = true
+		}
+	}
+
+	return status, nil
+}
+
+func (s *SCM) MergeProposal(ctx context.Context, proposal *git.Proposal, method git.MergeMethod) error {
+	number, ok := prNumber(proposal)
+	if !ok {
+		return fmt.Errorf("proposal %q: %w", proposal.ID, git.ErrProposalNotFound)
+	}
+
+	result, _, err := s.client.PullRequests.Merge(ctx, s.repoOwner, s.repoName, number, "", &github.PullRequestOptions{
+		MergeMethod: string(method),
+		// guard against merging a head which has moved since the status was inspected
+		SHA: proposal.HeadRevision,
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("proposal merged", "scm_type", "github", "proposal_url", proposal.URL, "sha", result.GetSHA())
+
+	return nil
+}
@@ -0,0 +1,385 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/get-glu/glu/internal/scm/scmtest"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/google/go-github/v64/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSCM(t *testing.T) {
+	scmtest.Run(t, func(t *testing.T) (git.Proposer, scmtest.Fake) {
+		server := newFakeGitHub(t)
+		return New(server.client(t), "org", "app"), server
+	})
+}
+
+func TestSCM_CreateProposal_Labels(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitHub(t)
+		scm    = New(server.client(t), "org", "app")
+	)
+
+	require.NoError(t, scm.CreateProposal(ctx, &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}, git.ProposalOption{Labels: []string{"glu", "staging"}}))
+	assert.Equal(t, []string{"glu", "staging"}, server.prs[1].labels)
+}
+
+func TestSCM_GetProposalStatus_CommitStatuses(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitHub(t)
+		scm    = New(server.client(t), "org", "app")
+	)
+
+	proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+	require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+
+	pr := server.prs[1]
+	// neutral and skipped check runs do not block merging
+	pr.runs = append(pr.runs,
+		&github.CheckRun{Name: github.String("optional"), Status: github.String("completed"), Conclusion: github.String("neutral")},
+		&github.CheckRun{Name: github.String("skipped"), Status: github.String("completed"), Conclusion: github.String("skipped")},
+		&github.CheckRun{Name: github.String("cancelled"), Status: github.String("completed"), Conclusion: github.String("cancelled")},
+	)
+	// commit statuses are reported alongside check runs
+	pr.statuses = append(pr.statuses,
+		&github.RepoStatus{Context: github.String("ci/success"), State: github.String("success")},
+		&github.RepoStatus{Context: github.String("ci/pending"), State: github.String("pending")},
+		&github.RepoStatus{Context: github.String("ci/error"), State: github.String("error")},
+	)
+
+	status, err := scm.GetProposalStatus(ctx, proposal)
+	require.NoError(t, err)
+	assert.Equal(t, []git.Check{
+		{Name: "optional", State: git.CheckStateSuccess},
+		{Name: "skipped", State: git.CheckStateSuccess},
+		{Name: "cancelled", State: git.CheckStateFailure},
+		{Name: "ci/success", State: git.CheckStateSuccess},
+		{Name: "ci/pending", State: git.CheckStatePending},
+		{Name: "ci/error", State: git.CheckStateFailure},
+	}, status.Checks)
+}
+
+func TestSCM_GetProposalStatus_Pagination(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitHub(t)
+		scm    = New(server.client(t), "org", "app")
+	)
+
+	proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+	require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+
+	// more checks and reviews than fit on a single page, with the
+	// failing check, pending status and blocking review on the last page
+	pr := server.prs[1]
+	for i := range 100 {
+		pr.runs = append(pr.runs, &github.CheckRun{Name: github.String(fmt.Sprintf("run-%d", i)), Status: github.String("completed"), Conclusion: github.String("success")})
+		pr.statuses = append(pr.statuses, &github.RepoStatus{Context: github.String(fmt.Sprintf("status-%d", i)), State: github.String("success")})
+		pr.reviews = append(pr.reviews, &github.PullRequestReview{State: github.String("APPROVED"), User: &github.User{Login: github.String(fmt.Sprintf("user-%d", i))}})
+	}
+
+	pr.runs = append(pr.runs, &github.CheckRun{Name: github.String("required"), Status: github.String("completed"), Conclusion: github.String("failure")})
+	pr.statuses = append(pr.statuses, &github.RepoStatus{Context: github.String("ci/pending"), State: github.String("pending")})
+	pr.reviews = append(pr.reviews, &github.PullRequestReview{State: github.String("CHANGES_REQUESTED"), User: &github.User{Login: github.String("blocker")}})
+
+	status, err := scm.GetProposalStatus(ctx, proposal)
+	require.NoError(t, err)
+
+	assert.Len(t, status.Checks, 202)
+	assert.Contains(t, status.Checks, git.Check{Name: "required", State: git.CheckStateFailure})
+	assert.Contains(t, status.Checks, git.Check{Name: "ci/pending", State: git.CheckStatePending})
+	assert.Equal(t, git.CheckStateFailure, status.ChecksState())
+	assert.Equal(t, 100, status.Approvals)
+	assert.True(t, status.ChangesRequested)
+}
+
+type fakePullRequest struct {
+	*github.PullRequest
+	labels   []string
+	comments []string
+	runs     []*github.CheckRun
+	statuses []*github.RepoStatus
+	reviews  []*github.PullRequestReview
+	merged   string
+}
+
+// fakeGitHub is a minimal in-memory implementation of the
+// GitHub pull requests API used by the SCM.
+type fakeGitHub struct {
+	*scmtest.Server
+
+	prs map[int]*fakePullRequest
+}
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	t.Helper()
+
+	f := &fakeGitHub{Server: scmtest.NewServer(t), prs: map[int]*fakePullRequest{}}
+
+	f.Handle("GET /repos/org/app/pulls", f.list)
+	f.Handle("POST /repos/org/app/pulls", f.create)
+	f.Handle("GET /repos/org/app/pulls/{number}", f.get)
+	f.Handle("PATCH /repos/org/app/pulls/{number}", f.update)
+	f.Handle("GET /repos/org/app/pulls/{number}/reviews", f.listReviews)
+	f.Handle("PUT /repos/org/app/pulls/{number}/merge", f.merge)
+	f.Handle("POST /repos/org/app/issues/{number}/comments", f.comment)
+	f.Handle("POST /repos/org/app/issues/{number}/labels", f.label)
+	f.Handle("GET /repos/org/app/commits/{sha}/check-runs", f.checkRuns)
+	f.Handle("GET /repos/org/app/commits/{sha}/status", f.combinedStatus)
+
+	return f
+}
+
+// client returns a GitHub client for the API served by the fake.
+func (f *fakeGitHub) client(t *testing.T) *github.Client {
+	t.Helper()
+
+	baseURL, err := url.Parse(f.URL + "/")
+	require.NoError(t, err)
+
+	client := github.NewClient(f.Client())
+	client.BaseURL = baseURL
+
+	return client
+}
+
+func (f *fakeGitHub) Comments(id string) []string {
+	number, _ := strconv.Atoi(id)
+	if pr, ok := f.prs[number]; ok {
+		return pr.comments
+	}
+
+	return nil
+}
+
+func (f *fakeGitHub) Check(id, name string, state git.CheckState) {
+	run := &github.CheckRun{Name: github.String(name), Status: github.String("in_progress")}
+	switch state {
+	case git.CheckStateSuccess:
+		run.Status, run.Conclusion = github.String("completed"), github.String("success")
+	case git.CheckStateFailure:
+		run.Status, run.Conclusion = github.String("completed"), github.String("failure")
+	}
+
+	number, _ := strconv.Atoi(id)
+	f.prs[number].runs = append(f.prs[number].runs, run)
+}
+
+func (f *fakeGitHub) Review(id, user string, approve bool) {
+	review := &github.PullRequestReview{State: github.String("CHANGES_REQUESTED"), User: &github.User{Login: github.String(user)}}
+	if approve {
+		review.State = github.String("APPROVED")
+	}
+
+	number, _ := strconv.Atoi(id)
+	// comments do not change the decision of a reviewer
+	f.prs[number].reviews = append(f.prs[number].reviews, review, &github.PullRequestReview{
+		State: github.String("COMMENTED"),
+		User:  &github.User{Login: github.String(user)},
+	})
+}
+
+func (f *fakeGitHub) Merged(id string) (git.MergeMethod, bool) {
+	number, _ := strconv.Atoi(id)
+	pr := f.prs[number]
+	return git.MergeMethod(pr.merged), pr.merged != ""
+}
+
+func (f *fakeGitHub) list(w http.ResponseWriter, r *http.Request) {
+	prs := []*github.PullRequest{}
+	for i := 1; i <= len(f.prs); i++ {
+		pr := f.prs[i]
+		if pr.GetState() == r.URL.Query().Get("state") && pr.Base.GetRef() == r.URL.Query().Get("base") {
+			prs = append(prs, pr.PullRequest)
+		}
+	}
+
+	scmtest.Encode(w, http.StatusOK, prs)
+}
+
+func (f *fakeGitHub) create(w http.ResponseWriter, r *http.Request) {
+	var body github.NewPullRequest
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	number := len(f.prs) + 1
+	pr := &fakePullRequest{PullRequest: &github.PullRequest{
+		Number:    github.Int(number),
+		HTMLURL:   github.String(fmt.Sprintf("https://github.com/org/app/pull/%d", number)),
+		State:     github.String("open"),
+		Mergeable: github.Bool(true),
+		Head:      &github.PullRequestBranch{Ref: body.Head, SHA: github.String(scmtest.HeadRevision)},
+		Base:      &github.PullRequestBranch{Ref: body.Base, SHA: github.String(scmtest.BaseRevision)},
+	}}
+
+	f.prs[number] = pr
+
+	scmtest.Encode(w, http.StatusCreated, pr.PullRequest)
+}
+
+func (f *fakeGitHub) lookup(w http.ResponseWriter, r *http.Request) (*fakePullRequest, bool) {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	pr, ok := f.prs[number]
+	if !ok {
+		http.NotFound(w, r)
+	}
+
+	return pr, ok
+}
+
+func (f *fakeGitHub) get(w http.ResponseWriter, r *http.Request) {
+	if pr, ok := f.lookup(w, r); ok {
+		scmtest.Encode(w, http.StatusOK, pr.PullRequest)
+	}
+}
+
+func (f *fakeGitHub) update(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		State string `json:"state"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	if body.State != "" {
+		pr.State = github.String(body.State)
+	}
+
+	scmtest.Encode(w, http.StatusOK, pr.PullRequest)
+}
+
+func (f *fakeGitHub) listReviews(w http.ResponseWriter, r *http.Request) {
+	if pr, ok := f.lookup(w, r); ok {
+		scmtest.Encode(w, http.StatusOK, page(w, r, append([]*github.PullRequestReview{}, pr.reviews...)))
+	}
+}
+
+func (f *fakeGitHub) merge(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		MergeMethod string `json:"merge_method"`
+		SHA         string `json:"sha"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	if body.SHA != pr.Head.GetSHA() {
+		http.Error(w, "Head branch was modified", http.StatusConflict)
+		return
+	}
+
+	pr.merged = body.MergeMethod
+	pr.State = github.String("closed")
+
+	scmtest.Encode(w, http.StatusOK, &github.PullRequestMergeResult{Merged: github.Bool(true), SHA: github.String("merge-sha")})
+}
+
+func (f *fakeGitHub) comment(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body github.IssueComment
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	pr.comments = append(pr.comments, body.GetBody())
+
+	scmtest.Encode(w, http.StatusCreated, &body)
+}
+
+func (f *fakeGitHub) label(w http.ResponseWriter, r *http.Request) {
+	pr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var labels []string
+	if !scmtest.Decode(w, r, &labels) {
+		return
+	}
+
+	pr.labels = append(pr.labels, labels...)
+
+	scmtest.Encode(w, http.StatusOK, []*github.Label{})
+}
+
+// checkRuns reports the check runs of the head of every pull request.
+func (f *fakeGitHub) checkRuns(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("sha") != scmtest.HeadRevision {
+		http.NotFound(w, r)
+		return
+	}
+
+	runs := []*github.CheckRun{}
+	for i := 1; i <= len(f.prs); i++ {
+		runs = append(runs, f.prs[i].runs...)
+	}
+
+	scmtest.Encode(w, http.StatusOK, &github.ListCheckRunsResults{Total: github.Int(len(runs)), CheckRuns: page(w, r, runs)})
+}
+
+// combinedStatus reports the commit statuses of the head of every pull request.
+func (f *fakeGitHub) combinedStatus(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("sha") != scmtest.HeadRevision {
+		http.NotFound(w, r)
+		return
+	}
+
+	statuses := []*github.RepoStatus{}
+	for i := 1; i <= len(f.prs); i++ {
+		statuses = append(statuses, f.prs[i].statuses...)
+	}
+
+	scmtest.Encode(w, http.StatusOK, &github.CombinedStatus{TotalCount: github.Int(len(statuses)), Statuses: page(w, r, statuses)})
+}
+
+// page returns the page of items identified by the page and per_page query parameters
+// and links the next page (if any) via the Link header, as the GitHub API does.
+func page[T any](w http.ResponseWriter, r *http.Request, items []T) []T {
+	var (
+		query      = r.URL.Query()
+		number, _  = strconv.Atoi(query.Get("page"))
+		perPage, _ = strconv.Atoi(query.Get("per_page"))
+	)
+
+	number = max(number, 1)
+	if perPage <= 0 {
+		perPage = 30
+	}
+
+	start := min((number-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	if end < len(items) {
+		query.Set("page", strconv.Itoa(number+1))
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, query.Encode()))
+	}
+
+	return items[start:end]
+}
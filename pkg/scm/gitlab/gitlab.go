@@ -0,0 +1,355 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/get-glu/glu/internal/scm"
+	"github.com/get-glu/glu/pkg/phases/git"
+)
+
+var _ git.MergingProposer = (*SCM)(nil)
+
+// SCM is a git.Proposer which manages proposals as GitLab merge requests.
+type SCM struct {
+	client  *scm.Client
+	project string
+}
+
+// New constructs a new GitLab SCM for the project identified by its full path (e.g. group/subgroup/name).
+// The baseURL is the root of the GitLab REST API (e.g. https://gitlab.com/api/v4).
+// The client is expected to authenticate requests (e.g. via an access token).
+func New(client *http.Client, baseURL, project string) *SCM {
+	return &SCM{
+		client:  scm.NewClient("gitlab", client, baseURL),
+		project: project,
+	}
+}
+
+type mergeRequest struct {
+	IID          int    `json:"iid"`
+	WebURL       string `json:"web_url"`
+	State        string `json:"state"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	SHA          string `json:"sha"`
+	HasConflicts bool   `json:"has_conflicts"`
+	MergeStatus  string `json:"merge_status"`
+	// DetailedMergeStatus is reported by newer versions of GitLab (15.6+)
+	DetailedMergeStatus string `json:"detailed_merge_status"`
+	DiffRefs            struct {
+		BaseSHA string `json:"base_sha"`
+		HeadSHA string `json:"head_sha"`
+	} `json:"diff_refs"`
+}
+
+func (m *mergeRequest) headSHA() string {
+	if m.SHA != "" {
+		return m.SHA
+	}
+
+	return m.DiffRefs.HeadSHA
+}
+
+// mergeable returns true once GitLab has checked the merge request and reports it can be merged.
+// Merge requests which are still being checked (e.g. checking or unchecked) are not yet mergeable.
+func (m *mergeRequest) mergeable() bool {
+	if m.HasConflicts {
+		return false
+	}
+
+	if m.DetailedMergeStatus != "" {
+		return m.DetailedMergeStatus == "mergeable"
+	}
+
+	return m.MergeStatus == "can_be_merged"
+}
+
+func (s *SCM) GetCurrentProposal(ctx context.Context, baseBranch, branchPrefix string) (*git.Proposal, error) {
+	var (
+		mrs      = s.listMRs(ctx, baseBranch)
+		proposal *git.Proposal
+	)
+
+	for mr := range mrs.All() {
+		if !strings.HasPrefix(mr.SourceBranch, branchPrefix) {
+			continue
+		}
+
+		parts := strings.Split(mr.SourceBranch, "/")
+		proposal = &git.Proposal{
+			ID:  strconv.Itoa(mr.IID),
+			URL: mr.WebURL,
+
+			BaseBranch:   mr.TargetBranch,
+			BaseRevision: mr.DiffRefs.BaseSHA,
+			Branch:       mr.SourceBranch,
+			HeadRevision: mr.headSHA(),
+			Digest:       parts[len(parts)-1],
+
+			Annotations: map[string]string{},
+		}
+		break
+	}
+
+	if err := mrs.Err(); err != nil {
+		return nil, err
+	}
+
+	if proposal == nil {
+		return nil, fmt.Errorf("base %q: prefix %q: %w", baseBranch, branchPrefix, git.ErrProposalNotFound)
+	}
+
+	return proposal, nil
+}
+
+func (s *SCM) IsProposalOpen(ctx context.Context, proposal *git.Proposal) bool {
+	iid, ok := mrIID(proposal)
+	if !ok {
+		return false
+	}
+
+	var mr mergeRequest
+	if _, err := s.client.Do(ctx, http.MethodGet, s.mrPath(iid), nil, &mr); err != nil {
+		slog.Warn("could not check if MR is open", "reason", "error getting MR", "error", err)
+		return false
+	}
+
+	return mr.State == "opened"
+}
+
+func (s *SCM) CreateProposal(ctx context.Context, proposal *git.Proposal, opts git.ProposalOption) error {
+	slog := slog.With(
+		"branch", proposal.Branch,
+		"base", proposal.BaseBranch,
+	)
+
+	body := map[string]any{
+		"source_branch": proposal.Branch,
+		"target_branch": proposal.BaseBranch,
+		"title":         proposal.Title,
+		"description":   proposal.Body,
+	}
+
+	if len(opts.Labels) > 0 {
+		body["labels"] = strings.Join(opts.Labels, ",")
+	}
+
+	var mr mergeRequest
+	if _, err := s.client.Do(ctx, http.MethodPost, s.projectPath()+"/merge_requests", body, &mr); err != nil {
+		return err
+	}
+
+	slog.Info("proposal created", "scm_type", "gitlab", "proposal_url", mr.WebURL)
+
+	proposal.ID = strconv.Itoa(mr.IID)
+	proposal.URL = mr.WebURL
+	proposal.BaseRevision = mr.DiffRefs.BaseSHA
+	proposal.HeadRevision = mr.headSHA()
+	proposal.Annotations = map[string]string{}
+
+	return nil
+}
+
+func (s *SCM) CloseProposal(ctx context.Context, proposal *git.Proposal) error {
+	slog := slog.With(
+		"branch", proposal.Branch,
+		"base", proposal.BaseBranch,
+	)
+
+	iid, ok := mrIID(proposal)
+	if !ok {
+		return nil
+	}
+
+	var mr mergeRequest
+	if _, err := s.client.Do(ctx, http.MethodPut, s.mrPath(iid), map[string]any{
+		"state_event": "close",
+	}, &mr); err != nil {
+		return err
+	}
+
+	slog.Info("proposal closed", "scm_type", "gitlab", "proposal_url", mr.WebURL)
+
+	proposal.BaseRevision = mr.DiffRefs.BaseSHA
+	proposal.HeadRevision = mr.headSHA()
+
+	return nil
+}
+
+func (s *SCM) CommentProposal(ctx context.Context, proposal *git.Proposal, message string) error {
+	iid, ok := mrIID(proposal)
+	if !ok {
+		return nil
+	}
+
+	_, err := s.client.Do(ctx, http.MethodPost, s.mrPath(iid)+"/notes", map[string]any{
+		"body": message,
+	}, nil)
+
+	return err
+}
+
+func (s *SCM) GetProposalStatus(ctx context.Context, proposal *git.Proposal) (*git.ProposalStatus, error) {
+	iid, ok := mrIID(proposal)
+	if !ok {
+		return nil, fmt.Errorf("proposal %q: %w", proposal.ID, git.ErrProposalNotFound)
+	}
+
+	var mr mergeRequest
+	if _, err := s.client.Do(ctx, http.MethodGet, s.mrPath(iid), nil, &mr); err != nil {
+		return nil, err
+	}
+
+	proposal.HeadRevision = mr.headSHA()
+
+	status := &git.ProposalStatus{
+		Mergeable:        mr.mergeable(),
+		ChangesRequested: mr.DetailedMergeStatus == "requested_changes",
+	}
+
+	for page := "1"; page != ""; {
+		var statuses []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		}
+
+		query := url.Values{"per_page": []string{"100"}, "page": []string{page}}
+		resp, err := s.client.Do(ctx, http.MethodGet, s.projectPath()+"/repository/commits/"+url.PathEscape(mr.headSHA())+"/statuses?"+query.Encode(), nil, &statuses)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, st := range statuses {
+			state := git.CheckStatePending
+			switch st.Status {
+			case "success", "skipped":
+				state = git.CheckStateSuccess
+			case "failed", "canceled":
+				state = git.CheckStateFailure
+			}
+
+			status.Checks = append(status.Checks, git.Check{Name: st.Name, State: state})
+		}
+
+		page = resp.Header.Get("X-Next-Page")
+	}
+
+	var approvals struct {
+		ApprovedBy []json.RawMessage `json:"approved_by"`
+	}
+
+	if _, err := s.client.Do(ctx, http.MethodGet, s.mrPath(iid)+"/approvals", nil, &approvals); err != nil {
+		return nil, err
+	}
+
+	status.Approvals = len(approvals.ApprovedBy)
+
+	return status, nil
+}
+
+func (s *SCM) MergeProposal(ctx context.Context, proposal *git.Proposal, method git.MergeMethod) error {
+	iid, ok := mrIID(proposal)
+	if !ok {
+		return fmt.Errorf("proposal %q: %w", proposal.ID, git.ErrProposalNotFound)
+	}
+
+	body := map[string]any{
+		// guard against merging a head which has moved since the status was inspected
+		"sha": proposal.HeadRevision,
+	}
+
+	switch method {
+	case git.MergeMethodMerge:
+	case git.MergeMethodSquash:
+		body["squash"] = true
+	default:
+		// rebasing is configured per project in gitlab via its merge method setting
+		return fmt.Errorf("gitlab: %w: %q", git.ErrUnsupportedMergeMethod, method)
+	}
+
+	var mr mergeRequest
+	if _, err := s.client.Do(ctx, http.MethodPut, s.mrPath(iid)+"/merge", body, &mr); err != nil {
+		return err
+	}
+
+	slog.Info("proposal merged", "scm_type", "gitlab", "proposal_url", mr.WebURL)
+
+	return nil
+}
+
+func (s *SCM) projectPath() string {
+	return "/projects/" + url.PathEscape(s.project)
+}
+
+func (s *SCM) mrPath(iid int) string {
+	return s.projectPath() + "/merge_requests/" + strconv.Itoa(iid)
+}
+
+func mrIID(proposal *git.Proposal) (int, bool) {
+	iid, err := strconv.Atoi(proposal.ID)
+	if err != nil {
+		slog.Warn("could not check if MR is open", "reason", "missing MR iid on proposal", "error", err)
+		return 0, false
+	}
+
+	return iid, true
+}
+
+type mrs struct {
+	ctx  context.Context
+	scm  *SCM
+	base string
+
+	err error
+}
+
+func (s *SCM) listMRs(ctx context.Context, base string) *mrs {
+	return &mrs{ctx, s, base, nil}
+}
+
+func (m *mrs) Err() error {
+	return m.err
+}
+
+func (m *mrs) All() iter.Seq[*mergeRequest] {
+	return iter.Seq[*mergeRequest](func(yield func(*mergeRequest) bool) {
+		page := "1"
+		for {
+			query := url.Values{
+				"state":         []string{"opened"},
+				"target_branch": []string{m.base},
+				"per_page":      []string{"100"},
+				"page":          []string{page},
+			}
+
+			var mrs []*mergeRequest
+			resp, err := m.scm.client.Do(m.ctx, http.MethodGet, m.scm.projectPath()+"/merge_requests?"+query.Encode(), nil, &mrs)
+			if err != nil {
+				m.err = err
+				return
+			}
+
+			for _, mr := range mrs {
+				if !strings.HasPrefix(mr.SourceBranch, "glu/") {
+					continue
+				}
+
+				if !yield(mr) {
+					return
+				}
+			}
+
+			if page = resp.Header.Get("X-Next-Page"); page == "" {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,401 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/get-glu/glu/internal/scm/scmtest"
+	"github.com/get-glu/glu/pkg/phases/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const project = "group/subgroup/app"
+
+func TestSCM(t *testing.T) {
+	scmtest.Run(t, func(t *testing.T) (git.Proposer, scmtest.Fake) {
+		server := newFakeGitLab(t)
+		return New(server.Client(), server.URL+"/api/v4", project), server
+	})
+}
+
+func TestSCM_CreateProposal_Labels(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitLab(t)
+		scm    = New(server.Client(), server.URL+"/api/v4", project)
+	)
+
+	// labels are supplied as a comma separated list
+	require.NoError(t, scm.CreateProposal(ctx, &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}, git.ProposalOption{Labels: []string{"glu", "staging"}}))
+	assert.Equal(t, "glu,staging", server.mrs[1].labels)
+}
+
+func TestSCM_GetCurrentProposal_Pagination(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitLab(t)
+		scm    = New(server.Client(), server.URL+"/api/v4", project)
+	)
+
+	// the fake returns at most two merge requests per page
+	for _, phase := range []string{"one", "two", "three", "four", "five"} {
+		require.NoError(t, scm.CreateProposal(ctx, &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/" + phase + "/abcdef"}, git.ProposalOption{}))
+	}
+
+	proposal, err := scm.GetCurrentProposal(ctx, "main", "glu/pipeline/five")
+	require.NoError(t, err)
+	assert.Equal(t, "5", proposal.ID)
+}
+
+func TestSCM_GetProposalStatus_Pagination(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitLab(t)
+		scm    = New(server.Client(), server.URL+"/api/v4", project)
+	)
+
+	proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+	require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+
+	// the fake returns at most two statuses per page
+	for _, name := range []string{"build", "lint", "test", "e2e"} {
+		server.Check(proposal.ID, name, git.CheckStateSuccess)
+	}
+
+	server.Check(proposal.ID, "deploy", git.CheckStateFailure)
+
+	status, err := scm.GetProposalStatus(ctx, proposal)
+	require.NoError(t, err)
+	assert.Equal(t, []git.Check{
+		{Name: "build", State: git.CheckStateSuccess},
+		{Name: "lint", State: git.CheckStateSuccess},
+		{Name: "test", State: git.CheckStateSuccess},
+		{Name: "e2e", State: git.CheckStateSuccess},
+		{Name: "deploy", State: git.CheckStateFailure},
+	}, status.Checks)
+}
+
+func TestSCM_GetProposalStatus_Mergeable(t *testing.T) {
+	for _, test := range []struct {
+		name                string
+		mergeStatus         string
+		detailedMergeStatus string
+		hasConflicts        bool
+		mergeable           bool
+	}{
+		{name: "can be merged", mergeStatus: "can_be_merged", mergeable: true},
+		{name: "cannot be merged", mergeStatus: "cannot_be_merged"},
+		{name: "unchecked", mergeStatus: "unchecked"},
+		{name: "checking", mergeStatus: "checking"},
+		{name: "conflicts", mergeStatus: "can_be_merged", hasConflicts: true},
+		{name: "detailed mergeable", mergeStatus: "can_be_merged", detailedMergeStatus: "mergeable", mergeable: true},
+		{name: "detailed checking", mergeStatus: "can_be_merged", detailedMergeStatus: "checking"},
+		{name: "detailed unchecked", mergeStatus: "can_be_merged", detailedMergeStatus: "unchecked"},
+		{name: "detailed not approved", mergeStatus: "can_be_merged", detailedMergeStatus: "not_approved"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				ctx    = context.Background()
+				server = newFakeGitLab(t)
+				scm    = New(server.Client(), server.URL+"/api/v4", project)
+			)
+
+			proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+			require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+
+			mr := server.mrs[1]
+			mr.MergeStatus = test.mergeStatus
+			mr.DetailedMergeStatus = test.detailedMergeStatus
+			mr.HasConflicts = test.hasConflicts
+
+			status, err := scm.GetProposalStatus(ctx, proposal)
+			require.NoError(t, err)
+			assert.Equal(t, test.mergeable, status.Mergeable)
+		})
+	}
+}
+
+func TestSCM_MergeProposal_Rebase(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = newFakeGitLab(t)
+		scm    = New(server.Client(), server.URL+"/api/v4", project)
+	)
+
+	proposal := &git.Proposal{BaseBranch: "main", Branch: "glu/pipeline/phase/abcdef"}
+	require.NoError(t, scm.CreateProposal(ctx, proposal, git.ProposalOption{}))
+
+	// rebasing is configured per project rather than per merge
+	require.ErrorIs(t, scm.MergeProposal(ctx, proposal, git.MergeMethodRebase), git.ErrUnsupportedMergeMethod)
+
+	_, merged := server.Merged(proposal.ID)
+	assert.False(t, merged)
+}
+
+type fakeStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type fakeMergeRequest struct {
+	mergeRequest
+	labels    string
+	notes     []string
+	statuses  []fakeStatus
+	approvers []string
+	merged    git.MergeMethod
+}
+
+// fakeGitLab is a minimal in-memory implementation of the
+// GitLab merge requests API used by the SCM.
+type fakeGitLab struct {
+	*scmtest.Server
+
+	mrs map[int]*fakeMergeRequest
+}
+
+func newFakeGitLab(t *testing.T) *fakeGitLab {
+	t.Helper()
+
+	f := &fakeGitLab{Server: scmtest.NewServer(t), mrs: map[int]*fakeMergeRequest{}}
+
+	f.handle("GET /api/v4/projects/{project}/merge_requests", f.list)
+	f.handle("POST /api/v4/projects/{project}/merge_requests", f.create)
+	f.handle("GET /api/v4/projects/{project}/merge_requests/{iid}", f.get)
+	f.handle("PUT /api/v4/projects/{project}/merge_requests/{iid}", f.update)
+	f.handle("POST /api/v4/projects/{project}/merge_requests/{iid}/notes", f.note)
+	f.handle("GET /api/v4/projects/{project}/merge_requests/{iid}/approvals", f.approvals)
+	f.handle("GET /api/v4/projects/{project}/repository/commits/{sha}/statuses", f.statuses)
+	f.handle("PUT /api/v4/projects/{project}/merge_requests/{iid}/merge", f.merge)
+
+	return f
+}
+
+// handle ensures the project is identified by its escaped full path.
+func (f *fakeGitLab) handle(pattern string, fn http.HandlerFunc) {
+	f.Handle(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("project") != project {
+			http.NotFound(w, r)
+			return
+		}
+
+		fn(w, r)
+	})
+}
+
+func (f *fakeGitLab) Comments(id string) []string {
+	iid, _ := strconv.Atoi(id)
+	if mr, ok := f.mrs[iid]; ok {
+		return mr.notes
+	}
+
+	return nil
+}
+
+func (f *fakeGitLab) Check(id, name string, state git.CheckState) {
+	status := map[git.CheckState]string{
+		git.CheckStateSuccess: "success",
+		git.CheckStatePending: "pending",
+		git.CheckStateFailure: "failed",
+	}[state]
+
+	iid, _ := strconv.Atoi(id)
+	f.mrs[iid].statuses = append(f.mrs[iid].statuses, fakeStatus{Name: name, Status: status})
+}
+
+// Review approves the merge request on behalf of user or otherwise revokes their
+// approval and requests changes, as reported via the detailed merge status.
+func (f *fakeGitLab) Review(id, user string, approve bool) {
+	iid, _ := strconv.Atoi(id)
+	mr := f.mrs[iid]
+	mr.approvers = slices.DeleteFunc(mr.approvers, func(u string) bool { return u == user })
+
+	if approve {
+		mr.approvers = append(mr.approvers, user)
+		return
+	}
+
+	mr.DetailedMergeStatus = "requested_changes"
+}
+
+func (f *fakeGitLab) Merged(id string) (git.MergeMethod, bool) {
+	iid, _ := strconv.Atoi(id)
+	mr := f.mrs[iid]
+	return mr.merged, mr.merged != ""
+}
+
+// list returns the open merge requests targeting the requested branch.
+func (f *fakeGitLab) list(w http.ResponseWriter, r *http.Request) {
+	mrs := []*fakeMergeRequest{}
+	for i := 1; i <= len(f.mrs); i++ {
+		mr := f.mrs[i]
+		if mr.State == r.URL.Query().Get("state") && mr.TargetBranch == r.URL.Query().Get("target_branch") {
+			mrs = append(mrs, mr)
+		}
+	}
+
+	page(w, r, mrs)
+}
+
+// page writes the requested page of items, returning at most two items
+// per page in order to exercise pagination.
+func page[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	const pageSize = 2
+
+	current, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	current = max(current, 1)
+
+	start := min((current-1)*pageSize, len(items))
+	end := min(start+pageSize, len(items))
+	if end < len(items) {
+		w.Header().Set("X-Next-Page", strconv.Itoa(current+1))
+	}
+
+	scmtest.Encode(w, http.StatusOK, items[start:end])
+}
+
+func (f *fakeGitLab) create(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		Labels       string `json:"labels"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	mr := &fakeMergeRequest{labels: body.Labels}
+	mr.IID = len(f.mrs) + 1
+	mr.WebURL = fmt.Sprintf("https://gitlab.example.com/%s/-/merge_requests/%d", project, mr.IID)
+	mr.State = "opened"
+	mr.MergeStatus = "can_be_merged"
+	mr.SourceBranch = body.SourceBranch
+	mr.TargetBranch = body.TargetBranch
+	mr.SHA = scmtest.HeadRevision
+	mr.DiffRefs.BaseSHA = scmtest.BaseRevision
+
+	f.mrs[mr.IID] = mr
+
+	scmtest.Encode(w, http.StatusCreated, mr)
+}
+
+func (f *fakeGitLab) lookup(w http.ResponseWriter, r *http.Request) (*fakeMergeRequest, bool) {
+	iid, _ := strconv.Atoi(r.PathValue("iid"))
+	mr, ok := f.mrs[iid]
+	if !ok {
+		http.NotFound(w, r)
+	}
+
+	return mr, ok
+}
+
+func (f *fakeGitLab) get(w http.ResponseWriter, r *http.Request) {
+	if mr, ok := f.lookup(w, r); ok {
+		scmtest.Encode(w, http.StatusOK, mr)
+	}
+}
+
+func (f *fakeGitLab) update(w http.ResponseWriter, r *http.Request) {
+	mr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		StateEvent string `json:"state_event"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	if body.StateEvent == "close" {
+		mr.State = "closed"
+	}
+
+	scmtest.Encode(w, http.StatusOK, mr)
+}
+
+func (f *fakeGitLab) note(w http.ResponseWriter, r *http.Request) {
+	mr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Body string `json:"body"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	mr.notes = append(mr.notes, body.Body)
+
+	scmtest.Encode(w, http.StatusCreated, map[string]any{"id": len(mr.notes), "body": body.Body})
+}
+
+func (f *fakeGitLab) approvals(w http.ResponseWriter, r *http.Request) {
+	mr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	approvedBy := []any{}
+	for _, user := range mr.approvers {
+		approvedBy = append(approvedBy, map[string]any{"user": map[string]string{"username": user}})
+	}
+
+	scmtest.Encode(w, http.StatusOK, map[string]any{"approved_by": approvedBy})
+}
+
+// statuses reports the statuses of the head of the merge request.
+func (f *fakeGitLab) statuses(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("sha") != scmtest.HeadRevision {
+		http.NotFound(w, r)
+		return
+	}
+
+	statuses := []fakeStatus{}
+	for i := 1; i <= len(f.mrs); i++ {
+		statuses = append(statuses, f.mrs[i].statuses...)
+	}
+
+	page(w, r, statuses)
+}
+
+func (f *fakeGitLab) merge(w http.ResponseWriter, r *http.Request) {
+	mr, ok := f.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		SHA    string `json:"sha"`
+		Squash bool   `json:"squash"`
+	}
+
+	if !scmtest.Decode(w, r, &body) {
+		return
+	}
+
+	if body.SHA != mr.SHA {
+		http.Error(w, "SHA does not match HEAD of source branch", http.StatusConflict)
+		return
+	}
+
+	mr.merged = git.MergeMethodMerge
+	if body.Squash {
+		mr.merged = git.MergeMethodSquash
+	}
+
+	mr.State = "merged"
+
+	scmtest.Encode(w, http.StatusOK, mr)
+}
@@ -0,0 +1,244 @@
+// Package links generates URLs for viewing git commits, branches, comparisons
+// and files within the web UI of an SCM provider.
+package links
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"text/template"
+
+	giturls "github.com/whilp/git-urls"
+)
+
+const (
+	ProviderGitHub              = "github"
+	ProviderGitLab              = "gitlab"
+	ProviderGitea               = "gitea"
+	ProviderBitbucket           = "bitbucket"
+	ProviderBitbucketDataCenter = "bitbucket_datacenter"
+)
+
+// Templates are the text/template definitions used to generate each kind of URL.
+// Each template is executed with a Repository along with the following fields (where relevant):
+// .SHA (commit), .Branch (branch), .Base and .Head (compare) and .Revision and .File (file).
+// Values are inserted verbatim, so templates should escape them using either the builtin
+// urlquery function (query parameters) or the pathescape function (path segments).
+// An empty template disables generating that kind of URL.
+type Templates struct {
+	Commit  string
+	Branch  string
+	Compare string
+	File    string
+}
+
+// Builtin are the templates for each of the supported SCM providers.
+var Builtin = map[string]Templates{
+	ProviderGitHub: {
+		Commit:  "{{.BaseURL}}/{{.Path}}/commit/{{pathescape .SHA}}",
+		Branch:  "{{.BaseURL}}/{{.Path}}/tree/{{pathescape .Branch}}",
+		Compare: "{{.BaseURL}}/{{.Path}}/compare/{{pathescape .Base}}...{{pathescape .Head}}",
+		File:    "{{.BaseURL}}/{{.Path}}/blob/{{pathescape .Revision}}/{{pathescape .File}}",
+	},
+	ProviderGitLab: {
+		Commit:  "{{.BaseURL}}/{{.Path}}/-/commit/{{pathescape .SHA}}",
+		Branch:  "{{.BaseURL}}/{{.Path}}/-/tree/{{pathescape .Branch}}",
+		Compare: "{{.BaseURL}}/{{.Path}}/-/compare/{{pathescape .Base}}...{{pathescape .Head}}",
+		File:    "{{.BaseURL}}/{{.Path}}/-/blob/{{pathescape .Revision}}/{{pathescape .File}}",
+	},
+	ProviderGitea: {
+		Commit:  "{{.BaseURL}}/{{.Path}}/commit/{{pathescape .SHA}}",
+		Branch:  "{{.BaseURL}}/{{.Path}}/src/branch/{{pathescape .Branch}}",
+		Compare: "{{.BaseURL}}/{{.Path}}/compare/{{pathescape .Base}}...{{pathescape .Head}}",
+		File:    "{{.BaseURL}}/{{.Path}}/src/commit/{{pathescape .Revision}}/{{pathescape .File}}",
+	},
+	ProviderBitbucket: {
+		Commit:  "{{.BaseURL}}/{{.Path}}/commits/{{pathescape .SHA}}",
+		Branch:  "{{.BaseURL}}/{{.Path}}/branch/{{pathescape .Branch}}",
+		Compare: "{{.BaseURL}}/{{.Path}}/branches/compare/{{urlquery .Head}}%0D{{urlquery .Base}}",
+		File:    "{{.BaseURL}}/{{.Path}}/src/{{pathescape .Revision}}/{{pathescape .File}}",
+	},
+	ProviderBitbucketDataCenter: {
+		Commit:  "{{.BaseURL}}/projects/{{.Owner}}/repos/{{.Name}}/commits/{{pathescape .SHA}}",
+		Branch:  "{{.BaseURL}}/projects/{{.Owner}}/repos/{{.Name}}/browse?at={{urlquery \"refs/heads/\" .Branch}}",
+		Compare: "{{.BaseURL}}/projects/{{.Owner}}/repos/{{.Name}}/compare/diff?sourceBranch={{urlquery \"refs/heads/\" .Head}}&targetBranch={{urlquery \"refs/heads/\" .Base}}",
+		File:    "{{.BaseURL}}/projects/{{.Owner}}/repos/{{.Name}}/browse/{{pathescape .File}}?at={{urlquery .Revision}}",
+	},
+}
+
+// funcs are the functions available to templates in addition to the text/template builtins.
+var funcs = template.FuncMap{"pathescape": pathEscape}
+
+// pathEscape escapes each segment of a (potentially slash separated) path
+// so that it can be safely embedded within a URL path.
+func pathEscape(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// Repository identifies a repository within an SCM provider.
+type Repository struct {
+	// BaseURL is the root of the providers web UI (e.g. https://github.com)
+	BaseURL string
+	// Path is the full path of the repository (e.g. owner/name or group/subgroup/name)
+	Path string
+	// Owner is the first segment of the path (e.g. the organization or project key)
+	Owner string
+	// Name is the remainder of the path following the owner
+	Name string
+}
+
+// BaseURL returns the root URL of the SCM host serving the provided remote URL.
+// The scheme and port are retained for http(s) remotes, whereas ssh remotes
+// (where the port identifies the SSH daemon) are assumed to be served via https
+// on the default port.
+func BaseURL(remote *url.URL) string {
+	if remote.Scheme == "http" || remote.Scheme == "https" {
+		return remote.Scheme + "://" + remote.Host
+	}
+
+	return "https://" + remote.Hostname()
+}
+
+// ParseRepository derives a Repository from a git remote URL (http or ssh).
+func ParseRepository(remote string) (Repository, error) {
+	u, err := giturls.Parse(remote)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	// bitbucket data center serves http clones from /scm/<project>/<repo>
+	path = strings.TrimPrefix(path, "scm/")
+
+	owner, name, ok := strings.Cut(path, "/")
+	if !ok || owner == "" || name == "" {
+		return Repository{}, fmt.Errorf("unexpected repository URL path: %q", u.Path)
+	}
+
+	return Repository{
+		BaseURL: BaseURL(u),
+		Path:    path,
+		Owner:   owner,
+		Name:    name,
+	}, nil
+}
+
+// DetectProvider returns the provider for well-known public SCM hosts.
+// It returns false if the provider cannot be determined from the host alone.
+func DetectProvider(repo Repository) (string, bool) {
+	switch strings.TrimPrefix(repo.BaseURL, "https://") {
+	case "github.com":
+		return ProviderGitHub, true
+	case "gitlab.com":
+		return ProviderGitLab, true
+	case "gitea.com", "codeberg.org":
+		return ProviderGitea, true
+	case "bitbucket.org":
+		return ProviderBitbucket, true
+	}
+
+	return "", false
+}
+
+// Provider generates URLs for a repository from a set of templates.
+type Provider struct {
+	repo Repository
+
+	commit, branch, compare, file *template.Template
+}
+
+// New constructs a new Provider for the repository using the provided templates.
+func New(repo Repository, templates Templates) (*Provider, error) {
+	p := &Provider{repo: repo}
+	for _, t := range []struct {
+		name string
+		text string
+		dst  **template.Template
+	}{
+		{"commit", templates.Commit, &p.commit},
+		{"branch", templates.Branch, &p.branch},
+		{"compare", templates.Compare, &p.compare},
+		{"file", templates.File, &p.file},
+	} {
+		if t.text == "" {
+			continue
+		}
+
+		tmpl, err := template.New(t.name).Option("missingkey=error").Funcs(funcs).Parse(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s template: %w", t.name, err)
+		}
+
+		*t.dst = tmpl
+	}
+
+	return p, nil
+}
+
+// NewBuiltin constructs a new Provider using the builtin templates for the named provider.
+// Any non-empty overrides replace the respective builtin template.
+func NewBuiltin(provider string, repo Repository, overrides Templates) (*Provider, error) {
+	templates, ok := Builtin[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown link provider %q", provider)
+	}
+
+	for _, override := range []struct{ src, dst *string }{
+		{&overrides.Commit, &templates.Commit},
+		{&overrides.Branch, &templates.Branch},
+		{&overrides.Compare, &templates.Compare},
+		{&overrides.File, &templates.File},
+	} {
+		if *override.src != "" {
+			*override.dst = *override.src
+		}
+	}
+
+	return New(repo, templates)
+}
+
+// CommitURL returns a URL to view the identified commit.
+func (p *Provider) CommitURL(sha string) string {
+	return p.execute(p.commit, map[string]any{"SHA": sha})
+}
+
+// BranchURL returns a URL to view the identified branch.
+func (p *Provider) BranchURL(branch string) string {
+	return p.execute(p.branch, map[string]any{"Branch": branch})
+}
+
+// CompareURL returns a URL to view the comparison of the head with the base.
+func (p *Provider) CompareURL(base, head string) string {
+	return p.execute(p.compare, map[string]any{"Base": base, "Head": head})
+}
+
+// FileURL returns a URL to view the file at the provided path as of the revision.
+func (p *Provider) FileURL(revision, file string) string {
+	return p.execute(p.file, map[string]any{"Revision": revision, "File": strings.TrimPrefix(file, "/")})
+}
+
+func (p *Provider) execute(tmpl *template.Template, data map[string]any) string {
+	if p == nil || tmpl == nil {
+		return ""
+	}
+
+	data["BaseURL"] = p.repo.BaseURL
+	data["Path"] = p.repo.Path
+	data["Owner"] = p.repo.Owner
+	data["Name"] = p.repo.Name
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		slog.Warn("generating link", "template", tmpl.Name(), "error", err)
+		return ""
+	}
+
+	return buf.String()
+}
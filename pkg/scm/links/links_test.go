@@ -0,0 +1,268 @@
+package links_test
+
+import (
+	"testing"
+
+	"github.com/get-glu/glu/pkg/scm/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepository(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		remote   string
+		expected links.Repository
+		err      string
+	}{
+		{
+			name:   "https",
+			remote: "https://github.com/get-glu/glu.git",
+			expected: links.Repository{
+				BaseURL: "https://github.com",
+				Path:    "get-glu/glu",
+				Owner:   "get-glu",
+				Name:    "glu",
+			},
+		},
+		{
+			name:   "https nested groups",
+			remote: "https://gitlab.com/group/subgroup/project",
+			expected: links.Repository{
+				BaseURL: "https://gitlab.com",
+				Path:    "group/subgroup/project",
+				Owner:   "group",
+				Name:    "subgroup/project",
+			},
+		},
+		{
+			name:   "ssh scp-like",
+			remote: "git@github.com:get-glu/glu.git",
+			expected: links.Repository{
+				BaseURL: "https://github.com",
+				Path:    "get-glu/glu",
+				Owner:   "get-glu",
+				Name:    "glu",
+			},
+		},
+		{
+			name:   "ssh with port",
+			remote: "ssh://git@bitbucket.example.com:7999/proj/repo.git",
+			expected: links.Repository{
+				BaseURL: "https://bitbucket.example.com",
+				Path:    "proj/repo",
+				Owner:   "proj",
+				Name:    "repo",
+			},
+		},
+		{
+			name:   "http with port",
+			remote: "http://git.example.com:3000/owner/repo.git",
+			expected: links.Repository{
+				BaseURL: "http://git.example.com:3000",
+				Path:    "owner/repo",
+				Owner:   "owner",
+				Name:    "repo",
+			},
+		},
+		{
+			name:   "https with port",
+			remote: "https://git.example.com:8443/owner/repo.git",
+			expected: links.Repository{
+				BaseURL: "https://git.example.com:8443",
+				Path:    "owner/repo",
+				Owner:   "owner",
+				Name:    "repo",
+			},
+		},
+		{
+			name:   "bitbucket data center scm path",
+			remote: "https://bitbucket.example.com/scm/proj/repo.git",
+			expected: links.Repository{
+				BaseURL: "https://bitbucket.example.com",
+				Path:    "proj/repo",
+				Owner:   "proj",
+				Name:    "repo",
+			},
+		},
+		{
+			name:   "missing name",
+			remote: "https://github.com/get-glu",
+			err:    `unexpected repository URL path: "/get-glu"`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			repo, err := links.ParseRepository(test.remote)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, repo)
+		})
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	for _, test := range []struct {
+		baseURL  string
+		expected string
+		ok       bool
+	}{
+		{baseURL: "https://github.com", expected: links.ProviderGitHub, ok: true},
+		{baseURL: "https://gitlab.com", expected: links.ProviderGitLab, ok: true},
+		{baseURL: "https://gitea.com", expected: links.ProviderGitea, ok: true},
+		{baseURL: "https://codeberg.org", expected: links.ProviderGitea, ok: true},
+		{baseURL: "https://bitbucket.org", expected: links.ProviderBitbucket, ok: true},
+		{baseURL: "https://git.example.com"},
+	} {
+		t.Run(test.baseURL, func(t *testing.T) {
+			provider, ok := links.DetectProvider(links.Repository{BaseURL: test.baseURL})
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, provider)
+		})
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	repo := links.Repository{
+		BaseURL: "https://scm.example.com",
+		Path:    "owner/repo",
+		Owner:   "owner",
+		Name:    "repo",
+	}
+
+	type urls struct {
+		commit, branch, compare, file string
+	}
+
+	for _, test := range []struct {
+		provider string
+		expected urls
+	}{
+		{
+			provider: links.ProviderGitHub,
+			expected: urls{
+				commit:  "https://scm.example.com/owner/repo/commit/abc123",
+				branch:  "https://scm.example.com/owner/repo/tree/glu/sync%20prod",
+				compare: "https://scm.example.com/owner/repo/compare/main...glu/sync%20prod",
+				file:    "https://scm.example.com/owner/repo/blob/abc123/env/prod%23a.yaml",
+			},
+		},
+		{
+			provider: links.ProviderGitLab,
+			expected: urls{
+				commit:  "https://scm.example.com/owner/repo/-/commit/abc123",
+				branch:  "https://scm.example.com/owner/repo/-/tree/glu/sync%20prod",
+				compare: "https://scm.example.com/owner/repo/-/compare/main...glu/sync%20prod",
+				file:    "https://scm.example.com/owner/repo/-/blob/abc123/env/prod%23a.yaml",
+			},
+		},
+		{
+			provider: links.ProviderGitea,
+			expected: urls{
+				commit:  "https://scm.example.com/owner/repo/commit/abc123",
+				branch:  "https://scm.example.com/owner/repo/src/branch/glu/sync%20prod",
+				compare: "https://scm.example.com/owner/repo/compare/main...glu/sync%20prod",
+				file:    "https://scm.example.com/owner/repo/src/commit/abc123/env/prod%23a.yaml",
+			},
+		},
+		{
+			provider: links.ProviderBitbucket,
+			expected: urls{
+				commit:  "https://scm.example.com/owner/repo/commits/abc123",
+				branch:  "https://scm.example.com/owner/repo/branch/glu/sync%20prod",
+				compare: "https://scm.example.com/owner/repo/branches/compare/glu%2Fsync+prod%0Dmain",
+				file:    "https://scm.example.com/owner/repo/src/abc123/env/prod%23a.yaml",
+			},
+		},
+		{
+			provider: links.ProviderBitbucketDataCenter,
+			expected: urls{
+				commit:  "https://scm.example.com/projects/owner/repos/repo/commits/abc123",
+				branch:  "https://scm.example.com/projects/owner/repos/repo/browse?at=refs%2Fheads%2Fglu%2Fsync+prod",
+				compare: "https://scm.example.com/projects/owner/repos/repo/compare/diff?sourceBranch=refs%2Fheads%2Fglu%2Fsync+prod&targetBranch=refs%2Fheads%2Fmain",
+				file:    "https://scm.example.com/projects/owner/repos/repo/browse/env/prod%23a.yaml?at=abc123",
+			},
+		},
+	} {
+		t.Run(test.provider, func(t *testing.T) {
+			provider, err := links.NewBuiltin(test.provider, repo, links.Templates{})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected.commit, provider.CommitURL("abc123"))
+			assert.Equal(t, test.expected.branch, provider.BranchURL("glu/sync prod"))
+			assert.Equal(t, test.expected.compare, provider.CompareURL("main", "glu/sync prod"))
+			assert.Equal(t, test.expected.file, provider.FileURL("abc123", "/env/prod#a.yaml"))
+		})
+	}
+}
+
+func TestNewBuiltin_Overrides(t *testing.T) {
+	repo := links.Repository{
+		BaseURL: "https://github.example.com",
+		Path:    "owner/repo",
+		Owner:   "owner",
+		Name:    "repo",
+	}
+
+	for _, test := range []struct {
+		name      string
+		provider  string
+		overrides links.Templates
+		commit    string
+		branch    string
+		err       string
+	}{
+		{
+			name:     "no overrides",
+			provider: links.ProviderGitHub,
+			commit:   "https://github.example.com/owner/repo/commit/abc123",
+			branch:   "https://github.example.com/owner/repo/tree/main",
+		},
+		{
+			name:      "commit override",
+			provider:  links.ProviderGitHub,
+			overrides: links.Templates{Commit: "{{.BaseURL}}/c/{{.Name}}/{{pathescape .SHA}}"},
+			commit:    "https://github.example.com/c/repo/abc123",
+			branch:    "https://github.example.com/owner/repo/tree/main",
+		},
+		{
+			name:      "invalid override",
+			provider:  links.ProviderGitHub,
+			overrides: links.Templates{Branch: "{{.Branch"},
+			err:       "parsing branch template",
+		},
+		{
+			name:     "unknown provider",
+			provider: "unknown",
+			err:      `unknown link provider "unknown"`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			provider, err := links.NewBuiltin(test.provider, repo, test.overrides)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.commit, provider.CommitURL("abc123"))
+			assert.Equal(t, test.branch, provider.BranchURL("main"))
+		})
+	}
+}
+
+func TestNew_EmptyTemplates(t *testing.T) {
+	provider, err := links.New(links.Repository{BaseURL: "https://git.example.com"}, links.Templates{
+		Commit: "{{.BaseURL}}/commit/{{.SHA}}",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://git.example.com/commit/abc123", provider.CommitURL("abc123"))
+	// empty templates disable the respective links
+	assert.Empty(t, provider.BranchURL("main"))
+	assert.Empty(t, provider.CompareURL("main", "feature"))
+	assert.Empty(t, provider.FileURL("abc123", "file.txt"))
+}
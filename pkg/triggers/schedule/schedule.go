@@ -48,6 +48,11 @@ func (t *Trigger) Run(ctx context.Context, edge core.Edge) {
 					continue
 				}
 
+				if errors.Is(err, edges.ErrGated) {
+					slog.Debug("triggered edge", "reason", "Gated", "error", err)
+					continue
+				}
+
 				slog.Error("triggered edge", "error", err)
 			}
 		}
@@ -4,14 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
+	"maps"
 	"net/http"
 	"slices"
 	"strings"
 
+	"github.com/get-glu/glu/internal/webhooks"
 	"github.com/get-glu/glu/pkg/containers"
 	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/edges"
+	srcgit "github.com/get-glu/glu/pkg/phases/git"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -77,8 +83,11 @@ func (s *Server) setupRoutes() {
 			r.Get("/pipelines/{pipeline}", s.getPipeline)
 			r.Get("/pipelines/{pipeline}/phases/{phase}", s.getPhase)
 			r.Get("/pipelines/{pipeline}/phases/{phase}/history", s.phaseHistory)
+			r.Get("/pipelines/{pipeline}/phases/{phase}/proposal", s.phaseProposal)
 			r.Post("/pipelines/{pipeline}/from/{from}/to/{to}/perform", s.edgePerform)
 			r.Post("/pipelines/{pipeline}/phases/{phase}/rollback/{version}", s.phaseRollback)
+			r.Post("/webhooks/oci", s.ociWebhook)
+			r.Post("/webhooks/git", s.gitWebhook)
 		})
 	})
 }
@@ -102,8 +111,20 @@ type pipelineResponse struct {
 }
 
 type phaseResponse struct {
-	Descriptor core.Descriptor  `json:"descriptor,omitempty"`
-	Resource   resourceResponse `json:"resource,omitempty"`
+	Descriptor  core.Descriptor   `json:"descriptor,omitempty"`
+	Resource    resourceResponse  `json:"resource,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// annotatedPhase is a phase which exposes annotations describing
+// its own state (e.g. the open proposal of a git phase).
+type annotatedPhase interface {
+	Annotations() map[string]string
+}
+
+// proposingPhase is a phase which can report its current open proposal.
+type proposingPhase interface {
+	Proposal(context.Context) (*srcgit.Proposal, error)
 }
 
 type edgeResponse struct {
@@ -134,13 +155,19 @@ func (s *Server) createPhaseResponse(ctx context.Context, phase core.Phase) (pha
 		return phaseResponse{}, err
 	}
 
-	return phaseResponse{
+	response := phaseResponse{
 		Descriptor: phase.Descriptor(),
 		Resource: resourceResponse{
 			Digest:      digest,
 			Annotations: annotations,
 		},
-	}, nil
+	}
+
+	if a, ok := phase.(annotatedPhase); ok {
+		response.Annotations = a.Annotations()
+	}
+
+	return response, nil
 }
 
 func (s *Server) createPipelineResponse(ctx context.Context, pipeline *core.Pipeline) (pipelineResponse, error) {
@@ -323,6 +350,18 @@ func (s *Server) edgePerform(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, edges.ErrGated) {
+			slog.Debug("promotion gated", "error", err)
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+
+		if errors.Is(err, core.ErrConflict) {
+			slog.Debug("promotion conflicted", "error", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
 		slog.Error("performing promotion", "error", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -388,6 +427,55 @@ func (s *Server) phaseHistory(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) phaseProposal(w http.ResponseWriter, r *http.Request) {
+	slog := slog.With("path", r.URL.Path)
+
+	pipeline, err := s.system.GetPipeline(chi.URLParam(r, "pipeline"))
+	if err != nil {
+		slog.Debug("resource not found", "error", err)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	phaseName := chi.URLParam(r, "phase")
+	phase, err := pipeline.PhaseByName(phaseName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, core.ErrNotFound) {
+			slog.Debug("resource not found", "error", err)
+			status = http.StatusNotFound
+		}
+
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	proposing, ok := phase.(proposingPhase)
+	if !ok {
+		http.Error(w, "operation not permitted on phase kind", http.StatusBadRequest)
+		return
+	}
+
+	proposal, err := proposing.Proposal(r.Context())
+	if err != nil {
+		if errors.Is(err, srcgit.ErrProposalNotFound) {
+			slog.Debug("proposal not found", "error", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		slog.Error("getting phase proposal", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(proposal); err != nil {
+		slog.Error("encoding response", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (s *Server) phaseRollback(w http.ResponseWriter, r *http.Request) {
 	slog := slog.With("path", r.URL.Path)
 
@@ -437,3 +525,227 @@ func (s *Server) phaseRollback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// maxWebhookPayloadSize is the maximum size of a webhook request body read by the server.
+const maxWebhookPayloadSize = 5 << 20
+
+// webhookAuthStatus returns the status code for a webhook authentication error.
+// Receivers without a secret are reported as not found, given they are disabled.
+func webhookAuthStatus(err error) int {
+	if errors.Is(err, webhooks.ErrNotConfigured) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusUnauthorized
+}
+
+type webhookResponse struct {
+	Refreshed []string `json:"refreshed"`
+	Failed    []string `json:"failed,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// writeWebhookResponse reports the targets which were refreshed along with any which failed.
+// Failures of some targets are reported without failing the request, given senders often retry
+// the whole delivery on a server error. It responds 500 when every target failed to refresh.
+func writeWebhookResponse(w http.ResponseWriter, response webhookResponse, err error) {
+	slices.Sort(response.Refreshed)
+	slices.Sort(response.Failed)
+
+	status := http.StatusAccepted
+	if err != nil {
+		slog.Error("handling webhook", "error", err)
+
+		response.Error = err.Error()
+		if len(response.Refreshed) == 0 {
+			status = http.StatusInternalServerError
+		}
+	}
+
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "error", err)
+	}
+}
+
+// refreshablePhase is a phase which can be forced to refresh its state
+// from the source identified by its reference (e.g. an oci.Phase).
+type refreshablePhase interface {
+	core.Phase
+	Reference() string
+	Refresh(context.Context) error
+}
+
+func (s *Server) ociWebhook(w http.ResponseWriter, r *http.Request) {
+	slog := slog.With("path", r.URL.Path)
+
+	conf, err := s.system.Configuration()
+	if err != nil {
+		slog.Error("reading configuration", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := webhooks.Authenticate(r, body, conf.conf.Server.Webhooks.OCI.Secret); err != nil {
+		slog.Debug("authenticating webhook", "error", err)
+		http.Error(w, err.Error(), webhookAuthStatus(err))
+		return
+	}
+
+	events, err := webhooks.ParseImageEvents(body)
+	if err != nil {
+		slog.Debug("parsing webhook", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var (
+		response = webhookResponse{Refreshed: []string{}}
+		errs     []error
+	)
+
+	for _, pipeline := range s.system.Pipelines() {
+		for phase := range pipeline.Phases() {
+			rphase, ok := phase.(refreshablePhase)
+			if !ok {
+				continue
+			}
+
+			if !slices.ContainsFunc(events, func(e webhooks.ImageEvent) bool {
+				return e.Matches(rphase.Reference())
+			}) {
+				continue
+			}
+
+			desc := phase.Descriptor().String()
+
+			slog.Debug("refreshing phase", "phase", desc)
+
+			// continue refreshing the remaining phases, such that one
+			// failing phase does not prevent the others observing the push
+			if err := rphase.Refresh(r.Context()); err != nil {
+				response.Failed = append(response.Failed, desc)
+				errs = append(errs, fmt.Errorf("refreshing phase %q: %w", desc, err))
+				continue
+			}
+
+			response.Refreshed = append(response.Refreshed, desc)
+		}
+	}
+
+	writeWebhookResponse(w, response, errors.Join(errs...))
+}
+
+// mergingPhase is a phase which merges its open proposal once it is ready (e.g. a git.Phase).
+type mergingPhase interface {
+	core.Phase
+	Remote() string
+	MergeIfReady(context.Context) error
+}
+
+func (s *Server) gitWebhook(w http.ResponseWriter, r *http.Request) {
+	slog := slog.With("path", r.URL.Path)
+
+	conf, err := s.system.Configuration()
+	if err != nil {
+		slog.Error("reading configuration", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := webhooks.Authenticate(r, body, conf.conf.Server.Webhooks.Git.Secret); err != nil {
+		slog.Debug("authenticating webhook", "error", err)
+		http.Error(w, err.Error(), webhookAuthStatus(err))
+		return
+	}
+
+	events, err := webhooks.ParsePushEvents(body)
+	if err != nil {
+		slog.Debug("parsing webhook", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var (
+		response = webhookResponse{Refreshed: []string{}}
+		errs     []error
+		repos    = conf.gitRepositoriesMatching(func(remote string) bool {
+			return slices.ContainsFunc(events, func(e webhooks.PushEvent) bool {
+				return e.Matches(remote)
+			})
+		})
+	)
+
+	for _, name := range slices.Sorted(maps.Keys(repos)) {
+		repo := repos[name]
+		for _, event := range events {
+			if !event.Matches(repo.Remote().URLs[0]) || !repo.Tracks(event.Branch) {
+				continue
+			}
+
+			target := name + "@" + event.Branch
+
+			slog.Debug("fetching repository", "repository", name, "branch", event.Branch, "revision", event.Revision)
+
+			// continue fetching the remaining repositories, such that one
+			// failing fetch does not prevent the others observing the push
+			if err := repo.Fetch(r.Context(), event.Branch); err != nil {
+				response.Failed = append(response.Failed, target)
+				errs = append(errs, fmt.Errorf("fetching %q: %w", target, err))
+				continue
+			}
+
+			response.Refreshed = append(response.Refreshed, target)
+		}
+	}
+
+	proposalEvents, err := webhooks.ParseProposalEvents(body)
+	if err != nil {
+		slog.Debug("parsing webhook", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, pipeline := range s.system.Pipelines() {
+		for phase := range pipeline.Phases() {
+			mphase, ok := phase.(mergingPhase)
+			if !ok {
+				continue
+			}
+
+			if !slices.ContainsFunc(proposalEvents, func(e webhooks.ProposalEvent) bool {
+				return e.Matches(mphase.Remote())
+			}) {
+				continue
+			}
+
+			desc := phase.Descriptor().String()
+
+			slog.Debug("merging proposal if ready", "phase", desc)
+
+			// continue with the remaining phases, such that one failing
+			// merge does not prevent other ready proposals being merged
+			if err := mphase.MergeIfReady(r.Context()); err != nil {
+				response.Failed = append(response.Failed, desc)
+				errs = append(errs, fmt.Errorf("merging proposal of phase %q: %w", desc, err))
+				continue
+			}
+
+			response.Refreshed = append(response.Refreshed, desc)
+		}
+	}
+
+	writeWebhookResponse(w, response, errors.Join(errs...))
+}
@@ -0,0 +1,602 @@
+package glu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/get-glu/glu/internal/git"
+	"github.com/get-glu/glu/pkg/config"
+	"github.com/get-glu/glu/pkg/containers"
+	"github.com/get-glu/glu/pkg/core"
+	"github.com/get-glu/glu/pkg/edges"
+	gfs "github.com/get-glu/glu/pkg/fs"
+	"github.com/get-glu/glu/pkg/glutest"
+	srcgit "github.com/get-glu/glu/pkg/phases/git"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_EdgePerform(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "performed", status: http.StatusOK},
+		{name: "no change", err: core.ErrNoChange, status: http.StatusNoContent},
+		{name: "gated", err: fmt.Errorf("%w: source not ready", edges.ErrGated), status: http.StatusPreconditionFailed},
+		{name: "conflict", err: fmt.Errorf(`pushing "main": %w`, core.ErrConflict), status: http.StatusConflict},
+		{name: "failed", err: errors.New("push failed"), status: http.StatusInternalServerError},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pipeline := core.NewPipeline(Name("pipeline"))
+			require.NoError(t, pipeline.AddEdge(&stubEdge{from: "staging", to: "production", err: test.err}))
+
+			system := NewSystem(context.Background(), Name("system")).AddPipeline(pipeline)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pipelines/pipeline/from/staging/to/production/perform", nil)
+			rec := httptest.NewRecorder()
+
+			system.server.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+		})
+	}
+}
+
+func TestServer_OCIWebhook(t *testing.T) {
+	const payload = `{"events":[{"action":"push","target":{"repository":"team/app","tag":"v1"},"request":{"host":"registry.local"}}]}`
+
+	for _, test := range []struct {
+		name      string
+		secret    string
+		token     string
+		payload   string
+		phases    []*stubRefreshablePhase
+		status    int
+		refreshed []string
+		failed    []string
+		errMsg    string
+	}{
+		{
+			name:   "not configured",
+			token:  "secret",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "unauthorized",
+			secret: "secret",
+			token:  "other",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:    "invalid payload",
+			secret:  "secret",
+			token:   "secret",
+			payload: `{`,
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:      "no matching phase",
+			secret:    "secret",
+			token:     "secret",
+			phases:    []*stubRefreshablePhase{{stubPhase: stubPhase{name: "staging"}, reference: "registry.local/team/other:v1"}},
+			status:    http.StatusAccepted,
+			refreshed: []string{},
+		},
+		{
+			name:   "refreshed",
+			secret: "secret",
+			token:  "secret",
+			phases: []*stubRefreshablePhase{
+				{stubPhase: stubPhase{name: "staging"}, reference: "registry.local/team/app:v1"},
+				{stubPhase: stubPhase{name: "production"}, reference: "registry.local/team/app:v2"},
+				{stubPhase: stubPhase{name: "digest"}, reference: "registry.local/team/app@sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+			},
+			status:    http.StatusAccepted,
+			refreshed: []string{"pipeline/digest", "pipeline/staging"},
+		},
+		{
+			name:   "partially failed",
+			secret: "secret",
+			token:  "secret",
+			phases: []*stubRefreshablePhase{
+				{stubPhase: stubPhase{name: "staging"}, reference: "registry.local/team/app:v1", err: errors.New("registry unavailable")},
+				{stubPhase: stubPhase{name: "production"}, reference: "registry.local/team/app:v1"},
+			},
+			status:    http.StatusAccepted,
+			refreshed: []string{"pipeline/production"},
+			failed:    []string{"pipeline/staging"},
+			errMsg:    `refreshing phase "pipeline/staging": registry unavailable`,
+		},
+		{
+			name:   "failed",
+			secret: "secret",
+			token:  "secret",
+			phases: []*stubRefreshablePhase{
+				{stubPhase: stubPhase{name: "staging"}, reference: "registry.local/team/app:v1", err: errors.New("registry unavailable")},
+			},
+			status:    http.StatusInternalServerError,
+			refreshed: []string{},
+			failed:    []string{"pipeline/staging"},
+			errMsg:    `refreshing phase "pipeline/staging": registry unavailable`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pipeline := core.NewPipeline(Name("pipeline"))
+			for _, phase := range test.phases {
+				require.NoError(t, pipeline.AddPhase(phase))
+			}
+
+			system := newTestSystem(t, &config.Config{
+				Server: config.Server{Webhooks: config.Webhooks{OCI: config.Webhook{Secret: test.secret}}},
+			}, pipeline)
+
+			body := payload
+			if test.payload != "" {
+				body = test.payload
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/oci", strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+test.token)
+			rec := httptest.NewRecorder()
+
+			system.server.ServeHTTP(rec, req)
+
+			require.Equal(t, test.status, rec.Code, rec.Body.String())
+			if test.refreshed == nil {
+				return
+			}
+
+			var response webhookResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, test.refreshed, response.Refreshed)
+			assert.Equal(t, test.failed, response.Failed)
+			assert.Equal(t, test.errMsg, response.Error)
+
+			// every matching phase is refreshed regardless of earlier failures
+			matched := slices.Concat(test.refreshed, test.failed)
+			for _, phase := range test.phases {
+				assert.Equal(t, slices.Contains(matched, "pipeline/"+phase.name), phase.refreshed, phase.name)
+			}
+		})
+	}
+}
+
+func TestServer_GitWebhook(t *testing.T) {
+	secret := config.Webhooks{Git: config.GitWebhook{Secret: "secret"}}
+
+	// pushEvent commits to the remote and returns a push event for the commit
+	// which identifies the repository by both of the provided URLs
+	pushEvent := func(t *testing.T, remote *glutest.Remote, cloneURL, sshURL string) string {
+		t.Helper()
+
+		require.NoError(t, remote.Commit("main", "update", map[string]string{"value.txt": uuid.NewString()}))
+
+		head, err := remote.Head("main")
+		require.NoError(t, err)
+
+		return fmt.Sprintf(`{"ref":"refs/heads/main","after":%q,"repository":{"clone_url":%q,"ssh_url":%q}}`, head, cloneURL, sshURL)
+	}
+
+	for _, test := range []struct {
+		name     string
+		webhooks config.Webhooks
+		token    string
+		// payload returns the webhook payload given the remotes of the configured
+		// repositories, of which the broken remote is unavailable once built
+		payload   func(t *testing.T, remotes map[string]*glutest.Remote) string
+		status    int
+		refreshed []string
+		failed    []string
+	}{
+		{
+			name:    "not configured",
+			token:   "secret",
+			payload: func(*testing.T, map[string]*glutest.Remote) string { return `{}` },
+			status:  http.StatusNotFound,
+		},
+		{
+			name:     "unauthorized",
+			webhooks: secret,
+			token:    "other",
+			payload:  func(*testing.T, map[string]*glutest.Remote) string { return `{}` },
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "no matching repository",
+			webhooks: secret,
+			token:    "secret",
+			payload: func(*testing.T, map[string]*glutest.Remote) string {
+				return `{"ref":"refs/heads/main","after":"abc","repository":{"clone_url":"https://github.com/get-glu/other.git"}}`
+			},
+			status:    http.StatusAccepted,
+			refreshed: []string{},
+		},
+		{
+			name:     "untracked branch",
+			webhooks: secret,
+			token:    "secret",
+			payload: func(_ *testing.T, remotes map[string]*glutest.Remote) string {
+				return fmt.Sprintf(`{"ref":"refs/heads/feature","after":"abc","repository":{"clone_url":%q}}`, remotes["staging"].URL())
+			},
+			status:    http.StatusAccepted,
+			refreshed: []string{},
+		},
+		{
+			name:     "fetched",
+			webhooks: secret,
+			token:    "secret",
+			payload: func(t *testing.T, remotes map[string]*glutest.Remote) string {
+				return pushEvent(t, remotes["staging"], remotes["staging"].URL(), remotes["production"].URL())
+			},
+			status:    http.StatusAccepted,
+			refreshed: []string{"production@main", "staging@main"},
+		},
+		{
+			name:     "partially failed",
+			webhooks: secret,
+			token:    "secret",
+			payload: func(t *testing.T, remotes map[string]*glutest.Remote) string {
+				return pushEvent(t, remotes["production"], remotes["production"].URL(), remotes["broken"].URL())
+			},
+			status:    http.StatusAccepted,
+			refreshed: []string{"production@main"},
+			failed:    []string{"broken@main"},
+		},
+		{
+			name:     "failed",
+			webhooks: secret,
+			token:    "secret",
+			payload: func(_ *testing.T, remotes map[string]*glutest.Remote) string {
+				return fmt.Sprintf(`{"ref":"refs/heads/main","after":"abc","repository":{"clone_url":%q}}`, remotes["broken"].URL())
+			},
+			status:    http.StatusInternalServerError,
+			refreshed: []string{},
+			failed:    []string{"broken@main"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &config.Config{
+				Credentials: config.Credentials{
+					"basic": {Type: config.CredentialTypeBasic, Basic: &config.BasicAuthConfig{Username: "glu", Password: "glu"}},
+				},
+				Sources: config.Sources{Git: config.GitSources{}},
+				Server:  config.Server{Webhooks: test.webhooks},
+			}
+
+			remotes := map[string]*glutest.Remote{}
+			for _, name := range []string{"staging", "production", "broken"} {
+				remote, err := glutest.NewRemote(map[string]string{"value.txt": "one"})
+				require.NoError(t, err)
+
+				t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+				remotes[name] = remote
+				conf.Sources.Git[name] = &config.GitRepository{
+					DefaultBranch: "main",
+					Remote:        &config.Remote{Name: "origin", URL: remote.URL(), Credential: "basic"},
+				}
+			}
+
+			system := newTestSystem(t, conf)
+
+			repos := map[string]*git.Repository{}
+			for name := range remotes {
+				repo, _, err := system.conf.GitRepository(name)
+				require.NoError(t, err)
+
+				t.Cleanup(func() { require.NoError(t, repo.Close()) })
+
+				repos[name] = repo
+			}
+
+			// the broken remote is no longer served, such that fetches from it fail
+			require.NoError(t, remotes["broken"].Close())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/git", strings.NewReader(test.payload(t, remotes)))
+			req.Header.Set("Authorization", test.token)
+			rec := httptest.NewRecorder()
+
+			system.server.ServeHTTP(rec, req)
+
+			require.Equal(t, test.status, rec.Code, rec.Body.String())
+			if test.refreshed == nil {
+				return
+			}
+
+			var response webhookResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, test.refreshed, response.Refreshed)
+			assert.Equal(t, test.failed, response.Failed)
+
+			// fetched repositories observe the pushed revision
+			for _, target := range response.Refreshed {
+				name, branch, _ := strings.Cut(target, "@")
+
+				expected, err := remotes[name].Head(branch)
+				require.NoError(t, err)
+
+				head, err := repos[name].Resolve(branch)
+				require.NoError(t, err)
+				assert.Equal(t, expected, head.String())
+			}
+		})
+	}
+}
+
+func TestServer_GitWebhook_Merge(t *testing.T) {
+	const payload = `{"action":"submitted","pull_request":{"number":1},"repository":{"clone_url":"https://github.com/get-glu/app.git"}}`
+
+	for _, test := range []struct {
+		name      string
+		phases    []*stubMergingPhase
+		status    int
+		refreshed []string
+		failed    []string
+	}{
+		{
+			name: "merged",
+			phases: []*stubMergingPhase{
+				{stubPhase: stubPhase{name: "staging"}, remote: "git@github.com:get-glu/app.git"},
+				{stubPhase: stubPhase{name: "production"}, remote: "https://github.com/get-glu/app"},
+				{stubPhase: stubPhase{name: "other"}, remote: "https://github.com/get-glu/other.git"},
+			},
+			status:    http.StatusAccepted,
+			refreshed: []string{"pipeline/production", "pipeline/staging"},
+		},
+		{
+			name: "partially failed",
+			phases: []*stubMergingPhase{
+				{stubPhase: stubPhase{name: "staging"}, remote: "https://github.com/get-glu/app.git", err: errors.New("merge conflict")},
+				{stubPhase: stubPhase{name: "production"}, remote: "https://github.com/get-glu/app.git"},
+			},
+			status:    http.StatusAccepted,
+			refreshed: []string{"pipeline/production"},
+			failed:    []string{"pipeline/staging"},
+		},
+		{
+			name: "failed",
+			phases: []*stubMergingPhase{
+				{stubPhase: stubPhase{name: "staging"}, remote: "https://github.com/get-glu/app.git", err: errors.New("merge conflict")},
+				{stubPhase: stubPhase{name: "production"}, remote: "https://github.com/get-glu/app.git", err: errors.New("merge conflict")},
+			},
+			status:    http.StatusInternalServerError,
+			refreshed: []string{},
+			failed:    []string{"pipeline/production", "pipeline/staging"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pipeline := core.NewPipeline(Name("pipeline"))
+			for _, phase := range test.phases {
+				require.NoError(t, pipeline.AddPhase(phase))
+			}
+
+			system := newTestSystem(t, &config.Config{
+				Server: config.Server{Webhooks: config.Webhooks{Git: config.GitWebhook{Secret: "secret"}}},
+			}, pipeline)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/git", strings.NewReader(payload))
+			req.Header.Set("Authorization", "secret")
+			rec := httptest.NewRecorder()
+
+			system.server.ServeHTTP(rec, req)
+
+			require.Equal(t, test.status, rec.Code, rec.Body.String())
+
+			var response webhookResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, test.refreshed, response.Refreshed)
+			assert.Equal(t, test.failed, response.Failed)
+
+			// merges are attempted for every matching phase regardless of earlier failures
+			attempted := slices.Concat(test.refreshed, test.failed)
+			for _, phase := range test.phases {
+				assert.Equal(t, slices.Contains(attempted, "pipeline/"+phase.name), phase.attempted, phase.name)
+			}
+		})
+	}
+}
+
+func TestServer_PhaseProposal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	remote, err := glutest.NewRemote(map[string]string{"value.txt": "one"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, remote.Close()) })
+
+	var (
+		proposer = glutest.NewProposer(remote)
+		pipeline = core.NewPipeline(Name("pipeline"))
+		newPhase = func(name string) *srcgit.Phase[*testValue] {
+			phase, err := glutest.NewGitPhase(ctx, remote, "pipeline", Name(name), func() *testValue { return &testValue{} }, proposer,
+				srcgit.ProposeChanges[*testValue](srcgit.ProposalOption{}))
+			require.NoError(t, err)
+
+			require.NoError(t, pipeline.AddPhase(phase))
+
+			return phase
+		}
+		staging = newPhase("staging")
+	)
+
+	// only the staging phase has an open proposal
+	newPhase("production")
+	require.NoError(t, pipeline.AddPhase(&stubPhase{name: "stub"}))
+
+	proposer.SetStatus(srcgit.ProposalStatus{
+		Checks:    []srcgit.Check{{Name: "build", State: srcgit.CheckStateSuccess}},
+		Approvals: 1,
+		Mergeable: true,
+	})
+
+	_, err = staging.Update(ctx, &testValue{"two"})
+	require.NoError(t, err)
+
+	system := newTestSystem(t, &config.Config{}, pipeline)
+
+	for _, test := range []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "proposal", path: "/api/v1/pipelines/pipeline/phases/staging/proposal", status: http.StatusOK},
+		{name: "no proposal", path: "/api/v1/pipelines/pipeline/phases/production/proposal", status: http.StatusNotFound},
+		{name: "not a git phase", path: "/api/v1/pipelines/pipeline/phases/stub/proposal", status: http.StatusBadRequest},
+		{name: "phase not found", path: "/api/v1/pipelines/pipeline/phases/missing/proposal", status: http.StatusNotFound},
+		{name: "pipeline not found", path: "/api/v1/pipelines/missing/phases/staging/proposal", status: http.StatusNotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.path, nil)
+			rec := httptest.NewRecorder()
+
+			system.server.ServeHTTP(rec, req)
+
+			require.Equal(t, test.status, rec.Code, rec.Body.String())
+			if test.status != http.StatusOK {
+				return
+			}
+
+			var proposal srcgit.Proposal
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&proposal))
+
+			assert.Equal(t, "1", proposal.ID)
+			assert.Equal(t, "main", proposal.BaseBranch)
+			assert.Equal(t, "two", proposal.Digest)
+			assert.Equal(t, &srcgit.ProposalStatus{
+				Checks:    []srcgit.Check{{Name: "build", State: srcgit.CheckStateSuccess}},
+				Approvals: 1,
+				Mergeable: true,
+			}, proposal.Status)
+			assert.Equal(t, "success", proposal.Annotations[srcgit.AnnotationProposalChecksKey])
+			assert.Equal(t, "approved", proposal.Annotations[srcgit.AnnotationProposalReviewKey])
+		})
+	}
+}
+
+// newTestSystem constructs a system with the provided configuration and pipelines.
+func newTestSystem(t *testing.T, conf *config.Config, pipelines ...*core.Pipeline) *System {
+	t.Helper()
+
+	system := NewSystem(context.Background(), Name("system"))
+	system.conf = newConfigSource(system.ctx, conf)
+
+	for _, pipeline := range pipelines {
+		system.AddPipeline(pipeline)
+	}
+
+	return system
+}
+
+type stubPhase struct {
+	name string
+}
+
+func (p *stubPhase) Descriptor() core.Descriptor {
+	return core.Descriptor{Kind: "stub", Pipeline: "pipeline", Metadata: Name(p.name)}
+}
+
+func (p *stubPhase) Get(context.Context) (core.Resource, error) { return nil, core.ErrNotFound }
+
+func (p *stubPhase) History(context.Context, ...containers.Option[core.HistoryOptions]) ([]core.State, error) {
+	return nil, nil
+}
+
+type stubRefreshablePhase struct {
+	stubPhase
+	reference string
+	err       error
+	refreshed bool
+}
+
+func (p *stubRefreshablePhase) Reference() string { return p.reference }
+
+func (p *stubRefreshablePhase) Refresh(context.Context) error {
+	p.refreshed = true
+	return p.err
+}
+
+type stubMergingPhase struct {
+	stubPhase
+	remote    string
+	err       error
+	attempted bool
+}
+
+func (p *stubMergingPhase) Remote() string { return p.remote }
+
+func (p *stubMergingPhase) MergeIfReady(context.Context) error {
+	p.attempted = true
+	return p.err
+}
+
+// testValue is a git resource whose value is stored in value.txt.
+type testValue struct {
+	Value string
+}
+
+func (v *testValue) Digest() (string, error) {
+	return v.Value, nil
+}
+
+func (v *testValue) ReadFrom(_ context.Context, _ core.Descriptor, fs gfs.Filesystem) error {
+	data, err := iofs.ReadFile(gfs.ToFS(fs), "value.txt")
+	if err != nil {
+		return err
+	}
+
+	v.Value = string(data)
+
+	return nil
+}
+
+func (v *testValue) WriteTo(_ context.Context, _ core.Descriptor, fs gfs.Filesystem) error {
+	fi, err := fs.OpenFile("value.txt", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(fi, v.Value); err != nil {
+		return err
+	}
+
+	return fi.Close()
+}
+
+type stubEdge struct {
+	from, to string
+	err      error
+}
+
+func (e *stubEdge) Kind() string { return "stub" }
+
+func (e *stubEdge) From() core.Descriptor {
+	return core.Descriptor{Kind: "stub", Pipeline: "pipeline", Metadata: Name(e.from)}
+}
+
+func (e *stubEdge) To() core.Descriptor {
+	return core.Descriptor{Kind: "stub", Pipeline: "pipeline", Metadata: Name(e.to)}
+}
+
+func (e *stubEdge) Perform(context.Context) (*core.Result, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return &core.Result{}, nil
+}
+
+func (e *stubEdge) CanPerform(context.Context) (bool, error) { return true, nil }
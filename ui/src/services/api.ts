@@ -1,4 +1,4 @@
-import { Phase, Pipeline, Result, State } from '@/types/pipeline';
+import { Phase, Pipeline, Proposal, Result, State } from '@/types/pipeline';
 import { System } from '@/types/system';
 import { createApi, fetchBaseQuery } from '@reduxjs/toolkit/query/react';
 
@@ -20,6 +20,9 @@ export const api = createApi({
     getPhaseHistory: builder.query<State[], { pipeline: string; phase: string }>({
       query: ({ pipeline, phase }) => `/pipelines/${pipeline}/phases/${phase}/history`
     }),
+    getPhaseProposal: builder.query<Proposal, { pipeline: string; phase: string }>({
+      query: ({ pipeline, phase }) => `/pipelines/${pipeline}/phases/${phase}/proposal`
+    }),
     rollbackPhase: builder.mutation<Result, { pipeline: string; phase: string; version: string }>({
       query: ({ pipeline, phase, version }) => ({
         url: `/pipelines/${pipeline}/phases/${phase}/rollback/${version}`,
@@ -41,6 +44,7 @@ export const {
   useGetPipelineQuery,
   useGetPhaseQuery,
   useGetPhaseHistoryQuery,
+  useGetPhaseProposalQuery,
   useRollbackPhaseMutation,
   useEdgePerformMutation
 } = api;
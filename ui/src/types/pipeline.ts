@@ -12,6 +12,33 @@ export interface Pipeline {
 export interface Phase {
   descriptor: Descriptor;
   resource: Resource;
+  annotations?: Record<string, string>;
+}
+
+export interface Check {
+  name: string;
+  state: 'pending' | 'success' | 'failure';
+}
+
+export interface ProposalStatus {
+  checks?: Check[];
+  approvals: number;
+  changes_requested: boolean;
+  mergeable: boolean;
+}
+
+export interface Proposal {
+  id: string;
+  url: string;
+  base_revision: string;
+  base_branch: string;
+  branch: string;
+  head_revision: string;
+  digest: string;
+  title?: string;
+  body?: string;
+  status?: ProposalStatus;
+  annotations?: Record<string, string>;
 }
 
 export interface Edge {